@@ -0,0 +1,30 @@
+package cluster
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// unixSocketScheme is a prefix of the address indicating a path of Unix domain socket.
+const unixSocketScheme = "unix://"
+
+// Listen announces on given address. The address can be either a TCP address (host:port),
+// or a path of Unix domain socket with "unix://" prefix (e.g. unix:///var/run/lrmr.sock).
+func Listen(address string) (net.Listener, error) {
+	return net.Listen(splitNetwork(address))
+}
+
+// dial connects to given address, which can be either a TCP address or a Unix domain socket.
+func dial(ctx context.Context, address string) (net.Conn, error) {
+	network, addr := splitNetwork(address)
+	var d net.Dialer
+	return d.DialContext(ctx, network, addr)
+}
+
+func splitNetwork(address string) (network, addr string) {
+	if strings.HasPrefix(address, unixSocketScheme) {
+		return "unix", strings.TrimPrefix(address, unixSocketScheme)
+	}
+	return "tcp", address
+}
@@ -3,23 +3,34 @@ package cluster
 import (
 	"context"
 	"path"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ab180/lrmr/cluster/node"
 	"github.com/ab180/lrmr/coordinator"
 	"github.com/airbloc/logger"
 	"github.com/pkg/errors"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/atomic"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/status"
 )
 
 var log = logger.New("lrmr.cluster")
 
-const nodeNs = "nodes"
+const (
+	nodeNs         = "nodes"
+	drainingNodeNs = "draining/nodes"
+	nodeLoadNs     = "loads/nodes"
+)
+
+// unregisterTimeout is the maximum duration to remove the node info on unregistering.
+const unregisterTimeout = 5 * time.Second
 
 // ErrNotFound is returned when an node with given host is not found.
 var ErrNotFound = errors.New("node not found")
@@ -44,6 +55,20 @@ type Cluster interface {
 	// It returns ErrNotFound if node with given host does not exist.
 	Get(ctx context.Context, host string) (*node.Node, error)
 
+	// WatchNodes sends events of nodes with given type joining or leaving the cluster, until the context is cancelled.
+	// An empty type watches every node.
+	WatchNodes(ctx context.Context, typ node.Type) <-chan NodeEvent
+
+	// Drain marks the node with given host as draining. Draining nodes are excluded from List,
+	// so that no more tasks are scheduled to them. Tasks already running on the node are rescheduled by the master
+	// (see master.Master.Drain), not by the cluster.
+	// The mark is cleared when the node unregisters or registers again, or expires after Options.DrainTTL.
+	Drain(ctx context.Context, host string) error
+
+	// Loads returns the latest loads published by the nodes (see node.Registration.ReportLoad), by their hosts.
+	// Autoscalers can also watch the keys under "loads/nodes/" in the coordinator.
+	Loads(ctx context.Context) (map[string]node.Load, error)
+
 	// States returns a cluster-wide state.
 	States() State
 
@@ -60,6 +85,10 @@ type cluster struct {
 	grpcConns    map[string]*grpc.ClientConn
 	grpcConnsMu  sync.Mutex
 	options      Options
+
+	// dialLocks are locks of each host, so that only one connection is dialed to a host at once
+	// while other hosts can be dialed concurrently.
+	dialLocks sync.Map
 }
 
 func OpenRemote(clusterState coordinator.Coordinator, opt Options) (Cluster, error) {
@@ -74,7 +103,25 @@ func OpenRemote(clusterState coordinator.Coordinator, opt Options) (Cluster, err
 		// log.Warn("inter-node RPC is in insecure mode. we recommend configuring TLS credentials.")
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
 	}
-	grpcOpts = append(grpcOpts, grpc.WithBlock())
+	grpcOpts = append(grpcOpts, grpc.WithBlock(), grpc.WithContextDialer(dial))
+
+	var callOpts []grpc.CallOption
+	if opt.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(opt.MaxRecvMsgSize))
+	}
+	if opt.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(opt.MaxSendMsgSize))
+	}
+	if len(callOpts) > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+	if opt.KeepaliveInterval > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                opt.KeepaliveInterval,
+			Timeout:             opt.KeepaliveTimeout,
+			PermitWithoutStream: opt.KeepalivePermitWithoutStream,
+		}))
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	return &cluster{
@@ -89,6 +136,10 @@ func OpenRemote(clusterState coordinator.Coordinator, opt Options) (Cluster, err
 
 // Register registers node to the coordinator and makes it discoverable.
 // registration will be automatically deleted if cluster's context is cancelled.
+//
+// It returns as soon as the context is done, even if the coordinator does not honor the context.
+// The liveness of the node is probed only after the registration succeeds; a registration which
+// is completed after returning is never probed, and expires with its lease.
 func (c *cluster) Register(ctx context.Context, n *node.Node) (node.Registration, error) {
 	nodeCtx, cancel := context.WithCancel(c.ctx)
 	nodeReg := &nodeRegistration{
@@ -98,19 +149,39 @@ func (c *cluster) Register(ctx context.Context, n *node.Node) (node.Registration
 		node:    n,
 	}
 
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- c.register(ctx, nodeReg)
+	}()
+	select {
+	case err := <-errChan:
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	case <-ctx.Done():
+		cancel()
+		return nil, errors.Wrap(ctx.Err(), "register node")
+	}
+	go c.sendPeriodicLivenessProbe(nodeReg)
+	log.Verbose("{} node registered as {}", n.Type, n.Host)
+	return nodeReg, nil
+}
+
+// register puts the node info with a new liveness lease.
+func (c *cluster) register(ctx context.Context, reg *nodeRegistration) error {
 	lease, err := c.clusterState.GrantLease(ctx, c.options.LivenessProbeInterval)
 	if err != nil {
-		return nil, errors.Wrap(err, "grant TTL")
+		return errors.Wrap(err, "grant TTL")
 	}
-	if err := c.clusterState.KeepAlive(c.ctx, lease); err != nil {
-		return nil, errors.Wrap(err, "start liveness prove")
+	txn := coordinator.NewTxn().
+		Put(path.Join(nodeNs, reg.node.Host), reg.node).
+		DeleteKey(path.Join(drainingNodeNs, reg.node.Host))
+	if _, err := c.clusterState.Commit(ctx, txn, coordinator.WithLease(lease)); err != nil {
+		return errors.Wrap(err, "register node info")
 	}
-	nodeReg.livenessLease = lease
-	if err := c.clusterState.Put(ctx, path.Join(nodeNs, n.Host), n, coordinator.WithLease(lease)); err != nil {
-		return nil, errors.Wrap(err, "register node info")
-	}
-	log.Verbose("{} node registered as {}", n.Type, n.Host)
-	return nodeReg, nil
+	reg.livenessLease.Store(int64(lease))
+	return nil
 }
 
 // Connect tries to connect the host and returns gRPC connection.
@@ -119,16 +190,22 @@ func (c *cluster) Connect(ctx context.Context, host string) (*grpc.ClientConn, e
 	dialCtx, cancel := context.WithTimeout(ctx, c.options.ConnectTimeout)
 	defer cancel()
 
-	c.grpcConnsMu.Lock()
-	defer c.grpcConnsMu.Unlock()
+	l, _ := c.dialLocks.LoadOrStore(host, new(sync.Mutex))
+	dialLock := l.(*sync.Mutex)
+	dialLock.Lock()
+	defer dialLock.Unlock()
 
+	c.grpcConnsMu.Lock()
 	conn, ok := c.grpcConns[host]
+	c.grpcConnsMu.Unlock()
 	if !ok {
 		return c.establishNewConnection(dialCtx, host)
 	}
 	if conn.GetState() == connectivity.TransientFailure {
 		// TODO: retry limit
+		c.grpcConnsMu.Lock()
 		delete(c.grpcConns, host)
+		c.grpcConnsMu.Unlock()
 		return c.establishNewConnection(dialCtx, host)
 	}
 	return conn, nil
@@ -137,13 +214,15 @@ func (c *cluster) Connect(ctx context.Context, host string) (*grpc.ClientConn, e
 // establishNewConnection creates a new connection to given host. the context is only used for
 // dialing the host, and cancelling the context after the method return does not affect the connection.
 //
-// this method is not race-protected; you need to acquire lock before calling the method.
+// this method is not race-protected; you need to acquire the dial lock of the host before calling the method.
 func (c *cluster) establishNewConnection(ctx context.Context, host string) (*grpc.ClientConn, error) {
 	conn, err := grpc.DialContext(ctx, host, c.grpcOptions...)
 	if err != nil {
 		return nil, err
 	}
+	c.grpcConnsMu.Lock()
 	c.grpcConns[host] = conn
+	c.grpcConnsMu.Unlock()
 	return conn, nil
 }
 
@@ -157,6 +236,18 @@ func (c *cluster) List(ctx context.Context, option ...ListOption) ([]*node.Node,
 	if err != nil {
 		return nil, errors.Wrap(err, "scan etcd")
 	}
+	drainingItems, err := c.clusterState.Scan(ctx, drainingNodeNs)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan etcd")
+	}
+	draining := make(map[string]bool, len(drainingItems))
+	for _, item := range drainingItems {
+		var host string
+		if err := item.Unmarshal(&host); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal item %s", item.Key)
+		}
+		draining[host] = true
+	}
 
 	var nodes []*node.Node
 	for _, item := range items {
@@ -170,6 +261,9 @@ func (c *cluster) List(ctx context.Context, option ...ListOption) ([]*node.Node,
 		if opt.Tag != nil && !n.TagMatches(opt.Tag) {
 			continue
 		}
+		if draining[n.Host] {
+			continue
+		}
 		nodes = append(nodes, n)
 	}
 	return nodes, nil
@@ -179,7 +273,7 @@ func (c *cluster) List(ctx context.Context, option ...ListOption) ([]*node.Node,
 // It returns cluster.ErrNotFound if node with given host does not exist.
 func (c *cluster) Get(ctx context.Context, host string) (*node.Node, error) {
 	n := new(node.Node)
-	if err := c.clusterState.Get(ctx, path.Join(nodeNs, n.Host), n); err != nil {
+	if err := c.clusterState.Get(ctx, path.Join(nodeNs, host), n); err != nil {
 		if err == coordinator.ErrNotFound {
 			return nil, ErrNotFound
 		}
@@ -188,6 +282,40 @@ func (c *cluster) Get(ctx context.Context, host string) (*node.Node, error) {
 	return n, nil
 }
 
+// Loads returns the latest loads published by the nodes, by their hosts.
+func (c *cluster) Loads(ctx context.Context) (map[string]node.Load, error) {
+	items, err := c.clusterState.Scan(ctx, nodeLoadNs)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan etcd")
+	}
+	loads := make(map[string]node.Load, len(items))
+	for _, item := range items {
+		var load node.Load
+		if err := item.Unmarshal(&load); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal item %s", item.Key)
+		}
+		loads[strings.TrimPrefix(item.Key, nodeLoadNs+"/")] = load
+	}
+	return loads, nil
+}
+
+// Drain marks the node with given host as draining, excluding the node from List.
+func (c *cluster) Drain(ctx context.Context, host string) error {
+	var opts []coordinator.WriteOption
+	if c.options.DrainTTL > 0 {
+		lease, err := c.clusterState.GrantLease(ctx, c.options.DrainTTL)
+		if err != nil {
+			return errors.Wrap(err, "grant TTL")
+		}
+		opts = append(opts, coordinator.WithLease(lease))
+	}
+	if err := c.clusterState.Put(ctx, path.Join(drainingNodeNs, host), host, opts...); err != nil {
+		return errors.Wrapf(err, "mark %s as draining", host)
+	}
+	log.Info("Node {} is draining", host)
+	return nil
+}
+
 // States returns a cluster-wide state.
 func (c *cluster) States() State {
 	return c.clusterState
@@ -212,24 +340,48 @@ func (c *cluster) Close() (err error) {
 
 // nodeRegistration implements node.Registration.
 type nodeRegistration struct {
-	ctx           context.Context
-	cancel        context.CancelFunc
-	cluster       Cluster
-	node          *node.Node
-	livenessLease clientv3.LeaseID
+	ctx     context.Context
+	cancel  context.CancelFunc
+	cluster Cluster
+	node    *node.Node
+
+	// livenessLease can be renewed if the lease expires, while the coordinator is unavailable.
+	livenessLease atomic.Int64
+
+	// loadReporter is a func() node.Load set by ReportLoad.
+	loadReporter atomic.Value
 }
 
 // Info returns a node's information.
-func (n nodeRegistration) Info() *node.Node {
+func (n *nodeRegistration) Info() *node.Node {
 	return n.node
 }
 
 // States returns an NodeState, which is ephemeral.
-func (n nodeRegistration) States() node.State {
-	return n.cluster.States().WithOptions(coordinator.WithLease(n.livenessLease))
+func (n *nodeRegistration) States() node.State {
+	return n.cluster.States().WithOptions(coordinator.WithLease(n.lease()))
+}
+
+func (n *nodeRegistration) lease() clientv3.LeaseID {
+	return clientv3.LeaseID(n.livenessLease.Load())
+}
+
+// ReportLoad publishes the load returned by fn on each liveness probe.
+func (n *nodeRegistration) ReportLoad(fn func() node.Load) {
+	n.loadReporter.Store(fn)
 }
 
 // Unregister removes node from the cluster's node list, and clears all NodeState.
-func (n nodeRegistration) Unregister() {
+func (n *nodeRegistration) Unregister() {
 	n.cancel()
+
+	// the node info is removed right away, rather than waiting for the lease to expire
+	ctx, cancel := context.WithTimeout(context.Background(), unregisterTimeout)
+	defer cancel()
+	if _, err := n.cluster.States().Commit(ctx, coordinator.NewTxn().
+		Delete(path.Join(nodeNs, n.node.Host)).
+		Delete(path.Join(nodeLoadNs, n.node.Host)).
+		DeleteKey(path.Join(drainingNodeNs, n.node.Host))); err != nil {
+		log.Warn("Failed to remove node info of {}: {}", n.node.Host, err)
+	}
 }
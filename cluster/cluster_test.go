@@ -4,13 +4,18 @@ import (
 	"context"
 	"net"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ab180/lrmr/cluster"
 	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
 	"github.com/ab180/lrmr/test/integration"
+	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/atomic"
 	"go.uber.org/goleak"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
@@ -77,6 +82,293 @@ func TestCluster_Register(t *testing.T) {
 	}))
 }
 
+func TestCluster_Drain(t *testing.T) {
+	Convey("Given a cluster", t, WithCluster(func(ctx context.Context, c cluster.Cluster) {
+		Convey("When a registered node is drained", func() {
+			nr, err := c.Register(ctx, &node.Node{
+				Host: "test",
+				Type: node.Worker,
+			})
+			So(err, ShouldBeNil)
+			So(c.Drain(ctx, "test"), ShouldBeNil)
+
+			Convey("It should be excluded from the list", func() {
+				listedNodes, err := c.List(ctx)
+				So(err, ShouldBeNil)
+				So(listedNodes, ShouldBeEmpty)
+				nr.Unregister()
+			})
+
+			Convey("The mark should be cleared after unregister", func() {
+				nr.Unregister()
+
+				marks, err := c.States().Scan(ctx, "draining/nodes")
+				So(err, ShouldBeNil)
+				So(marks, ShouldBeEmpty)
+			})
+		})
+	}))
+
+	Convey("Given a cluster with a TTL of drain marks", t, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+		defer cancel()
+
+		opt := cluster.DefaultOptions()
+		opt.LivenessProbeInterval = tick
+		opt.DrainTTL = 2 * tick
+		c, err := cluster.OpenRemote(coordinator.NewLocalMemory(), opt)
+		So(err, ShouldBeNil)
+		defer c.Close()
+
+		nr, err := c.Register(ctx, &node.Node{
+			Host: "test",
+			Type: node.Worker,
+		})
+		So(err, ShouldBeNil)
+		defer nr.Unregister()
+
+		Convey("A drained node should be listed again after the TTL", func() {
+			So(c.Drain(ctx, "test"), ShouldBeNil)
+			listedNodes, err := c.List(ctx)
+			So(err, ShouldBeNil)
+			So(listedNodes, ShouldBeEmpty)
+
+			time.Sleep(3 * tick)
+			listedNodes, err = c.List(ctx)
+			So(err, ShouldBeNil)
+			So(listedNodes, ShouldHaveLength, 1)
+		})
+	})
+}
+
+func TestCluster_Loads(t *testing.T) {
+	Convey("Given a cluster", t, WithCluster(func(ctx context.Context, c cluster.Cluster) {
+		Convey("When a registered node reports its load", func() {
+			nr, err := c.Register(ctx, &node.Node{
+				Host: "test",
+				Type: node.Worker,
+			})
+			So(err, ShouldBeNil)
+			nr.ReportLoad(func() node.Load {
+				return node.Load{RunningTasks: 3, QueuedTasks: 1}
+			})
+			time.Sleep(2 * tick)
+
+			Convey("The load should be published along the liveness probes", func() {
+				loads, err := c.Loads(ctx)
+				So(err, ShouldBeNil)
+				So(loads, ShouldContainKey, "test")
+				So(loads["test"].RunningTasks, ShouldEqual, 3)
+				So(loads["test"].QueuedTasks, ShouldEqual, 1)
+				So(loads["test"].UpdatedAt, ShouldHappenWithin, 2*tick, time.Now())
+				nr.Unregister()
+			})
+
+			Convey("The load should be removed after unregister", func() {
+				nr.Unregister()
+
+				loads, err := c.Loads(ctx)
+				So(err, ShouldBeNil)
+				So(loads, ShouldNotContainKey, "test")
+			})
+		})
+	}))
+}
+
+func TestCluster_WatchNodes(t *testing.T) {
+	Convey("Given a cluster", t, WithCluster(func(ctx context.Context, c cluster.Cluster) {
+		Convey("When watching worker nodes", func() {
+			watchCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			events := c.WatchNodes(watchCtx, node.Worker)
+
+			Convey("Registering and unregistering a node should be notified", func() {
+				_, err := c.Register(ctx, &node.Node{Host: "master", Type: node.Master})
+				So(err, ShouldBeNil)
+				nr, err := c.Register(ctx, &node.Node{Host: "test", Type: node.Worker})
+				So(err, ShouldBeNil)
+
+				ev := receiveNodeEvent(events)
+				So(ev.Type, ShouldEqual, cluster.NodeJoined)
+				So(ev.Node.Host, ShouldEqual, "test")
+				So(ev.Node.Type, ShouldEqual, node.Worker)
+
+				nr.Unregister()
+				ev = receiveNodeEvent(events)
+				So(ev.Type, ShouldEqual, cluster.NodeLeft)
+				So(ev.Node.Host, ShouldEqual, "test")
+			})
+
+			Convey("The events should be closed after the context is cancelled", func() {
+				cancel()
+				select {
+				case _, ok := <-events:
+					So(ok, ShouldBeFalse)
+				case <-time.After(testTimeout):
+					So("events are not closed", ShouldBeEmpty)
+				}
+			})
+		})
+	}))
+}
+
+func receiveNodeEvent(events <-chan cluster.NodeEvent) cluster.NodeEvent {
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(testTimeout):
+		So("no event received", ShouldBeEmpty)
+		return cluster.NodeEvent{}
+	}
+}
+
+func TestCluster_RegisterWithDeadline(t *testing.T) {
+	Convey("Given a cluster with a slow coordinator", t, func() {
+		crd := &slowCoordinator{Coordinator: coordinator.NewLocalMemory(), delay: 5 * tick}
+		opt := cluster.DefaultOptions()
+		opt.LivenessProbeInterval = tick
+		opt.LivenessProbeJitter = 0
+
+		c, err := cluster.OpenRemote(crd, opt)
+		So(err, ShouldBeNil)
+		defer c.Close()
+
+		Convey("Registering should return on the deadline of the context", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), tick)
+			defer cancel()
+
+			startedAt := time.Now()
+			_, err := c.Register(ctx, &node.Node{Host: "test", Type: node.Worker})
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			So(time.Since(startedAt), ShouldBeLessThan, 2*tick)
+
+			Convey("Liveness of the node should never be probed", func() {
+				time.Sleep(crd.delay + 2*tick)
+				So(crd.probes.Load(), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+// slowCoordinator delays granting leases regardless of the context.
+type slowCoordinator struct {
+	coordinator.Coordinator
+	delay  time.Duration
+	probes atomic.Int32
+}
+
+func (s *slowCoordinator) GrantLease(ctx context.Context, ttl time.Duration) (clientv3.LeaseID, error) {
+	time.Sleep(s.delay)
+	return s.Coordinator.GrantLease(ctx, ttl)
+}
+
+func (s *slowCoordinator) KeepAliveOnce(ctx context.Context, lease clientv3.LeaseID) error {
+	s.probes.Inc()
+	return s.Coordinator.KeepAliveOnce(ctx, lease)
+}
+
+func TestCluster_Keepalive(t *testing.T) {
+	Convey("Given a connection through a proxy", t, func() {
+		opt := cluster.DefaultOptions()
+		opt.KeepaliveTimeout = tick
+
+		lis, err := net.Listen("tcp", "127.0.0.1:")
+		So(err, ShouldBeNil)
+		srv := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(opt.KeepaliveEnforcementPolicy()))
+		go srv.Serve(lis)
+		defer srv.Stop()
+
+		proxy := newBlackholeProxy(lis.Addr().String())
+		defer proxy.Close()
+
+		c, err := cluster.OpenRemote(coordinator.NewLocalMemory(), opt)
+		So(err, ShouldBeNil)
+		defer c.Close()
+
+		conn, err := c.Connect(context.Background(), proxy.Addr())
+		So(err, ShouldBeNil)
+		So(conn.GetState(), ShouldEqual, connectivity.Ready)
+
+		Convey("When the connection is silently dropped", func() {
+			proxy.drop.Store(true)
+
+			Convey("It should be detected within the keepalive window", func() {
+				ctx, cancel := context.WithTimeout(context.Background(), opt.KeepaliveInterval+opt.KeepaliveTimeout+2*tick)
+				defer cancel()
+				So(conn.WaitForStateChange(ctx, connectivity.Ready), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+// blackholeProxy relays TCP connections to the target, until drop is set. After that,
+// it keeps the connections open while discarding every byte, like a peer died silently.
+type blackholeProxy struct {
+	net.Listener
+	target string
+	drop   atomic.Bool
+
+	conns   []net.Conn
+	connsMu sync.Mutex
+}
+
+func newBlackholeProxy(target string) *blackholeProxy {
+	lis, err := net.Listen("tcp", "127.0.0.1:")
+	So(err, ShouldBeNil)
+
+	p := &blackholeProxy{Listener: lis, target: target}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			upstream, err := net.Dial("tcp", target)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			p.connsMu.Lock()
+			p.conns = append(p.conns, conn, upstream)
+			p.connsMu.Unlock()
+
+			go p.relay(conn, upstream)
+			go p.relay(upstream, conn)
+		}
+	}()
+	return p
+}
+
+func (p *blackholeProxy) Addr() string {
+	return p.Listener.Addr().String()
+}
+
+// Close stops the proxy, closing the relayed connections.
+func (p *blackholeProxy) Close() error {
+	p.connsMu.Lock()
+	defer p.connsMu.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+	return p.Listener.Close()
+}
+
+func (p *blackholeProxy) relay(dst, src net.Conn) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			return
+		}
+		if p.drop.Load() {
+			continue
+		}
+		if _, err := dst.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
 func TestCluster_Connect(t *testing.T) {
 	Convey("Given a cluster", t, WithCluster(func(ctx context.Context, c cluster.Cluster) {
 		Convey("With connectable nodes", WithTestNodes(c, func(nodes []node.Registration) {
@@ -0,0 +1,99 @@
+package cluster
+
+import (
+	"context"
+	"math/rand"
+	"path"
+	"time"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/pkg/errors"
+)
+
+// sendPeriodicLivenessProbe extends TTL of the lease with jittered intervals until the node is unregistered.
+// Failed probes are retried in shorter intervals with exponential backoff, so that the node can be
+// discovered again soon after the coordinator recovers.
+func (c *cluster) sendPeriodicLivenessProbe(reg *nodeRegistration) {
+	failures := 0
+	for {
+		interval := nextProbeInterval(c.options.LivenessProbeInterval, c.options.LivenessProbeJitter)
+		if failures > 0 {
+			interval = retryInterval(c.options.LivenessProbeRetryInterval, c.options.LivenessProbeInterval/3, failures)
+		}
+		select {
+		case <-time.After(interval):
+			if err := c.proveLiveness(reg); err != nil {
+				if reg.ctx.Err() != nil {
+					return
+				}
+				failures++
+				log.Warn("Failed to send liveness probe (failed {} times): {}", failures, err)
+				continue
+			}
+			if failures > 0 {
+				log.Info("Liveness probe of {} recovered after {} failures.", reg.node.Host, failures)
+			}
+			failures = 0
+			c.publishLoad(reg)
+		case <-reg.ctx.Done():
+			return
+		}
+	}
+}
+
+// proveLiveness extends TTL of the lease. If the lease has already expired, e.g. the coordinator
+// was unavailable longer than the TTL, the node is registered again with a new lease.
+func (c *cluster) proveLiveness(reg *nodeRegistration) error {
+	ctx, cancel := context.WithTimeout(reg.ctx, c.options.LivenessProbeInterval/3)
+	defer cancel()
+
+	err := c.clusterState.KeepAliveOnce(ctx, reg.lease())
+	if errors.Cause(err) == coordinator.ErrLeaseNotFound {
+		log.Warn("Liveness lease of {} has expired. Registering again.", reg.node.Host)
+		return c.register(ctx, reg)
+	}
+	return err
+}
+
+// publishLoad writes the load reported by the node under the liveness lease, so that it expires with the node.
+func (c *cluster) publishLoad(reg *nodeRegistration) {
+	report, ok := reg.loadReporter.Load().(func() node.Load)
+	if !ok {
+		return
+	}
+	load := report()
+	load.UpdatedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(reg.ctx, c.options.LivenessProbeInterval/3)
+	defer cancel()
+	if err := reg.States().Put(ctx, path.Join(nodeLoadNs, reg.node.Host), load); err != nil {
+		log.Warn("Failed to publish load of {}: {}", reg.node.Host, err)
+	}
+}
+
+// nextProbeInterval returns a duration until the next liveness probe, which is a third of the TTL
+// randomized with given jitter. The jitter is capped so that the duration never exceeds the TTL.
+func nextProbeInterval(ttl, jitter time.Duration) time.Duration {
+	interval := ttl / 3
+	if jitter > interval {
+		jitter = interval
+	}
+	if jitter <= 0 {
+		return interval
+	}
+	return interval - jitter + time.Duration(rand.Int63n(int64(2*jitter)))
+}
+
+// retryInterval returns a duration until retrying a failed liveness probe,
+// which doubles on each failure from base up to max.
+func retryInterval(base, max time.Duration, failures int) time.Duration {
+	interval := base
+	for i := 1; i < failures && interval < max; i++ {
+		interval *= 2
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/atomic"
+)
+
+func TestNextProbeInterval(t *testing.T) {
+	Convey("Given a TTL of liveness lease", t, func() {
+		ttl := 9 * time.Second
+
+		Convey("Without jitter, it should probe three times in the TTL", func() {
+			So(nextProbeInterval(ttl, 0), ShouldEqual, 3*time.Second)
+		})
+
+		Convey("With jitter, intervals should be distributed within the jitter bounds", func() {
+			jitter := time.Second
+			var lessThanMean, moreThanMean int
+			for i := 0; i < 1000; i++ {
+				interval := nextProbeInterval(ttl, jitter)
+				So(interval, ShouldBeBetweenOrEqual, 3*time.Second-jitter, 3*time.Second+jitter)
+				if interval < 3*time.Second {
+					lessThanMean++
+				} else {
+					moreThanMean++
+				}
+			}
+			So(lessThanMean, ShouldBeGreaterThan, 0)
+			So(moreThanMean, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("With jitter larger than the TTL, intervals should never exceed the TTL", func() {
+			for i := 0; i < 1000; i++ {
+				interval := nextProbeInterval(ttl, 2*ttl)
+				So(interval, ShouldBeGreaterThanOrEqualTo, 0)
+				So(interval, ShouldBeLessThan, ttl)
+			}
+		})
+	})
+}
+
+func TestCluster_LivenessProbeRetry(t *testing.T) {
+	Convey("Given a node registered to the coordinator", t, func() {
+		crd := &unavailableCoordinator{Coordinator: coordinator.NewLocalMemory()}
+		opt := DefaultOptions()
+		opt.LivenessProbeInterval = 300 * time.Millisecond
+		opt.LivenessProbeJitter = 0
+		opt.LivenessProbeRetryInterval = 10 * time.Millisecond
+
+		c, err := OpenRemote(crd, opt)
+		So(err, ShouldBeNil)
+		defer c.Close()
+
+		ctx := context.Background()
+		_, err = c.Register(ctx, &node.Node{Host: "localhost:1234", Type: node.Worker})
+		So(err, ShouldBeNil)
+
+		Convey("When the coordinator is unavailable longer than the TTL", func() {
+			crd.down.Store(true)
+			time.Sleep(opt.LivenessProbeInterval + 100*time.Millisecond)
+
+			nodes, err := c.List(ctx)
+			So(err, ShouldBeNil)
+			So(nodes, ShouldBeEmpty)
+
+			Convey("The node should be registered again soon after the coordinator recovers", func() {
+				crd.down.Store(false)
+				recoveredAt := time.Now()
+
+				for len(nodes) == 0 && time.Since(recoveredAt) < opt.LivenessProbeInterval {
+					time.Sleep(5 * time.Millisecond)
+					nodes, err = c.List(ctx)
+					So(err, ShouldBeNil)
+				}
+				So(nodes, ShouldHaveLength, 1)
+				So(time.Since(recoveredAt), ShouldBeLessThan, opt.LivenessProbeInterval/3+50*time.Millisecond)
+			})
+		})
+	})
+}
+
+func TestRetryInterval(t *testing.T) {
+	Convey("Retry interval should double on each failure up to the max", t, func() {
+		base, max := 100*time.Millisecond, time.Second
+		So(retryInterval(base, max, 1), ShouldEqual, 100*time.Millisecond)
+		So(retryInterval(base, max, 2), ShouldEqual, 200*time.Millisecond)
+		So(retryInterval(base, max, 4), ShouldEqual, 800*time.Millisecond)
+		So(retryInterval(base, max, 5), ShouldEqual, time.Second)
+		So(retryInterval(base, max, 100), ShouldEqual, time.Second)
+	})
+}
+
+// unavailableCoordinator simulates an outage of the coordinator on updating leases and writing.
+type unavailableCoordinator struct {
+	coordinator.Coordinator
+	down atomic.Bool
+}
+
+var errUnavailable = errors.New("coordinator unavailable")
+
+func (u *unavailableCoordinator) GrantLease(ctx context.Context, ttl time.Duration) (clientv3.LeaseID, error) {
+	if u.down.Load() {
+		return clientv3.NoLease, errUnavailable
+	}
+	return u.Coordinator.GrantLease(ctx, ttl)
+}
+
+func (u *unavailableCoordinator) KeepAliveOnce(ctx context.Context, lease clientv3.LeaseID) error {
+	if u.down.Load() {
+		return errUnavailable
+	}
+	return u.Coordinator.KeepAliveOnce(ctx, lease)
+}
+
+func (u *unavailableCoordinator) Commit(ctx context.Context, txn *coordinator.Txn, opts ...coordinator.WriteOption) ([]coordinator.TxnResult, error) {
+	if u.down.Load() {
+		return nil, errUnavailable
+	}
+	return u.Coordinator.Commit(ctx, txn, opts...)
+}
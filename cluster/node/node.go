@@ -2,6 +2,7 @@ package node
 
 import (
 	"runtime"
+	"time"
 
 	"github.com/ab180/lrmr/coordinator"
 )
@@ -18,6 +19,9 @@ type Node struct {
 	Type      Type   `json:"type"`
 	Executors int    `json:"executors"`
 
+	// Memory is the amount of memory in bytes available to the node. Zero means unknown.
+	Memory uint64 `json:"memory,omitempty"`
+
 	// Tag is used for affinity rules (e.g. resource locality, ...)
 	Tag map[string]string `json:"tag,omitempty"`
 }
@@ -39,6 +43,17 @@ func (n *Node) TagMatches(selector map[string]string) bool {
 	return true
 }
 
+// Load is a load of the node published along its liveness probes, which can be watched by external autoscalers.
+type Load struct {
+	// RunningTasks is the number of the tasks running on the node.
+	RunningTasks int `json:"runningTasks"`
+
+	// QueuedTasks is the number of the tasks waiting for slots of the task pools.
+	QueuedTasks int `json:"queuedTasks"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
 // State represents an ephemeral state of the node.
 // It will be cleared automatically after the node stops.
 type State coordinator.KV
@@ -51,6 +66,10 @@ type Registration interface {
 	// States returns an ephemeral node state.
 	States() State
 
+	// ReportLoad publishes the load returned by fn on each liveness probe of the node.
+	// The load is removed with the node (see cluster.Cluster.Loads).
+	ReportLoad(fn func() Load)
+
 	// Unregister removes node from the cluster's node list, and clears all NodeState.
 	Unregister()
 }
@@ -0,0 +1,102 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+)
+
+// NodeEventType is a type of changes in the nodes of a cluster.
+type NodeEventType string
+
+const (
+	// NodeJoined is sent when a node is registered.
+	NodeJoined NodeEventType = "joined"
+
+	// NodeLeft is sent when a node is unregistered or its liveness lease expires.
+	NodeLeft NodeEventType = "left"
+)
+
+// NodeEvent is a change in the nodes of a cluster.
+type NodeEvent struct {
+	Type NodeEventType
+	Node *node.Node
+}
+
+// WatchNodes sends events of nodes with given type joining or leaving the cluster, until the context is cancelled.
+// An empty type watches every node. Nodes already registered are sent as joined first, so that the events
+// describe the whole topology. The channel is closed after the context is cancelled.
+func (c *cluster) WatchNodes(ctx context.Context, typ node.Type) <-chan NodeEvent {
+	events := make(chan NodeEvent)
+
+	// the watch is started before scanning, so that changes between them are not lost
+	watchCtx, cancel := context.WithCancel(ctx)
+	watchEvents := c.clusterState.Watch(watchCtx, nodeNs+"/")
+
+	go func() {
+		defer close(events)
+		defer cancel()
+
+		send := func(t NodeEventType, n *node.Node) bool {
+			if typ != "" && n.Type != typ {
+				return true
+			}
+			select {
+			case events <- NodeEvent{Type: t, Node: n}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// nodes are kept by the keys, since deletion events don't have the node information
+		known := make(map[string]*node.Node)
+		items, err := c.clusterState.Scan(ctx, nodeNs+"/")
+		if err != nil {
+			log.Warn("Failed to scan nodes to watch: {}", err)
+		}
+		for _, item := range items {
+			n := new(node.Node)
+			if err := item.Unmarshal(n); err != nil {
+				log.Warn("Failed to unmarshal node {}: {}", item.Key, err)
+				continue
+			}
+			known[item.Key] = n
+			if !send(NodeJoined, n) {
+				return
+			}
+		}
+
+		for ev := range watchEvents {
+			switch ev.Type {
+			case coordinator.PutEvent:
+				n := new(node.Node)
+				if err := ev.Item.Unmarshal(n); err != nil {
+					log.Warn("Failed to unmarshal node {}: {}", ev.Item.Key, err)
+					continue
+				}
+				_, registered := known[ev.Item.Key]
+				known[ev.Item.Key] = n
+				if registered {
+					// registered again with a new lease
+					continue
+				}
+				if !send(NodeJoined, n) {
+					return
+				}
+
+			case coordinator.DeleteEvent:
+				n, ok := known[ev.Item.Key]
+				if !ok {
+					continue
+				}
+				delete(known, ev.Item.Key)
+				if !send(NodeLeft, n) {
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
@@ -5,15 +5,50 @@ import (
 
 	"github.com/ab180/lrmr/cluster/node"
 	"github.com/creasty/defaults"
+	"google.golang.org/grpc/keepalive"
 )
 
 type Options struct {
 	ConnectTimeout time.Duration `default:"3s"`
 
 	// LivenessProbeInterval specifies interval for notifying this node's liveness to other nodes.
-	// If a liveness probe fails, the node would not be visible until the next tick of the liveness probe.
+	// If a liveness probe fails, it is retried with LivenessProbeRetryInterval.
+	// The node is probed three times in an interval, so that a few failures of probe can be tolerated.
 	LivenessProbeInterval time.Duration `default:"10s"`
 
+	// LivenessProbeJitter randomizes each interval between liveness probes up to given duration,
+	// so that nodes started together would not probe their liveness at the same time.
+	// It is capped to a third of LivenessProbeInterval to prevent the node from being expired.
+	LivenessProbeJitter time.Duration `default:"1s"`
+
+	// LivenessProbeRetryInterval is an interval for retrying a failed liveness probe. It doubles on each
+	// consecutive failure up to a third of LivenessProbeInterval, and resets after a successful probe.
+	// If the lease has expired while failing, the node is registered again with a new lease.
+	LivenessProbeRetryInterval time.Duration `default:"500ms"`
+
+	// KeepaliveInterval is an interval of pinging the peer of a connection without activity, so that a connection
+	// to a dead peer is closed proactively and redialed on the next Connect, instead of being found broken on use.
+	// gRPC does not ping more often than every 10 seconds. Zero disables keepalive.
+	KeepaliveInterval time.Duration `default:"10s"`
+
+	// KeepaliveTimeout is the duration to wait for a ping to be acknowledged before closing the connection.
+	KeepaliveTimeout time.Duration `default:"5s"`
+
+	// KeepalivePermitWithoutStream pings the peers of connections with no active stream, which are
+	// the most of the connections cached between jobs.
+	KeepalivePermitWithoutStream bool `default:"true"`
+
+	// MaxRecvMsgSize and MaxSendMsgSize limit sizes of messages received and sent by the connections to
+	// other nodes, in bytes. Zero uses the default of gRPC. Workers set them from their Input.MaxRecvSize
+	// and Output.MaxSendMsgSize unless they are set, so that both sides of a connection have the same limits.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// DrainTTL is a duration to keep the mark of a draining node (see Cluster.Drain), so that a node
+	// which is never registered again (e.g. decommissioned without unregistering) would not be marked forever.
+	// Zero keeps the mark until the node unregisters or registers again.
+	DrainTTL time.Duration `default:"24h"`
+
 	TLSCertPath       string
 	TLSCertServerName string
 }
@@ -25,6 +60,15 @@ func DefaultOptions() (o Options) {
 	return
 }
 
+// KeepaliveEnforcementPolicy returns a policy for the servers to accept keepalive pings from the clients with
+// the options. Without it, servers close connections pinged more often than every 5 minutes.
+func (o Options) KeepaliveEnforcementPolicy() keepalive.EnforcementPolicy {
+	return keepalive.EnforcementPolicy{
+		MinTime:             o.KeepaliveInterval,
+		PermitWithoutStream: o.KeepalivePermitWithoutStream,
+	}
+}
+
 type ListOption struct {
 	Type node.Type
 	Tag  map[string]string
@@ -0,0 +1,29 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+)
+
+// WarmUp connects the hosts ahead of time, so that the first use of the connections would not stall
+// on dialing. Each host is dialed in ConnectTimeout. It returns errors of the hosts failed to connect, by the hosts.
+func WarmUp(ctx context.Context, c Cluster, hosts []string) map[string]error {
+	failures := make(map[string]error)
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+	)
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			if _, err := c.Connect(ctx, host); err != nil {
+				lock.Lock()
+				failures[host] = err
+				lock.Unlock()
+			}
+		}(host)
+	}
+	wg.Wait()
+	return failures
+}
@@ -0,0 +1,176 @@
+package lrmr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ColumnType is a type of values in a column.
+type ColumnType int
+
+const (
+	StringColumn ColumnType = iota
+	Int64Column
+	Float64Column
+	BoolColumn
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case StringColumn:
+		return "string"
+	case Int64Column:
+		return "int64"
+	case Float64Column:
+		return "float64"
+	case BoolColumn:
+		return "bool"
+	}
+	return fmt.Sprintf("ColumnType(%d)", int(t))
+}
+
+// Field is a column of ColumnSchema, decoded from the field with the same name in the row values.
+type Field struct {
+	Name string
+	Type ColumnType
+}
+
+// ColumnSchema describes columns of a ColumnBatch.
+type ColumnSchema []Field
+
+// ColumnBatch is a batch of rows in columnar layout. Each column is a slice of the Go type of
+// the ColumnType ([]string, []int64, []float64 or []bool), so that it can be handed to columnar
+// formats like Apache Arrow without decoding the rows again.
+type ColumnBatch struct {
+	Schema  ColumnSchema
+	Columns []interface{}
+	NumRows int
+}
+
+// ColumnTypeError is returned when a value of a row does not match with the type of its column.
+type ColumnTypeError struct {
+	Row   int
+	Field Field
+	Value interface{}
+}
+
+func (e *ColumnTypeError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("row %d: missing %s value of column %s", e.Row, e.Field.Type, e.Field.Name)
+	}
+	return fmt.Sprintf("row %d: column %s expects %s, but got %T", e.Row, e.Field.Name, e.Field.Type, e.Value)
+}
+
+// CollectColumns collects results like Collect, and decodes them into columns of given schema.
+// Values of the rows must be encoded from maps or structs having the fields of the schema.
+func (r *RunningJob) CollectColumns(schema ColumnSchema) (*ColumnBatch, error) {
+	return r.CollectColumnsWithContext(context.Background(), schema)
+}
+
+// CollectColumnsWithContext is like CollectColumns, but stops waiting for the results when the context is cancelled.
+func (r *RunningJob) CollectColumnsWithContext(ctx context.Context, schema ColumnSchema) (*ColumnBatch, error) {
+	rows, err := r.CollectWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewColumnBatch(schema, rows)
+}
+
+// NewColumnBatch decodes values of the rows into columns of given schema.
+func NewColumnBatch(schema ColumnSchema, rows []*lrdd.Row) (*ColumnBatch, error) {
+	b := &ColumnBatch{
+		Schema:  schema,
+		Columns: make([]interface{}, len(schema)),
+		NumRows: len(rows),
+	}
+	for i, f := range schema {
+		switch f.Type {
+		case StringColumn:
+			b.Columns[i] = make([]string, len(rows))
+		case Int64Column:
+			b.Columns[i] = make([]int64, len(rows))
+		case Float64Column:
+			b.Columns[i] = make([]float64, len(rows))
+		case BoolColumn:
+			b.Columns[i] = make([]bool, len(rows))
+		default:
+			return nil, errors.Errorf("unknown type of column %s: %s", f.Name, f.Type)
+		}
+	}
+	for n, row := range rows {
+		var values map[string]interface{}
+		if err := msgpack.Unmarshal(row.Value, &values); err != nil {
+			return nil, errors.Wrapf(err, "decode row %d", n)
+		}
+		for i, f := range schema {
+			if err := b.set(i, n, values[f.Name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// set puts the value into the column, converting integers and floats into 64-bit ones.
+func (b *ColumnBatch) set(column, row int, v interface{}) error {
+	ok := false
+	switch col := b.Columns[column].(type) {
+	case []string:
+		col[row], ok = v.(string)
+	case []int64:
+		col[row], ok = toInt64(v)
+	case []float64:
+		col[row], ok = toFloat64(v)
+	case []bool:
+		col[row], ok = v.(bool)
+	}
+	if !ok {
+		return &ColumnTypeError{Row: row, Field: b.Schema[column], Value: v}
+	}
+	return nil
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		if n > 1<<63-1 {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// toFloat64 converts floats and integers, which can be decoded from whole numbers, into float64.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	if i, ok := toInt64(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
@@ -0,0 +1,64 @@
+package lrmr
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type columnarRecord struct {
+	Name  string
+	Count int
+	Score float32
+	Valid bool
+}
+
+func TestNewColumnBatch(t *testing.T) {
+	schema := ColumnSchema{
+		{Name: "Name", Type: StringColumn},
+		{Name: "Count", Type: Int64Column},
+		{Name: "Score", Type: Float64Column},
+		{Name: "Valid", Type: BoolColumn},
+	}
+
+	Convey("Given rows of structs", t, func() {
+		rows := []*lrdd.Row{
+			lrdd.Value(columnarRecord{Name: "foo", Count: 1, Score: 0.5, Valid: true}),
+			lrdd.Value(columnarRecord{Name: "bar", Count: 300, Score: 2, Valid: false}),
+		}
+
+		Convey("It should be decoded into typed columns", func() {
+			b, err := NewColumnBatch(schema, rows)
+			So(err, ShouldBeNil)
+			So(b.NumRows, ShouldEqual, 2)
+			So(b.Columns[0], ShouldResemble, []string{"foo", "bar"})
+			So(b.Columns[1], ShouldResemble, []int64{1, 300})
+			So(b.Columns[2], ShouldResemble, []float64{0.5, 2})
+			So(b.Columns[3], ShouldResemble, []bool{true, false})
+		})
+	})
+
+	Convey("Given a row with a value not matching with its column", t, func() {
+		rows := []*lrdd.Row{
+			lrdd.Value(map[string]interface{}{"Name": "foo", "Count": 1, "Score": 1, "Valid": true}),
+			lrdd.Value(map[string]interface{}{"Name": "bar", "Count": "many", "Score": 1, "Valid": true}),
+		}
+
+		Convey("It should return ColumnTypeError describing the value", func() {
+			_, err := NewColumnBatch(schema, rows)
+			So(err, ShouldHaveSameTypeAs, &ColumnTypeError{})
+			So(err.Error(), ShouldEqual, "row 1: column Count expects int64, but got string")
+		})
+	})
+
+	Convey("Given a row missing a value of a column", t, func() {
+		rows := []*lrdd.Row{lrdd.Value(map[string]interface{}{"Name": "foo"})}
+
+		Convey("It should return ColumnTypeError", func() {
+			_, err := NewColumnBatch(schema, rows)
+			So(err, ShouldHaveSameTypeAs, &ColumnTypeError{})
+			So(err.Error(), ShouldEqual, "row 0: missing int64 value of column Count")
+		})
+	})
+}
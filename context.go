@@ -2,9 +2,13 @@ package lrmr
 
 import (
 	"context"
+	"math/rand"
 	"time"
 
+	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/transformation"
+	"github.com/airbloc/logger"
+	"github.com/pkg/errors"
 )
 
 type Context interface {
@@ -35,3 +39,86 @@ func (c cancelableContext) Done() <-chan struct{} {
 func (c cancelableContext) Deadline() (deadline time.Time, ok bool) {
 	return c.cancelCtx.Deadline()
 }
+
+// batchEmitterContext is a Context given to Transformer, emitting batches to the output of the transformer.
+type batchEmitterContext struct {
+	Context
+	emitBatch func([]*lrdd.Row)
+}
+
+func contextWithBatchEmitter(ctx Context, emitBatch func([]*lrdd.Row)) Context {
+	return &batchEmitterContext{
+		Context:   ctx,
+		emitBatch: emitBatch,
+	}
+}
+
+func (c batchEmitterContext) EmitBatch(rows []*lrdd.Row) {
+	c.emitBatch(rows)
+}
+
+// driverPartitionID is a partition ID of the transformations running in the driver.
+const driverPartitionID = "_driver"
+
+// driverContext is a Context of the transformations running in the driver, e.g. merging the results of
+// Dataset.ReduceByKeyLocally. Features only available in the tasks (e.g. metrics) are ignored.
+type driverContext struct {
+	context.Context
+	session   *Session
+	jobID     string
+	stageName string
+	rand      *rand.Rand
+	err       error
+}
+
+func newDriverContext(ctx context.Context, s *Session, jobID, stageName string) *driverContext {
+	return &driverContext{
+		Context:   ctx,
+		session:   s,
+		jobID:     jobID,
+		stageName: stageName,
+		rand:      transformation.NewPartitionRand(jobID, driverPartitionID),
+	}
+}
+
+func (c *driverContext) Broadcast(key string) interface{}      { return c.session.broadcasts[key] }
+func (c *driverContext) SideInput(string) map[string]*lrdd.Row { return nil }
+func (c *driverContext) Param(key string) string               { return c.session.options.Params[key] }
+func (c *driverContext) WorkerLocalOption(string) interface{}  { return nil }
+func (c *driverContext) PartitionID() string                   { return driverPartitionID }
+func (c *driverContext) StageName() string                     { return c.stageName }
+func (c *driverContext) JobID() string                         { return c.jobID }
+func (c *driverContext) NumPartitions() int                    { return 1 }
+func (c *driverContext) PeerPartitions() map[string]string     { return nil }
+func (c *driverContext) SetMetric(string, int)                 {}
+func (c *driverContext) Heartbeat()                            {}
+func (c *driverContext) Provenance() bool                      { return false }
+func (c *driverContext) Quarantine(*lrdd.Row, error)           {}
+func (c *driverContext) EmitToDriver(*lrdd.Row)                {}
+func (c *driverContext) Rand() *rand.Rand                      { return c.rand }
+func (c *driverContext) Logger() logger.Logger                 { return log }
+
+func (c *driverContext) AddMetric(string, int, ...transformation.MetricAggregation) {}
+
+func (c *driverContext) TempDir() (string, error) {
+	return "", errors.New("temp dir is not available in the driver")
+}
+
+func (c *driverContext) SaveState(string, interface{}) error {
+	return errors.New("task state is not available in the driver")
+}
+
+func (c *driverContext) LoadState(string, interface{}) (bool, error) {
+	return false, errors.New("task state is not available in the driver")
+}
+
+func (c *driverContext) EmitBatch([]*lrdd.Row) {
+	c.Fail(errors.New("EmitBatch is only available in Transformer"))
+}
+
+// Fail keeps the error, which is returned by the function running the transformation in the driver.
+func (c *driverContext) Fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
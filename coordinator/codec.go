@@ -0,0 +1,70 @@
+package coordinator
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes and decodes values stored in the coordinator.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, ptrToV interface{}) error
+}
+
+var (
+	// JSONCodec stores values in JSON. It is used by default.
+	JSONCodec Codec = jsonCodec{}
+
+	// MsgpackCodec stores values in MessagePack, which is more compact and faster to decode than JSON.
+	// Field names are taken from `json` struct tags, as the JSON codec does.
+	MsgpackCodec Codec = msgpackCodec{}
+
+	// GobCodec stores values with encoding/gob. Note that gob only encodes exported fields
+	// so that values of embedded unexported structs are not stored.
+	GobCodec Codec = gobCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return jsoniter.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, ptrToV interface{}) error {
+	return jsoniter.Unmarshal(data, ptrToV)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.UseJSONTag(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, ptrToV interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.UseJSONTag(true)
+	return dec.Decode(ptrToV)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, ptrToV interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(ptrToV)
+}
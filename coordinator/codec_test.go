@@ -0,0 +1,74 @@
+package coordinator
+
+import (
+	gocontext "context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type codecTestItem struct {
+	Name   string            `json:"name"`
+	Count  int               `json:"count"`
+	Tags   map[string]string `json:"tags"`
+	Values []string          `json:"values"`
+}
+
+func TestCodec(t *testing.T) {
+	codecs := map[string]Codec{
+		"JSONCodec":    JSONCodec,
+		"MsgpackCodec": MsgpackCodec,
+		"GobCodec":     GobCodec,
+	}
+	for name, codec := range codecs {
+		Convey("Given LocalMemoryCoordinator with "+name, t, func() {
+			crd := NewLocalMemory(WithLocalCodec(codec))
+			ctx := gocontext.Background()
+			item := codecTestItem{
+				Name:   "foo",
+				Count:  3,
+				Tags:   map[string]string{"Type": "worker"},
+				Values: []string{"a", "b"},
+			}
+
+			Convey("Values should be round-tripped by Get", func() {
+				So(crd.Put(ctx, "item", item), ShouldBeNil)
+
+				var got codecTestItem
+				So(crd.Get(ctx, "item", &got), ShouldBeNil)
+				So(got, ShouldResemble, item)
+			})
+
+			Convey("Values should be round-tripped by Scan", func() {
+				So(crd.Put(ctx, "items/1", item), ShouldBeNil)
+
+				items, err := crd.Scan(ctx, "items/")
+				So(err, ShouldBeNil)
+				So(items, ShouldHaveLength, 1)
+
+				var got codecTestItem
+				So(items[0].Unmarshal(&got), ShouldBeNil)
+				So(got, ShouldResemble, item)
+			})
+
+			Convey("Values should be round-tripped by Watch", func() {
+				wctx, cancel := gocontext.WithCancel(ctx)
+				defer cancel()
+				events := crd.Watch(wctx, "watched/")
+
+				_, err := crd.Commit(ctx, NewTxn().Put("watched/1", item))
+				So(err, ShouldBeNil)
+
+				select {
+				case ev := <-events:
+					var got codecTestItem
+					So(ev.Item.Unmarshal(&got), ShouldBeNil)
+					So(got, ShouldResemble, item)
+				case <-time.After(time.Second):
+					So("timeout", ShouldBeEmpty)
+				}
+			})
+		})
+	}
+}
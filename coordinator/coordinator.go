@@ -11,6 +11,9 @@ import (
 var (
 	ErrNotFound   = errors.New("key not found")
 	ErrNotCounter = errors.New("key is not a counter")
+
+	// ErrLeaseNotFound is returned when the lease is expired or does not exist.
+	ErrLeaseNotFound = errors.New("requested lease not found")
 )
 
 type Coordinator interface {
@@ -25,6 +28,13 @@ type Coordinator interface {
 	// KeepAlive tries to extend given lease's TTL until the context is cancelled or reaches deadline.
 	KeepAlive(ctx context.Context, lease clientv3.LeaseID) error
 
+	// KeepAliveOnce extends given lease's TTL only once.
+	KeepAliveOnce(ctx context.Context, lease clientv3.LeaseID) error
+
+	// NewElection joins a leader election with given name as a candidate. The leadership of the candidate
+	// is kept alive with a lease of given TTL, so that other candidates can be elected if the process dies.
+	NewElection(ctx context.Context, name string, ttl time.Duration) (Election, error)
+
 	// Close closes coordinator.
 	Close() error
 }
@@ -54,6 +64,11 @@ type WriteOption func(o *WriteOptions)
 
 type WriteOptions struct {
 	Lease clientv3.LeaseID
+
+	// SerializableReads makes Get, Scan and ReadCounter to be served by any member of etcd without consensus,
+	// which is faster but can return stale values. By default, reads are linearizable, reflecting every write
+	// completed before them through any coordinator.
+	SerializableReads bool
 }
 
 func WithLease(l clientv3.LeaseID) WriteOption {
@@ -62,6 +77,23 @@ func WithLease(l clientv3.LeaseID) WriteOption {
 	}
 }
 
+// WithSerializableReads makes reads of the KV faster, at the cost of possibly reading stale values.
+// It suits reads which tolerate staleness, such as listing nodes periodically.
+func WithSerializableReads() WriteOption {
+	return func(o *WriteOptions) {
+		o.SerializableReads = true
+	}
+}
+
+// WithLinearizableReads makes reads of the KV to observe every write completed before them (read-your-writes),
+// even if the writes are done through another coordinator. It is the default, and can be given explicitly
+// where the consistency is required.
+func WithLinearizableReads() WriteOption {
+	return func(o *WriteOptions) {
+		o.SerializableReads = false
+	}
+}
+
 func buildWriteOption(opt []WriteOption) (o WriteOptions) {
 	for _, optApplyFn := range opt {
 		optApplyFn(&o)
@@ -0,0 +1,105 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoLeader is returned when no candidate is elected as a leader of the election.
+var ErrNoLeader = errors.New("no leader elected")
+
+// electionNs is a namespace of the keys used for leader elections.
+const electionNs = "elections/"
+
+// Election elects a leader among the candidates campaigning on the same election name.
+type Election interface {
+	// Campaign blocks until the candidate is elected as the leader, or the context is done.
+	Campaign(ctx context.Context, value string) error
+
+	// Leader returns the value of the current leader. ErrNoLeader is returned if there's no leader.
+	Leader(ctx context.Context) (string, error)
+
+	// Resign gives up the leadership, so that other candidates can be elected.
+	Resign(ctx context.Context) error
+
+	// Done returns a channel closed when the candidate loses its session (e.g. its lease expired).
+	// After that, the leadership is not guaranteed and the election must be created again.
+	Done() <-chan struct{}
+
+	// Close resigns and releases the resources of the candidate.
+	Close() error
+}
+
+// localElection is an Election within a process, used by the local memory coordinator.
+type localElection struct {
+	slot *localElectionSlot
+
+	elected   bool
+	mu        sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// localElectionSlot is a leadership shared by the candidates of an election.
+type localElectionSlot struct {
+	token  chan struct{}
+	leader string
+	mu     sync.RWMutex
+}
+
+func newLocalElectionSlot() *localElectionSlot {
+	return &localElectionSlot{token: make(chan struct{}, 1)}
+}
+
+func (e *localElection) Campaign(ctx context.Context, value string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.elected {
+		return nil
+	}
+	select {
+	case e.slot.token <- struct{}{}:
+	case <-e.done:
+		return ErrLeaseNotFound
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	e.slot.mu.Lock()
+	e.slot.leader = value
+	e.slot.mu.Unlock()
+	e.elected = true
+	return nil
+}
+
+func (e *localElection) Leader(context.Context) (string, error) {
+	e.slot.mu.RLock()
+	defer e.slot.mu.RUnlock()
+	if len(e.slot.token) == 0 {
+		return "", ErrNoLeader
+	}
+	return e.slot.leader, nil
+}
+
+func (e *localElection) Resign(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.elected {
+		return nil
+	}
+	e.slot.mu.Lock()
+	e.slot.leader = ""
+	<-e.slot.token
+	e.slot.mu.Unlock()
+	e.elected = false
+	return nil
+}
+
+func (e *localElection) Done() <-chan struct{} {
+	return e.done
+}
+
+func (e *localElection) Close() error {
+	e.closeOnce.Do(func() { close(e.done) })
+	return e.Resign(context.Background())
+}
@@ -5,17 +5,26 @@ import (
 	"time"
 
 	"github.com/airbloc/logger"
-	jsoniter "github.com/json-iterator/go"
+	"github.com/thoas/go-funk"
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
 	"go.etcd.io/etcd/client/v3/namespace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	// counterMark is value used for counter keys. If a key's value equals to counterMark,
 	// it means the key is counter and its value would be its version.
 	counterMark = "__counter"
+
+	// watchRetryInterval is a delay before re-establishing a broken watch. It doubles while the watch keeps
+	// breaking without delivering any events, up to maxWatchRetryInterval.
+	watchRetryInterval    = 100 * time.Millisecond
+	maxWatchRetryInterval = 5 * time.Second
 )
 
 type Etcd struct {
@@ -24,42 +33,90 @@ type Etcd struct {
 	Watcher clientv3.Watcher
 	Lease   clientv3.Lease
 
-	log  logger.Logger
-	opts []WriteOption
+	log      logger.Logger
+	codec    Codec
+	opts     []WriteOption
+	nsPrefix string
 }
 
-func NewEtcd(endpoints []string, nsPrefix string) (Coordinator, error) {
+// NewEtcd connects to the etcd cluster with given endpoints. Requests are balanced across
+// the endpoints in round-robin manner, so that losing one of the members does not stall the coordinator.
+func NewEtcd(endpoints []string, nsPrefix string, opts ...EtcdOption) (Coordinator, error) {
+	opt := defaultEtcdOptions()
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
 	cfg := clientv3.Config{
 		Endpoints:   endpoints,
-		DialTimeout: 5 * time.Second,
+		DialTimeout: opt.dialTimeout,
 		DialOptions: []grpc.DialOption{grpc.WithBlock()},
 	}
 	cli, err := clientv3.New(cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &Etcd{
-		Client:  cli,
-		KV:      namespace.NewKV(cli, nsPrefix),
-		Watcher: namespace.NewWatcher(cli, nsPrefix),
-		Lease:   namespace.NewLease(cli, nsPrefix),
-		log:     logger.New("etcd"),
-	}, nil
+	e := &Etcd{
+		Client:   cli,
+		KV:       namespace.NewKV(cli, nsPrefix),
+		Watcher:  namespace.NewWatcher(cli, nsPrefix),
+		Lease:    namespace.NewLease(cli, nsPrefix),
+		log:      logger.New("etcd"),
+		codec:    opt.codec,
+		nsPrefix: nsPrefix,
+	}
+	if opt.healthCheckInterval > 0 {
+		go e.checkEndpointHealth(endpoints, opt)
+	}
+	return e, nil
+}
+
+// checkEndpointHealth periodically excludes unhealthy endpoints from the client until the client is closed.
+// If all endpoints are unhealthy, every endpoint is used.
+func (e *Etcd) checkEndpointHealth(endpoints []string, opt etcdOptions) {
+	ticker := time.NewTicker(opt.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			var healthy []string
+			for _, ep := range endpoints {
+				ctx, cancel := context.WithTimeout(e.Client.Ctx(), opt.healthCheckTimeout)
+				_, err := e.Client.Status(ctx, ep)
+				cancel()
+				if err != nil {
+					e.log.Verbose("Endpoint {} is unhealthy: {}", ep, err)
+					continue
+				}
+				healthy = append(healthy, ep)
+			}
+			if len(healthy) == 0 {
+				healthy = endpoints
+			}
+			if !funk.Equal(healthy, e.Client.Endpoints()) {
+				e.Client.SetEndpoints(healthy...)
+			}
+
+		case <-e.Client.Ctx().Done():
+			return
+		}
+	}
 }
 
 func (e *Etcd) Get(ctx context.Context, key string, valuePtr interface{}) error {
-	resp, err := e.KV.Get(ctx, key)
+	resp, err := e.KV.Get(ctx, key, e.readOptions()...)
 	if err != nil {
 		return err
 	}
 	if len(resp.Kvs) == 0 {
 		return ErrNotFound
 	}
-	return jsoniter.Unmarshal(resp.Kvs[0].Value, valuePtr)
+	return e.codec.Unmarshal(resp.Kvs[0].Value, valuePtr)
 }
 
 func (e *Etcd) Scan(ctx context.Context, prefix string) (results []RawItem, err error) {
-	resp, err := e.KV.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	opts := append(e.readOptions(), clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	resp, err := e.KV.Get(ctx, prefix, opts...)
 	if err != nil {
 		return
 	}
@@ -67,55 +124,149 @@ func (e *Etcd) Scan(ctx context.Context, prefix string) (results []RawItem, err
 		results = append(results, RawItem{
 			Key:   string(kv.Key),
 			Value: kv.Value,
+			codec: e.codec,
 		})
 	}
 	return
 }
 
+// Watch subscribes modification events of the keys starting with given prefix. If the underlying
+// watch breaks (e.g. losing the leader or the connection), it is re-established from the last seen revision
+// so that no events are missed, and a ResyncEvent is delivered to notify the reconnection. The watch requires
+// the etcd member to have a leader (clientv3.WithRequireLeader), so that a watch on a member partitioned from
+// the cluster breaks and is re-established instead of silently receiving nothing.
+//
+// If events are lost due to compaction, a ResyncEvent with Lossy is delivered, and the subscriber needs to
+// re-read the keys. On an error which would not go away by retrying (e.g. permission denied), the channel is closed.
 func (e *Etcd) Watch(ctx context.Context, prefix string) chan WatchEvent {
 	watchChan := make(chan WatchEvent)
 
-	wc := e.Watcher.Watch(ctx, prefix, clientv3.WithPrefix())
 	go func() {
 		defer close(watchChan)
-		for wr := range wc {
-			if err := wr.Err(); err != nil {
-				e.log.Error("watch error", err)
-				continue
+
+		var lastRev int64
+		retryInterval := watchRetryInterval
+		for attempt := 0; ; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-time.After(retryInterval):
+				case <-ctx.Done():
+					return
+				}
+			}
+			opts := []clientv3.OpOption{clientv3.WithPrefix()}
+			if lastRev > 0 {
+				opts = append(opts, clientv3.WithRev(lastRev+1))
+			}
+			wc := e.Watcher.Watch(clientv3.WithRequireLeader(ctx), prefix, opts...)
+			if attempt > 0 {
+				e.log.Verbose("Watch on {} is re-established from revision {}", prefix, lastRev+1)
+				if !sendWatchEvent(ctx, watchChan, WatchEvent{Type: ResyncEvent, Revision: lastRev}) {
+					return
+				}
 			}
-			for _, e := range wr.Events {
-				switch e.Type {
-				case mvccpb.PUT:
-					if string(e.Kv.Value) == counterMark {
-						watchChan <- WatchEvent{
-							Type:    CounterEvent,
-							Item:    RawItem{Key: string(e.Kv.Key)},
-							Counter: e.Kv.Version,
-						}
-						continue
+			delivered := false
+			for wr := range wc {
+				if wr.Created && lastRev == 0 {
+					// the watch starts right after the revision on its creation
+					lastRev = wr.Header.Revision
+				}
+				if wr.CompactRevision > lastRev {
+					// events until the compacted revision are lost
+					e.log.Warn("Watch on {} missed events from revision {} to {} due to compaction",
+						prefix, lastRev+1, wr.CompactRevision-1)
+					lastRev = wr.CompactRevision - 1
+					if !sendWatchEvent(ctx, watchChan, WatchEvent{Type: ResyncEvent, Revision: lastRev, Lossy: true}) {
+						return
 					}
-					watchChan <- WatchEvent{
-						Type: PutEvent,
-						Item: RawItem{
-							Key:   string(e.Kv.Key),
-							Value: e.Kv.Value,
-						},
+					continue
+				}
+				if err := wr.Err(); err != nil {
+					if !isTransientWatchError(err) {
+						e.log.Error("Watch on {} failed", err, prefix)
+						return
 					}
-
-				case mvccpb.DELETE:
-					watchChan <- WatchEvent{
-						Type: DeleteEvent,
-						Item: RawItem{Key: string(e.Kv.Key)},
+					e.log.Warn("Watch on {} broke: {}", prefix, err)
+					continue
+				}
+				for _, ev := range wr.Events {
+					if !sendWatchEvent(ctx, watchChan, e.toWatchEvent(ev)) {
+						return
 					}
+					lastRev = ev.Kv.ModRevision
+					delivered = true
 				}
 			}
+			if ctx.Err() != nil {
+				return
+			}
+			if delivered {
+				retryInterval = watchRetryInterval
+				continue
+			}
+			retryInterval *= 2
+			if retryInterval > maxWatchRetryInterval {
+				retryInterval = maxWatchRetryInterval
+			}
 		}
 	}()
 	return watchChan
 }
 
+// isTransientWatchError returns false if the watch error would not go away by re-establishing the watch.
+func isTransientWatchError(err error) bool {
+	var code codes.Code
+	switch e := err.(type) {
+	case rpctypes.EtcdError:
+		code = e.Code()
+	default:
+		code = status.Code(err)
+	}
+	switch code {
+	case codes.PermissionDenied, codes.Unauthenticated, codes.InvalidArgument:
+		return false
+	}
+	return true
+}
+
+func (e *Etcd) toWatchEvent(ev *clientv3.Event) WatchEvent {
+	if ev.Type == mvccpb.DELETE {
+		return WatchEvent{
+			Type:     DeleteEvent,
+			Item:     RawItem{Key: string(ev.Kv.Key), codec: e.codec},
+			Revision: ev.Kv.ModRevision,
+		}
+	}
+	if string(ev.Kv.Value) == counterMark {
+		return WatchEvent{
+			Type:     CounterEvent,
+			Item:     RawItem{Key: string(ev.Kv.Key), codec: e.codec},
+			Counter:  ev.Kv.Version,
+			Revision: ev.Kv.ModRevision,
+		}
+	}
+	return WatchEvent{
+		Type: PutEvent,
+		Item: RawItem{
+			Key:   string(ev.Kv.Key),
+			Value: ev.Kv.Value,
+			codec: e.codec,
+		},
+		Revision: ev.Kv.ModRevision,
+	}
+}
+
+func sendWatchEvent(ctx context.Context, watchChan chan WatchEvent, ev WatchEvent) bool {
+	select {
+	case watchChan <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (e *Etcd) Put(ctx context.Context, key string, value interface{}, opts ...WriteOption) error {
-	jsonVal, err := jsoniter.MarshalToString(value)
+	val, err := e.codec.Marshal(value)
 	if err != nil {
 		return err
 	}
@@ -124,7 +275,7 @@ func (e *Etcd) Put(ctx context.Context, key string, value interface{}, opts ...W
 	if opt.Lease != clientv3.NoLease {
 		etcdOpts = append(etcdOpts, clientv3.WithLease(opt.Lease))
 	}
-	_, err = e.KV.Put(ctx, key, jsonVal, etcdOpts...)
+	_, err = e.KV.Put(ctx, key, string(val), etcdOpts...)
 	return err
 }
 
@@ -139,18 +290,22 @@ func (e *Etcd) Commit(ctx context.Context, txn *Txn, opts ...WriteOption) ([]Txn
 	for _, op := range txn.Ops {
 		switch op.Type {
 		case PutEvent:
-			jsonVal, err := jsoniter.MarshalToString(op.Value)
+			val, err := e.codec.Marshal(op.Value)
 			if err != nil {
 				return nil, err
 			}
-			txOps = append(txOps, clientv3.OpPut(op.Key, jsonVal, etcdOpts...))
+			txOps = append(txOps, clientv3.OpPut(op.Key, string(val), etcdOpts...))
 
 		case CounterEvent:
 			countOpts := append(etcdOpts, clientv3.WithPrevKV())
 			txOps = append(txOps, clientv3.OpPut(op.Key, counterMark, countOpts...))
 
 		case DeleteEvent:
-			txOps = append(txOps, clientv3.OpDelete(op.Key, clientv3.WithPrefix()))
+			var deleteOpts []clientv3.OpOption
+			if op.IsPrefix {
+				deleteOpts = append(deleteOpts, clientv3.WithPrefix())
+			}
+			txOps = append(txOps, clientv3.OpDelete(op.Key, deleteOpts...))
 		}
 	}
 	etcdTxnResults, err := e.KV.Txn(ctx).Then(txOps...).Commit()
@@ -198,6 +353,60 @@ func (e *Etcd) KeepAlive(ctx context.Context, lease clientv3.LeaseID) error {
 	return err
 }
 
+func (e *Etcd) KeepAliveOnce(ctx context.Context, lease clientv3.LeaseID) error {
+	_, err := e.Lease.KeepAliveOnce(ctx, lease)
+	if err == rpctypes.ErrLeaseNotFound {
+		return ErrLeaseNotFound
+	}
+	return err
+}
+
+// NewElection joins the election using concurrency primitives of etcd. The candidate's session
+// is kept alive until the election is closed or the context is cancelled.
+func (e *Etcd) NewElection(ctx context.Context, name string, ttl time.Duration) (Election, error) {
+	// the session is not namespaced since it operates on the raw client
+	s, err := concurrency.NewSession(e.Client, concurrency.WithTTL(int(ttl.Seconds())), concurrency.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &etcdElection{
+		session:  s,
+		election: concurrency.NewElection(s, e.nsPrefix+electionNs+name),
+	}, nil
+}
+
+type etcdElection struct {
+	session  *concurrency.Session
+	election *concurrency.Election
+}
+
+func (e *etcdElection) Campaign(ctx context.Context, value string) error {
+	return e.election.Campaign(ctx, value)
+}
+
+func (e *etcdElection) Leader(ctx context.Context) (string, error) {
+	resp, err := e.election.Leader(ctx)
+	if err == concurrency.ErrElectionNoLeader {
+		return "", ErrNoLeader
+	} else if err != nil {
+		return "", err
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+func (e *etcdElection) Resign(ctx context.Context) error {
+	return e.election.Resign(ctx)
+}
+
+func (e *etcdElection) Done() <-chan struct{} {
+	return e.session.Done()
+}
+
+func (e *etcdElection) Close() error {
+	// closing session revokes its lease, which resigns the leadership
+	return e.session.Close()
+}
+
 func (e *Etcd) IncrementCounter(ctx context.Context, key string) (counter int64, err error) {
 	// uses version as a cheap atomic counter
 	result, err := e.KV.Put(ctx, key, counterMark, clientv3.WithPrevKV())
@@ -213,7 +422,7 @@ func (e *Etcd) IncrementCounter(ctx context.Context, key string) (counter int64,
 }
 
 func (e *Etcd) ReadCounter(ctx context.Context, key string) (counter int64, err error) {
-	resp, err := e.KV.Get(ctx, key)
+	resp, err := e.KV.Get(ctx, key, e.readOptions()...)
 	if err != nil {
 		return
 	}
@@ -229,6 +438,14 @@ func (e *Etcd) ReadCounter(ctx context.Context, key string) (counter int64, err
 	return
 }
 
+// readOptions returns options of etcd reads, which are linearizable unless WithSerializableReads is given.
+func (e *Etcd) readOptions() []clientv3.OpOption {
+	if buildWriteOption(e.opts).SerializableReads {
+		return []clientv3.OpOption{clientv3.WithSerializable()}
+	}
+	return nil
+}
+
 func (e *Etcd) Delete(ctx context.Context, prefix string) (deleted int64, err error) {
 	var opts []clientv3.OpOption
 	if prefix == "" {
@@ -246,15 +463,58 @@ func (e *Etcd) Delete(ctx context.Context, prefix string) (deleted int64, err er
 
 func (e *Etcd) WithOptions(opt ...WriteOption) KV {
 	return &Etcd{
-		Client:  e.Client,
-		KV:      e.KV,
-		Watcher: e.Watcher,
-		Lease:   e.Lease,
-		log:     logger.New("etcd"),
-		opts:    opt,
+		Client:   e.Client,
+		KV:       e.KV,
+		Watcher:  e.Watcher,
+		Lease:    e.Lease,
+		log:      logger.New("etcd"),
+		codec:    e.codec,
+		opts:     opt,
+		nsPrefix: e.nsPrefix,
 	}
 }
 
 func (e *Etcd) Close() error {
 	return e.Client.Close()
 }
+
+type etcdOptions struct {
+	dialTimeout         time.Duration
+	healthCheckInterval time.Duration
+	healthCheckTimeout  time.Duration
+	codec               Codec
+}
+
+func defaultEtcdOptions() etcdOptions {
+	return etcdOptions{
+		dialTimeout:        5 * time.Second,
+		healthCheckTimeout: 1 * time.Second,
+		codec:              JSONCodec,
+	}
+}
+
+type EtcdOption func(*etcdOptions)
+
+// WithDialTimeout sets timeout for connecting to the etcd cluster.
+func WithDialTimeout(timeout time.Duration) EtcdOption {
+	return func(opt *etcdOptions) {
+		opt.dialTimeout = timeout
+	}
+}
+
+// WithEndpointHealthCheck enables periodic health check of the endpoints, excluding unhealthy
+// endpoints from the round-robin. An endpoint is regarded as unhealthy if it does not respond in the timeout.
+func WithEndpointHealthCheck(interval, timeout time.Duration) EtcdOption {
+	return func(opt *etcdOptions) {
+		opt.healthCheckInterval = interval
+		opt.healthCheckTimeout = timeout
+	}
+}
+
+// WithCodec sets the codec used for encoding values stored in etcd. JSONCodec is used by default.
+// Every process sharing the same namespace must use the same codec.
+func WithCodec(codec Codec) EtcdOption {
+	return func(opt *etcdOptions) {
+		opt.codec = codec
+	}
+}
@@ -0,0 +1,286 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/airbloc/logger"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEtcd_Watch(t *testing.T) {
+	Convey("Given an etcd coordinator whose watch breaks in the middle", t, func() {
+		const numEvents = 10
+		w := newBrokenWatcher(numEvents, numEvents/2)
+		e := &Etcd{Watcher: w, log: logger.New("etcd"), codec: JSONCodec}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		Convey("When watching keys", func() {
+			var received []WatchEvent
+			for ev := range e.Watch(ctx, "key/") {
+				received = append(received, ev)
+				if len(received) == numEvents+1 {
+					break
+				}
+			}
+
+			Convey("It should notify the resync after the break", func() {
+				So(received, ShouldHaveLength, numEvents+1)
+				So(received[numEvents/2].Type, ShouldEqual, ResyncEvent)
+				So(received[numEvents/2].Revision, ShouldEqual, numEvents/2+1)
+			})
+
+			Convey("It should resume from the last seen revision without missing events", func() {
+				var keys []string
+				for _, ev := range received {
+					if ev.Type == PutEvent {
+						keys = append(keys, ev.Item.Key)
+					}
+				}
+				So(keys, ShouldHaveLength, numEvents)
+				for i, key := range keys {
+					So(key, ShouldEqual, fmt.Sprintf("key/%d", i))
+				}
+				So(w.startRevisions(), ShouldResemble, []int64{0, numEvents/2 + 2})
+			})
+		})
+	})
+}
+
+func TestEtcd_Watch_Compaction(t *testing.T) {
+	Convey("Given an etcd coordinator whose watch misses events due to compaction", t, func() {
+		w := &scriptedWatcher{script: func(attempt int) ([]clientv3.WatchResponse, bool) {
+			return []clientv3.WatchResponse{
+				{Header: etcdserverpb.ResponseHeader{Revision: 1}, Created: true},
+				{CompactRevision: 5, Canceled: true},
+			}, true
+		}}
+		e := &Etcd{Watcher: w, log: logger.New("etcd"), codec: JSONCodec}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		Convey("It should notify a lossy resync", func() {
+			ev := <-e.Watch(ctx, "key/")
+			So(ev.Type, ShouldEqual, ResyncEvent)
+			So(ev.Lossy, ShouldBeTrue)
+			So(ev.Revision, ShouldEqual, 4)
+		})
+	})
+}
+
+func TestEtcd_Watch_Backoff(t *testing.T) {
+	Convey("Given an etcd coordinator whose watch keeps breaking without any events", t, func() {
+		w := &scriptedWatcher{script: func(attempt int) ([]clientv3.WatchResponse, bool) {
+			return nil, false
+		}}
+		e := &Etcd{Watcher: w, log: logger.New("etcd"), codec: JSONCodec}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		Convey("It should back off re-establishing the watch", func() {
+			for range e.Watch(ctx, "key/") {
+			}
+			// 0ms, 100ms, 300ms, 700ms; retrying in a fixed interval would take 10 attempts
+			So(w.numAttempts(), ShouldBeLessThanOrEqualTo, 5)
+		})
+	})
+}
+
+func TestIsTransientWatchError(t *testing.T) {
+	Convey("Errors of a lost leader or connection should be transient", t, func() {
+		So(isTransientWatchError(rpctypes.ErrNoLeader), ShouldBeTrue)
+		So(isTransientWatchError(rpctypes.Error(status.Error(codes.Unavailable, "unavailable"))), ShouldBeTrue)
+	})
+	Convey("Errors of authorization should not be transient", t, func() {
+		So(isTransientWatchError(rpctypes.ErrPermissionDenied), ShouldBeFalse)
+		So(isTransientWatchError(status.Error(codes.Unauthenticated, "unauthenticated")), ShouldBeFalse)
+	})
+}
+
+// scriptedWatcher is a clientv3.Watcher sending responses given by its script on each watch.
+// The watch is closed after the responses unless the script tells to keep it open.
+type scriptedWatcher struct {
+	script func(attempt int) (responses []clientv3.WatchResponse, keepOpen bool)
+
+	mu       sync.Mutex
+	attempts int
+}
+
+func (w *scriptedWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	w.mu.Lock()
+	attempt := w.attempts
+	w.attempts++
+	w.mu.Unlock()
+
+	responses, keepOpen := w.script(attempt)
+	wc := make(chan clientv3.WatchResponse)
+	go func() {
+		defer close(wc)
+		for _, wr := range responses {
+			select {
+			case wc <- wr:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if keepOpen {
+			<-ctx.Done()
+		}
+	}()
+	return wc
+}
+
+func (w *scriptedWatcher) numAttempts() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.attempts
+}
+
+func (w *scriptedWatcher) RequestProgress(context.Context) error { return nil }
+func (w *scriptedWatcher) Close() error                          { return nil }
+
+// brokenWatcher is a clientv3.Watcher replaying its events from the requested revision,
+// whose first watch breaks after given number of events.
+type brokenWatcher struct {
+	events     []*clientv3.Event
+	breakAfter int
+
+	mu   sync.Mutex
+	revs []int64
+}
+
+func newBrokenWatcher(numEvents, breakAfter int) *brokenWatcher {
+	w := &brokenWatcher{breakAfter: breakAfter}
+	for i := 0; i < numEvents; i++ {
+		w.events = append(w.events, &clientv3.Event{
+			Type: mvccpb.PUT,
+			Kv: &mvccpb.KeyValue{
+				Key:         []byte(fmt.Sprintf("key/%d", i)),
+				Value:       []byte(`"value"`),
+				ModRevision: int64(i + 2),
+			},
+		})
+	}
+	return w
+}
+
+func (w *brokenWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	startRev := clientv3.OpGet(key, opts...).Rev()
+
+	w.mu.Lock()
+	isFirst := len(w.revs) == 0
+	w.revs = append(w.revs, startRev)
+	w.mu.Unlock()
+
+	wc := make(chan clientv3.WatchResponse)
+	go func() {
+		defer close(wc)
+		select {
+		case wc <- clientv3.WatchResponse{Header: etcdserverpb.ResponseHeader{Revision: 1}, Created: true}:
+		case <-ctx.Done():
+			return
+		}
+		sent := 0
+		for _, ev := range w.events {
+			if ev.Kv.ModRevision < startRev {
+				continue
+			}
+			if isFirst && sent == w.breakAfter {
+				return
+			}
+			select {
+			case wc <- clientv3.WatchResponse{Events: []*clientv3.Event{ev}}:
+				sent++
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+	}()
+	return wc
+}
+
+func (w *brokenWatcher) startRevisions() []int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.revs
+}
+
+func (w *brokenWatcher) RequestProgress(context.Context) error { return nil }
+func (w *brokenWatcher) Close() error                          { return nil }
+
+func TestEtcd_ReadConsistency(t *testing.T) {
+	Convey("Given two coordinators on an etcd cluster whose follower lags behind the leader", t, func() {
+		kv := newLaggingKV()
+		writer := &Etcd{KV: kv, log: logger.New("etcd"), codec: JSONCodec}
+		reader := &Etcd{KV: kv, log: logger.New("etcd"), codec: JSONCodec}
+		ctx := context.Background()
+
+		Convey("When a value is written through a coordinator", func() {
+			So(writer.Put(ctx, "jobs/J1", "created"), ShouldBeNil)
+
+			Convey("It should be visible to linearizable reads of another coordinator right away", func() {
+				var v string
+				So(reader.WithOptions(WithLinearizableReads()).Get(ctx, "jobs/J1", &v), ShouldBeNil)
+				So(v, ShouldEqual, "created")
+
+				items, err := reader.Scan(ctx, "jobs/")
+				So(err, ShouldBeNil)
+				So(items, ShouldHaveLength, 1)
+			})
+
+			Convey("Serializable reads may not see it yet", func() {
+				var v string
+				serializable := reader.WithOptions(WithSerializableReads())
+				So(serializable.Get(ctx, "jobs/J1", &v), ShouldEqual, ErrNotFound)
+
+				items, err := serializable.Scan(ctx, "jobs/")
+				So(err, ShouldBeNil)
+				So(items, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+// laggingKV is a clientv3.KV whose writes are only applied to the leader. Serializable reads are served
+// by a follower which never catches up, while linearizable reads are served by the leader.
+type laggingKV struct {
+	clientv3.KV
+	leader map[string]string
+}
+
+func newLaggingKV() *laggingKV {
+	return &laggingKV{leader: make(map[string]string)}
+}
+
+func (l *laggingKV) Put(_ context.Context, key, val string, _ ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	l.leader[key] = val
+	return &clientv3.PutResponse{}, nil
+}
+
+func (l *laggingKV) Get(_ context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp := &clientv3.GetResponse{}
+	op := clientv3.OpGet(key, opts...)
+	if op.IsSerializable() {
+		return resp, nil
+	}
+	for k, v := range l.leader {
+		if k == key || (len(op.RangeBytes()) > 0 && k >= key && k < string(op.RangeBytes())) {
+			resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	return resp, nil
+}
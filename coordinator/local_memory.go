@@ -7,7 +7,6 @@ import (
 	"sync"
 	"time"
 
-	jsoniter "github.com/json-iterator/go"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -15,6 +14,7 @@ type localMemoryCoordinator struct {
 	opt    localMemoryOptions
 	data   sync.Map
 	leases sync.Map
+	ttls   sync.Map
 
 	counter     map[string]int64
 	counterLock sync.RWMutex
@@ -22,6 +22,8 @@ type localMemoryCoordinator struct {
 	subscriptions []subscription
 	subsLock      sync.RWMutex
 
+	elections sync.Map
+
 	optsApplied []WriteOption
 }
 
@@ -38,7 +40,12 @@ type subscription struct {
 // NewLocalMemory creates local variable based coordinator.
 // Only used for test purpose.
 func NewLocalMemory(opts ...LocalMemoryOption) Coordinator {
+	opt := localMemoryOptions{codec: JSONCodec}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
 	return &localMemoryCoordinator{
+		opt:     opt,
 		counter: map[string]int64{},
 	}
 }
@@ -94,15 +101,21 @@ func (lmc *localMemoryCoordinator) Put(ctx context.Context, key string, value in
 }
 
 func (lmc *localMemoryCoordinator) put(k string, v interface{}, lease clientv3.LeaseID) error {
-	raw, err := jsoniter.Marshal(v)
+	raw, err := lmc.opt.codec.Marshal(v)
 	if err != nil {
 		return err
 	}
+	lmc.putRaw(k, raw, lease)
+	return nil
+}
+
+func (lmc *localMemoryCoordinator) putRaw(k string, raw []byte, lease clientv3.LeaseID) {
 	entry := entry{
 		lease: lease,
 		item: RawItem{
 			Key:   k,
 			Value: raw,
+			codec: lmc.opt.codec,
 		},
 	}
 	lmc.data.Store(k, entry)
@@ -110,7 +123,6 @@ func (lmc *localMemoryCoordinator) put(k string, v interface{}, lease clientv3.L
 		Type: PutEvent,
 		Item: entry.item,
 	})
-	return nil
 }
 
 func (lmc *localMemoryCoordinator) IncrementCounter(ctx context.Context, key string) (count int64, err error) {
@@ -150,18 +162,33 @@ func (lmc *localMemoryCoordinator) Commit(ctx context.Context, txn *Txn, opts ..
 	if err := lmc.simulate(ctx); err != nil {
 		return nil, err
 	}
+	// values are marshalled before applying any operation, so that failed transaction leaves no changes
+	rawValues := make([][]byte, len(txn.Ops))
+	for i, op := range txn.Ops {
+		if op.Type != PutEvent {
+			continue
+		}
+		raw, err := lmc.opt.codec.Marshal(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		rawValues[i] = raw
+	}
+
 	results := make([]TxnResult, len(txn.Ops))
 	for i, op := range txn.Ops {
 		switch op.Type {
 		case PutEvent:
 			opt := buildWriteOption(opts)
-			if err := lmc.put(op.Key, op.Value, opt.Lease); err != nil {
-				return nil, err
-			}
+			lmc.putRaw(op.Key, rawValues[i], opt.Lease)
 		case CounterEvent:
 			results[i].Counter = lmc.incrementCounter(op.Key)
 		case DeleteEvent:
-			results[i].Deleted = lmc.delete(op.Key)
+			if op.IsPrefix {
+				results[i].Deleted = lmc.delete(op.Key)
+			} else {
+				results[i].Deleted = lmc.deleteKey(op.Key)
+			}
 		}
 		results[i].Type = op.Type
 	}
@@ -180,28 +207,36 @@ func (lmc *localMemoryCoordinator) delete(prefix string) (deleted int64) {
 	lmc.data.Range(func(key, value interface{}) bool {
 		k := key.(string)
 		if strings.HasPrefix(k, prefix) {
-			lmc.data.Delete(k)
-			lmc.counterLock.Lock()
-			if _, ok := lmc.counter[k]; ok {
-				delete(lmc.counter, k)
-			}
-			lmc.counterLock.Unlock()
-
-			go lmc.notifySubscribers(WatchEvent{
-				Type: DeleteEvent,
-				Item: RawItem{Key: k},
-			})
-			deleted += 1
+			deleted += lmc.deleteKey(k)
 		}
 		return true
 	})
 	return deleted
 }
 
+func (lmc *localMemoryCoordinator) deleteKey(k string) (deleted int64) {
+	if _, ok := lmc.data.Load(k); !ok {
+		return 0
+	}
+	lmc.data.Delete(k)
+	lmc.counterLock.Lock()
+	if _, ok := lmc.counter[k]; ok {
+		delete(lmc.counter, k)
+	}
+	lmc.counterLock.Unlock()
+
+	go lmc.notifySubscribers(WatchEvent{
+		Type: DeleteEvent,
+		Item: RawItem{Key: k},
+	})
+	return 1
+}
+
 func (lmc *localMemoryCoordinator) GrantLease(ctx context.Context, ttl time.Duration) (clientv3.LeaseID, error) {
 	lease := clientv3.LeaseID(rand.Uint64())
 	deadline := time.Now().Add(ttl)
 	lmc.leases.Store(lease, deadline)
+	lmc.ttls.Store(lease, ttl)
 	return lease, nil
 }
 
@@ -222,6 +257,18 @@ func (lmc *localMemoryCoordinator) KeepAlive(ctx context.Context, lease clientv3
 	return nil
 }
 
+func (lmc *localMemoryCoordinator) KeepAliveOnce(ctx context.Context, lease clientv3.LeaseID) error {
+	if err := lmc.simulate(ctx); err != nil {
+		return err
+	}
+	ttl, ok := lmc.ttls.Load(lease)
+	if !ok || lmc.isAfterDeadline(lease) {
+		return ErrLeaseNotFound
+	}
+	lmc.leases.Store(lease, time.Now().Add(ttl.(time.Duration)))
+	return nil
+}
+
 func (lmc *localMemoryCoordinator) isAfterDeadline(lease clientv3.LeaseID) (expired bool) {
 	if lease == clientv3.NoLease {
 		return false
@@ -239,6 +286,17 @@ func (lmc *localMemoryCoordinator) expireLease(key interface{}, lease clientv3.L
 	lmc.data.Delete(key)
 }
 
+func (lmc *localMemoryCoordinator) NewElection(ctx context.Context, name string, ttl time.Duration) (Election, error) {
+	if err := lmc.simulate(ctx); err != nil {
+		return nil, err
+	}
+	slot, _ := lmc.elections.LoadOrStore(name, newLocalElectionSlot())
+	return &localElection{
+		slot: slot.(*localElectionSlot),
+		done: make(chan struct{}),
+	}, nil
+}
+
 func (lmc *localMemoryCoordinator) Watch(ctx context.Context, prefix string) chan WatchEvent {
 	lmc.subsLock.Lock()
 	defer lmc.subsLock.Unlock()
@@ -294,6 +352,7 @@ func (lmc *localMemoryCoordinator) Close() error {
 type localMemoryOptions struct {
 	simulatedDelay time.Duration
 	simulatedError error
+	codec          Codec
 }
 
 type LocalMemoryOption func(*localMemoryOptions)
@@ -309,3 +368,10 @@ func WithSimulatedError(err error) LocalMemoryOption {
 		opt.simulatedError = err
 	}
 }
+
+// WithLocalCodec sets the codec used for encoding stored values. JSONCodec is used by default.
+func WithLocalCodec(codec Codec) LocalMemoryOption {
+	return func(opt *localMemoryOptions) {
+		opt.codec = codec
+	}
+}
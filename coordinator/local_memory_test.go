@@ -74,3 +74,60 @@ func TestLocalMemoryCoordinator_GrantLease(t *testing.T) {
 		})
 	})
 }
+
+func TestLocalMemoryCoordinator_Commit(t *testing.T) {
+	Convey("Given LocalMemoryCoordinator", t, func() {
+		crd := NewLocalMemory()
+		ctx := gocontext.Background()
+		So(crd.Put(ctx, "jobs/J1", "job"), ShouldBeNil)
+		So(crd.Put(ctx, "jobs/J10", "job"), ShouldBeNil)
+		So(crd.Put(ctx, "status/J1", "status"), ShouldBeNil)
+
+		Convey("Committing a transaction with puts and deletes", func() {
+			results, err := crd.Commit(ctx, NewTxn().
+				Put("errors/J1", "error").
+				DeleteKey("jobs/J1").
+				DeleteKey("status/J1"))
+			So(err, ShouldBeNil)
+			So(results, ShouldHaveLength, 3)
+			So(results[1].Deleted, ShouldEqual, 1)
+			So(results[2].Deleted, ShouldEqual, 1)
+
+			Convey("It should apply all operations", func() {
+				var val string
+				So(crd.Get(ctx, "errors/J1", &val), ShouldBeNil)
+				So(crd.Get(ctx, "jobs/J1", &val), ShouldEqual, ErrNotFound)
+				So(crd.Get(ctx, "status/J1", &val), ShouldEqual, ErrNotFound)
+			})
+
+			Convey("It should only delete the exact key", func() {
+				var val string
+				So(crd.Get(ctx, "jobs/J10", &val), ShouldBeNil)
+			})
+		})
+
+		Convey("Committing a transaction with a failing operation", func() {
+			_, err := crd.Commit(ctx, NewTxn().
+				DeleteKey("jobs/J1").
+				Put("errors/J1", make(chan int)).
+				DeleteKey("status/J1"))
+			So(err, ShouldNotBeNil)
+
+			Convey("It should apply none of the operations", func() {
+				var val string
+				So(crd.Get(ctx, "jobs/J1", &val), ShouldBeNil)
+				So(crd.Get(ctx, "status/J1", &val), ShouldBeNil)
+				So(crd.Get(ctx, "errors/J1", &val), ShouldEqual, ErrNotFound)
+			})
+		})
+
+		Convey("Committing a transaction with Delete", func() {
+			results, err := crd.Commit(ctx, NewTxn().Delete("jobs/J1"))
+			So(err, ShouldBeNil)
+
+			Convey("It should delete all keys with the prefix", func() {
+				So(results[0].Deleted, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -48,9 +48,19 @@ func (t *Txn) IncrementCounter(key string, opts ...clientv3.OpOption) *Txn {
 
 // Delete performs a batch operation deleting all keys starting with given prefix within the transaction.
 func (t *Txn) Delete(keyPrefix string) *Txn {
+	t.Ops = append(t.Ops, BatchOp{
+		Type:     DeleteEvent,
+		Key:      keyPrefix,
+		IsPrefix: true,
+	})
+	return t
+}
+
+// DeleteKey performs a batch operation deleting exactly the given key within the transaction.
+func (t *Txn) DeleteKey(key string) *Txn {
 	t.Ops = append(t.Ops, BatchOp{
 		Type: DeleteEvent,
-		Key:  keyPrefix,
+		Key:  key,
 	})
 	return t
 }
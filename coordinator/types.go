@@ -1,7 +1,6 @@
 package coordinator
 
 import (
-	jsoniter "github.com/json-iterator/go"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -12,6 +11,10 @@ const (
 	PutEvent EventType = iota
 	DeleteEvent
 	CounterEvent
+
+	// ResyncEvent notifies that the watch has been broken and re-established. Events after Revision
+	// are delivered following the event. Unless the event is Lossy, the subscriber doesn't need to re-read the keys.
+	ResyncEvent
 )
 
 type WatchEvent struct {
@@ -20,17 +23,32 @@ type WatchEvent struct {
 
 	// Counter is a new value of the counter when the event is CounterEvent.
 	Counter int64
+
+	// Revision is a revision of the modification, or the last seen revision when the event is ResyncEvent.
+	// It is always zero for coordinators without revisions.
+	Revision int64
+
+	// Lossy is set on ResyncEvent if events before Revision have been lost (e.g. compacted),
+	// so that the subscriber needs to re-read the keys.
+	Lossy bool
 }
 
 // RawItem is a data of item which isn't unmarshalled yet.
 type RawItem struct {
 	Key   string
 	Value []byte
+
+	// codec is the Codec of the coordinator which the item is read from.
+	codec Codec
 }
 
 func (r RawItem) Unmarshal(value interface{}) error {
+	codec := r.codec
+	if codec == nil {
+		codec = JSONCodec
+	}
 	// assuming that the value is a struct pointer
-	return jsoniter.Unmarshal(r.Value, value)
+	return codec.Unmarshal(r.Value, value)
 }
 
 type BatchOp struct {
@@ -38,4 +56,7 @@ type BatchOp struct {
 	Key     string
 	Value   interface{}
 	Options []clientv3.OpOption
+
+	// IsPrefix indicates that a DeleteEvent operation is applied to all keys starting with the Key.
+	IsPrefix bool
 }
@@ -1,7 +1,9 @@
 package lrmr
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/ab180/lrmr/internal/util"
 	"github.com/ab180/lrmr/job"
@@ -10,6 +12,7 @@ import (
 	"github.com/ab180/lrmr/partitions"
 	"github.com/ab180/lrmr/stage"
 	"github.com/ab180/lrmr/transformation"
+	"github.com/pkg/errors"
 )
 
 // Dataset is less-resilient distributed dataset
@@ -24,6 +27,19 @@ type Dataset struct {
 	defaultPlan partitions.Plan
 
 	NumStages int
+
+	// persist keeps output of the final stage in the workers, compressed with persistCompression.
+	persist            bool
+	persistCompression job.Compression
+
+	// sideInputs are datasets collected before running the dataset, to be looked up by stages.
+	sideInputs map[string]*Dataset
+
+	// peeks are callbacks of Dataset.Peek by the names of the stages.
+	peeks map[string]func(*lrdd.Row)
+
+	// mergeSortBy is the ordering which the input of the next stage is merge-sorted with.
+	mergeSortBy Ordering
 }
 
 func newDataset(sess *Session, input InputProvider) *Dataset {
@@ -38,7 +54,12 @@ func newDataset(sess *Session, input InputProvider) *Dataset {
 }
 
 func (d *Dataset) addStage(name string, tf transformation.Transformation) {
-	st := stage.New(name, tf, stage.InputFrom(*d.lastStage()))
+	in := stage.InputFrom(*d.lastStage())
+	if d.mergeSortBy != nil {
+		in.MergeSortedBy = &stage.SerializableOrdering{KeyOrdering: d.mergeSortBy}
+		d.mergeSortBy = nil
+	}
+	st := stage.New(name, tf, in)
 	d.lastStage().SetOutputTo(st)
 
 	d.stages = append(d.stages, st)
@@ -50,6 +71,13 @@ func (d *Dataset) Do(t Transformer) *Dataset {
 	return d
 }
 
+// ValidateSchema checks rows of the last stage with the validator, as a gate of data quality.
+// Invalid rows are handled by the policy: either failing the task, or being quarantined from the output.
+func (d *Dataset) ValidateSchema(v SchemaValidator, policy InvalidRowPolicy) *Dataset {
+	d.addStage(d.stageName(v), &validateTransformation{validator: v, policy: policy})
+	return d
+}
+
 func (d *Dataset) Map(m Mapper) *Dataset {
 	d.addStage(d.stageName(m), &mapTransformation{m})
 	return d
@@ -61,20 +89,123 @@ func (d *Dataset) FlatMap(fm FlatMapper) *Dataset {
 }
 
 func (d *Dataset) Reduce(r Reducer) *Dataset {
-	d.addStage(d.stageName(r), &reduceTransformation{r})
+	if !partitions.IsSkewed(d.lastPlan().Partitioner) {
+		d.addStage(d.stageName(r), &reduceTransformation{r})
+		return d
+	}
+	pr, ok := r.(PartialReducer)
+	if !ok {
+		log.Warn("{} does not implement PartialReducer. Hot keys would not be split.", util.NameOfType(r))
+		d.lastPlan().Partitioner = partitions.NewHashKeyPartitioner()
+		d.addStage(d.stageName(r), &reduceTransformation{r})
+		return d
+	}
+	// rows of hot keys are reduced in multiple partitions, so partial results need to be merged again
+	name := d.stageName(r)
+	d.addStage(name, &reduceTransformation{r})
+	d.lastPlan().Partitioner = partitions.NewHashKeyPartitioner()
+	d.addStage(name+"Merge", &mergeReduceTransformation{pr})
+	return d
+}
+
+// ReduceWithStateTTL is like Reduce, but evicts state of the keys not updated within the TTL, which bounds memory
+// of reducing unbounded inputs. Evicted state is emitted as a result of the key if emitExpired is true,
+// or discarded otherwise. Rows of an evicted key arriving later are reduced again from the initial value.
+// Expired state is checked in every half of the TTL, but no more often than every 10 milliseconds.
+//
+// Hot keys are not split with state TTL: if the dataset is partitioned by GroupByKeyWithSkew,
+// its partitioner is replaced with a hash partitioner, so that every row of a key is reduced in a single partition.
+func (d *Dataset) ReduceWithStateTTL(r Reducer, ttl time.Duration, emitExpired bool) *Dataset {
+	if ttl <= 0 {
+		return d.Reduce(r)
+	}
+	if partitions.IsSkewed(d.lastPlan().Partitioner) {
+		log.Warn("Hot keys are not split for {} with state TTL.", util.NameOfType(r))
+		d.lastPlan().Partitioner = partitions.NewHashKeyPartitioner()
+	}
+	d.addStage(d.stageName(r), &expiringReduceTransformation{
+		reduce:      reduceTransformation{r},
+		ttl:         ttl,
+		emitExpired: emitExpired,
+	})
 	return d
 }
 
+// ReduceByKeyLocally reduces rows by their keys in each partition without shuffling them, and merges the partial
+// results in the driver with PartialReducer.MergePartial. It returns the results keyed by the keys after the job
+// completes. Since every key is held in the driver, it is meant for small key spaces; use Reduce otherwise.
+func (d *Dataset) ReduceByKeyLocally(r PartialReducer) (map[string]*lrdd.Row, error) {
+	name := d.stageName(r)
+	d.addStage(name, &reduceTransformation{r})
+
+	j, err := d.RunForCollect()
+	if err != nil {
+		return nil, err
+	}
+	partials, err := j.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	merger := newPartialMerger(r)
+	ctx := newDriverContext(context.Background(), d.session, j.Job.ID, name+"Merge")
+	for _, partial := range partials {
+		err := merger.merge(replacePartitionKey(ctx, partial.Key), partial)
+		if err == nil {
+			err = ctx.err
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "merge partial result of key %s", partial.Key)
+		}
+	}
+
+	results := make(map[string]*lrdd.Row)
+	for _, row := range merger.results() {
+		results[row.Key] = row
+	}
+	return results, nil
+}
+
 func (d *Dataset) Sort(s Sorter) *Dataset {
 	d.addStage(d.stageName(s), &sortTransformation{sorter: s})
 	return d
 }
 
+// SortByKey sorts rows in each partition by their keys with given Ordering.
+func (d *Dataset) SortByKey(o Ordering) *Dataset {
+	d.addStage(d.stageName(o), &sortTransformation{sorter: orderingSorter{o}})
+	return d
+}
+
+// SortMergeByKey sorts rows in each partition with the ordering and groups them by their keys,
+// merging the sorted rows from the partitions in the next stage so that each of its partitions
+// receives the rows in the order without sorting them again.
+func (d *Dataset) SortMergeByKey(o Ordering) *Dataset {
+	d.SortByKey(o).GroupByKey()
+	d.mergeSortBy = o
+	return d
+}
+
 func (d *Dataset) GroupByKey() *Dataset {
 	d.lastPlan().Partitioner = partitions.NewHashKeyPartitioner()
 	return d
 }
 
+// GroupByKeyWithHash groups rows by their keys with given hash function, instead of the default FNV-1a.
+func (d *Dataset) GroupByKeyWithHash(h partitions.HashFunc) *Dataset {
+	d.lastPlan().Partitioner = partitions.NewHashKeyPartitionerWithHash(h)
+	return d
+}
+
+// GroupByKeyWithSkew groups rows by their keys, but splits rows of hot keys in given key-frequency profile
+// over multiple partitions. Reduce following it merges the partial results of the hot keys in an additional
+// stage if the Reducer implements PartialReducer. Otherwise, hot keys are not split.
+// Other transformations following it may receive rows of a hot key in multiple partitions.
+func (d *Dataset) GroupByKeyWithSkew(frequencies map[string]int64) *Dataset {
+	d.lastPlan().Partitioner = partitions.NewSkewedKeyPartitioner(frequencies)
+	return d
+}
+
 func (d *Dataset) GroupByKnownKeys(knownKeys []string) *Dataset {
 	d.lastPlan().Partitioner = partitions.NewFiniteKeyPartitioner(knownKeys)
 	return d
@@ -85,6 +216,18 @@ func (d *Dataset) Shuffle() *Dataset {
 	return d
 }
 
+// Coalesce merges partitions of the last stage into at most n partitions for the next stage without shuffling,
+// reducing the number of tasks when the partitions are small. It is ignored if the output of the last stage
+// is already partitioned, e.g. by GroupByKey, since merging can't replace the shuffle.
+func (d *Dataset) Coalesce(n int) *Dataset {
+	if p := d.lastPlan().Partitioner; p != nil && !partitions.IsPreserved(p) {
+		log.Warn("Coalesce after {} is ignored, since its output is already partitioned.", d.lastStage().Name)
+		return d
+	}
+	d.lastPlan().Partitioner = partitions.NewCoalescingPartitioner(n)
+	return d
+}
+
 func (d *Dataset) Repartition(n int) *Dataset {
 	d.defaultPlan.DesiredCount = n
 	return d
@@ -100,6 +243,53 @@ func (d *Dataset) Broadcast(key string, value interface{}) *Dataset {
 	return d
 }
 
+// WithSideInput declares a side input of the last stage. The side dataset is collected and broadcast
+// to every task of the stage before the job runs, and can be looked up with Context.SideInput by the name.
+// Side inputs should be small enough to fit in the memory of each task.
+func (d *Dataset) WithSideInput(name string, side *Dataset) *Dataset {
+	if d.sideInputs == nil {
+		d.sideInputs = make(map[string]*Dataset)
+	}
+	d.sideInputs[name] = side
+	d.lastStage().SideInputs = append(d.lastStage().SideInputs, name)
+	return d
+}
+
+// Peek samples up to n output rows from each partition of the last stage, and calls fn with them on the driver
+// after the job completes, without altering the output. It is a debugging tool: sampled rows are stored
+// in the coordinator, so n should be kept small.
+func (d *Dataset) Peek(n int, fn func(*lrdd.Row)) *Dataset {
+	if len(d.stages) == 1 {
+		log.Warn("Peek on the input is ignored. Add a stage before Peek.")
+		return d
+	}
+	if d.peeks == nil {
+		d.peeks = make(map[string]func(*lrdd.Row))
+	}
+	d.peeks[d.lastStage().Name] = fn
+	d.lastStage().Peek = n
+	return d
+}
+
+// WithFailureTolerance lets the last stage succeed with up to given fraction of its partitions failed,
+// for best-effort jobs. Rows of the failed partitions are missing from the output, and the partitions can be
+// found with RunningJob.DroppedPartitions. The stage fails as usual once more partitions fail.
+// It applies only to the last stage at the time of the call. Output rows of the tasks of the stage are held
+// in memory until the tasks succeed, so that a dropped partition never delivers a part of its rows,
+// and rows sent to a dropped partition by upstream tasks are discarded without failing them.
+func (d *Dataset) WithFailureTolerance(maxFailedFraction float64) *Dataset {
+	if len(d.stages) == 1 {
+		log.Warn("WithFailureTolerance on the input is ignored. Add a stage before WithFailureTolerance.")
+		return d
+	}
+	if maxFailedFraction < 0 || maxFailedFraction >= 1 {
+		log.Warn("WithFailureTolerance on {} is ignored, since {} is not in [0, 1).", d.lastStage().Name, maxFailedFraction)
+		return d
+	}
+	d.lastStage().FailureTolerance = maxFailedFraction
+	return d
+}
+
 func (d *Dataset) WithWorkerCount(n int) *Dataset {
 	d.defaultPlan.MaxNodes = n
 	return d
@@ -110,15 +300,23 @@ func (d *Dataset) WithConcurrencyPerWorker(n int) *Dataset {
 	return d
 }
 
-func (d *Dataset) Collect() ([]*lrdd.Row, error) {
-	// add collect stage for the master
-	d.PartitionedBy(master.NewCollectPartitioner()).
-		Repartition(1).
-		WithWorkerCount(1).
-		WithConcurrencyPerWorker(1).
-		addStage(master.CollectStageName, &master.Collector{})
+// Persist keeps output of the final stage in the memory of the workers after the job completes,
+// so that it can be read by another job with Session.FromJobOutput. The output is kept until the workers stop.
+func (d *Dataset) Persist() *Dataset {
+	d.persist = true
+	return d
+}
 
-	j, err := d.session.Run(d)
+// PersistCompressed is like Persist, but keeps the output compressed with given algorithm to save memory
+// of the workers. The output is decompressed when another job reads it.
+func (d *Dataset) PersistCompressed(c job.Compression) *Dataset {
+	d.persist = true
+	d.persistCompression = c
+	return d
+}
+
+func (d *Dataset) Collect() ([]*lrdd.Row, error) {
+	j, err := d.RunForCollect()
 	if err != nil {
 		return nil, err
 	}
@@ -145,6 +343,42 @@ func (d *Dataset) Collect() ([]*lrdd.Row, error) {
 	return res, nil
 }
 
+// WriteTo writes rows of the final stage into given Sink, and waits for the job to finish.
+// The writes can be paced with WithRateLimit for sinks backed by rate-limited systems.
+func (d *Dataset) WriteTo(sink Sink, opts ...WriteOption) error {
+	opt, err := buildWriteOptions(opts)
+	if err != nil {
+		return err
+	}
+	d.addStage(d.stageName(sink), &sinkTransformation{sink: sink, rateLimit: opt.RateLimit})
+
+	j, err := d.session.Run(d)
+	if err != nil {
+		return err
+	}
+	return j.Wait()
+}
+
+// ForEach runs the ForEacher with every row of the final stage on the workers, and waits for the job to finish.
+// Nothing is sent to the driver but the number of the handled rows. Since functions can't be sent to the workers,
+// the ForEacher should be a type registered with RegisterTypes. An error returned from the ForEacher fails the task.
+func (d *Dataset) ForEach(fe ForEacher) (handled int, err error) {
+	d.addStage(d.stageName(fe), &forEachTransformation{fe})
+
+	j, err := d.session.Run(d)
+	if err != nil {
+		return 0, err
+	}
+	if err := j.Wait(); err != nil {
+		return 0, err
+	}
+	m, err := j.Metrics()
+	if err != nil {
+		return 0, errors.Wrap(err, "read metrics")
+	}
+	return m[forEachMetric], nil
+}
+
 func (d *Dataset) stageName(v interface{}) string {
 	name := fmt.Sprintf("%s%d", util.NameOfType(v), d.NumStages)
 	d.NumStages += 1
@@ -155,6 +389,19 @@ func (d *Dataset) Run() (*RunningJob, error) {
 	return d.session.Run(d)
 }
 
+// RunForCollect runs the dataset with the results collected to the master.
+// The results can be retrieved with RunningJob.Collect or RunningJob.CollectPartition.
+func (d *Dataset) RunForCollect() (*RunningJob, error) {
+	// add collect stage for the master
+	d.PartitionedBy(master.NewCollectPartitioner()).
+		Repartition(1).
+		WithWorkerCount(1).
+		WithConcurrencyPerWorker(1).
+		addStage(master.CollectStageName, &master.Collector{})
+
+	return d.session.Run(d)
+}
+
 func (d *Dataset) lastStage() *stage.Stage {
 	return &d.stages[len(d.stages)-1]
 }
@@ -0,0 +1,97 @@
+package lrmr
+
+import (
+	"context"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+// OutputSummary describes the persisted output of a job, from which deferred stages are built.
+type OutputSummary struct {
+	JobID string
+
+	// Rows is the number of rows in the output.
+	Rows int
+
+	// Partitions is the number of partitions in the output.
+	Partitions int
+}
+
+// DeferredDataset is a dataset whose following stages are built after its preceding stages complete.
+type DeferredDataset struct {
+	upstream *Dataset
+	build    func(next *Dataset, out OutputSummary) *Dataset
+}
+
+// Then defers building the following stages until the stages so far complete, so that the stages can be decided
+// by the data (e.g. a schema inferred from the input). The stages so far are run as a job with persisted output,
+// and the dataset returned by build, which reads the output, is submitted as a dependent job.
+func (d *Dataset) Then(build func(next *Dataset, out OutputSummary) *Dataset) *DeferredDataset {
+	return &DeferredDataset{
+		upstream: d,
+		build:    build,
+	}
+}
+
+func (dd *DeferredDataset) Run() (*RunningJob, error) {
+	ds, err := dd.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return ds.Run()
+}
+
+// RunForCollect is like Dataset.RunForCollect, running the dataset built from the output of the preceding stages.
+func (dd *DeferredDataset) RunForCollect() (*RunningJob, error) {
+	ds, err := dd.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return ds.RunForCollect()
+}
+
+func (dd *DeferredDataset) Collect() ([]*lrdd.Row, error) {
+	ds, err := dd.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return ds.Collect()
+}
+
+// resolve runs the preceding stages and builds the following stages from their output.
+func (dd *DeferredDataset) resolve() (*Dataset, error) {
+	upstream, err := dd.upstream.Persist().Run()
+	if err != nil {
+		return nil, errors.WithMessage(err, "run preceding stages")
+	}
+	if err := upstream.Wait(); err != nil {
+		return nil, err
+	}
+	sess := dd.upstream.session
+	out, err := summarizeOutput(sess.ctx, sess, upstream.Job.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "summarize output of job %s", upstream.Job.ID)
+	}
+	next := dd.build(sess.FromJobOutput(out.JobID), out)
+	if next == nil {
+		return nil, errors.New("no dataset built from the output")
+	}
+	return next, nil
+}
+
+func summarizeOutput(ctx context.Context, sess *Session, jobID string) (OutputSummary, error) {
+	locations, err := sess.master.JobManager.ListPersistedOutputs(ctx, jobID)
+	if err != nil {
+		return OutputSummary{}, err
+	}
+	rows, err := sess.master.JobManager.CountPersistedRows(ctx, jobID)
+	if err != nil {
+		return OutputSummary{}, err
+	}
+	return OutputSummary{
+		JobID:      jobID,
+		Rows:       rows,
+		Partitions: len(locations),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package lrmr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ab180/lrmr/partitions"
+)
+
+// ToDOT describes stages of the dataset as a directed graph in Graphviz DOT language, labeled with
+// planned number of partitions of each stage and partitioners between the stages.
+// It does not require a running cluster, so the number of partitions which depends on
+// available executors is shown as "auto".
+func (d *Dataset) ToDOT() string {
+	counts := d.plannedPartitionCounts()
+
+	var b strings.Builder
+	b.WriteString("digraph lrmr {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for i, s := range d.stages {
+		label := s.Name + "\n" + describePartitionCount(counts[i])
+		_, _ = fmt.Fprintf(&b, "  %s [label=%s];\n", strconv.Quote(s.Name), strconv.Quote(label))
+	}
+	for i := 0; i+1 < len(d.stages); i++ {
+		_, _ = fmt.Fprintf(&b, "  %s -> %s [label=%s];\n",
+			strconv.Quote(d.stages[i].Name),
+			strconv.Quote(d.stages[i+1].Name),
+			strconv.Quote(nameOfPartitioner(d.partitionerOf(i))))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// partitionerOf returns output partitioner of the stage, which would be set on scheduling.
+func (d *Dataset) partitionerOf(i int) partitions.Partitioner {
+	if p := d.plans[i].Partitioner; p != nil {
+		return p
+	}
+	return partitions.DefaultPartitioner(d.plans, i)
+}
+
+// plannedPartitionCounts returns number of partitions of each stage following partitions.Schedule.
+// The count is -1 if it depends on available executors.
+func (d *Dataset) plannedPartitionCounts() []int {
+	counts := make([]int, len(d.stages))
+	for i := range d.stages {
+		if i == 0 {
+			counts[i] = 1
+			continue
+		}
+		prev := d.partitionerOf(i - 1)
+		if _, ok := partitions.UnwrapPartitioner(prev).(partitions.MirroringPartitioner); ok || partitions.IsPreserved(prev) {
+			counts[i] = counts[i-1]
+			continue
+		}
+		// partitioners returning partitions without executors decide the number by themselves
+		planned := prev.PlanNext(d.plans[i].DesiredCount)
+		if len(planned) == 0 && d.plans[i].DesiredCount == partitions.Auto {
+			counts[i] = -1
+			continue
+		}
+		counts[i] = len(planned)
+	}
+	return counts
+}
+
+func describePartitionCount(n int) string {
+	switch n {
+	case -1:
+		return "auto partitions"
+	case 1:
+		return "1 partition"
+	default:
+		return fmt.Sprintf("%d partitions", n)
+	}
+}
+
+func nameOfPartitioner(p partitions.Partitioner) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", partitions.UnwrapPartitioner(p)), "*")
+}
@@ -0,0 +1,58 @@
+package lrmr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDataset_ToDOT(t *testing.T) {
+	Convey("Given a multi-stage dataset", t, func() {
+		sess := NewSession(context.Background(), nil)
+		ds := sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6}, 3).
+			Map(&dotTestMapper{}).
+			GroupByKnownKeys([]string{"odd", "even"}).
+			Reduce(&dotTestReducer{}).
+			Map(&dotTestMapper{})
+
+		dot := ds.ToDOT()
+
+		Convey("It should describe each stage with its number of partitions", func() {
+			So(dot, ShouldStartWith, "digraph lrmr {\n")
+			So(dot, ShouldContainSubstring, `"_input" [label="_input\n1 partition"];`)
+			So(dot, ShouldContainSubstring, `"dotTestMapper0" [label="dotTestMapper0\n3 partitions"];`)
+			So(dot, ShouldContainSubstring, `"dotTestReducer1" [label="dotTestReducer1\n2 partitions"];`)
+			So(dot, ShouldContainSubstring, `"dotTestMapper2" [label="dotTestMapper2\n2 partitions"];`)
+		})
+
+		Convey("It should connect adjacent stages with their partitioners", func() {
+			So(dot, ShouldContainSubstring, `"_input" -> "dotTestMapper0" [label="lrmr.chunkedInput"];`)
+			So(dot, ShouldContainSubstring, `"dotTestMapper0" -> "dotTestReducer1" [label="partitions.FiniteKeyPartitioner"];`)
+			So(dot, ShouldContainSubstring, `"dotTestReducer1" -> "dotTestMapper2" [label="partitions.PreservePartitioner"];`)
+		})
+
+		Convey("Number of partitions depending on executors should be shown as auto", func() {
+			dot := sess.Parallelize([]int{1, 2, 3}).Map(&dotTestMapper{}).ToDOT()
+			So(dot, ShouldContainSubstring, `"dotTestMapper0" [label="dotTestMapper0\nauto partitions"];`)
+			So(dot, ShouldContainSubstring, `"_input" -> "dotTestMapper0" [label="lrmr.parallelizedInput"];`)
+		})
+	})
+}
+
+type dotTestMapper struct{}
+
+func (*dotTestMapper) Map(_ Context, row *lrdd.Row) (*lrdd.Row, error) {
+	return row, nil
+}
+
+type dotTestReducer struct{}
+
+func (*dotTestReducer) InitialValue() interface{} {
+	return 0
+}
+
+func (*dotTestReducer) Reduce(_ Context, prev interface{}, _ *lrdd.Row) (interface{}, error) {
+	return prev.(int) + 1, nil
+}
@@ -0,0 +1,156 @@
+package lrmr
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Environment variables read by OptionsFromEnv.
+const (
+	// EnvEtcdEndpoints is a comma-separated list of etcd endpoints (e.g. "etcd-0:2379,etcd-1:2379").
+	EnvEtcdEndpoints = "LRMR_ETCD_ENDPOINTS"
+	EnvEtcdNamespace = "LRMR_ETCD_NAMESPACE"
+
+	EnvMasterListenHost     = "LRMR_MASTER_LISTEN_HOST"
+	EnvMasterAdvertisedHost = "LRMR_MASTER_ADVERTISED_HOST"
+	EnvWorkerListenHost     = "LRMR_WORKER_LISTEN_HOST"
+	EnvWorkerAdvertisedHost = "LRMR_WORKER_ADVERTISED_HOST"
+	EnvWorkerConcurrency    = "LRMR_WORKER_CONCURRENCY"
+
+	// EnvNodeTags is a comma-separated list of tags of the worker in key=value form (e.g. "zone=a,gpu=true").
+	EnvNodeTags = "LRMR_NODE_TAGS"
+
+	// EnvTLSCertPath and EnvTLSCertServerName configure TLS of connections between the nodes.
+	EnvTLSCertPath       = "LRMR_TLS_CERT_PATH"
+	EnvTLSCertServerName = "LRMR_TLS_CERT_SERVER_NAME"
+)
+
+// OptionsFromEnv returns the default options overridden by the environment variables which are set,
+// so that deployments (e.g. containers) can be configured without code changes. Empty variables are
+// regarded as unset. It returns an error describing the variable if any of them is invalid.
+func OptionsFromEnv() (Options, error) {
+	opt := DefaultOptions()
+	if err := ApplyEnv(&opt); err != nil {
+		return Options{}, err
+	}
+	return opt, nil
+}
+
+// ApplyEnv overrides the options with the environment variables which are set. It is like OptionsFromEnv,
+// but the variables take precedence over the options configured in code instead of the defaults.
+func ApplyEnv(opt *Options) error {
+	if v, ok := lookupEnv(EnvEtcdEndpoints); ok {
+		endpoints, err := parseEtcdEndpoints(v)
+		if err != nil {
+			return errors.Wrap(err, EnvEtcdEndpoints)
+		}
+		opt.EtcdEndpoints = endpoints
+	}
+	if v, ok := lookupEnv(EnvEtcdNamespace); ok {
+		opt.EtcdNamespace = v
+	}
+
+	hosts := []struct {
+		key      string
+		dst      *string
+		listener bool
+	}{
+		{EnvMasterListenHost, &opt.Master.ListenHost, true},
+		{EnvMasterAdvertisedHost, &opt.Master.AdvertisedHost, false},
+		{EnvWorkerListenHost, &opt.Worker.ListenHost, true},
+		{EnvWorkerAdvertisedHost, &opt.Worker.AdvertisedHost, false},
+	}
+	for _, h := range hosts {
+		v, ok := lookupEnv(h.key)
+		if !ok {
+			continue
+		}
+		if err := validateHost(v, h.listener); err != nil {
+			return errors.Wrap(err, h.key)
+		}
+		*h.dst = v
+	}
+
+	if v, ok := lookupEnv(EnvWorkerConcurrency); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return errors.Errorf("%s: %q is not a positive integer", EnvWorkerConcurrency, v)
+		}
+		opt.Worker.Concurrency = n
+	}
+	if v, ok := lookupEnv(EnvNodeTags); ok {
+		tags, err := parseNodeTags(v)
+		if err != nil {
+			return errors.Wrap(err, EnvNodeTags)
+		}
+		opt.Worker.NodeTags = tags
+	}
+
+	if v, ok := lookupEnv(EnvTLSCertPath); ok {
+		if _, err := os.Stat(v); err != nil {
+			return errors.Wrapf(err, "%s: TLS certificate", EnvTLSCertPath)
+		}
+		opt.Master.RPC.TLSCertPath = v
+		opt.Worker.RPC.TLSCertPath = v
+	}
+	if v, ok := lookupEnv(EnvTLSCertServerName); ok {
+		opt.Master.RPC.TLSCertServerName = v
+		opt.Worker.RPC.TLSCertServerName = v
+	}
+	return nil
+}
+
+func lookupEnv(key string) (string, bool) {
+	v := strings.TrimSpace(os.Getenv(key))
+	return v, v != ""
+}
+
+func parseEtcdEndpoints(v string) ([]string, error) {
+	var endpoints []string
+	for _, e := range strings.Split(v, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			return nil, errors.Errorf("empty endpoint in %q", v)
+		}
+		hostPort := e
+		if i := strings.Index(e, "://"); i >= 0 {
+			hostPort = e[i+3:]
+		}
+		if err := validateHost(hostPort, false); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, nil
+}
+
+// validateHost checks that the host is in host:port form. Listeners can also bind Unix domain sockets.
+func validateHost(v string, listener bool) error {
+	if listener && strings.HasPrefix(v, "unix://") {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return errors.Errorf("%q is not in host:port form", v)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return errors.Errorf("invalid port in %q", v)
+	}
+	return nil
+}
+
+func parseNodeTags(v string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("%q is not in key=value form", pair)
+		}
+		tags[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return tags, nil
+}
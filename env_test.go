@@ -0,0 +1,95 @@
+package lrmr
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	Convey("Given environment variables configuring a node", t, func() {
+		cert, err := ioutil.TempFile("", "lrmr-cert")
+		So(err, ShouldBeNil)
+		Reset(func() { _ = os.Remove(cert.Name()) })
+
+		setEnv(map[string]string{
+			EnvEtcdEndpoints:        "etcd-0:2379, http://etcd-1:2379",
+			EnvEtcdNamespace:        "prod/",
+			EnvMasterListenHost:     "0.0.0.0:7600",
+			EnvMasterAdvertisedHost: "master.lrmr:7600",
+			EnvWorkerListenHost:     "unix:///var/run/lrmr.sock",
+			EnvWorkerAdvertisedHost: "worker-0.lrmr:7466",
+			EnvWorkerConcurrency:    "12",
+			EnvNodeTags:             "zone=a, gpu=true",
+			EnvTLSCertPath:          cert.Name(),
+			EnvTLSCertServerName:    "lrmr",
+		})
+
+		Convey("Options should be overridden by them", func() {
+			opt, err := OptionsFromEnv()
+			So(err, ShouldBeNil)
+			So(opt.EtcdEndpoints, ShouldResemble, []string{"etcd-0:2379", "http://etcd-1:2379"})
+			So(opt.EtcdNamespace, ShouldEqual, "prod/")
+			So(opt.Master.ListenHost, ShouldEqual, "0.0.0.0:7600")
+			So(opt.Master.AdvertisedHost, ShouldEqual, "master.lrmr:7600")
+			So(opt.Worker.ListenHost, ShouldEqual, "unix:///var/run/lrmr.sock")
+			So(opt.Worker.AdvertisedHost, ShouldEqual, "worker-0.lrmr:7466")
+			So(opt.Worker.Concurrency, ShouldEqual, 12)
+			So(opt.Worker.NodeTags, ShouldResemble, map[string]string{"zone": "a", "gpu": "true"})
+			So(opt.Master.RPC.TLSCertPath, ShouldEqual, cert.Name())
+			So(opt.Worker.RPC.TLSCertPath, ShouldEqual, cert.Name())
+			So(opt.Worker.RPC.TLSCertServerName, ShouldEqual, "lrmr")
+
+			Convey("Options not set by them should be defaults", func() {
+				So(opt.Worker.Input.QueueLength, ShouldEqual, DefaultOptions().Worker.Input.QueueLength)
+			})
+		})
+
+		Convey("They should take precedence over options configured in code", func() {
+			opt := DefaultOptions()
+			opt.EtcdNamespace = "dev/"
+			opt.Worker.TaskPoolSize = 4
+			So(ApplyEnv(&opt), ShouldBeNil)
+			So(opt.EtcdNamespace, ShouldEqual, "prod/")
+			So(opt.Worker.TaskPoolSize, ShouldEqual, 4)
+		})
+
+		invalid := map[string]string{
+			EnvEtcdEndpoints:        "etcd-0:2379,,etcd-1:2379",
+			EnvMasterAdvertisedHost: "master.lrmr",
+			EnvWorkerListenHost:     "0.0.0.0:99999",
+			EnvWorkerConcurrency:    "-1",
+			EnvNodeTags:             "zone",
+			EnvTLSCertPath:          "/nonexistent/cert.pem",
+		}
+		for key, value := range invalid {
+			key, value := key, value
+			Convey("An invalid value of "+key+" should be reported", func() {
+				setEnv(map[string]string{key: value})
+
+				_, err := OptionsFromEnv()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, key)
+			})
+		}
+	})
+}
+
+// setEnv sets the environment variables until the end of the test.
+func setEnv(env map[string]string) {
+	for key, value := range env {
+		prev, existed := os.LookupEnv(key)
+		So(os.Setenv(key, value), ShouldBeNil)
+
+		key := key
+		Reset(func() {
+			if existed {
+				_ = os.Setenv(key, prev)
+			} else {
+				_ = os.Unsetenv(key)
+			}
+		})
+	}
+}
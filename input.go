@@ -1,12 +1,16 @@
 package lrmr
 
 import (
+	"bufio"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/output"
 	"github.com/ab180/lrmr/partitions"
+	"github.com/pkg/errors"
 )
 
 type InputProvider interface {
@@ -14,6 +18,23 @@ type InputProvider interface {
 	FeedInput(out output.Output) error
 }
 
+// isReplayable returns true if the input can be fed again, e.g. to run the job again on other nodes.
+// Inputs reading a stream and custom inputs are not regarded as replayable.
+func isReplayable(in InputProvider) bool {
+	switch in := in.(type) {
+	case *localInput, *parallelizedInput, *chunkedInput, *jobOutputInput:
+		return true
+	case *unionInput:
+		for _, sub := range in.inputs {
+			if !isReplayable(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 type localInput struct {
 	partitions.ShuffledPartitioner
 	Path string
@@ -36,3 +57,131 @@ type parallelizedInput struct {
 func (p parallelizedInput) FeedInput(out output.Output) error {
 	return out.Write(p.data...)
 }
+
+// unionInput feeds rows of the inputs one after another into a single input stage. Rows are distributed to
+// the partitions like parallelizedInput, regardless of the partitioning of each input.
+type unionInput struct {
+	partitions.ShuffledPartitioner
+	inputs []InputProvider
+}
+
+func (u unionInput) FeedInput(out output.Output) error {
+	for _, in := range u.inputs {
+		if err := in.FeedInput(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readerChunkSize is the size of chunks of a reader given to the split function.
+const readerChunkSize = 64 * 1024
+
+// readerInput streams rows from a reader on the driver. The reader is read in chunks of whole lines,
+// which are converted into rows with the split function and pushed before reading the next chunk.
+type readerInput struct {
+	partitions.ShuffledPartitioner
+	r     io.Reader
+	split func([]byte) []*lrdd.Row
+}
+
+func (r readerInput) FeedInput(out output.Output) error {
+	br := bufio.NewReaderSize(r.r, readerChunkSize)
+	chunk := make([]byte, 0, readerChunkSize)
+	for {
+		line, err := br.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
+			return errors.Wrap(err, "read")
+		}
+		chunk = append(chunk, line...)
+
+		// a line longer than the buffer is read across iterations, and is never split
+		eof := err == io.EOF
+		if len(chunk) > 0 && ((err == nil && len(chunk) >= readerChunkSize) || eof) {
+			if rows := r.split(chunk); len(rows) > 0 {
+				if err := out.Write(rows...); err != nil {
+					return err
+				}
+			}
+			chunk = make([]byte, 0, readerChunkSize)
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+// chunkedInput splits data into a fixed number of partitions with contiguous chunks.
+// See Session.ParallelizeN for the distribution rule.
+type chunkedInput struct {
+	NumPartitions int
+
+	data []*lrdd.Row
+
+	// indexes are positions of the rows in data, so that each row is assigned to its chunk
+	// regardless of the order it is fed in.
+	indexes map[*lrdd.Row]int
+}
+
+func newChunkedInput(data []*lrdd.Row, numPartitions int) *chunkedInput {
+	indexes := make(map[*lrdd.Row]int, len(data))
+	for i, row := range data {
+		indexes[row] = i
+	}
+	return &chunkedInput{
+		NumPartitions: numPartitions,
+		data:          data,
+		indexes:       indexes,
+	}
+}
+
+func (c *chunkedInput) PlanNext(int) []partitions.Partition {
+	return partitions.PlanForNumberOf(c.NumPartitions)
+}
+
+// DeterminePartition assigns the row to the chunk of its position in the data.
+func (c *chunkedInput) DeterminePartition(_ partitions.Context, r *lrdd.Row, _ int) (id string, err error) {
+	i, ok := c.indexes[r]
+	if !ok {
+		return "", errors.Errorf("row with key %q is not in the input", r.Key)
+	}
+	chunkSize, remainder := len(c.data)/c.NumPartitions, len(c.data)%c.NumPartitions
+	// first partitions have one more row than the others
+	if i < remainder*(chunkSize+1) {
+		return strconv.Itoa(i / (chunkSize + 1)), nil
+	}
+	return strconv.Itoa(remainder + (i-remainder*(chunkSize+1))/chunkSize), nil
+}
+
+func (c *chunkedInput) FeedInput(out output.Output) error {
+	return out.Write(c.data...)
+}
+
+// jobOutputInput reads the persisted output of a previous job. Partitions are assigned
+// to the nodes where the output is persisted, so that the rows are read locally without the master.
+type jobOutputInput struct {
+	JobID string
+
+	// locations are resolved on running the job.
+	locations partitions.Assignments
+}
+
+func (j *jobOutputInput) PlanNext(int) []partitions.Partition {
+	pp := make([]partitions.Partition, len(j.locations))
+	for i, l := range j.locations {
+		pp[i] = partitions.Partition{
+			ID:                 l.PartitionID,
+			AssignmentAffinity: map[string]string{"Host": l.Host},
+		}
+	}
+	return pp
+}
+
+func (j *jobOutputInput) DeterminePartition(partitions.Context, *lrdd.Row, int) (id string, err error) {
+	return "", partitions.ErrNoOutput
+}
+
+// FeedInput does nothing since the persisted output is fed by the workers.
+func (j *jobOutputInput) FeedInput(output.Output) error {
+	return nil
+}
@@ -1,6 +1,11 @@
 package input
 
-import "github.com/ab180/lrmr/job"
+import (
+	"github.com/ab180/lrmr/job"
+	"github.com/airbloc/logger"
+)
+
+var log = logger.New("input")
 
 type Input interface {
 	CloseWithStatus(s job.Status) error
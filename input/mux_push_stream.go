@@ -0,0 +1,70 @@
+package input
+
+import (
+	"context"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/pkg/errors"
+)
+
+// MuxPushStream is an input receiving rows of the source partition for multiple tasks from a stream.
+// Each request in the stream is routed to the reader of its destination task.
+type MuxPushStream struct {
+	stream     lrmrpb.Node_PushDataServer
+	dests      map[string]MuxDestination
+	source     string
+	dispatcher *streamDispatcher
+}
+
+// MuxDestination is a task receiving rows from MuxPushStream.
+type MuxDestination struct {
+	Reader *Reader
+
+	// Context is done when the task stops taking its input. Rows to the task are discarded after that,
+	// so that a failure of the task does not affect other tasks on the stream.
+	Context context.Context
+}
+
+// NewMuxPushStream creates an input dispatching rows from the stream to destinations by their task IDs.
+// If resumer is given, the stream can be resumed by another stream with the same header on a transient failure.
+func NewMuxPushStream(dests map[string]MuxDestination, stream lrmrpb.Node_PushDataServer, h *lrmrpb.DataHeader, resumer *Resumer) *MuxPushStream {
+	p := &MuxPushStream{
+		stream: stream,
+		dests:  dests,
+		source: h.FromPartitionID,
+	}
+	p.dispatcher = newStreamDispatcher(resumer, StreamKey(h), func(ctx context.Context, req *timedRequest) error {
+		d, ok := p.dests[req.TaskID]
+		if !ok {
+			return errors.Errorf("unknown destination task %s", req.TaskID)
+		}
+		if d.Context.Err() != nil {
+			return nil
+		}
+		d.Reader.opt.timer.Add(req.elapsed)
+		if err := d.Reader.WriteContext(d.Context, p.source, req.Data); err != nil && d.Context.Err() == nil {
+			return err
+		}
+		return nil
+	})
+	return p
+}
+
+// Dispatch routes rows from the stream until it ends or the context is done. The context is for stopping
+// the whole stream, e.g. after every destination has failed.
+func (p *MuxPushStream) Dispatch(ctx context.Context) error {
+	for _, d := range p.dests {
+		d.Reader.Add(p.source, p)
+	}
+	defer func() {
+		for _, d := range p.dests {
+			d.Reader.Done(p.source)
+		}
+	}()
+	return p.dispatcher.dispatch(ctx, p.stream)
+}
+
+func (p *MuxPushStream) CloseWithStatus(st job.Status) error {
+	return p.stream.SendMsg(st)
+}
@@ -2,56 +2,38 @@ package input
 
 import (
 	"context"
-	"io"
 
 	"github.com/ab180/lrmr/job"
 	"github.com/ab180/lrmr/lrmrpb"
-	"github.com/airbloc/logger"
-	"github.com/pkg/errors"
 )
 
 type PushStream struct {
-	stream lrmrpb.Node_PushDataServer
-	reader *Reader
+	stream     lrmrpb.Node_PushDataServer
+	reader     *Reader
+	source     string
+	dispatcher *streamDispatcher
 }
 
-func NewPushStream(r *Reader, stream lrmrpb.Node_PushDataServer) *PushStream {
-	return &PushStream{
+// NewPushStream creates an input receiving rows of the source partition in the header from the stream.
+// If resumer is given, the stream can be resumed by another stream with the same header on a transient failure.
+func NewPushStream(r *Reader, stream lrmrpb.Node_PushDataServer, h *lrmrpb.DataHeader, resumer *Resumer) *PushStream {
+	p := &PushStream{
 		stream: stream,
 		reader: r,
+		source: h.FromPartitionID,
 	}
+	p.dispatcher = newStreamDispatcher(resumer, StreamKey(h), func(ctx context.Context, req *timedRequest) error {
+		p.reader.opt.timer.Add(req.elapsed)
+		return p.reader.WriteContext(ctx, p.source, req.Data)
+	})
+	return p
 }
 
 func (p *PushStream) Dispatch(ctx context.Context) error {
-	p.reader.Add(p)
-	defer p.reader.Done()
+	p.reader.Add(p.source, p)
+	defer p.reader.Done(p.source)
 
-	errChan := make(chan error)
-	go func() {
-		defer func() {
-			if err := logger.WrapRecover(recover()); err != nil {
-				errChan <- err
-			}
-		}()
-		for {
-			req, err := p.stream.Recv()
-			if err != nil {
-				errChan <- err
-				return
-			}
-			p.reader.C <- req.Data
-		}
-	}()
-
-	select {
-	case err := <-errChan:
-		if err == io.EOF || err == context.Canceled {
-			return nil
-		}
-		return errors.Wrap(err, "stream dispatch")
-	case <-ctx.Done():
-		return ctx.Err()
-	}
+	return p.dispatcher.dispatch(ctx, p.stream)
 }
 
 func (p *PushStream) CloseWithStatus(st job.Status) error {
@@ -1,12 +1,20 @@
 package input
 
 import (
+	"context"
+	"strings"
+
+	"github.com/ab180/lrmr/internal/serialization"
 	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
 	"go.uber.org/atomic"
 
 	"sync"
 )
 
+// mergedBatchSize is the maximum number of rows in a batch sent to Reader.C after merge-sorting.
+const mergedBatchSize = 100
+
 type Reader struct {
 	C chan []*lrdd.Row
 
@@ -14,24 +22,103 @@ type Reader struct {
 	lock      sync.RWMutex
 	activeCnt atomic.Int64
 	closed    atomic.Bool
+
+	queueLen int
+	opt      readerOptions
+
+	// sources are per-source queues used for merge-sorting.
+	sources      map[string]*sourceQueue
+	sourceAdded  chan chan []*lrdd.Row
+	closedSignal chan struct{}
+}
+
+type sourceQueue struct {
+	rows      chan []*lrdd.Row
+	activeCnt int
 }
 
-func NewReader(queueLen int) *Reader {
-	return &Reader{
-		C: make(chan []*lrdd.Row, queueLen),
+func NewReader(queueLen int, opts ...ReaderOption) *Reader {
+	var opt readerOptions
+	for _, optFn := range opts {
+		optFn(&opt)
 	}
+	r := &Reader{
+		C:            make(chan []*lrdd.Row, queueLen),
+		queueLen:     queueLen,
+		opt:          opt,
+		closedSignal: make(chan struct{}),
+	}
+	if opt.mergeSortSources > 0 {
+		r.sources = make(map[string]*sourceQueue)
+		r.sourceAdded = make(chan chan []*lrdd.Row, opt.mergeSortSources)
+		go r.mergeSort()
+	}
+	return r
 }
 
-func (p *Reader) Add(in Input) {
+// Add adds an input sending rows from the source partition.
+func (p *Reader) Add(source string, in Input) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
 	p.inputs = append(p.inputs, in)
 	p.activeCnt.Inc()
+	if p.sources != nil {
+		q, ok := p.sources[source]
+		if !ok {
+			q = &sourceQueue{rows: make(chan []*lrdd.Row, p.queueLen)}
+			p.sources[source] = q
+			if len(p.sources) <= p.opt.mergeSortSources {
+				p.sourceAdded <- q.rows
+			}
+		}
+		q.activeCnt++
+	}
+}
+
+// Write sends rows from the source partition to the reader.
+func (p *Reader) Write(source string, rows []*lrdd.Row) {
+	_ = p.WriteContext(context.Background(), source, rows)
 }
 
-func (p *Reader) Done() {
+// WriteContext is like Write, but gives up the rows if the context is done while the reader is full
+// (e.g. the task reading it has failed).
+func (p *Reader) WriteContext(ctx context.Context, source string, rows []*lrdd.Row) error {
+	dest := p.C
+	if p.sources != nil {
+		p.lock.RLock()
+		q := p.sources[source]
+		p.lock.RUnlock()
+		if q == nil {
+			return errors.Errorf("rows from unknown source %s", source)
+		}
+		dest = q.rows
+	}
+	select {
+	case dest <- rows:
+		return nil
+	case <-p.closedSignal:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done notifies that an input from the source partition has finished.
+func (p *Reader) Done(source string) {
 	newActiveCnt := p.activeCnt.Dec()
+	if p.sources != nil {
+		// closing the queue lets the merger know that the source has ended
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		if q := p.sources[source]; q != nil {
+			q.activeCnt--
+			if q.activeCnt == 0 {
+				close(q.rows)
+			}
+		}
+		return
+	}
 	if newActiveCnt == 0 {
 		p.Close()
 	}
@@ -43,6 +130,115 @@ func (p *Reader) Close() {
 		return
 	}
 	// with CAS, only one goroutines can enter here
+	close(p.closedSignal)
+	if p.sources != nil {
+		// C is closed by the merger
+		return
+	}
 	close(p.C)
 	p.inputs = nil
 }
+
+// mergeSort merges rows from the sources into C in order of their keys, assuming that the rows
+// from each source are already sorted.
+func (p *Reader) mergeSort() {
+	defer close(p.C)
+
+	compare := p.opt.compareKeys
+	if compare == nil {
+		compare = strings.Compare
+	}
+
+	type head struct {
+		rows  []*lrdd.Row
+		queue chan []*lrdd.Row
+	}
+	heads := make([]*head, 0, p.opt.mergeSortSources)
+	for len(heads) < p.opt.mergeSortSources {
+		select {
+		case q := <-p.sourceAdded:
+			heads = append(heads, &head{queue: q})
+		case <-p.closedSignal:
+			return
+		}
+	}
+
+	// fill fetches next batch of the source, removing the source if it ends.
+	fill := func(i int) bool {
+		for len(heads[i].rows) == 0 {
+			select {
+			case rows, ok := <-heads[i].queue:
+				if !ok {
+					heads = append(heads[:i], heads[i+1:]...)
+					return false
+				}
+				heads[i].rows = rows
+			case <-p.closedSignal:
+				return false
+			}
+		}
+		return true
+	}
+	send := func(batch []*lrdd.Row) bool {
+		select {
+		case p.C <- batch:
+			return true
+		case <-p.closedSignal:
+			return false
+		}
+	}
+	for i := len(heads) - 1; i >= 0; i-- {
+		fill(i)
+	}
+
+	batch := make([]*lrdd.Row, 0, mergedBatchSize)
+	for len(heads) > 0 {
+		if p.closed.Load() {
+			return
+		}
+		min := 0
+		for i := 1; i < len(heads); i++ {
+			if compare(heads[i].rows[0].Key, heads[min].rows[0].Key) < 0 {
+				min = i
+			}
+		}
+		batch = append(batch, heads[min].rows[0])
+		heads[min].rows = heads[min].rows[1:]
+		if len(batch) == mergedBatchSize {
+			if !send(batch) {
+				return
+			}
+			batch = make([]*lrdd.Row, 0, mergedBatchSize)
+		}
+		fill(min)
+	}
+	if len(batch) > 0 {
+		send(batch)
+	}
+}
+
+type readerOptions struct {
+	mergeSortSources int
+	compareKeys      func(a, b string) int
+	timer            *serialization.Timer
+}
+
+type ReaderOption func(o *readerOptions)
+
+// WithMergeSortByKey makes the reader to merge rows from given number of sources into C in order of their keys,
+// using compare for ordering keys (or lexicographic order if compare is nil).
+// Rows from each source must be already sorted with the same order. Merging starts after every source is added,
+// and sources added beyond numSources are not read.
+func WithMergeSortByKey(numSources int, compare func(a, b string) int) ReaderOption {
+	return func(o *readerOptions) {
+		o.mergeSortSources = numSources
+		o.compareKeys = compare
+	}
+}
+
+// WithSerializationTimer makes the reader to measure decoding of the rows pushed to it with the timer.
+func WithSerializationTimer(t *serialization.Timer) ReaderOption {
+	return func(o *readerOptions) {
+		o.timer = t
+	}
+}
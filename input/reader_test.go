@@ -0,0 +1,101 @@
+package input
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReader_Sources(t *testing.T) {
+	Convey("Given a reader with inputs from two upstream partitions", t, func() {
+		r := NewReader(10)
+		r.Add("0", &stubInput{})
+		r.Add("1", &stubInput{})
+
+		Convey("It should receive rows from both, and be closed after both of them are done", func() {
+			go send(r, "0", []int{1, 3, 5}, 2)
+			go send(r, "1", []int{2, 4}, 2)
+
+			keys := readKeys(r)
+			So(keys, ShouldHaveLength, 5)
+			So(keys, ShouldContain, "1")
+			So(keys, ShouldContain, "4")
+			So(r.closed.Load(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestReader_MergeSortByKey(t *testing.T) {
+	Convey("Given a Reader merge-sorting two sources", t, func() {
+		r := NewReader(2, WithMergeSortByKey(2, func(a, b string) int {
+			x, _ := strconv.Atoi(a)
+			y, _ := strconv.Atoi(b)
+			return x - y
+		}))
+
+		Convey("When two sources send sorted rows in several batches", func() {
+			r.Add("0", &stubInput{})
+			r.Add("1", &stubInput{})
+			go send(r, "0", []int{1, 3, 5, 7, 9, 11, 200}, 2)
+			go send(r, "1", []int{2, 4, 6, 8, 10, 100}, 3)
+
+			Convey("It should output rows of both sources in sorted order", func() {
+				So(readKeys(r), ShouldResemble, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "100", "200"})
+			})
+		})
+
+		Convey("When one of the sources ends early", func() {
+			r.Add("0", &stubInput{})
+			r.Add("1", &stubInput{})
+			go send(r, "0", []int{1}, 1)
+			go send(r, "1", []int{2, 3, 4, 5, 6, 7, 8, 9}, 1)
+
+			Convey("It should output the rest of rows from the remaining source", func() {
+				So(readKeys(r), ShouldResemble, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"})
+			})
+		})
+	})
+}
+
+func send(r *Reader, source string, keys []int, batchSize int) {
+	defer r.Done(source)
+
+	for i := 0; i < len(keys); i += batchSize {
+		end := i + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var rows []*lrdd.Row
+		for _, k := range keys[i:end] {
+			rows = append(rows, lrdd.KeyValue(strconv.Itoa(k), k))
+		}
+		r.Write(source, rows)
+	}
+}
+
+func readKeys(r *Reader) (keys []string) {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case rows, ok := <-r.C:
+			if !ok {
+				return keys
+			}
+			for _, row := range rows {
+				keys = append(keys, row.Key)
+			}
+		case <-timeout:
+			return keys
+		}
+	}
+}
+
+type stubInput struct{}
+
+func (s *stubInput) CloseWithStatus(job.Status) error {
+	return nil
+}
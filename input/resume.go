@@ -0,0 +1,243 @@
+package input
+
+import (
+	"context"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/airbloc/logger"
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/metadata"
+)
+
+// Resumer hands over streams resuming broken streams to the inputs which have been dispatching them,
+// so that an input survives a transient network failure without losing or duplicating rows.
+type Resumer struct {
+	timeout time.Duration
+
+	mu          sync.Mutex
+	dispatchers map[string]*streamDispatcher
+}
+
+type resumingStream struct {
+	stream lrmrpb.Node_PushDataServer
+	done   chan struct{}
+}
+
+// NewResumer creates a Resumer. An input waits for the broken stream to be resumed for the timeout,
+// and a finished input can be still asked for its last received sequence for the timeout.
+func NewResumer(timeout time.Duration) *Resumer {
+	return &Resumer{
+		timeout:     timeout,
+		dispatchers: make(map[string]*streamDispatcher),
+	}
+}
+
+// StreamKey identifies a stream by its source and destinations.
+func StreamKey(h *lrmrpb.DataHeader) string {
+	return path.Join(h.FromHost, h.FromPartitionID, h.TaskID, strings.Join(h.TaskIDs, ","))
+}
+
+// Resume hands over the stream to the input dispatching the broken stream with the key, and blocks until
+// the input finishes dispatching the stream. If the input has already finished, it only responds the
+// last received sequence so that the sender can check whether every request has been received.
+func (r *Resumer) Resume(ctx context.Context, key string, stream lrmrpb.Node_PushDataServer) error {
+	r.mu.Lock()
+	d, ok := r.dispatchers[key]
+	r.mu.Unlock()
+	if !ok {
+		return errors.Errorf("no stream to resume: %s", key)
+	}
+	rs := &resumingStream{stream: stream, done: make(chan struct{})}
+	select {
+	case d.resumes <- rs:
+	case <-d.finished:
+		return d.respondLastSeq(stream, true)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-rs.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Resumer) register(d *streamDispatcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatchers[d.key] = d
+}
+
+// unregister forgets the finished dispatcher after the timeout.
+func (r *Resumer) unregister(d *streamDispatcher) {
+	time.AfterFunc(r.timeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.dispatchers[d.key] == d {
+			delete(r.dispatchers, d.key)
+		}
+	})
+}
+
+// streamDispatcher receives requests from a stream, following streams resuming it.
+type streamDispatcher struct {
+	resumer *Resumer
+	key     string
+	write   func(context.Context, *timedRequest) error
+
+	resumes  chan *resumingStream
+	finished chan struct{}
+
+	// mu guards lastSeq, which is a sequence number of the last written request.
+	mu      sync.Mutex
+	lastSeq int64
+}
+
+func newStreamDispatcher(resumer *Resumer, key string, write func(context.Context, *timedRequest) error) *streamDispatcher {
+	return &streamDispatcher{
+		resumer:  resumer,
+		key:      key,
+		write:    write,
+		resumes:  make(chan *resumingStream),
+		finished: make(chan struct{}),
+	}
+}
+
+func (d *streamDispatcher) dispatch(ctx context.Context, stream lrmrpb.Node_PushDataServer) error {
+	if d.resumer != nil {
+		d.resumer.register(d)
+		defer d.resumer.unregister(d)
+	}
+	var resumed *resumingStream
+	defer func() {
+		close(d.finished)
+		if resumed != nil {
+			close(resumed.done)
+		}
+	}()
+
+	for {
+		stopped := atomic.NewBool(false)
+		errChan := make(chan error, 1)
+		go d.receive(ctx, stream, stopped, errChan)
+
+		select {
+		case err := <-errChan:
+			if ctx.Err() != nil {
+				// a write given up since the reading task has been cancelled
+				return ctx.Err()
+			}
+			if err == io.EOF || err == context.Canceled {
+				return nil
+			}
+			if d.resumer == nil {
+				return errors.Wrap(err, "stream dispatch")
+			}
+			log.Warn("Stream {} broken. Waiting for the stream to be resumed: {}", d.key, err)
+			select {
+			case rs := <-d.resumes:
+				d.handOver(rs, &resumed, &stream)
+			case <-time.After(d.resumer.timeout):
+				return errors.Wrap(err, "stream dispatch")
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+		case rs := <-d.resumes:
+			// the sender can notice the failure before the receiver does
+			stopped.Store(true)
+			d.handOver(rs, &resumed, &stream)
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// handOver switches the current stream to the resuming stream, and responds the last received sequence.
+func (d *streamDispatcher) handOver(rs *resumingStream, resumed **resumingStream, stream *lrmrpb.Node_PushDataServer) {
+	if err := d.respondLastSeq(rs.stream, false); err != nil {
+		log.Warn("Failed to respond last sequence to the stream resuming {}: {}", d.key, err)
+	}
+	if *resumed != nil {
+		close((*resumed).done)
+	}
+	*resumed = rs
+	*stream = rs.stream
+}
+
+// respondLastSeq sends the last received sequence to the resuming stream in its header metadata.
+// closed is set if the input has finished and does not receive requests anymore.
+func (d *streamDispatcher) respondLastSeq(stream lrmrpb.Node_PushDataServer, closed bool) error {
+	d.mu.Lock()
+	lastSeq := d.lastSeq
+	d.mu.Unlock()
+
+	return stream.SendHeader(metadata.Pairs(
+		"lastSeq", strconv.FormatInt(lastSeq, 10),
+		"closed", strconv.FormatBool(closed),
+	))
+}
+
+func (d *streamDispatcher) receive(ctx context.Context, stream lrmrpb.Node_PushDataServer, stopped *atomic.Bool, errChan chan error) {
+	defer func() {
+		if err := logger.WrapRecover(recover()); err != nil {
+			errChan <- err
+		}
+	}()
+	for {
+		req := &timedRequest{PushDataRequest: new(lrmrpb.PushDataRequest)}
+		if err := stream.RecvMsg(req); err != nil {
+			errChan <- err
+			return
+		}
+		if err := d.writeOnce(ctx, req, stopped); err != nil {
+			errChan <- err
+			return
+		}
+		if stopped.Load() {
+			return
+		}
+	}
+}
+
+// timedRequest is a PushDataRequest measuring the time spent on decoding it, which is done by gRPC on receiving it.
+type timedRequest struct {
+	*lrmrpb.PushDataRequest
+	elapsed time.Duration
+}
+
+func (r *timedRequest) Unmarshal(data []byte) error {
+	start := time.Now()
+	err := r.PushDataRequest.Unmarshal(data)
+	r.elapsed = time.Since(start)
+	return err
+}
+
+// writeOnce writes the request unless it has been already written by the broken stream.
+func (d *streamDispatcher) writeOnce(ctx context.Context, req *timedRequest, stopped *atomic.Bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if stopped.Load() || (req.Seq > 0 && req.Seq <= d.lastSeq) {
+		return nil
+	}
+	if req.Seq > d.lastSeq+1 {
+		return errors.Errorf("requests from %d to %d are missing", d.lastSeq+1, req.Seq-1)
+	}
+	if err := d.write(ctx, req); err != nil {
+		return err
+	}
+	if req.Seq > 0 {
+		d.lastSeq = req.Seq
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package lrmr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestChunkedInput_DeterminePartition(t *testing.T) {
+	Convey("Given a chunked input", t, func() {
+		Convey("When the number of rows is divisible by the number of partitions", func() {
+			Convey("Rows should be evenly distributed", func() {
+				So(chunkSizes(12, 4), ShouldResemble, map[string]int{"0": 3, "1": 3, "2": 3, "3": 3})
+			})
+		})
+
+		Convey("When the number of rows is not divisible by the number of partitions", func() {
+			Convey("The remainder should be distributed to the first partitions", func() {
+				So(chunkSizes(10, 4), ShouldResemble, map[string]int{"0": 3, "1": 3, "2": 2, "3": 2})
+			})
+		})
+
+		Convey("When the number of rows is less than the number of partitions", func() {
+			Convey("Each row should have its own partition", func() {
+				So(chunkSizes(2, 4), ShouldResemble, map[string]int{"0": 1, "1": 1})
+			})
+		})
+
+		Convey("Rows should be split into contiguous chunks", func() {
+			in := newChunkedInput(lrdd.From(make([]int, 5)), 2)
+			var ids []string
+			for _, row := range in.data {
+				id, err := in.DeterminePartition(nil, row, 2)
+				So(err, ShouldBeNil)
+				ids = append(ids, id)
+			}
+			So(ids, ShouldResemble, []string{"0", "0", "0", "1", "1"})
+		})
+
+		Convey("Rows should be assigned to the chunk of their position regardless of the order they are fed", func() {
+			in := newChunkedInput(lrdd.From(make([]int, 5)), 2)
+			ids := make([]string, len(in.data))
+			for i := len(in.data) - 1; i >= 0; i-- {
+				id, err := in.DeterminePartition(nil, in.data[i], 2)
+				So(err, ShouldBeNil)
+				ids[i] = id
+			}
+			So(ids, ShouldResemble, []string{"0", "0", "0", "1", "1"})
+
+			Convey("Rows not in the input should be rejected", func() {
+				_, err := in.DeterminePartition(nil, lrdd.Value(0), 2)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestReaderInput_FeedInput(t *testing.T) {
+	Convey("Given a reader input larger than a chunk", t, func() {
+		line := strings.Repeat("x", 1000)
+		content := strings.Repeat(line+"\n", 200)
+
+		var chunks []string
+		in := &readerInput{
+			r: bytes.NewBufferString(content),
+			split: func(chunk []byte) (rows []*lrdd.Row) {
+				chunks = append(chunks, string(chunk))
+				for _, l := range strings.Split(strings.TrimSuffix(string(chunk), "\n"), "\n") {
+					rows = append(rows, lrdd.Value(l))
+				}
+				return rows
+			},
+		}
+		out := &recordingOutput{}
+		So(in.FeedInput(out), ShouldBeNil)
+
+		Convey("It should be split into multiple chunks of whole lines", func() {
+			So(len(chunks), ShouldBeGreaterThan, 1)
+			for _, c := range chunks {
+				So(strings.HasSuffix(c, "\n"), ShouldBeTrue)
+			}
+			So(strings.Join(chunks, ""), ShouldEqual, content)
+		})
+
+		Convey("Every line should be written as a row", func() {
+			So(out.rows, ShouldHaveLength, 200)
+		})
+	})
+}
+
+// chunkSizes returns number of rows in each partition after splitting numRows rows.
+func chunkSizes(numRows, numPartitions int) map[string]int {
+	in := newChunkedInput(lrdd.From(make([]int, numRows)), numPartitions)
+	So(in.PlanNext(1), ShouldHaveLength, numPartitions)
+
+	sizes := make(map[string]int)
+	for _, row := range in.data {
+		id, err := in.DeterminePartition(nil, row, numPartitions)
+		So(err, ShouldBeNil)
+		sizes[id]++
+	}
+	return sizes
+}
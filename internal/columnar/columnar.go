@@ -0,0 +1,164 @@
+// Package columnar implements an internal file format storing rows column-major, used for
+// persisted outputs and file sinks. It is not meant to be read by other programs,
+// and its layout can be changed without compatibility.
+//
+// A file starts with a header, which is the magic, the number of rows and descriptors of the columns.
+// Each descriptor has the name, type, encoding and the size of the column, so that readers can skip
+// columns they don't need. Column blocks follow the header in the order of the descriptors.
+package columnar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+var magic = []byte("LRC1")
+
+// Column names of the rows.
+const (
+	KeyColumn     = "key"
+	ValueColumn   = "value"
+	LineageColumn = "lineage"
+)
+
+// Type is a type of values in a column.
+type Type byte
+
+const (
+	String Type = iota + 1
+	Bytes
+)
+
+// Encoding is a layout of a column block.
+type Encoding byte
+
+const (
+	// Plain stores lengths of the values followed by the values concatenated.
+	Plain Encoding = iota
+	// Dictionary stores distinct values in Plain encoding, followed by the indices of the values.
+	Dictionary
+)
+
+// ColumnDesc describes a column in the header.
+type ColumnDesc struct {
+	Name     string
+	Type     Type
+	Encoding Encoding
+	Size     int
+}
+
+// Header describes rows and columns in a file.
+type Header struct {
+	NumRows int
+	Columns []ColumnDesc
+}
+
+// Encode writes the rows to w, column-major.
+func Encode(w io.Writer, rows []*lrdd.Row) error {
+	keys := make([][]byte, len(rows))
+	values := make([][]byte, len(rows))
+	var lineages [][]byte
+	for i, r := range rows {
+		keys[i] = []byte(r.Key)
+		values[i] = r.Value
+		if len(r.Lineage) > 0 && lineages == nil {
+			lineages = make([][]byte, len(rows))
+		}
+	}
+	if lineages != nil {
+		for i, r := range rows {
+			if len(r.Lineage) == 0 {
+				continue
+			}
+			data, err := proto.Marshal(&lrdd.Row{Lineage: r.Lineage})
+			if err != nil {
+				return errors.Wrapf(err, "marshal lineage of row #%d", i)
+			}
+			lineages[i] = data
+		}
+	}
+
+	keyBlock, keyEncoding := encodeColumn(keys)
+	header := Header{
+		NumRows: len(rows),
+		Columns: []ColumnDesc{
+			{Name: KeyColumn, Type: String, Encoding: keyEncoding, Size: len(keyBlock)},
+			{Name: ValueColumn, Type: Bytes, Encoding: Plain},
+		},
+	}
+	valueBlock := encodePlain(values)
+	header.Columns[1].Size = len(valueBlock)
+	blocks := [][]byte{keyBlock, valueBlock}
+
+	if lineages != nil {
+		lineageBlock := encodePlain(lineages)
+		header.Columns = append(header.Columns, ColumnDesc{Name: LineageColumn, Type: Bytes, Encoding: Plain, Size: len(lineageBlock)})
+		blocks = append(blocks, lineageBlock)
+	}
+
+	if _, err := w.Write(encodeHeader(header)); err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeColumn encodes the values with Dictionary encoding if they repeat enough, or with Plain encoding otherwise.
+func encodeColumn(values [][]byte) ([]byte, Encoding) {
+	indices := make(map[string]int)
+	var distinct [][]byte
+	for _, v := range values {
+		if _, ok := indices[string(v)]; !ok {
+			indices[string(v)] = len(distinct)
+			distinct = append(distinct, v)
+		}
+	}
+	if len(distinct)*2 > len(values) {
+		return encodePlain(values), Plain
+	}
+	buf := bytes.NewBuffer(encodePlain(distinct))
+	for _, v := range values {
+		writeUvarint(buf, uint64(indices[string(v)]))
+	}
+	return buf.Bytes(), Dictionary
+}
+
+func encodePlain(values [][]byte) []byte {
+	buf := new(bytes.Buffer)
+	writeUvarint(buf, uint64(len(values)))
+	for _, v := range values {
+		writeUvarint(buf, uint64(len(v)))
+	}
+	for _, v := range values {
+		buf.Write(v)
+	}
+	return buf.Bytes()
+}
+
+func encodeHeader(h Header) []byte {
+	buf := bytes.NewBuffer(append([]byte(nil), magic...))
+	writeUvarint(buf, uint64(h.NumRows))
+	writeUvarint(buf, uint64(len(h.Columns)))
+	for _, c := range h.Columns {
+		writeUvarint(buf, uint64(len(c.Name)))
+		buf.WriteString(c.Name)
+		buf.WriteByte(byte(c.Type))
+		buf.WriteByte(byte(c.Encoding))
+		writeUvarint(buf, uint64(c.Size))
+	}
+	return buf.Bytes()
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
@@ -0,0 +1,124 @@
+package columnar
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type event struct {
+	ID    int
+	Name  string
+	Score float64
+	Tags  []string
+}
+
+func TestEncode(t *testing.T) {
+	Convey("Given rows of various types", t, func() {
+		rows := []*lrdd.Row{
+			lrdd.Value("foo"),
+			lrdd.KeyValue("int", 42),
+			lrdd.KeyValue("float", 3.14),
+			lrdd.KeyValue("bool", true),
+			lrdd.KeyValue("struct", event{ID: 1, Name: "click", Score: 0.5, Tags: []string{"a", "b"}}),
+			lrdd.KeyValue("map", map[string]interface{}{"foo": "bar"}),
+			lrdd.KeyValue("nil", nil),
+			{Key: "empty"},
+			{Key: "lineage", Value: []byte{1}, Lineage: []*lrdd.Lineage{{Stage: "map", PartitionID: "3", Index: 7}}},
+		}
+
+		Convey("Decoded rows should be identical", func() {
+			buf := new(bytes.Buffer)
+			So(Encode(buf, rows), ShouldBeNil)
+
+			decoded, err := Decode(buf)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldHaveLength, len(rows))
+			for i, r := range decoded {
+				So(r.Key, ShouldEqual, rows[i].Key)
+				So(r.Value, ShouldResemble, rows[i].Value)
+				So(r.Lineage, ShouldResemble, rows[i].Lineage)
+			}
+
+			var e event
+			decoded[4].UnmarshalValue(&e)
+			So(e, ShouldResemble, event{ID: 1, Name: "click", Score: 0.5, Tags: []string{"a", "b"}})
+		})
+
+		Convey("Keys should be read without the other columns", func() {
+			buf := new(bytes.Buffer)
+			So(Encode(buf, rows), ShouldBeNil)
+
+			r, err := NewReaderBytes(buf.Bytes())
+			So(err, ShouldBeNil)
+			So(r.Header.NumRows, ShouldEqual, len(rows))
+			So(r.Header.Columns, ShouldHaveLength, 3)
+
+			keys, err := r.Keys()
+			So(err, ShouldBeNil)
+			So(keys, ShouldResemble, []string{"", "int", "float", "bool", "struct", "map", "nil", "empty", "lineage"})
+		})
+	})
+
+	Convey("Given rows with repeating keys", t, func() {
+		rows := make([]*lrdd.Row, 1000)
+		for i := range rows {
+			rows[i] = lrdd.KeyValue(fmt.Sprintf("key-%d", i%10), event{ID: i, Name: "view", Score: float64(i) / 10})
+		}
+		buf := new(bytes.Buffer)
+		So(Encode(buf, rows), ShouldBeNil)
+
+		Convey("Keys should be encoded with dictionary", func() {
+			r, err := NewReaderBytes(buf.Bytes())
+			So(err, ShouldBeNil)
+			So(r.Header.Columns[0].Encoding, ShouldEqual, Dictionary)
+
+			decoded, err := r.Rows()
+			So(err, ShouldBeNil)
+			for i, row := range decoded {
+				So(row.Key, ShouldEqual, rows[i].Key)
+				So(row.Value, ShouldResemble, rows[i].Value)
+			}
+		})
+
+		Convey("It should be smaller than JSON lines", func() {
+			lines := new(bytes.Buffer)
+			for _, row := range rows {
+				line, err := jsoniter.Marshal(row)
+				So(err, ShouldBeNil)
+				lines.Write(append(line, '\n'))
+			}
+			So(buf.Len(), ShouldBeLessThan, lines.Len()*2/3)
+		})
+	})
+
+	Convey("Given no rows", t, func() {
+		buf := new(bytes.Buffer)
+		So(Encode(buf, nil), ShouldBeNil)
+
+		Convey("It should decode no rows", func() {
+			decoded, err := Decode(buf)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a corrupted data", t, func() {
+		buf := new(bytes.Buffer)
+		So(Encode(buf, lrdd.From([]string{"foo", "bar", "baz"})), ShouldBeNil)
+
+		Convey("Decoding a truncated data should fail", func() {
+			_, err := Decode(bytes.NewReader(buf.Bytes()[:buf.Len()-2]))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Decoding a data without the magic should fail", func() {
+			_, err := Decode(bytes.NewReader(buf.Bytes()[1:]))
+			So(err, ShouldEqual, ErrInvalidFormat)
+		})
+	})
+}
@@ -0,0 +1,210 @@
+package columnar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidFormat is returned when reading data which is not written by Encode.
+var ErrInvalidFormat = errors.New("invalid columnar format")
+
+// Reader reads columns of encoded rows. Only the columns being read are decoded.
+type Reader struct {
+	Header Header
+	blocks map[string][]byte
+}
+
+// NewReader reads every column block from r.
+func NewReader(r io.Reader) (*Reader, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewReaderBytes(data)
+}
+
+// NewReaderBytes reads column blocks from the encoded data, without copying it.
+func NewReaderBytes(data []byte) (*Reader, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, ErrInvalidFormat
+	}
+	d := &decoder{data: data[len(magic):]}
+
+	var h Header
+	h.NumRows = d.int()
+	numColumns := d.int()
+	for i := 0; i < numColumns && d.err == nil; i++ {
+		var c ColumnDesc
+		c.Name = string(d.next(d.int()))
+		c.Type = Type(d.byte())
+		c.Encoding = Encoding(d.byte())
+		c.Size = d.int()
+		h.Columns = append(h.Columns, c)
+	}
+	blocks := make(map[string][]byte, len(h.Columns))
+	for _, c := range h.Columns {
+		blocks[c.Name] = d.next(c.Size)
+	}
+	if d.err != nil {
+		return nil, errors.Wrap(d.err, "read header")
+	}
+	return &Reader{Header: h, blocks: blocks}, nil
+}
+
+// Column decodes values of the column with given name. It returns nil if the column does not exist.
+func (r *Reader) Column(name string) ([][]byte, error) {
+	block, ok := r.blocks[name]
+	if !ok {
+		return nil, nil
+	}
+	var encoding Encoding
+	for _, c := range r.Header.Columns {
+		if c.Name == name {
+			encoding = c.Encoding
+		}
+	}
+	d := &decoder{data: block}
+	values := d.plain()
+	if encoding == Dictionary {
+		dict := values
+		values = make([][]byte, r.Header.NumRows)
+		for i := range values {
+			idx := d.uvarint()
+			if d.err == nil && idx >= uint64(len(dict)) {
+				return nil, errors.Wrapf(ErrInvalidFormat, "dictionary index %d out of %d", idx, len(dict))
+			}
+			values[i] = dict[idx]
+		}
+	}
+	if d.err != nil {
+		return nil, errors.Wrapf(d.err, "decode column %s", name)
+	}
+	if len(values) != r.Header.NumRows {
+		return nil, errors.Wrapf(ErrInvalidFormat, "column %s has %d values, expected %d", name, len(values), r.Header.NumRows)
+	}
+	return values, nil
+}
+
+// Keys decodes only the keys of the rows.
+func (r *Reader) Keys() ([]string, error) {
+	values, err := r.Column(KeyColumn)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(values))
+	for i, v := range values {
+		keys[i] = string(v)
+	}
+	return keys, nil
+}
+
+// Rows decodes every column into the rows.
+func (r *Reader) Rows() ([]*lrdd.Row, error) {
+	keys, err := r.Column(KeyColumn)
+	if err != nil {
+		return nil, err
+	}
+	values, err := r.Column(ValueColumn)
+	if err != nil {
+		return nil, err
+	}
+	lineages, err := r.Column(LineageColumn)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]*lrdd.Row, r.Header.NumRows)
+	for i := range rows {
+		rows[i] = &lrdd.Row{}
+		if keys != nil {
+			rows[i].Key = string(keys[i])
+		}
+		if values != nil && len(values[i]) > 0 {
+			rows[i].Value = values[i]
+		}
+		if lineages != nil && len(lineages[i]) > 0 {
+			l := new(lrdd.Row)
+			if err := proto.Unmarshal(lineages[i], l); err != nil {
+				return nil, errors.Wrapf(err, "unmarshal lineage of row #%d", i)
+			}
+			rows[i].Lineage = l.Lineage
+		}
+	}
+	return rows, nil
+}
+
+// Decode reads every row encoded by Encode.
+func Decode(r io.Reader) ([]*lrdd.Row, error) {
+	cr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return cr.Rows()
+}
+
+// decoder reads values from data, keeping the first error so that it can be checked once.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = ErrInvalidFormat
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// int reads a count or a length, which can't exceed the size of the remaining data in a valid format.
+func (d *decoder) int() int {
+	v := d.uvarint()
+	if v > uint64(len(d.data)) {
+		d.err = ErrInvalidFormat
+		return 0
+	}
+	return int(v)
+}
+
+func (d *decoder) byte() byte {
+	b := d.next(1)
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data) {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) plain() [][]byte {
+	values := make([][]byte, d.int())
+	lengths := make([]int, len(values))
+	for i := range lengths {
+		lengths[i] = d.int()
+	}
+	for i := range values {
+		values[i] = d.next(lengths[i])
+	}
+	return values
+}
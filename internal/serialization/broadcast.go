@@ -1,19 +1,33 @@
 package serialization
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 )
 
+// gzipMagic is the header of gzip, which can't be a beginning of JSON.
+var gzipMagic = []byte{0x1f, 0x8b}
+
 type Broadcast map[string]interface{}
 
-func SerializeBroadcast(b Broadcast) (s map[string][]byte, err error) {
+// SerializeBroadcast serializes values of the broadcast in JSON. Values larger than compressAbove bytes
+// are compressed with gzip, which is detected by DeserializeBroadcast. Zero disables the compression.
+func SerializeBroadcast(b Broadcast, compressAbove int) (s map[string][]byte, err error) {
 	s = make(map[string][]byte)
 	for k, v := range b {
 		s[k], err = jsoniter.Marshal(v)
 		if err != nil {
 			return nil, errors.Wrapf(err, "serialize broadcast %s", k)
 		}
+		if compressAbove > 0 && len(s[k]) > compressAbove {
+			if s[k], err = compress(s[k]); err != nil {
+				return nil, errors.Wrapf(err, "compress broadcast %s", k)
+			}
+		}
 	}
 	return s, nil
 }
@@ -21,6 +35,12 @@ func SerializeBroadcast(b Broadcast) (s map[string][]byte, err error) {
 func DeserializeBroadcast(data map[string][]byte) (Broadcast, error) {
 	b := make(Broadcast)
 	for k, raw := range data {
+		if bytes.HasPrefix(raw, gzipMagic) {
+			var err error
+			if raw, err = decompress(raw); err != nil {
+				return nil, errors.Wrapf(err, "decompress broadcast %s", k)
+			}
+		}
 		var v interface{}
 		if err := jsoniter.Unmarshal(raw, &v); err != nil {
 			return nil, errors.Wrapf(err, "deserialize broadcast %s", k)
@@ -29,3 +49,24 @@ func DeserializeBroadcast(data map[string][]byte) (Broadcast, error) {
 	}
 	return b, nil
 }
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
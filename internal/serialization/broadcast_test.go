@@ -0,0 +1,36 @@
+package serialization
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSerializeBroadcast(t *testing.T) {
+	Convey("Given a broadcast with a large compressible value and a small value", t, func() {
+		large := strings.Repeat("lrmr ", 10000)
+		b := Broadcast{"large": large, "small": "foo"}
+
+		Convey("When serialized with a compression threshold", func() {
+			s, err := SerializeBroadcast(b, 1024)
+			So(err, ShouldBeNil)
+
+			Convey("The large value should be compressed", func() {
+				uncompressed, err := SerializeBroadcast(b, 0)
+				So(err, ShouldBeNil)
+				So(len(s["large"]), ShouldBeLessThan, len(uncompressed["large"])/10)
+			})
+
+			Convey("The small value should be kept as it is", func() {
+				So(string(s["small"]), ShouldEqual, `"foo"`)
+			})
+
+			Convey("Both values should be deserialized transparently", func() {
+				decoded, err := DeserializeBroadcast(s)
+				So(err, ShouldBeNil)
+				So(decoded, ShouldResemble, b)
+			})
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package serialization
+
+import (
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// SideInput is a small dataset looked up by its row keys. If rows have a duplicated key, the last one is kept.
+type SideInput map[string]*lrdd.Row
+
+func SerializeSideInput(rows []*lrdd.Row) ([]byte, error) {
+	s, err := jsoniter.Marshal(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "serialize side input")
+	}
+	return s, nil
+}
+
+func DeserializeSideInputs(data map[string][]byte) (map[string]SideInput, error) {
+	sideInputs := make(map[string]SideInput, len(data))
+	for name, raw := range data {
+		var rows []*lrdd.Row
+		if err := jsoniter.Unmarshal(raw, &rows); err != nil {
+			return nil, errors.Wrapf(err, "deserialize side input %s", name)
+		}
+		si := make(SideInput, len(rows))
+		for _, r := range rows {
+			si[r.Key] = r
+		}
+		sideInputs[name] = si
+	}
+	return sideInputs, nil
+}
@@ -0,0 +1,48 @@
+package serialization
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+type timerKey struct{}
+
+// Timer accumulates time spent on serialization and deserialization, such as encoding rows sent to other nodes.
+// It is safe for concurrent use, and a nil Timer discards the time.
+type Timer struct {
+	elapsed atomic.Int64
+}
+
+// Add adds the duration to the timer.
+func (t *Timer) Add(d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.elapsed.Add(int64(d))
+}
+
+// Since adds the time elapsed since start to the timer.
+func (t *Timer) Since(start time.Time) {
+	t.Add(time.Since(start))
+}
+
+// Elapsed returns the accumulated time.
+func (t *Timer) Elapsed() time.Duration {
+	if t == nil {
+		return 0
+	}
+	return time.Duration(t.elapsed.Load())
+}
+
+// WithTimer returns a context carrying the timer, which streams opened with the context measure their encoding with.
+func WithTimer(ctx context.Context, t *Timer) context.Context {
+	return context.WithValue(ctx, timerKey{}, t)
+}
+
+// TimerFrom returns the timer carried by the context, or nil if there is none.
+func TimerFrom(ctx context.Context) *Timer {
+	t, _ := ctx.Value(timerKey{}).(*Timer)
+	return t
+}
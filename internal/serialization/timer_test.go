@@ -0,0 +1,33 @@
+package serialization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTimer(t *testing.T) {
+	Convey("Given a timer", t, func() {
+		timer := new(Timer)
+
+		Convey("It should accumulate durations", func() {
+			timer.Add(time.Second)
+			timer.Add(2 * time.Second)
+			So(timer.Elapsed(), ShouldEqual, 3*time.Second)
+		})
+
+		Convey("It should be carried by a context", func() {
+			ctx := WithTimer(context.Background(), timer)
+			So(TimerFrom(ctx), ShouldEqual, timer)
+			So(TimerFrom(context.Background()), ShouldBeNil)
+		})
+	})
+
+	Convey("A nil timer should discard durations", t, func() {
+		var timer *Timer
+		timer.Add(time.Second)
+		So(timer.Elapsed(), ShouldEqual, 0)
+	})
+}
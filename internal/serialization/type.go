@@ -11,6 +11,9 @@ import (
 
 var cache sync.Map
 
+// registered stores descriptors of the types registered explicitly with Register.
+var registered sync.Map
+
 // Type wraps reflect.Type with serialization support.
 // To deserialize the type on the remote, it also needs be available and registered in the remote side.
 type Type struct {
@@ -27,6 +30,37 @@ func TypeOf(v interface{}) Type {
 	return t
 }
 
+// Register registers type of given value explicitly. Unlike TypeOf, which registers types as a side-effect
+// of serialization, registered types are ensured to be registered in every process running the same code.
+func Register(v interface{}) Type {
+	t := TypeOf(v)
+	registered.Store(serializeTypeInfo(baseType(t.T.Type1())), true)
+	return t
+}
+
+// IsRegistered returns true if the type has been registered with Register.
+// Slices and pointers are regarded as registered if their element type is registered.
+func IsRegistered(t Type) bool {
+	if t.T == nil {
+		return true
+	}
+	base := baseType(t.T.Type1())
+	if base.PkgPath() == "" {
+		// primitives
+		return true
+	}
+	_, ok := registered.Load(serializeTypeInfo(base))
+	return ok
+}
+
+// baseType returns the element type of slices and pointers.
+func baseType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
 // TypeFromString loads and returns type from given type descriptor.
 // type descriptor is composed of <kind><pkgPath>.<typeName> (e.g. []*github.com/pkg/errors.Error).
 // It returns ErrUnresolved if given type is not found on this process/application.
@@ -71,6 +105,14 @@ func (t *Type) UnmarshalText(d []byte) (err error) {
 	return nil
 }
 
+func (t Type) MarshalBinary() ([]byte, error) {
+	return t.MarshalText()
+}
+
+func (t *Type) UnmarshalBinary(d []byte) error {
+	return t.UnmarshalText(d)
+}
+
 func serializeTypeInfo(typ reflect.Type) string {
 	if typ == nil {
 		return "nil"
@@ -55,3 +55,28 @@ func TestTypeFromString(t *testing.T) {
 		})
 	})
 }
+
+type registeredStruct struct{}
+
+type unregisteredStruct struct{}
+
+func TestIsRegistered(t *testing.T) {
+	Convey("Given a type registered with serialization.Register", t, func() {
+		Register(registeredStruct{})
+
+		Convey("The type and its pointers and slices should be regarded as registered", func() {
+			So(IsRegistered(TypeOf(registeredStruct{})), ShouldBeTrue)
+			So(IsRegistered(TypeOf(&registeredStruct{})), ShouldBeTrue)
+			So(IsRegistered(TypeOf([]*registeredStruct{})), ShouldBeTrue)
+		})
+
+		Convey("Types only serialized should not be regarded as registered", func() {
+			So(IsRegistered(TypeOf(&unregisteredStruct{})), ShouldBeFalse)
+		})
+
+		Convey("Primitives should be regarded as registered", func() {
+			So(IsRegistered(TypeOf(0)), ShouldBeTrue)
+			So(IsRegistered(TypeOf([]string{})), ShouldBeTrue)
+		})
+	})
+}
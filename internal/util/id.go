@@ -5,6 +5,18 @@ import (
 	"encoding/hex"
 )
 
+// IDGenerator generates identifiers of jobs. Implement it to use other ID schemes (e.g. ULID, UUID).
+type IDGenerator interface {
+	GenerateID(prefix string) string
+}
+
+// RandomIDGenerator generates an ID with given prefix and 8 random hex characters.
+type RandomIDGenerator struct{}
+
+func (RandomIDGenerator) GenerateID(prefix string) string {
+	return GenerateID(prefix)
+}
+
 func GenerateID(prefix string) string {
 	bytes := make([]byte, 4)
 	if _, err := rand.Read(bytes); err != nil {
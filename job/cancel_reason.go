@@ -0,0 +1,65 @@
+package job
+
+import (
+	"github.com/pkg/errors"
+)
+
+// CancelReason describes why a job has been aborted before its completion.
+type CancelReason string
+
+const (
+	// NotCancelled is a reason of failures which are not cancellations, or cancellations without any reason.
+	NotCancelled CancelReason = ""
+
+	// CancelledByUser is a reason of a job aborted explicitly, e.g. with RunningJob.Abort.
+	CancelledByUser CancelReason = "user"
+
+	// CancelledByTimeout is a reason of a job aborted since its driver has stopped waiting for it on a deadline.
+	CancelledByTimeout CancelReason = "timeout"
+
+	// CancelledByRebalance is a reason of a job aborted to be rescheduled on another set of nodes.
+	CancelledByRebalance CancelReason = "rebalance"
+
+	// CancelledByDeadNode is a reason of a job aborted since a node running its tasks has died.
+	CancelledByDeadNode CancelReason = "deadNode"
+)
+
+// CancelledError is an error which knows why the job has been cancelled.
+type CancelledError interface {
+	error
+	CancelReason() CancelReason
+}
+
+type cancelledError struct {
+	error
+	reason CancelReason
+}
+
+// WithCancelReason tags the error with given reason of the cancellation.
+func WithCancelReason(err error, reason CancelReason) error {
+	if err == nil {
+		return nil
+	}
+	return &cancelledError{error: err, reason: reason}
+}
+
+func (c *cancelledError) CancelReason() CancelReason {
+	return c.reason
+}
+
+func (c *cancelledError) Cause() error {
+	return c.error
+}
+
+func (c *cancelledError) Unwrap() error {
+	return c.error
+}
+
+// CancelReasonOf returns the reason of the outermost CancelledError in the chain of the error.
+func CancelReasonOf(err error) CancelReason {
+	var ce CancelledError
+	if errors.As(err, &ce) {
+		return ce.CancelReason()
+	}
+	return NotCancelled
+}
@@ -0,0 +1,113 @@
+package job
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	"github.com/ab180/lrmr/transformation"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var codecs = map[string]coordinator.Codec{
+	"JSONCodec":    coordinator.JSONCodec,
+	"MsgpackCodec": coordinator.MsgpackCodec,
+	"GobCodec":     coordinator.GobCodec,
+}
+
+func TestJob_Codec(t *testing.T) {
+	for name, codec := range codecs {
+		Convey("Given a Job encoded with "+name, t, func() {
+			j := largeJob(3, 4)
+
+			data, err := codec.Marshal(j)
+			So(err, ShouldBeNil)
+
+			Convey("It should be decoded with the same stages and partitions", func() {
+				got := new(Job)
+				So(codec.Unmarshal(data, got), ShouldBeNil)
+				So(got.ID, ShouldEqual, j.ID)
+				So(got.SubmittedAt.Equal(j.SubmittedAt), ShouldBeTrue)
+				So(got.Partitions, ShouldResemble, j.Partitions)
+				So(got.Stages, ShouldHaveLength, len(j.Stages))
+
+				for i, s := range got.Stages {
+					So(s.Name, ShouldEqual, j.Stages[i].Name)
+					So(s.Inputs, ShouldHaveLength, len(j.Stages[i].Inputs))
+					So(s.Function.Transformation, ShouldResemble, j.Stages[i].Function.Transformation)
+					So(s.Output.Partitioner.Partitioner, ShouldResemble, j.Stages[i].Output.Partitioner.Partitioner)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkJob_Codec(b *testing.B) {
+	j := largeJob(50, 200)
+	for name, codec := range codecs {
+		data, err := codec.Marshal(j)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name+"/Marshal", func(b *testing.B) {
+			b.ReportMetric(float64(len(data)), "bytes")
+			for i := 0; i < b.N; i++ {
+				if _, err := codec.Marshal(j); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"/Unmarshal", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := codec.Unmarshal(data, new(Job)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// largeJob creates a job with given number of stages, each of them having given number of partitions.
+func largeJob(numStages, numPartitions int) *Job {
+	j := &Job{
+		ID:          "J-codec",
+		Name:        "codec",
+		SubmittedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	var prev stage.Stage
+	for i := 0; i < numStages; i++ {
+		var s stage.Stage
+		if i == 0 {
+			s = stage.New("_input", &codecTestTransformation{Multiplier: i})
+		} else {
+			s = stage.New("stage"+strconv.Itoa(i), &codecTestTransformation{Multiplier: i}, stage.InputFrom(prev))
+		}
+		keys := make([]string, numPartitions)
+		assignments := make(partitions.Assignments, numPartitions)
+		for p := 0; p < numPartitions; p++ {
+			keys[p] = strconv.Itoa(p)
+			assignments[p] = partitions.Assignment{
+				PartitionID: keys[p],
+				Host:        "worker" + strconv.Itoa(p%8) + ":7466",
+			}
+		}
+		s.Output.Partitioner = partitions.WrapPartitioner(partitions.NewFiniteKeyPartitioner(keys))
+		j.Stages = append(j.Stages, s)
+		j.Partitions = append(j.Partitions, assignments)
+		prev = s
+	}
+	return j
+}
+
+type codecTestTransformation struct {
+	Multiplier int
+}
+
+func (c *codecTestTransformation) Apply(transformation.Context, chan *lrdd.Row, output.Output) error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package job
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/pkg/errors"
+)
+
+const jobSummaryNs = "summary/jobs"
+
+// ErrJobNotCompleted is returned on compacting statuses of the tasks of a running job.
+var ErrJobNotCompleted = errors.New("job not completed")
+
+// Summary is a compact record of a completed job, which is kept after statuses of its tasks are compacted.
+type Summary struct {
+	Status      RunningState `json:"status"`
+	SubmittedAt time.Time    `json:"submittedAt"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty"`
+
+	TotalTasks  int `json:"totalTasks"`
+	FailedTasks int `json:"failedTasks"`
+
+	// Metrics are aggregated over the tasks of the job.
+	Metrics Metrics `json:"metrics"`
+
+	StageDurations map[string]StageDuration `json:"stageDurations"`
+}
+
+// CompactTaskStatuses deletes statuses of the tasks of the completed job, replacing them with a Summary of the job.
+// States saved by the tasks and the routing of the job are also deleted.
+// It returns ErrJobNotCompleted if the job is running.
+func (m *Manager) CompactTaskStatuses(ctx context.Context, jobID string) (*Summary, error) {
+	js, err := m.GetJobStatus(ctx, jobID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get job status")
+	}
+	if js.CompletedAt == nil {
+		return nil, ErrJobNotCompleted
+	}
+	statuses, err := m.ListTaskStatusesInJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	durations, err := m.GetStageDurations(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{
+		Status:         js.Status,
+		SubmittedAt:    js.SubmittedAt,
+		CompletedAt:    js.CompletedAt,
+		TotalTasks:     len(statuses),
+		StageDurations: durations,
+	}
+	if summary.Metrics, err = AggregateMetrics(statuses); err != nil {
+		return nil, errors.Wrap(err, "aggregate metrics")
+	}
+	for _, ts := range statuses {
+		if ts.Status == Failed {
+			summary.FailedTasks++
+		}
+	}
+
+	txn := coordinator.NewTxn().
+		Put(path.Join(jobSummaryNs, jobID), summary).
+		Delete(path.Join(taskStatusNs, jobID) + "/").
+		Delete(path.Join(taskStateNs, jobID) + "/").
+		Delete(path.Join(routingNs, jobID) + "/")
+	if _, err := m.clusterState.Commit(ctx, txn); err != nil {
+		return nil, errors.Wrap(err, "etcd write")
+	}
+	m.log.Verbose("Compacted statuses of {} tasks in job {}", len(statuses), jobID)
+	return summary, nil
+}
+
+// GetJobSummary returns the summary of the job whose task statuses are compacted.
+// It returns coordinator.ErrNotFound if the job is not compacted.
+func (m *Manager) GetJobSummary(ctx context.Context, jobID string) (*Summary, error) {
+	summary := new(Summary)
+	if err := m.clusterState.Get(ctx, path.Join(jobSummaryNs, jobID), summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// CompactCompletedJobs compacts task statuses of the jobs completed before the retention,
+// and returns the number of the compacted jobs.
+func (m *Manager) CompactCompletedJobs(ctx context.Context, retention time.Duration) (compacted int, err error) {
+	items, err := m.clusterState.Scan(ctx, jobStatusNs+"/")
+	if err != nil {
+		return 0, errors.Wrap(err, "scan job statuses")
+	}
+	for _, item := range items {
+		jobID := strings.TrimPrefix(item.Key, jobStatusNs+"/")
+		if strings.Contains(jobID, "/") {
+			// counters of the job
+			continue
+		}
+		var js Status
+		if err := item.Unmarshal(&js); err != nil {
+			return compacted, errors.Wrapf(err, "unmarshal job status %s", item.Key)
+		}
+		if js.CompletedAt == nil || time.Since(*js.CompletedAt) < retention {
+			continue
+		}
+		if _, err := m.GetJobSummary(ctx, jobID); err == nil {
+			continue
+		} else if err != coordinator.ErrNotFound {
+			return compacted, errors.Wrapf(err, "get summary of job %s", jobID)
+		}
+		if _, err := m.CompactTaskStatuses(ctx, jobID); err != nil {
+			return compacted, errors.Wrapf(err, "compact job %s", jobID)
+		}
+		compacted++
+	}
+	return compacted, nil
+}
@@ -0,0 +1,70 @@
+package job
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrorClass classifies failures of tasks, to decide whether they are worth retrying or alerting.
+type ErrorClass string
+
+const (
+	// UnclassifiedError is a class of errors without any classification.
+	UnclassifiedError ErrorClass = ""
+
+	// UserError is caused by user code or data, and fails again on retry.
+	UserError ErrorClass = "user"
+
+	// InfrastructureError is caused by the cluster, e.g. losing a node or the coordinator.
+	InfrastructureError ErrorClass = "infrastructure"
+
+	// TransientError is temporary and likely to succeed on retry, e.g. a timeout.
+	TransientError ErrorClass = "transient"
+)
+
+// Retryable returns true if a task failed with the class of error can succeed on retry.
+// Unclassified errors are not regarded as retryable. Jobs are retried only if every error is retryable
+// (see lrmr.WithMaxRetries).
+func (c ErrorClass) Retryable() bool {
+	return c == InfrastructureError || c == TransientError
+}
+
+// ClassifiedError is an error which knows its ErrorClass. Errors returned from transformations can implement it,
+// or be wrapped with Classify.
+type ClassifiedError interface {
+	error
+	ErrorClass() ErrorClass
+}
+
+type classifiedError struct {
+	error
+	class ErrorClass
+}
+
+// Classify tags the error with given class.
+func Classify(err error, class ErrorClass) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{error: err, class: class}
+}
+
+func (c *classifiedError) ErrorClass() ErrorClass {
+	return c.class
+}
+
+func (c *classifiedError) Cause() error {
+	return c.error
+}
+
+func (c *classifiedError) Unwrap() error {
+	return c.error
+}
+
+// ClassOf returns the class of the outermost ClassifiedError in the chain of the error.
+func ClassOf(err error) ErrorClass {
+	var ce ClassifiedError
+	if errors.As(err, &ce) {
+		return ce.ErrorClass()
+	}
+	return UnclassifiedError
+}
@@ -0,0 +1,82 @@
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClassOf(t *testing.T) {
+	Convey("Given a classified error", t, func() {
+		err := Classify(errors.New("invalid row"), UserError)
+
+		Convey("Its class should be found through wrapping errors", func() {
+			So(ClassOf(err), ShouldEqual, UserError)
+			So(ClassOf(errors.Wrap(err, "map")), ShouldEqual, UserError)
+			So(err.Error(), ShouldEqual, "invalid row")
+		})
+
+		Convey("Errors without classification should be unclassified", func() {
+			So(ClassOf(errors.New("unknown")), ShouldEqual, UnclassifiedError)
+			So(Classify(nil, UserError), ShouldBeNil)
+		})
+	})
+}
+
+func TestTaskReporter_ReportFailure_ErrorClass(t *testing.T) {
+	Convey("Given a job with tasks", t, func() {
+		ctx := context.Background()
+		crd := coordinator.NewLocalMemory()
+		m := NewManager(crd)
+
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+		assignments := []partitions.Assignments{nil, {{PartitionID: "0", Host: "localhost"}, {PartitionID: "1", Host: "localhost"}}}
+		j, err := m.CreateJob(ctx, "test", stages, assignments, WithCollectAllErrors())
+		So(err, ShouldBeNil)
+
+		reportFailure := func(partitionID string, err error) {
+			task := NewTask(partitionID, node.New("localhost", node.Worker), j.ID, &stages[1])
+			status, createErr := m.CreateTask(ctx, task)
+			So(createErr, ShouldBeNil)
+			So(NewTaskReporter(ctx, crd, j, task.ID(), status).ReportFailure(err), ShouldBeNil)
+		}
+
+		Convey("When tasks fail with a user error and a transient error", func() {
+			reportFailure("0", errors.Wrap(Classify(errors.New("invalid row"), UserError), "map"))
+			reportFailure("1", Classify(errors.New("deadline exceeded"), TransientError))
+
+			Convey("The classes should be stored in the job errors", func() {
+				errs, err := m.GetJobErrors(ctx, j.ID)
+				So(err, ShouldBeNil)
+				So(errs, ShouldHaveLength, 2)
+
+				classes := make(map[string]ErrorClass)
+				for _, e := range errs {
+					classes[e.Task] = e.Class
+				}
+				So(classes[j.ID+"/map0/0"], ShouldEqual, UserError)
+				So(classes[j.ID+"/map0/1"], ShouldEqual, TransientError)
+			})
+
+			Convey("Only the transient error should be retryable", func() {
+				statuses, err := m.ListTaskStatusesInJob(ctx, j.ID)
+				So(err, ShouldBeNil)
+				So(statuses, ShouldHaveLength, 2)
+				for _, st := range statuses {
+					if st.ErrorClass == UserError {
+						So(st.ErrorClass.Retryable(), ShouldBeFalse)
+					} else {
+						So(st.ErrorClass, ShouldEqual, TransientError)
+						So(st.ErrorClass.Retryable(), ShouldBeTrue)
+					}
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,74 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxErrorDetailDepth limits the length of the chain serialized into ErrorDetail.
+const maxErrorDetailDepth = 32
+
+// ErrorDetail is a serialized form of an error in the wrap chain of a task error, which keeps the type and
+// the message of the error. Since the original error cannot be restored after the serialization, errors.Is
+// detects an ErrorDetail as the target if both have the same type and message, which works for sentinel errors.
+type ErrorDetail struct {
+	Type    string       `json:"type"`
+	Message string       `json:"message"`
+	Cause   *ErrorDetail `json:"cause,omitempty"`
+}
+
+// NewErrorDetail serializes the error and its wrap chain. Wrappers adding nothing to the message
+// of their cause (e.g. ones carrying stacktraces) are omitted.
+func NewErrorDetail(err error) *ErrorDetail {
+	if err == nil {
+		return nil
+	}
+	var chain []error
+	for e := err; e != nil && len(chain) < maxErrorDetailDepth; e = errors.Unwrap(e) {
+		if cause := errors.Unwrap(e); cause != nil && cause.Error() == e.Error() {
+			continue
+		}
+		chain = append(chain, e)
+	}
+
+	var detail *ErrorDetail
+	for i := len(chain) - 1; i >= 0; i-- {
+		detail = &ErrorDetail{
+			Type:    typeNameOf(chain[i]),
+			Message: chain[i].Error(),
+			Cause:   detail,
+		}
+	}
+	return detail
+}
+
+func (d *ErrorDetail) Error() string {
+	return d.Message
+}
+
+func (d *ErrorDetail) Unwrap() error {
+	if d.Cause == nil {
+		return nil
+	}
+	return d.Cause
+}
+
+// Is returns true if the target has the same type and message with the serialized error.
+func (d *ErrorDetail) Is(target error) bool {
+	if target == nil {
+		return false
+	}
+	return d.Type == typeNameOf(target) && d.Message == target.Error()
+}
+
+// Chain returns the errors in the wrap chain, from the outermost one.
+func (d *ErrorDetail) Chain() (chain []*ErrorDetail) {
+	for cur := d; cur != nil; cur = cur.Cause {
+		chain = append(chain, cur)
+	}
+	return chain
+}
+
+func typeNameOf(err error) string {
+	return fmt.Sprintf("%T", err)
+}
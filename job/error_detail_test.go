@@ -0,0 +1,51 @@
+package job
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var errSentinel = errors.New("sentinel")
+
+func TestErrorDetail(t *testing.T) {
+	Convey("Given an error wrapping a sentinel error", t, func() {
+		err := Classify(errors.Wrap(errors.WithMessage(errSentinel, "read row"), "apply"), UserError)
+		detail := NewErrorDetail(err)
+
+		Convey("Its chain should keep the messages and types, omitting wrappers adding nothing", func() {
+			chain := detail.Chain()
+			So(chain, ShouldHaveLength, 3)
+			So(chain[0].Message, ShouldEqual, "apply: read row: sentinel")
+			So(chain[1].Message, ShouldEqual, "read row: sentinel")
+			So(chain[2].Message, ShouldEqual, "sentinel")
+			So(chain[2].Type, ShouldEqual, "*errors.fundamental")
+		})
+
+		for name, codec := range codecs {
+			Convey("When a job error with it is round-tripped with "+name, func() {
+				data, err := codec.Marshal(Error{Task: "J/map0/0", Message: err.Error(), Detail: detail})
+				So(err, ShouldBeNil)
+
+				var decoded Error
+				So(codec.Unmarshal(data, &decoded), ShouldBeNil)
+
+				Convey("The sentinel error should be detected with errors.Is", func() {
+					So(stderrors.Is(decoded, errSentinel), ShouldBeTrue)
+					So(errors.Is(decoded, errSentinel), ShouldBeTrue)
+				})
+
+				Convey("Other errors should not be detected", func() {
+					So(errors.Is(decoded, errors.New("other")), ShouldBeFalse)
+					So(errors.Is(decoded, stderrors.New("sentinel")), ShouldBeFalse)
+				})
+			})
+		}
+	})
+
+	Convey("Errors recorded without details should not match any error", t, func() {
+		So(errors.Is(Error{Task: "J/map0/0", Message: "sentinel"}, errSentinel), ShouldBeFalse)
+	})
+}
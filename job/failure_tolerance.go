@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"math"
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+const droppedPartitionNs = "dropped/jobs"
+
+// DroppedPartition is a partition whose task has failed within the failure tolerance of its stage.
+// Rows of the partition are missing from the output of the stage.
+type DroppedPartition struct {
+	StageName   string `json:"stageName"`
+	PartitionID string `json:"partitionId"`
+	Error       Error  `json:"error"`
+}
+
+// ListDroppedPartitions returns partitions dropped from the stages of the job with failure tolerance.
+func (m *Manager) ListDroppedPartitions(ctx context.Context, jobID string) ([]DroppedPartition, error) {
+	items, err := m.clusterState.Scan(ctx, path.Join(droppedPartitionNs, jobID)+"/")
+	if err != nil {
+		return nil, errors.Wrap(err, "scan dropped partitions")
+	}
+	dropped := make([]DroppedPartition, len(items))
+	for i, item := range items {
+		if err := item.Unmarshal(&dropped[i]); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal dropped partition %s", item.Key)
+		}
+	}
+	return dropped, nil
+}
+
+// maxFailedTasks returns the number of tasks which can fail without failing the stage of the task.
+func (r *TaskReporter) maxFailedTasks() int {
+	s := r.job.GetStage(r.task.StageName)
+	if s == nil || s.FailureTolerance <= 0 {
+		return 0
+	}
+	total := len(r.job.GetPartitionsOfStage(r.task.StageName))
+	return int(math.Floor(s.FailureTolerance * float64(total)))
+}
+
+// reportToleratedFailure records the failed task as a dropped partition if it is within the failure tolerance,
+// or as an error of the job otherwise. failedTasks is the number of failed tasks in the stage including the task.
+func (r *TaskReporter) reportToleratedFailure(errDesc Error, failedTasks int) error {
+	if failedTasks > r.maxFailedTasks() {
+		if err := r.clusterState.Put(r.ctx, jobErrorKey(r.task), errDesc); err != nil {
+			return errors.Wrap(err, "write job error")
+		}
+		return nil
+	}
+	dropped := DroppedPartition{
+		StageName:   r.task.StageName,
+		PartitionID: r.task.PartitionID,
+		Error:       errDesc,
+	}
+	if err := r.clusterState.Put(r.ctx, path.Join(droppedPartitionNs, r.task.String()), dropped); err != nil {
+		return errors.Wrap(err, "write dropped partition")
+	}
+	r.dropped.Store(true)
+	r.log.Warn("Partition {} of stage {} dropped within its failure tolerance: {}", r.task.PartitionID, r.task.StageName, errDesc.Message)
+	return nil
+}
+
+// Dropped returns true if the task has failed within the failure tolerance of its stage,
+// which means that the job goes on without the partition of the task.
+func (r *TaskReporter) Dropped() bool {
+	return r.dropped.Load()
+}
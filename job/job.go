@@ -13,6 +13,163 @@ type Job struct {
 	Stages      []stage.Stage            `json:"stages"`
 	Partitions  []partitions.Assignments `json:"partitions"`
 	SubmittedAt time.Time                `json:"submittedAt"`
+
+	// TaskTimeout is a duration which a task can run without any progress, such as consuming input rows
+	// or calling Context.Heartbeat. Tasks exceeding the timeout are aborted. Zero means no timeout.
+	TaskTimeout time.Duration `json:"taskTimeout,omitempty"`
+
+	// PersistOutput keeps output of the final stage in the workers, so that it can be read by other jobs.
+	PersistOutput bool `json:"persistOutput,omitempty"`
+
+	// PersistCompression is an algorithm compressing the persisted output, trading CPU for memory.
+	PersistCompression Compression `json:"persistCompression,omitempty"`
+
+	// InputJobID is an ID of the job whose persisted output is read as the input of this job.
+	InputJobID string `json:"inputJobID,omitempty"`
+
+	// CollectAllErrors makes the job to wait for every task to complete and collect all errors
+	// before failing, instead of failing on the first error.
+	CollectAllErrors bool `json:"collectAllErrors,omitempty"`
+
+	// Provenance makes rows of the job to carry their lineage.
+	Provenance bool `json:"provenance,omitempty"`
+
+	// SkipEmptyPartitions completes tasks without running their transformations if every upstream task
+	// has written no rows to their partitions.
+	SkipEmptyPartitions bool `json:"skipEmptyPartitions,omitempty"`
+
+	// Weight is a share of task slots given to the job relative to other jobs running concurrently,
+	// on workers with fair scheduling. Zero is treated as 1.
+	Weight int `json:"weight,omitempty"`
+
+	// ReassignUnreachable reassigns partitions on a host which is unreachable on starting the job
+	// to other hosts, instead of failing the job.
+	ReassignUnreachable bool `json:"reassignUnreachable,omitempty"`
+
+	// OrderedCollect returns collected rows in the order of the partition IDs, instead of the order
+	// the partitions are assigned in.
+	OrderedCollect bool `json:"orderedCollect,omitempty"`
+
+	// Metadata is arbitrary business metadata of the job (e.g. owner, pipeline name, run ID)
+	// for filtering and auditing the jobs. It is not interpreted by lrmr.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// SkewDetection warns about stages with a partition receiving far more input than the others.
+	// Skews are not detected if it is nil.
+	SkewDetection *SkewDetection `json:"skewDetection,omitempty"`
+
+	// RoutingInCoordinator makes the workers read the output routing of the stages from the coordinator,
+	// where it is stored once per stage, instead of receiving it with every request creating the tasks.
+	RoutingInCoordinator bool `json:"routingInCoordinator,omitempty"`
+}
+
+// Compression is an algorithm compressing rows kept in the workers.
+type Compression string
+
+const (
+	NoCompression   Compression = ""
+	GzipCompression Compression = "gzip"
+)
+
+// Option configures a job on its creation.
+type Option func(j *Job)
+
+// WithID sets ID of the job, instead of generating it with the IDGenerator of the Manager.
+func WithID(id string) Option {
+	return func(j *Job) {
+		j.ID = id
+	}
+}
+
+// WithTaskTimeout sets TaskTimeout of the job.
+func WithTaskTimeout(d time.Duration) Option {
+	return func(j *Job) {
+		j.TaskTimeout = d
+	}
+}
+
+// WithPersistedOutput sets PersistOutput of the job.
+func WithPersistedOutput() Option {
+	return func(j *Job) {
+		j.PersistOutput = true
+	}
+}
+
+// WithPersistCompression sets PersistCompression of the job.
+func WithPersistCompression(c Compression) Option {
+	return func(j *Job) {
+		j.PersistCompression = c
+	}
+}
+
+// WithInputFromJob sets InputJobID of the job.
+func WithInputFromJob(jobID string) Option {
+	return func(j *Job) {
+		j.InputJobID = jobID
+	}
+}
+
+// WithCollectAllErrors sets CollectAllErrors of the job.
+func WithCollectAllErrors() Option {
+	return func(j *Job) {
+		j.CollectAllErrors = true
+	}
+}
+
+// WithProvenance sets Provenance of the job.
+func WithProvenance() Option {
+	return func(j *Job) {
+		j.Provenance = true
+	}
+}
+
+// WithSkipEmptyPartitions sets SkipEmptyPartitions of the job.
+func WithSkipEmptyPartitions() Option {
+	return func(j *Job) {
+		j.SkipEmptyPartitions = true
+	}
+}
+
+// WithReassignUnreachable sets ReassignUnreachable of the job.
+func WithReassignUnreachable() Option {
+	return func(j *Job) {
+		j.ReassignUnreachable = true
+	}
+}
+
+// WithOrderedCollect sets OrderedCollect of the job.
+func WithOrderedCollect() Option {
+	return func(j *Job) {
+		j.OrderedCollect = true
+	}
+}
+
+// WithSkewDetection sets SkewDetection of the job.
+func WithSkewDetection(d SkewDetection) Option {
+	return func(j *Job) {
+		j.SkewDetection = &d
+	}
+}
+
+// WithMetadata sets Metadata of the job.
+func WithMetadata(md map[string]string) Option {
+	return func(j *Job) {
+		j.Metadata = md
+	}
+}
+
+// WithWeight sets Weight of the job.
+func WithWeight(w int) Option {
+	return func(j *Job) {
+		j.Weight = w
+	}
+}
+
+// WithRoutingInCoordinator sets RoutingInCoordinator of the job.
+func WithRoutingInCoordinator() Option {
+	return func(j *Job) {
+		j.RoutingInCoordinator = true
+	}
 }
 
 func (j *Job) GetStage(name string) *stage.Stage {
@@ -24,9 +181,19 @@ func (j *Job) GetStage(name string) *stage.Stage {
 	return nil
 }
 
-func (j *Job) GetPartitionsOfStage(name string) partitions.Assignments {
+// GetStageIndex returns a position of the stage in the job, or -1 if the stage does not exist.
+func (j *Job) GetStageIndex(name string) int {
 	for i, s := range j.Stages {
 		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (j *Job) GetPartitionsOfStage(name string) partitions.Assignments {
+	for i, s := range j.Stages {
+		if s.Name == name && i < len(j.Partitions) {
 			return j.Partitions[i]
 		}
 	}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/ab180/lrmr/cluster"
 	"github.com/ab180/lrmr/coordinator"
 	"github.com/ab180/lrmr/internal/util"
+	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/partitions"
 	"github.com/ab180/lrmr/stage"
 	"github.com/airbloc/logger"
@@ -23,43 +25,114 @@ const (
 	taskStatusNs  = "status/tasks/"
 	jobStatusNs   = "status/jobs"
 	jobErrorNs    = "errors/jobs"
+
+	persistedOutputNs = "persisted/jobs"
+	persistedRowsNs   = "persisted/rows"
+	peekedRowsNs      = "peeked/jobs"
+	quarantinedRowsNs = "quarantined/jobs"
+	driverOutputsNs   = "driver/jobs"
+	routingNs         = "routing/jobs"
 )
 
+// maxOpsPerTxn is the maximum number of operations in a transaction, which is the default limit
+// of etcd (--max-txn-ops).
+const maxOpsPerTxn = 128
+
+// IDGenerator generates IDs of the jobs. Task IDs are derived from the ID of its job.
+type IDGenerator = util.IDGenerator
+
 type Manager struct {
 	clusterState cluster.State
+	idGenerator  IDGenerator
 	log          logger.Logger
 }
 
-func NewManager(cs cluster.State) *Manager {
-	return &Manager{
+type ManagerOption func(m *Manager)
+
+// WithIDGenerator replaces the default ID scheme of the jobs created by the manager.
+func WithIDGenerator(g IDGenerator) ManagerOption {
+	return func(m *Manager) {
+		if g != nil {
+			m.idGenerator = g
+		}
+	}
+}
+
+func NewManager(cs cluster.State, opts ...ManagerOption) *Manager {
+	m := &Manager{
 		clusterState: cs,
+		idGenerator:  util.RandomIDGenerator{},
 		log:          logger.New("lrmr/job.Manager"),
 	}
+	for _, optFn := range opts {
+		optFn(m)
+	}
+	return m
 }
 
-func (m *Manager) CreateJob(ctx context.Context, name string, stages []stage.Stage, assignments []partitions.Assignments) (*Job, error) {
+func (m *Manager) CreateJob(ctx context.Context, name string, stages []stage.Stage, assignments []partitions.Assignments, opts ...Option) (*Job, error) {
 	js := newStatus()
 	j := &Job{
-		ID:          util.GenerateID("J"),
+		ID:          m.idGenerator.GenerateID("J"),
 		Name:        name,
 		Stages:      stages,
 		Partitions:  assignments,
 		SubmittedAt: js.SubmittedAt,
 	}
+	for _, optFn := range opts {
+		optFn(j)
+	}
+	// statuses of the stages are written in chunks not to exceed the limit of a transaction. The job is written
+	// in the last transaction, so that it is not visible until every stage status is written.
+	stageStatuses := j.Stages
+	for len(stageStatuses)+2 > maxOpsPerTxn {
+		chunkLen := len(stageStatuses)
+		if chunkLen > maxOpsPerTxn {
+			chunkLen = maxOpsPerTxn
+		}
+		txn := coordinator.NewTxn()
+		for _, s := range stageStatuses[:chunkLen] {
+			txn.Put(path.Join(stageStatusNs, j.ID, s.Name), newStageStatus())
+		}
+		if _, err := m.clusterState.Commit(ctx, txn); err != nil {
+			m.cleanUpStageStatuses(j.ID)
+			return nil, errors.Wrap(err, "etcd write")
+		}
+		stageStatuses = stageStatuses[chunkLen:]
+	}
 	txn := coordinator.NewTxn().
 		Put(path.Join(jobNs, j.ID), j).
 		Put(path.Join(jobStatusNs, j.ID), js)
 
-	for _, s := range j.Stages {
+	for _, s := range stageStatuses {
 		txn.Put(path.Join(stageStatusNs, j.ID, s.Name), newStageStatus())
 	}
 	if _, err := m.clusterState.Commit(ctx, txn); err != nil {
+		if len(stageStatuses) < len(j.Stages) {
+			m.cleanUpStageStatuses(j.ID)
+		}
 		return nil, errors.Wrap(err, "etcd write")
 	}
 	m.log.Debug("Job created: {} ({})", j.Name, j.ID)
 	return j, nil
 }
 
+// cleanUpStageStatuses deletes statuses of the stages written by CreateJob failed partially.
+func (m *Manager) cleanUpStageStatuses(jobID string) {
+	// the context of CreateJob can be already canceled
+	if _, err := m.clusterState.Delete(context.Background(), path.Join(stageStatusNs, jobID)+"/"); err != nil {
+		m.log.Warn("Failed to clean up stage statuses of job {}: {}", jobID, err)
+	}
+}
+
+// UpdatePartitions saves assignments of the job, after its partitions are reassigned to other hosts.
+func (m *Manager) UpdatePartitions(ctx context.Context, j *Job) error {
+	if err := m.clusterState.Put(ctx, path.Join(jobNs, j.ID), j); err != nil {
+		return errors.Wrap(err, "etcd write")
+	}
+	return nil
+}
+
 func (m *Manager) GetJob(ctx context.Context, jobID string) (*Job, error) {
 	job := &Job{}
 	if err := m.clusterState.Get(ctx, path.Join(jobNs, jobID), job); err != nil {
@@ -101,10 +174,31 @@ func (m *Manager) GetJobErrors(ctx context.Context, jobID string) ([]Error, erro
 	return errs, nil
 }
 
+// GetJobErrorDetails returns the serialized wrap chains of the errors occurred in the job, keyed by the tasks.
+// Errors recorded without their chains are returned with their messages only.
+func (m *Manager) GetJobErrorDetails(ctx context.Context, jobID string) (map[string]*ErrorDetail, error) {
+	errs, err := m.GetJobErrors(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	details := make(map[string]*ErrorDetail, len(errs))
+	for _, e := range errs {
+		detail := e.Detail
+		if detail == nil {
+			detail = &ErrorDetail{Message: e.Message}
+		}
+		details[e.Task] = detail
+	}
+	return details, nil
+}
+
 func (m *Manager) WatchJobErrors(ctx context.Context, jobID string) chan Error {
 	errChan := make(chan Error)
 	go func() {
 		for event := range m.clusterState.Watch(ctx, path.Join(jobErrorNs, jobID)) {
+			if event.Type != coordinator.PutEvent {
+				continue
+			}
 			var e Error
 			if err := event.Item.Unmarshal(&e); err != nil {
 				m.log.Error("Failed to unmarshal error desc {}: {}", err, string(event.Item.Value))
@@ -134,6 +228,295 @@ func (m *Manager) ListJobs(ctx context.Context, prefixFormat string, args ...int
 	return jobs, nil
 }
 
+// ListJobsByMetadata returns jobs having given value for the key in their metadata.
+func (m *Manager) ListJobsByMetadata(ctx context.Context, key, value string) ([]*Job, error) {
+	jobs, err := m.ListJobs(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var matched []*Job
+	for _, j := range jobs {
+		if v, ok := j.Metadata[key]; ok && v == value {
+			matched = append(matched, j)
+		}
+	}
+	return matched, nil
+}
+
+// WatchCreatedJobs subscribes jobs created after the call, until the context is done.
+func (m *Manager) WatchCreatedJobs(ctx context.Context) chan *Job {
+	jobChan := make(chan *Job)
+	go func() {
+		defer close(jobChan)
+		for event := range m.clusterState.Watch(ctx, jobNs) {
+			if event.Type != coordinator.PutEvent {
+				continue
+			}
+			j := &Job{}
+			if err := event.Item.Unmarshal(j); err != nil {
+				m.log.Error("Failed to unmarshal job {}", err, event.Item.Key)
+				continue
+			}
+			select {
+			case jobChan <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return jobChan
+}
+
+// StageProgress is a progress of the tasks in a stage.
+type StageProgress struct {
+	Name        string `json:"name"`
+	TotalTasks  int    `json:"totalTasks"`
+	DoneTasks   int    `json:"doneTasks"`
+	FailedTasks int    `json:"failedTasks"`
+}
+
+// GetStageProgresses returns progresses of the stages in the job, except the input stage.
+func (m *Manager) GetStageProgresses(ctx context.Context, j *Job) ([]StageProgress, error) {
+	var progresses []StageProgress
+	for i, s := range j.Stages {
+		if i == 0 {
+			// input stage is run by the master
+			continue
+		}
+		done, err := m.clusterState.ReadCounter(ctx, path.Join(stageStatusNs, j.ID, s.Name, "doneTasks"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "read done tasks of stage %s", s.Name)
+		}
+		failed, err := m.clusterState.ReadCounter(ctx, path.Join(stageStatusNs, j.ID, s.Name, "failedTasks"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "read failed tasks of stage %s", s.Name)
+		}
+		progresses = append(progresses, StageProgress{
+			Name:        s.Name,
+			TotalTasks:  len(j.GetPartitionsOfStage(s.Name)),
+			DoneTasks:   int(done),
+			FailedTasks: int(failed),
+		})
+	}
+	return progresses, nil
+}
+
+// PartitionState is a coarse state of a partition in a stage, for monitoring.
+type PartitionState string
+
+const (
+	// PartitionPending is a state of the partition whose task is not created yet.
+	PartitionPending PartitionState = "pending"
+	PartitionRunning PartitionState = "running"
+
+	// PartitionDone is a state of the partition whose task has succeeded or failed. See Status for which one.
+	PartitionDone PartitionState = "done"
+)
+
+// PartitionStatus is a status of a partition in a stage.
+type PartitionStatus struct {
+	Task  TaskID         `json:"task"`
+	Host  string         `json:"host"`
+	State PartitionState `json:"state"`
+
+	// Status is a status of the task of the partition. It is nil if the partition is pending.
+	Status *TaskStatus `json:"status,omitempty"`
+}
+
+// ListStagePartitions returns statuses of every partition assigned to the stage of the job,
+// in the order of the assignments.
+func (m *Manager) ListStagePartitions(ctx context.Context, jobID, stageName string) ([]PartitionStatus, error) {
+	j, err := m.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get job")
+	}
+	if j.GetStage(stageName) == nil {
+		return nil, errors.Errorf("stage %s not found in job %s", stageName, jobID)
+	}
+	items, err := m.clusterState.Scan(ctx, path.Join(taskStatusNs, jobID, stageName)+"/")
+	if err != nil {
+		return nil, errors.Wrap(err, "scan task statuses")
+	}
+	taskStatuses := make(map[string]*TaskStatus, len(items))
+	for _, item := range items {
+		ts := new(TaskStatus)
+		if err := item.Unmarshal(ts); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal task status %s", item.Key)
+		}
+		taskStatuses[path.Base(item.Key)] = ts
+	}
+
+	assignments := j.GetPartitionsOfStage(stageName)
+	statuses := make([]PartitionStatus, len(assignments))
+	for i, a := range assignments {
+		ts := taskStatuses[a.PartitionID]
+		statuses[i] = PartitionStatus{
+			Task:   TaskID{JobID: jobID, StageName: stageName, PartitionID: a.PartitionID},
+			Host:   a.Host,
+			State:  partitionStateOf(ts),
+			Status: ts,
+		}
+	}
+	return statuses, nil
+}
+
+func partitionStateOf(ts *TaskStatus) PartitionState {
+	switch {
+	case ts == nil:
+		return PartitionPending
+	case ts.Status == Succeeded || ts.Status == Failed:
+		return PartitionDone
+	default:
+		return PartitionRunning
+	}
+}
+
+// MarkOutputPersisted records that the output of the partition in the final stage of the job
+// is persisted on the host, with the number of rows in the output.
+func (m *Manager) MarkOutputPersisted(ctx context.Context, jobID, partitionID, host string, rows int) error {
+	txn := coordinator.NewTxn().
+		Put(path.Join(persistedOutputNs, jobID, partitionID), host).
+		Put(path.Join(persistedRowsNs, jobID, partitionID), rows)
+
+	_, err := m.clusterState.Commit(ctx, txn)
+	return err
+}
+
+// UnmarkOutputPersisted removes the record of the persisted output of the partition, after the output is released.
+func (m *Manager) UnmarkOutputPersisted(ctx context.Context, jobID, partitionID string) error {
+	txn := coordinator.NewTxn().
+		DeleteKey(path.Join(persistedOutputNs, jobID, partitionID)).
+		DeleteKey(path.Join(persistedRowsNs, jobID, partitionID))
+
+	_, err := m.clusterState.Commit(ctx, txn)
+	return err
+}
+
+// CountPersistedRows returns the number of rows in the persisted output of the job.
+func (m *Manager) CountPersistedRows(ctx context.Context, jobID string) (int, error) {
+	items, err := m.clusterState.Scan(ctx, path.Join(persistedRowsNs, jobID))
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, item := range items {
+		var rows int
+		if err := item.Unmarshal(&rows); err != nil {
+			return 0, errors.Wrapf(err, "unmarshal item %s", item.Key)
+		}
+		total += rows
+	}
+	return total, nil
+}
+
+// ListPersistedOutputs returns locations of the persisted output of the job.
+// It returns empty assignments if the output of the job was not persisted.
+func (m *Manager) ListPersistedOutputs(ctx context.Context, jobID string) (partitions.Assignments, error) {
+	items, err := m.clusterState.Scan(ctx, path.Join(persistedOutputNs, jobID))
+	if err != nil {
+		return nil, err
+	}
+	assignments := make(partitions.Assignments, len(items))
+	for i, item := range items {
+		assignments[i].PartitionID = path.Base(item.Key)
+		if err := item.Unmarshal(&assignments[i].Host); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal item %s", item.Key)
+		}
+	}
+	return assignments, nil
+}
+
+// SaveRouting stores hosts of the partitions of the stage, which the tasks of the previous stage output to.
+// It is read by the workers with GetRouting if the job is created with WithRoutingInCoordinator.
+func (m *Manager) SaveRouting(ctx context.Context, jobID, stageName string, routing partitions.Assignments) error {
+	if err := m.clusterState.Put(ctx, path.Join(routingNs, jobID, stageName), routing); err != nil {
+		return errors.Wrap(err, "etcd write")
+	}
+	return nil
+}
+
+// GetRouting returns hosts of the partitions of the stage stored by SaveRouting.
+func (m *Manager) GetRouting(ctx context.Context, jobID, stageName string) (partitions.Assignments, error) {
+	var routing partitions.Assignments
+	if err := m.clusterState.Get(ctx, path.Join(routingNs, jobID, stageName), &routing); err != nil {
+		return nil, err
+	}
+	return routing, nil
+}
+
+// AddPeekedRows records rows sampled from the output of the task by Dataset.Peek.
+func (m *Manager) AddPeekedRows(ctx context.Context, ref TaskID, rows []*lrdd.Row) error {
+	return m.clusterState.Put(ctx, path.Join(peekedRowsNs, ref.String()), rows)
+}
+
+// ListPeekedRows returns rows sampled from the output of the stage, in the order of the partitions.
+func (m *Manager) ListPeekedRows(ctx context.Context, jobID, stageName string) ([]*lrdd.Row, error) {
+	items, err := m.clusterState.Scan(ctx, path.Join(peekedRowsNs, jobID, stageName)+"/")
+	if err != nil {
+		return nil, err
+	}
+	var peeked []*lrdd.Row
+	for _, item := range items {
+		var rows []*lrdd.Row
+		if err := item.Unmarshal(&rows); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal item %s", item.Key)
+		}
+		peeked = append(peeked, rows...)
+	}
+	return peeked, nil
+}
+
+// QuarantinedRow is a row excluded from the output of a task, with the reason.
+type QuarantinedRow struct {
+	Task   string    `json:"task"`
+	Row    *lrdd.Row `json:"row"`
+	Reason string    `json:"reason"`
+}
+
+// AddQuarantinedRows records rows quarantined by the task.
+func (m *Manager) AddQuarantinedRows(ctx context.Context, ref TaskID, rows []QuarantinedRow) error {
+	return m.clusterState.Put(ctx, path.Join(quarantinedRowsNs, ref.String()), rows)
+}
+
+// ListQuarantinedRows returns rows quarantined by the tasks of the job.
+func (m *Manager) ListQuarantinedRows(ctx context.Context, jobID string) ([]QuarantinedRow, error) {
+	items, err := m.clusterState.Scan(ctx, path.Join(quarantinedRowsNs, jobID)+"/")
+	if err != nil {
+		return nil, err
+	}
+	var quarantined []QuarantinedRow
+	for _, item := range items {
+		var rows []QuarantinedRow
+		if err := item.Unmarshal(&rows); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal item %s", item.Key)
+		}
+		quarantined = append(quarantined, rows...)
+	}
+	return quarantined, nil
+}
+
+// AddDriverOutputs records rows emitted to the driver by the task.
+func (m *Manager) AddDriverOutputs(ctx context.Context, ref TaskID, rows []*lrdd.Row) error {
+	return m.clusterState.Put(ctx, path.Join(driverOutputsNs, ref.String()), rows)
+}
+
+// ListDriverOutputs returns rows emitted to the driver by the tasks of the job.
+func (m *Manager) ListDriverOutputs(ctx context.Context, jobID string) ([]*lrdd.Row, error) {
+	items, err := m.clusterState.Scan(ctx, path.Join(driverOutputsNs, jobID)+"/")
+	if err != nil {
+		return nil, err
+	}
+	var emitted []*lrdd.Row
+	for _, item := range items {
+		var rows []*lrdd.Row
+		if err := item.Unmarshal(&rows); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal item %s", item.Key)
+		}
+		emitted = append(emitted, rows...)
+	}
+	return emitted, nil
+}
+
 func (m *Manager) CreateTask(ctx context.Context, task *Task) (*TaskStatus, error) {
 	status := NewTaskStatus()
 	if err := m.clusterState.Put(ctx, path.Join(taskStatusNs, task.ID().String()), status); err != nil {
@@ -158,8 +541,54 @@ func (m *Manager) GetTaskStatus(ctx context.Context, ref TaskID) (*TaskStatus, e
 	return status, nil
 }
 
+// GetStageDurations returns wall-clock spans of the stages of the job, keyed by stage names. Tasks which have
+// been completed without running are counted from their completion. Stages with no task reported are omitted.
+func (m *Manager) GetStageDurations(ctx context.Context, jobID string) (map[string]StageDuration, error) {
+	prefix := path.Join(taskStatusNs, jobID) + "/"
+	items, err := m.clusterState.Scan(ctx, prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan task statuses")
+	}
+	durations := make(map[string]StageDuration)
+	running := make(map[string]bool)
+	for _, item := range items {
+		ts := new(TaskStatus)
+		if err := item.Unmarshal(ts); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal task status %s", item.Key)
+		}
+		stageName := strings.SplitN(strings.TrimPrefix(item.Key, prefix), "/", 2)[0]
+
+		startedAt := ts.StartedAt
+		if startedAt == nil {
+			if ts.CompletedAt == nil {
+				// not started yet
+				running[stageName] = true
+				continue
+			}
+			startedAt = ts.CompletedAt
+		}
+		d, ok := durations[stageName]
+		if !ok || startedAt.Before(d.StartedAt) {
+			d.StartedAt = *startedAt
+		}
+		if ts.CompletedAt == nil {
+			running[stageName] = true
+		} else if d.CompletedAt == nil || ts.CompletedAt.After(*d.CompletedAt) {
+			d.CompletedAt = ts.CompletedAt
+		}
+		durations[stageName] = d
+	}
+	for stageName := range running {
+		if d, ok := durations[stageName]; ok {
+			d.CompletedAt = nil
+			durations[stageName] = d
+		}
+	}
+	return durations, nil
+}
+
 func (m *Manager) ListTaskStatusesInJob(ctx context.Context, jobID string) ([]*TaskStatus, error) {
-	items, err := m.clusterState.Scan(ctx, path.Join(taskStatusNs, jobID))
+	items, err := m.clusterState.Scan(ctx, path.Join(taskStatusNs, jobID)+"/")
 	if err != nil {
 		return nil, errors.Wrap(err, "get task")
 	}
@@ -0,0 +1,323 @@
+package job
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestManager_CreateJob(t *testing.T) {
+	Convey("Given a job.Manager", t, func() {
+		ctx := context.Background()
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+
+		Convey("Without an IDGenerator", func() {
+			m := NewManager(coordinator.NewLocalMemory())
+
+			Convey("It should generate a random job ID", func() {
+				j, err := m.CreateJob(ctx, "test", stages, nil)
+				So(err, ShouldBeNil)
+				So(j.ID, ShouldStartWith, "J")
+				So(j.ID, ShouldHaveLength, 9)
+			})
+		})
+
+		Convey("With a custom IDGenerator", func() {
+			m := NewManager(coordinator.NewLocalMemory(), WithIDGenerator(&sequentialIDGenerator{}))
+
+			Convey("It should be used for job IDs", func() {
+				j1, err := m.CreateJob(ctx, "test", stages, nil)
+				So(err, ShouldBeNil)
+				So(j1.ID, ShouldEqual, "J-1")
+
+				j2, err := m.CreateJob(ctx, "test", stages, nil)
+				So(err, ShouldBeNil)
+				So(j2.ID, ShouldEqual, "J-2")
+
+				Convey("Task IDs should be derived from the job ID", func() {
+					task := NewTask("0", node.New("localhost", node.Worker), j1.ID, &stages[1])
+					_, err := m.CreateTask(ctx, task)
+					So(err, ShouldBeNil)
+					So(task.ID().String(), ShouldEqual, "J-1/map0/0")
+
+					statuses, err := m.ListTaskStatusesInJob(ctx, j1.ID)
+					So(err, ShouldBeNil)
+					So(statuses, ShouldHaveLength, 1)
+				})
+			})
+		})
+	})
+}
+
+func TestManager_CreateJob_ManyStages(t *testing.T) {
+	Convey("Given a job.Manager on a coordinator limiting operations of a transaction", t, func() {
+		ctx := context.Background()
+		crd := &txnLimitedCoordinator{Coordinator: coordinator.NewLocalMemory()}
+		m := NewManager(crd)
+
+		stages := make([]stage.Stage, 300)
+		for i := range stages {
+			stages[i] = stage.Stage{Name: "map" + strconv.Itoa(i)}
+		}
+
+		Convey("A job with more stages than fit in a transaction should be created", func() {
+			j, err := m.CreateJob(ctx, "test", stages, nil)
+			So(err, ShouldBeNil)
+			So(crd.commits, ShouldBeGreaterThan, 1)
+
+			created, err := m.GetJob(ctx, j.ID)
+			So(err, ShouldBeNil)
+			So(created.Stages, ShouldHaveLength, 300)
+
+			items, err := crd.Scan(ctx, path.Join(stageStatusNs, j.ID)+"/")
+			So(err, ShouldBeNil)
+			So(items, ShouldHaveLength, 300)
+		})
+
+		Convey("Jobs with the number of stages around the limit of a transaction should be created", func() {
+			for _, n := range []int{maxOpsPerTxn - 2, maxOpsPerTxn - 1, maxOpsPerTxn, maxOpsPerTxn + 1} {
+				j, err := m.CreateJob(ctx, "test", stages[:n], nil)
+				So(err, ShouldBeNil)
+
+				created, err := m.GetJob(ctx, j.ID)
+				So(err, ShouldBeNil)
+				So(created.Stages, ShouldHaveLength, n)
+
+				items, err := crd.Scan(ctx, path.Join(stageStatusNs, j.ID)+"/")
+				So(err, ShouldBeNil)
+				So(items, ShouldHaveLength, n)
+			}
+		})
+
+		Convey("When writing the job fails after some stage statuses are written", func() {
+			crd.failAt = 2
+			_, err := m.CreateJob(ctx, "test", stages, nil)
+			So(err, ShouldNotBeNil)
+
+			Convey("Written stage statuses should be cleaned up", func() {
+				items, err := crd.Scan(ctx, stageStatusNs)
+				So(err, ShouldBeNil)
+				So(items, ShouldBeEmpty)
+
+				jobs, err := m.ListJobs(ctx, "")
+				So(err, ShouldBeNil)
+				So(jobs, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestManager_ListStagePartitions(t *testing.T) {
+	Convey("Given a job with a stage of three partitions", t, func() {
+		ctx := context.Background()
+		crd := coordinator.NewLocalMemory()
+		m := NewManager(crd)
+
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}, {Name: "map01"}}
+		assignments := []partitions.Assignments{
+			{{PartitionID: partitions.InputPartitionID, Host: "master"}},
+			{{PartitionID: "0", Host: "worker1"}, {PartitionID: "1", Host: "worker2"}, {PartitionID: "2", Host: "worker1"}},
+			{{PartitionID: "0", Host: "worker1"}},
+		}
+		j, err := m.CreateJob(ctx, "test", stages, assignments)
+		So(err, ShouldBeNil)
+
+		createTask := func(s *stage.Stage, partitionID string) *TaskReporter {
+			task := NewTask(partitionID, node.New("worker1", node.Worker), j.ID, s)
+			ts, err := m.CreateTask(ctx, task)
+			So(err, ShouldBeNil)
+			return NewTaskReporter(ctx, crd, j, task.ID(), ts)
+		}
+
+		Convey("When a task has succeeded, another is running and the other is not created", func() {
+			So(createTask(&stages[1], "0").ReportSuccess(), ShouldBeNil)
+			createTask(&stages[1], "1")
+			createTask(&stages[2], "0")
+
+			Convey("Every partition of the stage should be returned with its state", func() {
+				statuses, err := m.ListStagePartitions(ctx, j.ID, "map0")
+				So(err, ShouldBeNil)
+				So(statuses, ShouldHaveLength, 3)
+
+				So(statuses[0].Task.String(), ShouldEqual, j.ID+"/map0/0")
+				So(statuses[0].Host, ShouldEqual, "worker1")
+				So(statuses[0].State, ShouldEqual, PartitionDone)
+				So(statuses[0].Status.Status, ShouldEqual, Succeeded)
+
+				So(statuses[1].Host, ShouldEqual, "worker2")
+				So(statuses[1].State, ShouldEqual, PartitionRunning)
+				So(statuses[1].Status, ShouldNotBeNil)
+
+				So(statuses[2].State, ShouldEqual, PartitionPending)
+				So(statuses[2].Status, ShouldBeNil)
+			})
+		})
+
+		Convey("Listing partitions of an unknown stage should fail", func() {
+			_, err := m.ListStagePartitions(ctx, j.ID, "unknown")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestManager_GetStageDurations(t *testing.T) {
+	Convey("Given tasks of a job with synthetic timings", t, func() {
+		ctx := context.Background()
+		crd := coordinator.NewLocalMemory()
+		m := NewManager(crd)
+
+		base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		at := func(sec int) *time.Time {
+			t := base.Add(time.Duration(sec) * time.Second)
+			return &t
+		}
+		putStatus := func(task string, startedAt, completedAt *time.Time) {
+			ts := NewTaskStatus()
+			ts.StartedAt = startedAt
+			ts.CompletedAt = completedAt
+			So(crd.Put(ctx, path.Join(taskStatusNs, "J", task), ts), ShouldBeNil)
+		}
+		putStatus("map0/0", at(1), at(5))
+		putStatus("map0/1", at(2), at(8))
+		putStatus("map1/0", at(6), at(9))
+		putStatus("map1/1", nil, at(10)) // completed without running
+		putStatus("sort2/0", at(9), at(12))
+		putStatus("sort2/1", at(10), nil)
+
+		durations, err := m.GetStageDurations(ctx, "J")
+		So(err, ShouldBeNil)
+		So(durations, ShouldHaveLength, 3)
+
+		Convey("Each stage should span from the earliest start to the latest completion of its tasks", func() {
+			So(durations["map0"].StartedAt, ShouldEqual, *at(1))
+			So(*durations["map0"].CompletedAt, ShouldEqual, *at(8))
+			So(durations["map0"].Duration(), ShouldEqual, 7*time.Second)
+		})
+
+		Convey("Tasks completed without running should be counted from their completion", func() {
+			So(durations["map1"].StartedAt, ShouldEqual, *at(6))
+			So(durations["map1"].Duration(), ShouldEqual, 4*time.Second)
+		})
+
+		Convey("A stage with running tasks should not be completed", func() {
+			So(durations["sort2"].StartedAt, ShouldEqual, *at(9))
+			So(durations["sort2"].CompletedAt, ShouldBeNil)
+		})
+	})
+}
+
+func TestManager_CompactCompletedJobs(t *testing.T) {
+	Convey("Given jobs with task statuses", t, func() {
+		ctx := context.Background()
+		crd := coordinator.NewLocalMemory()
+		m := NewManager(crd, WithIDGenerator(&sequentialIDGenerator{}))
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+
+		createJob := func(completedAt *time.Time, state RunningState) *Job {
+			j, err := m.CreateJob(ctx, "test", stages, nil)
+			So(err, ShouldBeNil)
+			for i := 0; i < 3; i++ {
+				ts := NewTaskStatus()
+				ts.Metrics = Metrics{"Rows": 10}
+				ts.Start()
+				if i == 0 {
+					ts.Complete(Failed)
+				} else {
+					ts.Complete(Succeeded)
+				}
+				So(crd.Put(ctx, path.Join(taskStatusNs, j.ID, "map0", strconv.Itoa(i)), ts), ShouldBeNil)
+			}
+			js, err := m.GetJobStatus(ctx, j.ID)
+			So(err, ShouldBeNil)
+			js.Status = state
+			js.CompletedAt = completedAt
+			So(m.SetJobStatus(ctx, j.ID, js), ShouldBeNil)
+			return j
+		}
+		hourAgo, now := time.Now().Add(-time.Hour), time.Now()
+		old := createJob(&hourAgo, Succeeded)
+		recent := createJob(&now, Succeeded)
+		running := createJob(nil, Running)
+
+		Convey("When compacting jobs completed before the retention", func() {
+			compacted, err := m.CompactCompletedJobs(ctx, 10*time.Minute)
+			So(err, ShouldBeNil)
+			So(compacted, ShouldEqual, 1)
+
+			Convey("Task statuses of the job should be deleted", func() {
+				statuses, err := m.ListTaskStatusesInJob(ctx, old.ID)
+				So(err, ShouldBeNil)
+				So(statuses, ShouldBeEmpty)
+			})
+
+			Convey("Summary of the job should remain", func() {
+				summary, err := m.GetJobSummary(ctx, old.ID)
+				So(err, ShouldBeNil)
+				So(summary.Status, ShouldEqual, Succeeded)
+				So(summary.CompletedAt.Equal(hourAgo), ShouldBeTrue)
+				So(summary.TotalTasks, ShouldEqual, 3)
+				So(summary.FailedTasks, ShouldEqual, 1)
+				So(summary.Metrics["Rows"], ShouldEqual, 30)
+				So(summary.StageDurations, ShouldContainKey, "map0")
+			})
+
+			Convey("Task statuses of the other jobs should be kept", func() {
+				for _, j := range []*Job{recent, running} {
+					statuses, err := m.ListTaskStatusesInJob(ctx, j.ID)
+					So(err, ShouldBeNil)
+					So(statuses, ShouldHaveLength, 3)
+
+					_, err = m.GetJobSummary(ctx, j.ID)
+					So(err, ShouldEqual, coordinator.ErrNotFound)
+				}
+			})
+
+			Convey("Compacting again should do nothing", func() {
+				compacted, err := m.CompactCompletedJobs(ctx, 10*time.Minute)
+				So(err, ShouldBeNil)
+				So(compacted, ShouldEqual, 0)
+			})
+		})
+
+		Convey("Compacting a running job should fail", func() {
+			_, err := m.CompactTaskStatuses(ctx, running.ID)
+			So(err, ShouldEqual, ErrJobNotCompleted)
+		})
+	})
+}
+
+type sequentialIDGenerator struct {
+	seq int
+}
+
+func (s *sequentialIDGenerator) GenerateID(prefix string) string {
+	s.seq++
+	return prefix + "-" + strconv.Itoa(s.seq)
+}
+
+// txnLimitedCoordinator refuses transactions exceeding maxOpsPerTxn like etcd, and fails the failAt-th commit if set.
+type txnLimitedCoordinator struct {
+	coordinator.Coordinator
+	commits int
+	failAt  int
+}
+
+func (c *txnLimitedCoordinator) Commit(ctx context.Context, t *coordinator.Txn, opts ...coordinator.WriteOption) ([]coordinator.TxnResult, error) {
+	c.commits++
+	if len(t.Ops) > maxOpsPerTxn {
+		return nil, errors.New("too many operations in txn request")
+	}
+	if c.commits == c.failAt {
+		return nil, errors.New("commit failure")
+	}
+	return c.Coordinator.Commit(ctx, t, opts...)
+}
@@ -3,12 +3,24 @@ package job
 import (
 	"fmt"
 	"sort"
+	"time"
 
+	"github.com/ab180/lrmr/transformation"
+	"github.com/pkg/errors"
 	"github.com/thoas/go-funk"
 )
 
+// ErrMetricAggregationMismatch is returned when a metric is aggregated in different ways.
+var ErrMetricAggregationMismatch = errors.New("metric aggregated in different ways")
+
 type Metrics map[string]int
 
+// SerializationTimeMetric returns a name of the metric of time spent on serialization and deserialization by
+// the tasks of the stage in microseconds, such as decoding the stage and broadcasts and encoding pushed rows.
+func SerializationTimeMetric(stageName string) string {
+	return stageName + "/SerializationTime"
+}
+
 // Sum merges two metrics. When a key collides, it sums two key.
 func (m Metrics) Sum(o Metrics) (merged Metrics) {
 	merged = make(Metrics)
@@ -53,3 +65,81 @@ func (m Metrics) String() string {
 	}
 	return metricLogs
 }
+
+// AggregateMetrics aggregates metrics of the tasks with their aggregations (see TaskStatus.AddMetric).
+// It returns ErrMetricAggregationMismatch if tasks aggregate a metric in different ways.
+func AggregateMetrics(statuses []*TaskStatus) (Metrics, error) {
+	aggs := make(map[string]transformation.MetricAggregation)
+	for _, ts := range statuses {
+		for name := range ts.Metrics {
+			agg := aggregationOf(ts, name)
+			if prev, ok := aggs[name]; ok {
+				if err := checkMetricAggregation(name, prev, agg); err != nil {
+					return nil, err
+				}
+			}
+			aggs[name] = agg
+		}
+	}
+
+	// the value of the task completed last is kept by LastMetric
+	now := time.Now()
+	sorted := make([]*TaskStatus, len(statuses))
+	copy(sorted, statuses)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return completionTimeOf(sorted[i], now).Before(completionTimeOf(sorted[j], now))
+	})
+
+	aggregated := make(Metrics)
+	samples := make(map[string]int)
+	for _, ts := range sorted {
+		for name, val := range ts.Metrics {
+			prev, exists := aggregated[name]
+			switch aggs[name] {
+			case transformation.MaxMetric:
+				if !exists || val > prev {
+					aggregated[name] = val
+				}
+			case transformation.MinMetric:
+				if !exists || val < prev {
+					aggregated[name] = val
+				}
+			case transformation.LastMetric:
+				aggregated[name] = val
+			case transformation.AvgMetric:
+				aggregated[name] = prev + val
+				samples[name] += ts.MetricSamples[name]
+			default:
+				aggregated[name] = prev + val
+			}
+		}
+	}
+	for name, n := range samples {
+		if n > 0 {
+			aggregated[name] /= n
+		}
+	}
+	return aggregated, nil
+}
+
+func aggregationOf(ts *TaskStatus, name string) transformation.MetricAggregation {
+	if agg, ok := ts.MetricAggregations[name]; ok {
+		return agg
+	}
+	return transformation.SumMetric
+}
+
+func checkMetricAggregation(name string, prev, cur transformation.MetricAggregation) error {
+	if prev != cur {
+		return errors.Wrapf(ErrMetricAggregationMismatch, "%s is aggregated by both %s and %s", name, prev, cur)
+	}
+	return nil
+}
+
+// completionTimeOf returns when the task completed. Running tasks are regarded as completed now.
+func completionTimeOf(ts *TaskStatus, now time.Time) time.Time {
+	if ts.CompletedAt == nil {
+		return now
+	}
+	return *ts.CompletedAt
+}
@@ -0,0 +1,55 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/transformation"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAggregateMetrics(t *testing.T) {
+	Convey("Given statuses of tasks with metrics aggregated in various ways", t, func() {
+		completedAt := time.Now()
+		newStatus := func(order int, values ...int) *TaskStatus {
+			ts := NewTaskStatus()
+			at := completedAt.Add(time.Duration(order) * time.Second)
+			ts.CompletedAt = &at
+			for _, v := range values {
+				So(ts.AddMetric("Rows", v, transformation.SumMetric), ShouldBeNil)
+				So(ts.AddMetric("MaxMemory", v, transformation.MaxMetric), ShouldBeNil)
+				So(ts.AddMetric("MinMemory", v, transformation.MinMetric), ShouldBeNil)
+				So(ts.AddMetric("AvgLatency", v, transformation.AvgMetric), ShouldBeNil)
+				So(ts.AddMetric("Watermark", v, transformation.LastMetric), ShouldBeNil)
+			}
+			return ts
+		}
+		// the second one has been completed last
+		statuses := []*TaskStatus{newStatus(0, 3, 9), newStatus(2, 4), newStatus(1, 1, 2, 7)}
+
+		Convey("Each metric should be aggregated with its aggregation", func() {
+			m, err := AggregateMetrics(statuses)
+			So(err, ShouldBeNil)
+			So(m["Rows"], ShouldEqual, 26)
+			So(m["MaxMemory"], ShouldEqual, 9)
+			So(m["MinMemory"], ShouldEqual, 1)
+			So(m["AvgLatency"], ShouldEqual, 26/6)
+			So(m["Watermark"], ShouldEqual, 4)
+		})
+
+		Convey("Aggregating a metric in different ways should fail", func() {
+			ts := NewTaskStatus()
+			So(ts.AddMetric("MaxMemory", 100, transformation.SumMetric), ShouldBeNil)
+
+			_, err := AggregateMetrics(append(statuses, ts))
+			So(errors.Cause(err), ShouldEqual, ErrMetricAggregationMismatch)
+		})
+
+		Convey("Adding a metric in different ways in a task should fail", func() {
+			err := statuses[0].AddMetric("MaxMemory", 100, transformation.LastMetric)
+			So(errors.Cause(err), ShouldEqual, ErrMetricAggregationMismatch)
+			So(statuses[0].Metrics["MaxMemory"], ShouldEqual, 9)
+		})
+	})
+}
@@ -0,0 +1,99 @@
+package job
+
+import (
+	"context"
+	"path"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/pkg/errors"
+)
+
+const pausedJobNs = "paused/jobs"
+
+// PauseJob marks the job as paused. Tasks of the job stop reading their inputs and writing their outputs
+// until the job is resumed, keeping rows in flight.
+func (m *Manager) PauseJob(ctx context.Context, jobID string) error {
+	if err := m.clusterState.Put(ctx, path.Join(pausedJobNs, jobID), true); err != nil {
+		return errors.Wrap(err, "etcd write")
+	}
+	m.log.Info("Job {} paused", jobID)
+	return nil
+}
+
+// ResumeJob lets tasks of the paused job continue.
+func (m *Manager) ResumeJob(ctx context.Context, jobID string) error {
+	if err := m.ClearJobPause(ctx, jobID); err != nil {
+		return err
+	}
+	m.log.Info("Job {} resumed", jobID)
+	return nil
+}
+
+// ClearJobPause deletes the mark of the job being paused, if any. It is called on the completion of the job,
+// since a job can complete (e.g. aborted) while paused.
+func (m *Manager) ClearJobPause(ctx context.Context, jobID string) error {
+	if _, err := m.clusterState.Commit(ctx, coordinator.NewTxn().DeleteKey(path.Join(pausedJobNs, jobID))); err != nil {
+		return errors.Wrap(err, "etcd write")
+	}
+	return nil
+}
+
+// IsJobPaused returns true if the job is paused.
+func (m *Manager) IsJobPaused(ctx context.Context, jobID string) (bool, error) {
+	var paused bool
+	if err := m.clusterState.Get(ctx, path.Join(pausedJobNs, jobID), &paused); err != nil {
+		if err == coordinator.ErrNotFound {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "etcd read")
+	}
+	return paused, nil
+}
+
+// WatchJobPause sends whether the job is paused, starting with the current state and then on every change,
+// until the context is done.
+func (m *Manager) WatchJobPause(ctx context.Context, jobID string) chan bool {
+	pausedChan := make(chan bool)
+	key := path.Join(pausedJobNs, jobID)
+
+	// the watch is started before reading the current state, so that changes between them are not lost
+	events := m.clusterState.Watch(ctx, key)
+	go func() {
+		defer close(pausedChan)
+
+		send := func(paused bool) bool {
+			select {
+			case pausedChan <- paused:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		paused, err := m.IsJobPaused(ctx, jobID)
+		if err != nil {
+			m.log.Warn("Failed to read whether job {} is paused: {}", jobID, err)
+		}
+		if !send(paused) {
+			return
+		}
+		for ev := range events {
+			if ev.Item.Key != key {
+				continue
+			}
+			if ev.Type != coordinator.PutEvent && ev.Type != coordinator.DeleteEvent {
+				continue
+			}
+			// the state is read again instead of following the event, since events of a pause and a resume
+			// in quick succession (e.g. aborted right after paused) can be delivered out of order
+			paused, err := m.IsJobPaused(ctx, jobID)
+			if err != nil {
+				m.log.Warn("Failed to read whether job {} is paused: {}", jobID, err)
+				continue
+			}
+			if !send(paused) {
+				return
+			}
+		}
+	}()
+	return pausedChan
+}
@@ -14,6 +14,9 @@ import (
 	"go.uber.org/atomic"
 )
 
+// DefaultProgressInterval is the default minimum interval between progress reports of a task.
+const DefaultProgressInterval = 1 * time.Second
+
 type TaskReporter struct {
 	clusterState cluster.State
 
@@ -23,6 +26,18 @@ type TaskReporter struct {
 	flushMu sync.Mutex
 	dirty   atomic.Bool
 
+	// writeMu orders progress writes before the final status, so that a progress report written
+	// outside of flushMu never overwrites the completion of the task.
+	writeMu sync.Mutex
+
+	// dropped is set if the failure of the task has been tolerated by its stage.
+	dropped atomic.Bool
+
+	// ProgressInterval is the minimum interval between writes of ReportProgress, to avoid
+	// overloading the coordinator with tasks streaming their metrics. Zero disables progress reports.
+	ProgressInterval   time.Duration
+	lastProgressReport atomic.Int64
+
 	ctx context.Context
 	log logger.Logger
 }
@@ -35,6 +50,8 @@ func NewTaskReporter(ctx context.Context, cs cluster.State, j *Job, task TaskID,
 		status:       s,
 		ctx:          ctx,
 		log:          logger.New("lrmr.jobReporter"),
+
+		ProgressInterval: DefaultProgressInterval,
 	}
 }
 
@@ -49,7 +66,51 @@ func (r *TaskReporter) UpdateMetric(mutator func(Metrics)) {
 	r.UpdateStatus(func(ts *TaskStatus) { mutator(ts.Metrics) })
 }
 
+// ProgressDue returns true if ProgressInterval has elapsed since the last progress report,
+// so that callers can skip collecting metrics which ReportProgress would throttle anyway.
+func (r *TaskReporter) ProgressDue() bool {
+	if r.ProgressInterval <= 0 {
+		return false
+	}
+	return time.Since(time.Unix(0, r.lastProgressReport.Load())) >= r.ProgressInterval
+}
+
+// ReportProgress assigns given metrics to the task and writes them to the coordinator, so that metrics of
+// the job can be read while the task is running. Reports within ProgressInterval from the last one are
+// dropped; given metrics are expected to be cumulative, thus the next report supersedes them.
+func (r *TaskReporter) ReportProgress(metrics Metrics) error {
+	last := r.lastProgressReport.Load()
+	if r.ProgressInterval <= 0 || time.Since(time.Unix(0, last)) < r.ProgressInterval {
+		return nil
+	}
+	if !r.lastProgressReport.CAS(last, time.Now().UnixNano()) {
+		// reported by another goroutine in the meantime
+		return nil
+	}
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	r.flushMu.Lock()
+	if r.status.CompletedAt != nil {
+		// do not overwrite the final status
+		r.flushMu.Unlock()
+		return nil
+	}
+	r.status.Metrics = r.status.Metrics.Assign(metrics)
+	status := r.status.Clone()
+	r.dirty.Store(false)
+	r.flushMu.Unlock()
+
+	if err := r.clusterState.Put(r.ctx, path.Join(taskStatusNs, r.task.String()), status); err != nil {
+		r.dirty.Store(true)
+		return errors.Wrap(err, "write progress")
+	}
+	return nil
+}
+
 func (r *TaskReporter) ReportSuccess() error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
 	r.flushMu.Lock()
 	defer r.flushMu.Unlock()
 
@@ -70,15 +131,19 @@ func (r *TaskReporter) ReportSuccess() error {
 	return nil
 }
 
-// ReportFailure marks the task as failed. If the error is non-nil, it's added to the error list of the job.
-// Passing nil in error will only cancel the task.
+// ReportFailure marks the task as failed. If the error is non-nil, it's added to the error list of the job,
+// or to the dropped partitions of the job if the stage tolerates the failure. Passing nil in error will only
+// cancel the task.
 func (r *TaskReporter) ReportFailure(err error) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
 	r.flushMu.Lock()
 	defer r.flushMu.Unlock()
 
 	r.status.Complete(Failed)
 	if err != nil {
 		r.status.Error = err.Error()
+		r.status.ErrorClass = ClassOf(err)
 	}
 
 	txn := coordinator.NewTxn().
@@ -86,18 +151,30 @@ func (r *TaskReporter) ReportFailure(err error) error {
 		IncrementCounter(stageStatusKey(r.task, "doneTasks")).
 		IncrementCounter(stageStatusKey(r.task, "failedTasks"))
 
+	var errDesc Error
 	if err != nil {
-		errDesc := Error{
-			Task:       r.task.String(),
-			Message:    err.Error(),
-			Stacktrace: fmt.Sprintf("%+v", err),
+		errDesc = Error{
+			Task:         r.task.String(),
+			Message:      err.Error(),
+			Stacktrace:   fmt.Sprintf("%+v", err),
+			Class:        ClassOf(err),
+			CancelReason: CancelReasonOf(err),
+			Detail:       NewErrorDetail(err),
+		}
+		if r.maxFailedTasks() == 0 {
+			txn = txn.Put(jobErrorKey(r.task), errDesc)
 		}
-		txn = txn.Put(jobErrorKey(r.task), errDesc)
 	}
 	res, etcdErr := r.clusterState.Commit(r.ctx, txn)
 	if etcdErr != nil {
 		return errors.Wrap(etcdErr, "write etcd")
 	}
+	if err != nil && r.maxFailedTasks() > 0 {
+		// whether the failure is tolerated is decided by the order of the failures
+		if err := r.reportToleratedFailure(errDesc, int(res[2].Counter)); err != nil {
+			return err
+		}
+	}
 	elapsed := r.status.CompletedAt.Sub(r.status.SubmittedAt)
 	switch err.(type) {
 	case *logger.PanicError:
@@ -112,7 +189,8 @@ func (r *TaskReporter) ReportFailure(err error) error {
 }
 
 func (r *TaskReporter) checkForStageCompletion(currentDoneTasks, currentFailedTasks int) {
-	if currentFailedTasks == 1 {
+	maxFailedTasks := r.maxFailedTasks()
+	if currentFailedTasks == maxFailedTasks+1 && !r.collectsAllErrors() {
 		// to prevent race between workers, the failure is only reported by the first worker failed
 		if err := r.reportStageCompletion(Failed); err != nil {
 			r.log.Error("Failed to report completion of failed stage", err)
@@ -126,7 +204,14 @@ func (r *TaskReporter) checkForStageCompletion(currentDoneTasks, currentFailedTa
 			r.log.Error("Failed to get count of failed stages of {}/{}", err, r.task.JobID, r.task.StageName)
 			return
 		}
-		if failedTasks > 0 {
+		if failedTasks > int64(maxFailedTasks) {
+			if !r.collectsAllErrors() {
+				// already reported by the first worker failed
+				return
+			}
+			if err := r.reportStageCompletion(Failed); err != nil {
+				r.log.Error("Failed to report completion of failed stage", err)
+			}
 			return
 		}
 		if err := r.reportStageCompletion(Succeeded); err != nil {
@@ -135,6 +220,12 @@ func (r *TaskReporter) checkForStageCompletion(currentDoneTasks, currentFailedTa
 	}
 }
 
+// collectsAllErrors returns true if the job waits for every task to report their errors, instead of
+// failing on the first error. Failures from outside of the job's stages (e.g. aborts) always fail the job.
+func (r *TaskReporter) collectsAllErrors() bool {
+	return r.job.CollectAllErrors && r.job.GetStage(r.task.StageName) != nil
+}
+
 func (r *TaskReporter) reportStageCompletion(status RunningState) error {
 	r.log.Verbose("Reporting {} stage {}/{} (by {})", status, r.job.ID, r.task.StageName, r.task)
 
@@ -146,8 +237,17 @@ func (r *TaskReporter) reportStageCompletion(status RunningState) error {
 	if err := r.clusterState.Put(r.ctx, path.Join(stageStatusNs, r.job.ID, r.task.StageName), s); err != nil {
 		return errors.Wrap(err, "update stage status")
 	}
+	if status == Succeeded {
+		// skews need to be recorded before the job completes
+		r.detectSkew()
+	}
 	if status == Failed {
-		return r.reportJobCompletion(Failed)
+		if !r.collectsAllErrors() {
+			return r.reportJobCompletion(Failed)
+		}
+		if _, err := r.clusterState.IncrementCounter(r.ctx, path.Join(jobStatusNs, r.job.ID, "failedStages")); err != nil {
+			return errors.Wrap(err, "increment failed stage count")
+		}
 	}
 
 	doneStagesKey := path.Join(jobStatusNs, r.job.ID, "doneStages")
@@ -157,6 +257,13 @@ func (r *TaskReporter) reportStageCompletion(status RunningState) error {
 	}
 	totalStages := int64(len(r.job.Stages)) - 1
 	if doneStages == totalStages {
+		failedStages, err := r.clusterState.ReadCounter(r.ctx, path.Join(jobStatusNs, r.job.ID, "failedStages"))
+		if err != nil {
+			return errors.Wrap(err, "read failed stage count")
+		}
+		if failedStages > 0 {
+			return r.reportJobCompletion(Failed)
+		}
 		return r.reportJobCompletion(Succeeded)
 	}
 	return nil
@@ -0,0 +1,119 @@
+package job
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/stage"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/atomic"
+)
+
+func TestTaskReporter_ReportProgress(t *testing.T) {
+	Convey("Given a running task", t, func() {
+		ctx := context.Background()
+		m := NewManager(coordinator.NewLocalMemory())
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+		j, err := m.CreateJob(ctx, "test", stages, nil)
+		So(err, ShouldBeNil)
+
+		task := NewTask("0", node.New("localhost", node.Worker), j.ID, &stages[1])
+		status, err := m.CreateTask(ctx, task)
+		So(err, ShouldBeNil)
+
+		r := NewTaskReporter(ctx, m.clusterState, j, task.ID(), status)
+		r.ProgressInterval = 100 * time.Millisecond
+
+		metricsOf := func() Metrics {
+			statuses, err := m.ListTaskStatusesInJob(ctx, j.ID)
+			So(err, ShouldBeNil)
+			So(statuses, ShouldHaveLength, 1)
+			return statuses[0].Metrics
+		}
+
+		Convey("Mid-run metrics should reflect its partial progress", func() {
+			So(r.ReportProgress(Metrics{"rows": 10}), ShouldBeNil)
+			So(metricsOf()["rows"], ShouldEqual, 10)
+
+			Convey("Reports within the interval should be throttled", func() {
+				So(r.ProgressDue(), ShouldBeFalse)
+				So(r.ReportProgress(Metrics{"rows": 20}), ShouldBeNil)
+				So(metricsOf()["rows"], ShouldEqual, 10)
+
+				time.Sleep(r.ProgressInterval)
+				So(r.ProgressDue(), ShouldBeTrue)
+				So(r.ReportProgress(Metrics{"rows": 30}), ShouldBeNil)
+				So(metricsOf()["rows"], ShouldEqual, 30)
+			})
+
+			Convey("Reports after the completion should not overwrite the final metrics", func() {
+				r.UpdateMetric(func(metrics Metrics) { metrics["rows"] = 100 })
+				So(r.ReportSuccess(), ShouldBeNil)
+
+				time.Sleep(r.ProgressInterval)
+				So(r.ReportProgress(Metrics{"rows": 50}), ShouldBeNil)
+				So(metricsOf()["rows"], ShouldEqual, 100)
+			})
+		})
+	})
+}
+
+func TestTaskReporter_ReportProgress_SlowCoordinator(t *testing.T) {
+	Convey("Given a running task with a slow coordinator", t, func() {
+		ctx := context.Background()
+		crd := &blockingPutCoordinator{
+			Coordinator: coordinator.NewLocalMemory(),
+			entered:     make(chan struct{}, 1),
+			release:     make(chan struct{}),
+		}
+		m := NewManager(crd)
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+		j, err := m.CreateJob(ctx, "test", stages, nil)
+		So(err, ShouldBeNil)
+
+		task := NewTask("0", node.New("localhost", node.Worker), j.ID, &stages[1])
+		status, err := m.CreateTask(ctx, task)
+		So(err, ShouldBeNil)
+
+		r := NewTaskReporter(ctx, m.clusterState, j, task.ID(), status)
+		crd.blocking.Store(true)
+
+		reported := make(chan error, 1)
+		go func() { reported <- r.ReportProgress(Metrics{"rows": 10}) }()
+		<-crd.entered
+		defer close(crd.release)
+
+		Convey("Updating metrics should not wait for the progress to be written", func() {
+			updated := make(chan struct{})
+			go func() {
+				r.UpdateMetric(func(metrics Metrics) { metrics["rows"] = 20 })
+				close(updated)
+			}()
+			select {
+			case <-updated:
+			case <-time.After(time.Second):
+				t.Fatal("UpdateMetric is blocked by the progress report")
+			}
+		})
+	})
+}
+
+// blockingPutCoordinator blocks writes of task statuses until release is closed.
+type blockingPutCoordinator struct {
+	coordinator.Coordinator
+	blocking atomic.Bool
+	entered  chan struct{}
+	release  chan struct{}
+}
+
+func (c *blockingPutCoordinator) Put(ctx context.Context, key string, value interface{}, opts ...coordinator.WriteOption) error {
+	if c.blocking.Load() && strings.HasPrefix(key, taskStatusNs) {
+		c.entered <- struct{}{}
+		<-c.release
+	}
+	return c.Coordinator.Put(ctx, key, value, opts...)
+}
@@ -0,0 +1,163 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const skewNs = "skew/jobs"
+
+// SkewDetection configures detecting a partition receiving far more input than the others in its stage.
+type SkewDetection struct {
+	// Factor is the multiple of the median input of the stage a partition needs to exceed to be regarded as skewed,
+	// in either rows or bytes. Zero disables the detection.
+	Factor float64 `json:"factor" default:"4"`
+
+	// MinRows is the minimum number of input rows of a skewed partition, to ignore skews of small stages.
+	MinRows int `json:"minRows" default:"1000"`
+}
+
+// StageSkew is the most skewed partition of a stage detected with SkewDetection.
+type StageSkew struct {
+	StageName   string  `json:"stageName"`
+	PartitionID string  `json:"partitionId"`
+	Rows        int     `json:"rows"`
+	Bytes       int     `json:"bytes"`
+	MedianRows  int     `json:"medianRows"`
+	MedianBytes int     `json:"medianBytes"`
+	Ratio       float64 `json:"ratio"`
+}
+
+// InputRowsMetric returns a name of the metric counting input rows of the task.
+func InputRowsMetric(stageName, partitionID string) string {
+	return fmt.Sprintf("%s/%s/InputRows", stageName, partitionID)
+}
+
+// InputBytesMetric returns a name of the metric counting bytes of input rows of the task.
+func InputBytesMetric(stageName, partitionID string) string {
+	return fmt.Sprintf("%s/%s/InputBytes", stageName, partitionID)
+}
+
+// SkewRatioMetric returns a name of the metric of the skew ratio of the stage, which is set only if the stage is skewed.
+func SkewRatioMetric(stageName string) string {
+	return stageName + "/SkewRatio"
+}
+
+// DetectSkew returns the partition whose input exceeds the median of the stage the most, given input rows and bytes
+// of the partitions keyed by their IDs. It returns nil if no partition is skewed beyond the SkewDetection.
+func DetectSkew(stageName string, rows, bytes map[string]int, d SkewDetection) *StageSkew {
+	if d.Factor <= 0 || len(rows) < 2 {
+		return nil
+	}
+	medianRows, medianBytes := medianOf(rows), medianOf(bytes)
+
+	var skew *StageSkew
+	for id, n := range rows {
+		if n < d.MinRows {
+			continue
+		}
+		ratio := float64(n) / float64(max(medianRows, 1))
+		if bytesRatio := float64(bytes[id]) / float64(max(medianBytes, 1)); bytesRatio > ratio {
+			ratio = bytesRatio
+		}
+		if ratio < d.Factor || (skew != nil && ratio <= skew.Ratio) {
+			continue
+		}
+		skew = &StageSkew{
+			StageName:   stageName,
+			PartitionID: id,
+			Rows:        n,
+			Bytes:       bytes[id],
+			MedianRows:  medianRows,
+			MedianBytes: medianBytes,
+			Ratio:       ratio,
+		}
+	}
+	return skew
+}
+
+func medianOf(counts map[string]int) int {
+	if len(counts) == 0 {
+		return 0
+	}
+	values := make([]int, 0, len(counts))
+	for _, n := range counts {
+		values = append(values, n)
+	}
+	sort.Ints(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// SkewMetrics returns metrics of the skew ratios of the stages, rounded down.
+func SkewMetrics(skews []StageSkew) Metrics {
+	metrics := make(Metrics, len(skews))
+	for _, s := range skews {
+		metrics[SkewRatioMetric(s.StageName)] = int(s.Ratio)
+	}
+	return metrics
+}
+
+// ListStageSkews returns skews detected in the stages of the job.
+func (m *Manager) ListStageSkews(ctx context.Context, jobID string) ([]StageSkew, error) {
+	items, err := m.clusterState.Scan(ctx, path.Join(skewNs, jobID)+"/")
+	if err != nil {
+		return nil, errors.Wrap(err, "scan stage skews")
+	}
+	skews := make([]StageSkew, len(items))
+	for i, item := range items {
+		if err := item.Unmarshal(&skews[i]); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal stage skew %s", item.Key)
+		}
+	}
+	return skews, nil
+}
+
+// detectSkew warns about the stage of the task if one of its partitions is skewed. It should be called after
+// every task of the stage has succeeded, so that the input of the partitions are final.
+func (r *TaskReporter) detectSkew() {
+	if r.job.SkewDetection == nil || r.job.SkewDetection.Factor <= 0 {
+		return
+	}
+	items, err := r.clusterState.Scan(r.ctx, path.Join(taskStatusNs, r.job.ID, r.task.StageName)+"/")
+	if err != nil {
+		r.log.Warn("Failed to read task statuses of {}/{} to detect skew: {}", r.job.ID, r.task.StageName, err)
+		return
+	}
+	rows := make(map[string]int, len(items))
+	bytes := make(map[string]int, len(items))
+	for _, item := range items {
+		var ts TaskStatus
+		if err := item.Unmarshal(&ts); err != nil {
+			r.log.Warn("Failed to unmarshal task status {}: {}", item.Key, err)
+			return
+		}
+		id := path.Base(item.Key)
+		rows[id] = ts.Metrics[InputRowsMetric(r.task.StageName, id)]
+		bytes[id] = ts.Metrics[InputBytesMetric(r.task.StageName, id)]
+	}
+	skew := DetectSkew(r.task.StageName, rows, bytes, *r.job.SkewDetection)
+	if skew == nil {
+		return
+	}
+	r.log.Warn("Stage {}/{} is skewed: partition {} received {} rows ({} bytes), {}x of the median {} rows ({} bytes).",
+		r.job.ID, skew.StageName, skew.PartitionID, skew.Rows, skew.Bytes, fmt.Sprintf("%.1f", skew.Ratio),
+		skew.MedianRows, skew.MedianBytes)
+	if err := r.clusterState.Put(r.ctx, path.Join(skewNs, r.job.ID, r.task.StageName), skew); err != nil {
+		r.log.Warn("Failed to record skew of {}/{}: {}", r.job.ID, r.task.StageName, err)
+	}
+}
@@ -0,0 +1,50 @@
+package job
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDetectSkew(t *testing.T) {
+	Convey("Given a skew detection", t, func() {
+		d := SkewDetection{Factor: 4, MinRows: 100}
+
+		Convey("A partition exceeding the multiple of the median should be detected", func() {
+			rows := map[string]int{"0": 100, "1": 120, "2": 110, "3": 1000}
+			bytes := map[string]int{"0": 1000, "1": 1200, "2": 1100, "3": 10000}
+
+			skew := DetectSkew("map0", rows, bytes, d)
+			So(skew, ShouldNotBeNil)
+			So(skew.PartitionID, ShouldEqual, "3")
+			So(skew.Rows, ShouldEqual, 1000)
+			So(skew.MedianRows, ShouldEqual, 115)
+			So(skew.Ratio, ShouldBeGreaterThan, 8)
+		})
+
+		Convey("A partition skewed only in bytes should be detected", func() {
+			rows := map[string]int{"0": 100, "1": 100, "2": 100}
+			bytes := map[string]int{"0": 1000, "1": 1000, "2": 50000}
+
+			skew := DetectSkew("map0", rows, bytes, d)
+			So(skew, ShouldNotBeNil)
+			So(skew.PartitionID, ShouldEqual, "2")
+			So(skew.Ratio, ShouldEqual, 50)
+		})
+
+		Convey("Evenly distributed partitions should not be detected", func() {
+			rows := map[string]int{"0": 1000, "1": 1500, "2": 900}
+			So(DetectSkew("map0", rows, rows, d), ShouldBeNil)
+		})
+
+		Convey("Partitions smaller than the minimum should not be detected", func() {
+			rows := map[string]int{"0": 1, "1": 1, "2": 90}
+			So(DetectSkew("map0", rows, rows, d), ShouldBeNil)
+		})
+
+		Convey("Nothing should be detected if the detection is disabled", func() {
+			rows := map[string]int{"0": 100, "1": 100, "2": 10000}
+			So(DetectSkew("map0", rows, rows, SkewDetection{}), ShouldBeNil)
+		})
+	})
+}
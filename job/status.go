@@ -3,6 +3,7 @@ package job
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 )
 
@@ -53,17 +54,76 @@ func newStageStatus() *StageStatus {
 	return &StageStatus{baseStatus: newBaseStatus()}
 }
 
+// StageDuration is a wall-clock span of a stage, from the earliest start of its tasks to the latest completion.
+type StageDuration struct {
+	StartedAt time.Time
+
+	// CompletedAt is nil while any task of the stage is running.
+	CompletedAt *time.Time
+}
+
+// Duration returns the length of the span. If the stage is running, it is measured until now.
+func (d StageDuration) Duration() time.Duration {
+	if d.CompletedAt == nil {
+		return time.Since(d.StartedAt)
+	}
+	return d.CompletedAt.Sub(d.StartedAt)
+}
+
 // Error is an error caused job to stop.
 type Error struct {
 	Task       string
 	Message    string
 	Stacktrace string
+
+	// Class is the classification of the error, which decides its retryability.
+	Class ErrorClass `json:",omitempty"`
+
+	// CancelReason is why the job has been aborted, if the error is caused by an abort.
+	CancelReason CancelReason `json:",omitempty"`
+
+	// Detail is the serialized wrap chain of the error, which makes errors.Is to detect sentinel errors.
+	Detail *ErrorDetail `json:",omitempty"`
+}
+
+// Retryable returns true if the task is likely to succeed on retry.
+func (e Error) Retryable() bool {
+	return e.Class.Retryable()
+}
+
+// CancelReason returns why the job has been aborted, or NotCancelled if it has not been aborted.
+func (s Status) CancelReason() CancelReason {
+	for _, e := range s.Errors {
+		if e.CancelReason != NotCancelled {
+			return e.CancelReason
+		}
+	}
+	return NotCancelled
+}
+
+// Errors are errors collected from the tasks of a job.
+type Errors []Error
+
+func (ee Errors) Error() string {
+	msgs := make([]string, len(ee))
+	for i, e := range ee {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(ee), strings.Join(msgs, "; "))
 }
 
 func (e Error) Error() string {
 	return fmt.Sprintf("%s (%s)", e.Task, e.Message)
 }
 
+// Unwrap returns the serialized error which caused the task to fail, if it's recorded.
+func (e Error) Unwrap() error {
+	if e.Detail == nil {
+		return nil
+	}
+	return e.Detail
+}
+
 func (e Error) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
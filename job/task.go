@@ -2,10 +2,13 @@ package job
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ab180/lrmr/cluster/node"
 	"github.com/ab180/lrmr/stage"
+	"github.com/ab180/lrmr/transformation"
+	"github.com/pkg/errors"
 )
 
 type Task struct {
@@ -44,10 +47,34 @@ func (tid TaskID) String() string {
 	return fmt.Sprintf("%s/%s/%s", tid.JobID, tid.StageName, tid.PartitionID)
 }
 
+// ParseTaskID parses the string form of TaskID (e.g. Error.Task).
+func ParseTaskID(s string) (TaskID, error) {
+	frags := strings.SplitN(s, "/", 3)
+	if len(frags) != 3 {
+		return TaskID{}, fmt.Errorf("invalid task ID: %s", s)
+	}
+	return TaskID{JobID: frags[0], StageName: frags[1], PartitionID: frags[2]}, nil
+}
+
 type TaskStatus struct {
 	baseStatus
 	Error   string  `json:"error,omitempty"`
 	Metrics Metrics `json:"metrics"`
+
+	// MetricAggregations are aggregations of the metrics not summed (e.g. transformation.MaxMetric).
+	// MetricSamples are numbers of values added to the metrics aggregated by transformation.AvgMetric.
+	MetricAggregations map[string]transformation.MetricAggregation `json:"metricAggregations,omitempty"`
+	MetricSamples      map[string]int                              `json:"metricSamples,omitempty"`
+
+	// ErrorClass is the class of the error the task failed with.
+	ErrorClass ErrorClass `json:"errorClass,omitempty"`
+
+	// LastHeartbeatAt is the last time the task called Context.Heartbeat.
+	LastHeartbeatAt *time.Time `json:"lastHeartbeatAt,omitempty"`
+
+	// StartedAt is the time the task started running, after it is submitted. It is nil if the task
+	// has been completed without running (e.g. skipped or aborted before starting).
+	StartedAt *time.Time `json:"startedAt,omitempty"`
 }
 
 func NewTaskStatus() *TaskStatus {
@@ -57,14 +84,73 @@ func NewTaskStatus() *TaskStatus {
 	}
 }
 
+// Start marks the task as running.
+func (ts *TaskStatus) Start() {
+	now := time.Now()
+	ts.Status = Running
+	ts.StartedAt = &now
+}
+
 func (ts TaskStatus) Clone() TaskStatus {
 	m := make(Metrics)
 	for k, v := range ts.Metrics {
 		m[k] = v
 	}
+	aggs := make(map[string]transformation.MetricAggregation, len(ts.MetricAggregations))
+	for k, v := range ts.MetricAggregations {
+		aggs[k] = v
+	}
+	samples := make(map[string]int, len(ts.MetricSamples))
+	for k, v := range ts.MetricSamples {
+		samples[k] = v
+	}
 	return TaskStatus{
-		baseStatus: ts.baseStatus,
-		Error:      ts.Error,
-		Metrics:    m,
+		baseStatus:         ts.baseStatus,
+		Error:              ts.Error,
+		ErrorClass:         ts.ErrorClass,
+		Metrics:            m,
+		MetricAggregations: aggs,
+		MetricSamples:      samples,
+		LastHeartbeatAt:    ts.LastHeartbeatAt,
+		StartedAt:          ts.StartedAt,
+	}
+}
+
+// AddMetric adds the value to the metric of the task with the aggregation. It returns ErrMetricAggregationMismatch
+// if the metric has been added with another aggregation.
+func (ts *TaskStatus) AddMetric(name string, val int, agg transformation.MetricAggregation) error {
+	prev, exists := ts.Metrics[name]
+	if exists {
+		if err := checkMetricAggregation(name, aggregationOf(ts, name), agg); err != nil {
+			return err
+		}
+	}
+	switch agg {
+	case transformation.SumMetric:
+		ts.Metrics[name] = prev + val
+		return nil
+	case transformation.MaxMetric:
+		if !exists || val > prev {
+			ts.Metrics[name] = val
+		}
+	case transformation.MinMetric:
+		if !exists || val < prev {
+			ts.Metrics[name] = val
+		}
+	case transformation.AvgMetric:
+		if ts.MetricSamples == nil {
+			ts.MetricSamples = make(map[string]int)
+		}
+		ts.Metrics[name] = prev + val
+		ts.MetricSamples[name]++
+	case transformation.LastMetric:
+		ts.Metrics[name] = val
+	default:
+		return errors.Errorf("unknown aggregation %q of metric %s", agg, name)
+	}
+	if ts.MetricAggregations == nil {
+		ts.MetricAggregations = make(map[string]transformation.MetricAggregation)
 	}
+	ts.MetricAggregations[name] = agg
+	return nil
 }
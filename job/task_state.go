@@ -0,0 +1,33 @@
+package job
+
+import (
+	"context"
+	"path"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/pkg/errors"
+)
+
+// taskStateNs keeps states of the tasks saved by the transformations, which survive reruns of the tasks.
+const taskStateNs = "state/tasks"
+
+// SaveTaskState keeps the value of the key in the state of the task. Since the state is keyed by the reference
+// of the task, it can be read by reruns of the partition within the job.
+func (m *Manager) SaveTaskState(ctx context.Context, ref TaskID, key string, value interface{}) error {
+	if err := m.clusterState.Put(ctx, path.Join(taskStateNs, ref.String(), key), value); err != nil {
+		return errors.Wrapf(err, "save state %s of task %s", key, ref)
+	}
+	return nil
+}
+
+// LoadTaskState reads the value of the key in the state of the task into valuePtr.
+// It returns false if the key has not been saved.
+func (m *Manager) LoadTaskState(ctx context.Context, ref TaskID, key string, valuePtr interface{}) (bool, error) {
+	if err := m.clusterState.Get(ctx, path.Join(taskStateNs, ref.String(), key), valuePtr); err != nil {
+		if err == coordinator.ErrNotFound {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "load state %s of task %s", key, ref)
+	}
+	return true, nil
+}
@@ -27,27 +27,55 @@ type subscriptionHolder struct {
 	stages []func(j *Job, stageName string, stageStatus *StageStatus)
 	tasks  []func(j *Job, stageName string, doneCountInStage int)
 	mu     sync.RWMutex
+
+	// completed is the final status of the job once its completion is found.
+	completed *Status
+
+	// checked is set after the status of the job is read on the first registration of the completion callbacks.
+	checked bool
 }
 
 func NewJobTracker(cs cluster.State, jm *Manager) *Tracker {
+	wctx, cancel := context.WithCancel(context.Background())
 	t := &Tracker{
 		clusterState: cs,
 		jobManager:   jm,
+		stopTrack:    cancel,
 		log:          logger.New("lrmr.jobTracker"),
 	}
-	go t.watch()
+	go t.watch(wctx)
 	return t
 }
 
-// OnJobCompletion registers callback for completion events of given job.
+// OnJobCompletion registers callback for completion events of given job. If the job has already completed,
+// the callback is called immediately. The callback is called at most once.
 func (t *Tracker) OnJobCompletion(job *Job, callback func(*Job, *Status)) {
 	t.AddJob(job)
 	entry, _ := t.subscriptions.LoadOrStore(job.ID, &subscriptionHolder{})
 	sub := entry.(*subscriptionHolder)
 
+	var once sync.Once
+	callOnce := func(j *Job, st *Status) {
+		once.Do(func() { callback(j, st) })
+	}
 	sub.mu.Lock()
-	sub.jobs = append(sub.jobs, callback)
+	completed := sub.completed
+	if completed == nil {
+		sub.jobs = append(sub.jobs, callOnce)
+	}
+	checked := sub.checked
+	sub.checked = true
 	sub.mu.Unlock()
+
+	if completed != nil {
+		t.activeJobs.Delete(job.ID)
+		callOnce(job, completed)
+		return
+	}
+	if !checked {
+		// the completion may have been watched before the job is tracked, which is checked once per job
+		t.checkJobCompletion(job)
+	}
 }
 
 // OnStageCompletion registers callback for stage completion events in given job ID.
@@ -77,13 +105,17 @@ func (t *Tracker) AddJob(job *Job) {
 	t.activeJobs.Store(job.ID, job)
 }
 
-func (t *Tracker) watch() {
+func (t *Tracker) watch(wctx context.Context) {
 	defer t.log.Recover()
 
-	wctx, cancel := context.WithCancel(context.Background())
-	t.stopTrack = cancel
-
 	for event := range t.clusterState.Watch(wctx, statusNs) {
+		if event.Type == coordinator.ResyncEvent {
+			if event.Lossy {
+				// events may have been dropped during the resync, so completions are not guaranteed to be watched
+				t.rescanJobStatuses()
+			}
+			continue
+		}
 		if strings.HasPrefix(event.Item.Key, stageStatusNs) {
 			t.trackStageStatus(event)
 		}
@@ -143,31 +175,54 @@ func (t *Tracker) trackJobStatus(e coordinator.WatchEvent) {
 	job := j.(*Job)
 
 	if len(frags) == 3 && e.Type == coordinator.PutEvent {
-		// job status update
-		ctx, cancel := context.WithTimeout(context.TODO(), 3*time.Second)
-		defer cancel()
+		t.checkJobCompletion(job)
+	}
+}
 
-		jobStatus, err := t.jobManager.GetJobStatus(ctx, job.ID)
-		if err != nil {
-			t.log.Error("Failed to get job status of {}", job.ID)
-			return
-		}
-		if jobStatus.Status == Succeeded || jobStatus.Status == Failed {
-			sub, release := t.getSubscription(job.ID)
-			defer release()
+// rescanJobStatuses reads the status of every active job and fires callbacks of completed ones.
+func (t *Tracker) rescanJobStatuses() {
+	t.activeJobs.Range(func(_, j interface{}) bool {
+		t.checkJobCompletion(j.(*Job))
+		return true
+	})
+}
 
-			for _, callback := range sub.jobs {
-				callback(job, &jobStatus)
-			}
-			t.activeJobs.Delete(job.ID)
-		}
+func (t *Tracker) checkJobCompletion(job *Job) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 3*time.Second)
+	defer cancel()
+
+	jobStatus, err := t.jobManager.GetJobStatus(ctx, job.ID)
+	if err != nil {
+		t.log.Error("Failed to get job status of {}", job.ID)
+		return
+	}
+	if jobStatus.Status == Succeeded || jobStatus.Status == Failed {
+		t.completeJob(job, &jobStatus)
 	}
 }
 
+// completeJob calls the completion callbacks of the job, and calls the callbacks registered later immediately.
+func (t *Tracker) completeJob(job *Job, st *Status) {
+	entry, _ := t.subscriptions.LoadOrStore(job.ID, &subscriptionHolder{})
+	holder := entry.(*subscriptionHolder)
+	holder.mu.Lock()
+	holder.completed = st
+	holder.mu.Unlock()
+
+	sub, release := t.getSubscription(job.ID)
+	defer release()
+
+	for _, callback := range sub.jobs {
+		callback(job, st)
+	}
+	t.activeJobs.Delete(job.ID)
+}
+
 func (t *Tracker) getSubscription(jobID string) (sub *subscriptionHolder, release func()) {
 	entry, ok := t.subscriptions.Load(jobID)
 	if !ok {
-		return
+		// jobs added without any callbacks
+		return &subscriptionHolder{}, func() {}
 	}
 	sub = entry.(*subscriptionHolder)
 
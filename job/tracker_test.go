@@ -0,0 +1,156 @@
+package job
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/stage"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/atomic"
+)
+
+func TestTracker_OnJobCompletion(t *testing.T) {
+	Convey("Given a job completed before subscribing to it", t, func() {
+		ctx := context.Background()
+		crd := coordinator.NewLocalMemory()
+		m := NewManager(crd)
+		j, err := m.CreateJob(ctx, "test", []stage.Stage{{Name: "_input"}, {Name: "map0"}}, nil)
+		So(err, ShouldBeNil)
+
+		js := newStatus()
+		js.Complete(Succeeded)
+		So(m.SetJobStatus(ctx, j.ID, js), ShouldBeNil)
+
+		tracker := NewJobTracker(crd, m)
+		defer tracker.Close()
+
+		Convey("The callback should be called once with the completed status", func() {
+			var calls []RunningState
+			tracker.OnJobCompletion(j, func(_ *Job, st *Status) {
+				calls = append(calls, st.Status)
+			})
+			So(calls, ShouldResemble, []RunningState{Succeeded})
+		})
+	})
+
+	Convey("Given callbacks registered to a running job", t, func() {
+		ctx := context.Background()
+		crd := &statusCountingCoordinator{Coordinator: coordinator.NewLocalMemory()}
+		m := NewManager(crd)
+		j, err := m.CreateJob(ctx, "test", []stage.Stage{{Name: "_input"}, {Name: "map0"}}, nil)
+		So(err, ShouldBeNil)
+
+		cs := &resyncingState{Coordinator: crd, events: make(chan coordinator.WatchEvent)}
+		tracker := NewJobTracker(cs, m)
+		defer tracker.Close()
+		defer close(cs.events)
+
+		calls := make(chan RunningState, 10)
+		for i := 0; i < 5; i++ {
+			tracker.OnJobCompletion(j, func(_ *Job, st *Status) {
+				calls <- st.Status
+			})
+		}
+
+		Convey("The status of the job should be read only once on registration", func() {
+			So(crd.statusReads.Load(), ShouldEqual, 1)
+		})
+
+		Convey("When the job completes", func() {
+			js := newStatus()
+			js.Complete(Succeeded)
+			So(m.SetJobStatus(ctx, j.ID, js), ShouldBeNil)
+			cs.events <- coordinator.WatchEvent{
+				Type: coordinator.PutEvent,
+				Item: coordinator.RawItem{Key: path.Join(jobStatusNs, j.ID)},
+			}
+
+			Convey("Every callback should be called", func() {
+				for i := 0; i < 5; i++ {
+					select {
+					case st := <-calls:
+						So(st, ShouldEqual, Succeeded)
+					case <-time.After(time.Second):
+						So("timeout", ShouldBeEmpty)
+					}
+				}
+			})
+
+			Convey("Callbacks registered later should be called without reading the status again", func() {
+				<-calls
+				reads := crd.statusReads.Load()
+				var called RunningState
+				tracker.OnJobCompletion(j, func(_ *Job, st *Status) {
+					called = st.Status
+				})
+				So(called, ShouldEqual, Succeeded)
+				So(crd.statusReads.Load(), ShouldEqual, reads)
+			})
+		})
+	})
+}
+
+// statusCountingCoordinator counts reads of job statuses.
+type statusCountingCoordinator struct {
+	coordinator.Coordinator
+	statusReads atomic.Int32
+}
+
+func (c *statusCountingCoordinator) Get(ctx context.Context, key string, valuePtr interface{}) error {
+	if strings.HasPrefix(key, jobStatusNs) {
+		c.statusReads.Inc()
+	}
+	return c.Coordinator.Get(ctx, key, valuePtr)
+}
+
+// resyncingState delivers only the watch events sent to its channel.
+type resyncingState struct {
+	coordinator.Coordinator
+	events chan coordinator.WatchEvent
+}
+
+func (s *resyncingState) Watch(context.Context, string) chan coordinator.WatchEvent {
+	return s.events
+}
+
+func TestTracker_Resync(t *testing.T) {
+	Convey("Given a job completed while its status events are lost", t, func() {
+		ctx := context.Background()
+		crd := coordinator.NewLocalMemory()
+		m := NewManager(crd)
+		j, err := m.CreateJob(ctx, "test", []stage.Stage{{Name: "_input"}, {Name: "map0"}}, nil)
+		So(err, ShouldBeNil)
+
+		cs := &resyncingState{Coordinator: crd, events: make(chan coordinator.WatchEvent)}
+		tracker := NewJobTracker(cs, m)
+		defer tracker.Close()
+		defer close(cs.events)
+
+		called := make(chan RunningState, 1)
+		tracker.OnJobCompletion(j, func(_ *Job, st *Status) {
+			called <- st.Status
+		})
+
+		js := newStatus()
+		js.Complete(Succeeded)
+		So(m.SetJobStatus(ctx, j.ID, js), ShouldBeNil)
+
+		Convey("A lossy resync should fire the completion callback", func() {
+			cs.events <- coordinator.WatchEvent{Type: coordinator.ResyncEvent, Lossy: true}
+			So(<-called, ShouldEqual, Succeeded)
+		})
+
+		Convey("A lossless resync should not rescan the job statuses", func() {
+			cs.events <- coordinator.WatchEvent{Type: coordinator.ResyncEvent}
+			select {
+			case <-called:
+				So("callback called", ShouldBeEmpty)
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	})
+}
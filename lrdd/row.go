@@ -1,9 +1,24 @@
 package lrdd
 
 import (
+	"bytes"
+	"sync"
+
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// maxPooledBufferSize is the maximum capacity of the buffers kept in encodeBufferPool,
+// so that a few large values would not make the pool hold too much memory.
+const maxPooledBufferSize = 64 * 1024
+
+// encodeBufferPool is a pool of buffers encoding values of the rows. Encoded bytes are copied out of the buffer,
+// so that a buffer is never shared by rows.
+var encodeBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
 func (m Row) UnmarshalValue(ptr interface{}) {
 	err := msgpack.Unmarshal(m.Value, ptr)
 	if err != nil {
@@ -20,9 +35,29 @@ func KeyValue(k string, v interface{}) *Row {
 }
 
 func mustEncode(v interface{}) []byte {
-	raw, err := msgpack.Marshal(v)
-	if err != nil {
+	buf := encodeBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			encodeBufferPool.Put(buf)
+		}
+	}()
+	buf.Reset()
+
+	enc := msgpack.GetEncoder()
+	defer msgpack.PutEncoder(enc)
+	enc.Reset(buf)
+
+	if err := enc.Encode(v); err != nil {
 		panic(err)
 	}
+	raw := make([]byte, buf.Len())
+	copy(raw, buf.Bytes())
 	return raw
 }
+
+// InheritLineage adds lineage of the rows which the row is derived from.
+func (m *Row) InheritLineage(from ...*Row) {
+	for _, r := range from {
+		m.Lineage = append(m.Lineage, r.Lineage...)
+	}
+}
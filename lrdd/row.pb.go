@@ -25,6 +25,8 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 type Row struct {
 	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// lineage are origins of the row. It is only set on a job with provenance enabled.
+	Lineage []*Lineage `protobuf:"bytes,3,rep,name=lineage,proto3" json:"lineage,omitempty"`
 }
 
 func (m *Row) Reset()         { *m = Row{} }
@@ -74,23 +76,98 @@ func (m *Row) GetValue() []byte {
 	return nil
 }
 
+func (m *Row) GetLineage() []*Lineage {
+	if m != nil {
+		return m.Lineage
+	}
+	return nil
+}
+
+// Lineage is an origin of a row, which is the row at the index of the partition in the stage
+// where the row first appeared.
+type Lineage struct {
+	Stage       string `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	PartitionID string `protobuf:"bytes,2,opt,name=partitionID,proto3" json:"partitionID,omitempty"`
+	Index       int64  `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+}
+
+func (m *Lineage) Reset()         { *m = Lineage{} }
+func (m *Lineage) String() string { return proto.CompactTextString(m) }
+func (*Lineage) ProtoMessage()    {}
+func (*Lineage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a848aec1798397f, []int{1}
+}
+func (m *Lineage) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Lineage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Lineage.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Lineage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Lineage.Merge(m, src)
+}
+func (m *Lineage) XXX_Size() int {
+	return m.Size()
+}
+func (m *Lineage) XXX_DiscardUnknown() {
+	xxx_messageInfo_Lineage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Lineage proto.InternalMessageInfo
+
+func (m *Lineage) GetStage() string {
+	if m != nil {
+		return m.Stage
+	}
+	return ""
+}
+
+func (m *Lineage) GetPartitionID() string {
+	if m != nil {
+		return m.PartitionID
+	}
+	return ""
+}
+
+func (m *Lineage) GetIndex() int64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Row)(nil), "lrdd.Row")
+	proto.RegisterType((*Lineage)(nil), "lrdd.Lineage")
 }
 
 func init() { proto.RegisterFile("lrdd/row.proto", fileDescriptor_6a848aec1798397f) }
 
 var fileDescriptor_6a848aec1798397f = []byte{
-	// 144 bytes of a gzipped FileDescriptorProto
+	// 222 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xcb, 0x29, 0x4a, 0x49,
-	0xd1, 0x2f, 0xca, 0x2f, 0xd7, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0xf1, 0x95, 0x74,
-	0xb9, 0x98, 0x83, 0xf2, 0xcb, 0x85, 0x04, 0xb8, 0x98, 0xb3, 0x53, 0x2b, 0x25, 0x18, 0x15, 0x18,
+	0xd1, 0x2f, 0xca, 0x2f, 0xd7, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0xf1, 0x95, 0xc2,
+	0xb8, 0x98, 0x83, 0xf2, 0xcb, 0x85, 0x04, 0xb8, 0x98, 0xb3, 0x53, 0x2b, 0x25, 0x18, 0x15, 0x18,
 	0x35, 0x38, 0x83, 0x40, 0x4c, 0x21, 0x11, 0x2e, 0xd6, 0xb2, 0xc4, 0x9c, 0xd2, 0x54, 0x09, 0x26,
-	0x05, 0x46, 0x0d, 0x9e, 0x20, 0x08, 0xc7, 0xc9, 0xf4, 0xc4, 0x23, 0x39, 0xc6, 0x0b, 0x8f, 0xe4,
-	0x18, 0x1f, 0x3c, 0x92, 0x63, 0x9c, 0xf0, 0x58, 0x8e, 0xe1, 0xc2, 0x63, 0x39, 0x86, 0x1b, 0x8f,
-	0xe5, 0x18, 0xa2, 0xa4, 0xd3, 0x33, 0x4b, 0x32, 0x4a, 0x93, 0xf4, 0x92, 0xf3, 0x73, 0xf5, 0x4b,
-	0x32, 0x52, 0x8b, 0xf2, 0x52, 0xf5, 0x73, 0x8a, 0x72, 0x8b, 0xf4, 0x41, 0xb6, 0x24, 0xb1, 0x81,
-	0xad, 0x34, 0x06, 0x04, 0x00, 0x00, 0xff, 0xff, 0x7b, 0xfd, 0x38, 0xeb, 0x84, 0x00, 0x00, 0x00,
+	0x05, 0x46, 0x0d, 0x9e, 0x20, 0x08, 0x47, 0x48, 0x9d, 0x8b, 0x3d, 0x27, 0x33, 0x2f, 0x35, 0x31,
+	0x3d, 0x55, 0x82, 0x59, 0x81, 0x59, 0x83, 0xdb, 0x88, 0x57, 0x0f, 0x64, 0x8c, 0x9e, 0x0f, 0x44,
+	0x30, 0x08, 0x26, 0xab, 0x14, 0xce, 0xc5, 0x0e, 0x15, 0x03, 0x99, 0x54, 0x5c, 0x02, 0xd2, 0x01,
+	0x31, 0x1d, 0xc2, 0x11, 0x52, 0xe0, 0xe2, 0x2e, 0x48, 0x2c, 0x2a, 0xc9, 0x2c, 0xc9, 0xcc, 0xcf,
+	0xf3, 0x74, 0x01, 0xdb, 0xc2, 0x19, 0x84, 0x2c, 0x04, 0xd2, 0x97, 0x99, 0x97, 0x92, 0x5a, 0x21,
+	0xc1, 0xac, 0xc0, 0xa8, 0xc1, 0x1c, 0x04, 0xe1, 0x38, 0x99, 0x9c, 0x78, 0x24, 0xc7, 0x78, 0xe1,
+	0x91, 0x1c, 0xe3, 0x83, 0x47, 0x72, 0x8c, 0x13, 0x1e, 0xcb, 0x31, 0x5c, 0x78, 0x2c, 0xc7, 0x70,
+	0xe3, 0xb1, 0x1c, 0x43, 0x94, 0x54, 0x7a, 0x66, 0x49, 0x46, 0x69, 0x92, 0x5e, 0x72, 0x7e, 0xae,
+	0x7e, 0x62, 0x92, 0xa1, 0x85, 0x81, 0x7e, 0x4e, 0x51, 0x6e, 0x91, 0x3e, 0xc8, 0x7d, 0x49, 0x6c,
+	0x60, 0x3f, 0x1b, 0x03, 0x06, 0x00, 0x95, 0x93, 0xf5, 0x06, 0x05, 0x01, 0x00, 0x00,
 }
 
 func (m *Row) Marshal() (dAtA []byte, err error) {
@@ -113,6 +190,20 @@ func (m *Row) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Lineage) > 0 {
+		for iNdEx := len(m.Lineage) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Lineage[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRow(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if len(m.Value) > 0 {
 		i -= len(m.Value)
 		copy(dAtA[i:], m.Value)
@@ -130,6 +221,48 @@ func (m *Row) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *Lineage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Lineage) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Lineage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Index != 0 {
+		i = encodeVarintRow(dAtA, i, uint64(m.Index))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.PartitionID) > 0 {
+		i -= len(m.PartitionID)
+		copy(dAtA[i:], m.PartitionID)
+		i = encodeVarintRow(dAtA, i, uint64(len(m.PartitionID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Stage) > 0 {
+		i -= len(m.Stage)
+		copy(dAtA[i:], m.Stage)
+		i = encodeVarintRow(dAtA, i, uint64(len(m.Stage)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintRow(dAtA []byte, offset int, v uint64) int {
 	offset -= sovRow(v)
 	base := offset
@@ -155,6 +288,32 @@ func (m *Row) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRow(uint64(l))
 	}
+	if len(m.Lineage) > 0 {
+		for _, e := range m.Lineage {
+			l = e.Size()
+			n += 1 + l + sovRow(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *Lineage) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Stage)
+	if l > 0 {
+		n += 1 + l + sovRow(uint64(l))
+	}
+	l = len(m.PartitionID)
+	if l > 0 {
+		n += 1 + l + sovRow(uint64(l))
+	}
+	if m.Index != 0 {
+		n += 1 + sovRow(uint64(m.Index))
+	}
 	return n
 }
 
@@ -259,6 +418,176 @@ func (m *Row) Unmarshal(dAtA []byte) error {
 				m.Value = []byte{}
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Lineage", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRow
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRow
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Lineage = append(m.Lineage, &Lineage{})
+			if err := m.Lineage[len(m.Lineage)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRow(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRow
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRow
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Lineage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRow
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Lineage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Lineage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stage", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRow
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRow
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Stage = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRow
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRow
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PartitionID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRow(dAtA[iNdEx:])
@@ -2,10 +2,14 @@ package lrdd
 
 import (
 	"math"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/gogo/protobuf/proto"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func TestRow_Encode(t *testing.T) {
@@ -66,6 +70,60 @@ func TestRow_Encode(t *testing.T) {
 	})
 }
 
+func TestValue_Pooling(t *testing.T) {
+	Convey("When encoding many values concurrently with pooled buffers", t, func() {
+		const numWorkers, numValues = 8, 500
+		rows := make([][]*Row, numWorkers)
+		var wg sync.WaitGroup
+		for w := 0; w < numWorkers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < numValues; i++ {
+					rows[w] = append(rows[w], Value(strings.Repeat(strconv.Itoa(w), i%100)))
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		Convey("Each row should keep its own value", func() {
+			for w := range rows {
+				for i, row := range rows[w] {
+					var decoded string
+					row.UnmarshalValue(&decoded)
+					So(decoded, ShouldEqual, strings.Repeat(strconv.Itoa(w), i%100))
+				}
+			}
+		})
+
+		Convey("The encoding should be same with msgpack.Marshal", func() {
+			v := &testStruct{Foo: math.E, Bar: "pooled"}
+			expected, err := msgpack.Marshal(v)
+			So(err, ShouldBeNil)
+			So(Value(v).Value, ShouldResemble, expected)
+		})
+	})
+}
+
+func BenchmarkValue(b *testing.B) {
+	v := &testStruct{Foo: math.Pi, Bar: strings.Repeat("lrmr", 64)}
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := msgpack.Marshal(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Value(v)
+		}
+	})
+}
+
 type testStruct struct {
 	Foo float64
 	Bar string
@@ -87,6 +87,8 @@ type CreateTasksRequest struct {
 	Input        []*Input          `protobuf:"bytes,4,rep,name=input,proto3" json:"input,omitempty"`
 	Output       *Output           `protobuf:"bytes,5,opt,name=output,proto3" json:"output,omitempty"`
 	Broadcasts   map[string][]byte `protobuf:"bytes,6,rep,name=broadcasts,proto3" json:"broadcasts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	SideInputs   map[string][]byte `protobuf:"bytes,7,rep,name=sideInputs,proto3" json:"sideInputs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Params       map[string]string `protobuf:"bytes,8,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (m *CreateTasksRequest) Reset()         { *m = CreateTasksRequest{} }
@@ -164,6 +166,20 @@ func (m *CreateTasksRequest) GetBroadcasts() map[string][]byte {
 	return nil
 }
 
+func (m *CreateTasksRequest) GetSideInputs() map[string][]byte {
+	if m != nil {
+		return m.SideInputs
+	}
+	return nil
+}
+
+func (m *CreateTasksRequest) GetParams() map[string]string {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
 type Job struct {
 	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
@@ -413,6 +429,11 @@ func (m *CreateTaskResponse) GetTaskID() string {
 // metadata with key "header" and value of DataHeader is required.
 type PushDataRequest struct {
 	Data []*lrdd.Row `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	// taskID is a destination of the data if the stream is multiplexed to multiple tasks.
+	TaskID string `protobuf:"bytes,2,opt,name=taskID,proto3" json:"taskID,omitempty"`
+	// seq is a sequence number of the request in the stream, starting from 1. It is used for resuming
+	// a broken stream without losing or duplicating data.
+	Seq int64 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
 }
 
 func (m *PushDataRequest) Reset()         { *m = PushDataRequest{} }
@@ -455,6 +476,20 @@ func (m *PushDataRequest) GetData() []*lrdd.Row {
 	return nil
 }
 
+func (m *PushDataRequest) GetTaskID() string {
+	if m != nil {
+		return m.TaskID
+	}
+	return ""
+}
+
+func (m *PushDataRequest) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
 // PollDataRequest is a request to poll data for a worker to process.
 // metadata with key "header" and value of DataHeader is required.
 type PollDataRequest struct {
@@ -555,8 +590,15 @@ func (m *PollDataResponse) GetIsEOF() bool {
 }
 
 type DataHeader struct {
-	TaskID   string `protobuf:"bytes,1,opt,name=taskID,proto3" json:"taskID,omitempty"`
-	FromHost string `protobuf:"bytes,2,opt,name=fromHost,proto3" json:"fromHost,omitempty"`
+	TaskID          string `protobuf:"bytes,1,opt,name=taskID,proto3" json:"taskID,omitempty"`
+	FromHost        string `protobuf:"bytes,2,opt,name=fromHost,proto3" json:"fromHost,omitempty"`
+	FromPartitionID string `protobuf:"bytes,3,opt,name=fromPartitionID,proto3" json:"fromPartitionID,omitempty"`
+	// taskIDs are destinations of a stream multiplexed to multiple tasks on a host.
+	// taskID is ignored if it is set.
+	TaskIDs []string `protobuf:"bytes,4,rep,name=taskIDs,proto3" json:"taskIDs,omitempty"`
+	// resume is set if the stream resumes a stream broken by a transient failure. The receiver responds
+	// the sequence number of the last received request in header metadata with key "lastSeq".
+	Resume bool `protobuf:"varint,5,opt,name=resume,proto3" json:"resume,omitempty"`
 }
 
 func (m *DataHeader) Reset()         { *m = DataHeader{} }
@@ -606,11 +648,191 @@ func (m *DataHeader) GetFromHost() string {
 	return ""
 }
 
+func (m *DataHeader) GetFromPartitionID() string {
+	if m != nil {
+		return m.FromPartitionID
+	}
+	return ""
+}
+
+func (m *DataHeader) GetTaskIDs() []string {
+	if m != nil {
+		return m.TaskIDs
+	}
+	return nil
+}
+
+func (m *DataHeader) GetResume() bool {
+	if m != nil {
+		return m.Resume
+	}
+	return false
+}
+
+type NodeInfo struct {
+	Version         string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	GitCommit       string   `protobuf:"bytes,2,opt,name=gitCommit,proto3" json:"gitCommit,omitempty"`
+	ProtocolVersion int32    `protobuf:"varint,3,opt,name=protocolVersion,proto3" json:"protocolVersion,omitempty"`
+	Features        []string `protobuf:"bytes,4,rep,name=features,proto3" json:"features,omitempty"`
+}
+
+func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
+func (m *NodeInfo) String() string { return proto.CompactTextString(m) }
+func (*NodeInfo) ProtoMessage()    {}
+func (*NodeInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f4e130d388338f6d, []int{10}
+}
+func (m *NodeInfo) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NodeInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NodeInfo.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NodeInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeInfo.Merge(m, src)
+}
+func (m *NodeInfo) XXX_Size() int {
+	return m.Size()
+}
+func (m *NodeInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeInfo proto.InternalMessageInfo
+
+func (m *NodeInfo) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetGitCommit() string {
+	if m != nil {
+		return m.GitCommit
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetProtocolVersion() int32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
+func (m *NodeInfo) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
+type GetTaskLogsRequest struct {
+	TaskID string `protobuf:"bytes,1,opt,name=taskID,proto3" json:"taskID,omitempty"`
+}
+
+func (m *GetTaskLogsRequest) Reset()         { *m = GetTaskLogsRequest{} }
+func (m *GetTaskLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTaskLogsRequest) ProtoMessage()    {}
+func (*GetTaskLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f4e130d388338f6d, []int{11}
+}
+func (m *GetTaskLogsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetTaskLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetTaskLogsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetTaskLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTaskLogsRequest.Merge(m, src)
+}
+func (m *GetTaskLogsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetTaskLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTaskLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTaskLogsRequest proto.InternalMessageInfo
+
+func (m *GetTaskLogsRequest) GetTaskID() string {
+	if m != nil {
+		return m.TaskID
+	}
+	return ""
+}
+
+type GetTaskLogsResponse struct {
+	// lines are recent log lines of the task, in the order of emission.
+	Lines []string `protobuf:"bytes,1,rep,name=lines,proto3" json:"lines,omitempty"`
+}
+
+func (m *GetTaskLogsResponse) Reset()         { *m = GetTaskLogsResponse{} }
+func (m *GetTaskLogsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTaskLogsResponse) ProtoMessage()    {}
+func (*GetTaskLogsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f4e130d388338f6d, []int{12}
+}
+func (m *GetTaskLogsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetTaskLogsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetTaskLogsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetTaskLogsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTaskLogsResponse.Merge(m, src)
+}
+func (m *GetTaskLogsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetTaskLogsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTaskLogsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTaskLogsResponse proto.InternalMessageInfo
+
+func (m *GetTaskLogsResponse) GetLines() []string {
+	if m != nil {
+		return m.Lines
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("lrmrpb.Input_Type", Input_Type_name, Input_Type_value)
 	proto.RegisterEnum("lrmrpb.Output_Type", Output_Type_name, Output_Type_value)
 	proto.RegisterType((*CreateTasksRequest)(nil), "lrmrpb.CreateTasksRequest")
 	proto.RegisterMapType((map[string][]byte)(nil), "lrmrpb.CreateTasksRequest.BroadcastsEntry")
+	proto.RegisterMapType((map[string]string)(nil), "lrmrpb.CreateTasksRequest.ParamsEntry")
+	proto.RegisterMapType((map[string][]byte)(nil), "lrmrpb.CreateTasksRequest.SideInputsEntry")
 	proto.RegisterType((*Job)(nil), "lrmrpb.Job")
 	proto.RegisterType((*Input)(nil), "lrmrpb.Input")
 	proto.RegisterType((*Output)(nil), "lrmrpb.Output")
@@ -621,54 +843,71 @@ func init() {
 	proto.RegisterType((*PollDataRequest)(nil), "lrmrpb.PollDataRequest")
 	proto.RegisterType((*PollDataResponse)(nil), "lrmrpb.PollDataResponse")
 	proto.RegisterType((*DataHeader)(nil), "lrmrpb.DataHeader")
+	proto.RegisterType((*NodeInfo)(nil), "lrmrpb.NodeInfo")
+	proto.RegisterType((*GetTaskLogsRequest)(nil), "lrmrpb.GetTaskLogsRequest")
+	proto.RegisterType((*GetTaskLogsResponse)(nil), "lrmrpb.GetTaskLogsResponse")
 }
 
 func init() { proto.RegisterFile("lrmrpb/rpc.proto", fileDescriptor_f4e130d388338f6d) }
 
 var fileDescriptor_f4e130d388338f6d = []byte{
-	// 665 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xdd, 0x4e, 0xdb, 0x48,
-	0x14, 0xce, 0xe4, 0x4f, 0xc9, 0x81, 0x4d, 0xa2, 0x59, 0xc4, 0x5a, 0xde, 0xdd, 0x10, 0x19, 0xa9,
-	0x4d, 0xab, 0xca, 0x41, 0xf4, 0xa6, 0xad, 0x84, 0x54, 0x28, 0xb4, 0x84, 0x02, 0x89, 0x06, 0xfa,
-	0x00, 0x13, 0x3c, 0x04, 0x17, 0xc7, 0xe3, 0x7a, 0x26, 0x45, 0x79, 0x8b, 0xbe, 0x55, 0x2b, 0xf5,
-	0x86, 0xcb, 0x5e, 0x22, 0x78, 0x91, 0x6a, 0x66, 0x6c, 0x6a, 0x87, 0x42, 0x6f, 0xa2, 0x73, 0xce,
-	0xf7, 0x63, 0x9f, 0xcf, 0x99, 0x81, 0x56, 0x10, 0x4f, 0xe2, 0x68, 0xd4, 0x8b, 0xa3, 0x13, 0x37,
-	0x8a, 0xb9, 0xe4, 0xb8, 0x6a, 0x26, 0xf6, 0xd2, 0x98, 0x8f, 0xb9, 0x1e, 0xf5, 0x54, 0x65, 0x50,
-	0xfb, 0xdf, 0x31, 0xe7, 0xe3, 0x80, 0xf5, 0x74, 0x37, 0x9a, 0x9e, 0xf6, 0xd8, 0x24, 0x92, 0xb3,
-	0x04, 0x6c, 0x04, 0xb1, 0xe7, 0xf5, 0x62, 0x7e, 0x91, 0xf4, 0xff, 0xf9, 0xa1, 0x64, 0x71, 0x48,
-	0x83, 0x5e, 0x34, 0x92, 0xb3, 0x88, 0x89, 0x9e, 0xfe, 0x35, 0xa8, 0xf3, 0xb5, 0x08, 0xf8, 0x4d,
-	0xcc, 0xa8, 0x64, 0xc7, 0x54, 0x9c, 0x0b, 0xc2, 0x3e, 0x4d, 0x99, 0x90, 0x78, 0x05, 0x4a, 0x1f,
-	0xf9, 0xc8, 0x42, 0x1d, 0xd4, 0x5d, 0x58, 0xff, 0xcb, 0x4d, 0x94, 0xee, 0xde, 0xd1, 0xe0, 0x90,
-	0x28, 0x04, 0x2f, 0x41, 0x45, 0x48, 0x3a, 0x66, 0x56, 0xb1, 0x83, 0xba, 0x75, 0x62, 0x1a, 0xec,
-	0xc0, 0x62, 0x44, 0x63, 0xe9, 0x4b, 0x9f, 0x87, 0xfd, 0x6d, 0x61, 0x95, 0x3a, 0xa5, 0x6e, 0x9d,
-	0xe4, 0x66, 0x78, 0x15, 0x2a, 0x7e, 0x18, 0x4d, 0xa5, 0x55, 0xee, 0x94, 0xb4, 0xb9, 0x59, 0xd5,
-	0xed, 0xab, 0x21, 0x31, 0x18, 0x7e, 0x04, 0x55, 0x3e, 0x95, 0x8a, 0x55, 0xd1, 0xaf, 0xd0, 0x48,
-	0x59, 0x03, 0x3d, 0x25, 0x09, 0x8a, 0xf7, 0x00, 0x46, 0x31, 0xa7, 0xde, 0x09, 0x15, 0x52, 0x58,
-	0x55, 0xed, 0xf8, 0x34, 0xe5, 0xde, 0xdd, 0xcb, 0xdd, 0xba, 0x25, 0xef, 0x84, 0x32, 0x9e, 0x91,
-	0x8c, 0xda, 0xde, 0x80, 0xe6, 0x1c, 0x8c, 0x5b, 0x50, 0x3a, 0x67, 0x33, 0x1d, 0x43, 0x9d, 0xa8,
-	0x52, 0xed, 0xfd, 0x99, 0x06, 0x53, 0xb3, 0xf7, 0x22, 0x31, 0xcd, 0xab, 0xe2, 0x0b, 0xe4, 0x3c,
-	0x81, 0xd2, 0x1e, 0x1f, 0xe1, 0x06, 0x14, 0x7d, 0x2f, 0x51, 0x14, 0x7d, 0x0f, 0x63, 0x28, 0x87,
-	0x74, 0x92, 0xe6, 0xa4, 0x6b, 0xe7, 0x3d, 0x54, 0xfa, 0xc9, 0x9a, 0x65, 0x15, 0xac, 0xa6, 0x37,
-	0xd6, 0x71, 0x2e, 0x0a, 0xf7, 0x78, 0x16, 0x31, 0xa2, 0x71, 0xc7, 0x86, 0xb2, 0xea, 0x70, 0x0d,
-	0xca, 0xc3, 0x0f, 0x47, 0xbb, 0xad, 0x82, 0xae, 0x06, 0xfb, 0xfb, 0x2d, 0xe4, 0x5c, 0x21, 0xa8,
-	0x9a, 0x54, 0xf0, 0xe3, 0x9c, 0xdd, 0xdf, 0xf9, 0xcc, 0x32, 0x7e, 0xf8, 0x00, 0x9a, 0xb7, 0xdf,
-	0xe4, 0x98, 0xef, 0x72, 0x21, 0xad, 0xa2, 0xce, 0x6e, 0x75, 0x4e, 0x33, 0xcc, 0xb3, 0x4c, 0x68,
-	0xf3, 0x5a, 0x7b, 0x0b, 0x96, 0x7e, 0x47, 0xfc, 0x53, 0x7c, 0xf5, 0x6c, 0x7c, 0x0f, 0xad, 0xf8,
-	0x12, 0x16, 0x94, 0xe9, 0x01, 0x8d, 0x22, 0x3f, 0x1c, 0xab, 0x48, 0xcf, 0xd4, 0x2b, 0x1b, 0x5f,
-	0x5d, 0xe3, 0x65, 0xa8, 0x4a, 0x2a, 0xce, 0xfb, 0xdb, 0x89, 0x73, 0xd2, 0x39, 0xcf, 0xb2, 0x7f,
-	0x6f, 0xc2, 0x44, 0xc4, 0x43, 0xc1, 0x32, 0x6c, 0x94, 0x63, 0xaf, 0x41, 0x73, 0x38, 0x15, 0x67,
-	0xdb, 0x54, 0xd2, 0xf4, 0x24, 0xfc, 0x0f, 0x65, 0x8f, 0x4a, 0x6a, 0x21, 0x9d, 0x4f, 0xdd, 0x55,
-	0xa7, 0xcb, 0x25, 0xfc, 0x82, 0xe8, 0xb1, 0xb3, 0x02, 0xcd, 0x21, 0x0f, 0x82, 0xac, 0x62, 0x11,
-	0x50, 0xa8, 0x7d, 0x4b, 0x04, 0x85, 0xce, 0x3b, 0x68, 0xfd, 0x22, 0x24, 0x8f, 0x7f, 0xd8, 0x53,
-	0x85, 0xe4, 0x8b, 0x9d, 0xc1, 0x5b, 0xbd, 0x4a, 0x8d, 0x98, 0xc6, 0x79, 0x0d, 0xa0, 0x4c, 0x76,
-	0x19, 0xf5, 0x58, 0x7c, 0xdf, 0x06, 0xd8, 0x86, 0xda, 0x69, 0xcc, 0x27, 0xc9, 0x27, 0x55, 0xc8,
-	0x6d, 0xbf, 0xfe, 0x1d, 0x41, 0xf9, 0x90, 0x7b, 0x0c, 0x6f, 0xc2, 0x42, 0xe6, 0x6c, 0x60, 0xfb,
-	0xfe, 0x03, 0x63, 0x2f, 0xbb, 0xe6, 0xae, 0x71, 0xd3, 0xbb, 0xc6, 0xdd, 0x51, 0x77, 0x0d, 0xde,
-	0x80, 0x5a, 0x9a, 0x14, 0xfe, 0x27, 0xd5, 0xcf, 0x65, 0x77, 0x9f, 0xb8, 0x8b, 0xf0, 0x26, 0xd4,
-	0xd2, 0x54, 0x32, 0xf2, 0x7c, 0x90, 0xb6, 0x75, 0x17, 0x30, 0x01, 0x76, 0xd1, 0x1a, 0xda, 0xb2,
-	0xbe, 0x5d, 0xb7, 0xd1, 0xe5, 0x75, 0x1b, 0x5d, 0x5d, 0xb7, 0xd1, 0x97, 0x9b, 0x76, 0xe1, 0xf2,
-	0xa6, 0x5d, 0xf8, 0x71, 0xd3, 0x2e, 0x8c, 0xaa, 0xfa, 0x71, 0xcf, 0x7f, 0x06, 0x00, 0x00, 0xff,
-	0xff, 0xd1, 0xb6, 0xd2, 0x89, 0x57, 0x05, 0x00, 0x00,
+	// 896 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xdf, 0x6e, 0xdb, 0xb6,
+	0x17, 0x0e, 0x2d, 0xd9, 0xb5, 0x8e, 0xf3, 0x8b, 0x0d, 0xd6, 0xe8, 0x4f, 0x50, 0x3b, 0xd7, 0x50,
+	0x81, 0xce, 0xfb, 0x03, 0xb9, 0xc8, 0x6e, 0xd6, 0x01, 0x1d, 0xd0, 0x34, 0x69, 0x93, 0x2c, 0x6d,
+	0x0c, 0x26, 0xdb, 0xc5, 0xee, 0xe8, 0x98, 0x71, 0xb5, 0xc8, 0xa2, 0x4a, 0xd2, 0x2d, 0xfc, 0x06,
+	0xbb, 0xdc, 0x03, 0xec, 0x75, 0x06, 0xec, 0x66, 0x40, 0x2f, 0x77, 0x59, 0x24, 0x2f, 0x32, 0x90,
+	0x94, 0x6c, 0xd9, 0xa9, 0x1b, 0xf4, 0xc6, 0xe0, 0xf9, 0xf3, 0x7d, 0xfc, 0x0e, 0xcf, 0xf1, 0x11,
+	0xb4, 0x12, 0x31, 0x11, 0xd9, 0xb0, 0x2f, 0xb2, 0xb3, 0x28, 0x13, 0x5c, 0x71, 0x5c, 0xb3, 0x9e,
+	0xa0, 0x3d, 0xe6, 0x63, 0x6e, 0x5c, 0x7d, 0x7d, 0xb2, 0xd1, 0xe0, 0xee, 0x98, 0xf3, 0x71, 0xc2,
+	0xfa, 0xc6, 0x1a, 0x4e, 0xcf, 0xfb, 0x6c, 0x92, 0xa9, 0x59, 0x1e, 0xdc, 0x4a, 0xc4, 0x68, 0xd4,
+	0x17, 0xfc, 0x5d, 0x6e, 0xdf, 0x8b, 0x53, 0xc5, 0x44, 0x4a, 0x93, 0x7e, 0x36, 0x54, 0xb3, 0x8c,
+	0xc9, 0xbe, 0xf9, 0xb5, 0xd1, 0xf0, 0x1f, 0x17, 0xf0, 0x33, 0xc1, 0xa8, 0x62, 0xa7, 0x54, 0x5e,
+	0x48, 0xc2, 0xde, 0x4c, 0x99, 0x54, 0xf8, 0x3e, 0x38, 0xbf, 0xf1, 0xa1, 0x8f, 0xba, 0xa8, 0xd7,
+	0xd8, 0xfe, 0x5f, 0x94, 0x23, 0xa3, 0xc3, 0x93, 0xe3, 0x57, 0x44, 0x47, 0x70, 0x1b, 0xaa, 0x52,
+	0xd1, 0x31, 0xf3, 0x2b, 0x5d, 0xd4, 0xf3, 0x88, 0x35, 0x70, 0x08, 0x9b, 0x19, 0x15, 0x2a, 0x56,
+	0x31, 0x4f, 0x0f, 0x76, 0xa5, 0xef, 0x74, 0x9d, 0x9e, 0x47, 0x96, 0x7c, 0xf8, 0x01, 0x54, 0xe3,
+	0x34, 0x9b, 0x2a, 0xdf, 0xed, 0x3a, 0x86, 0xdc, 0x96, 0x1a, 0x1d, 0x68, 0x27, 0xb1, 0x31, 0xfc,
+	0x10, 0x6a, 0x7c, 0xaa, 0x74, 0x56, 0xd5, 0x48, 0xd8, 0x2a, 0xb2, 0x8e, 0x8d, 0x97, 0xe4, 0x51,
+	0x7c, 0x08, 0x30, 0x14, 0x9c, 0x8e, 0xce, 0xa8, 0x54, 0xd2, 0xaf, 0x19, 0xc6, 0xaf, 0x8b, 0xdc,
+	0xeb, 0x75, 0x45, 0x3b, 0xf3, 0xe4, 0xbd, 0x54, 0x89, 0x19, 0x29, 0xa1, 0x35, 0x97, 0x8c, 0x47,
+	0xcc, 0xe8, 0x90, 0xfe, 0xad, 0x1b, 0xb9, 0x4e, 0xe6, 0xc9, 0x39, 0xd7, 0x02, 0x8d, 0x7f, 0x84,
+	0x5a, 0x46, 0x05, 0x9d, 0x48, 0xbf, 0x6e, 0x78, 0x1e, 0x7e, 0x82, 0x67, 0x60, 0x12, 0x2d, 0x47,
+	0x8e, 0x0a, 0x9e, 0x40, 0x73, 0x45, 0x2a, 0x6e, 0x81, 0x73, 0xc1, 0x66, 0xa6, 0x25, 0x1e, 0xd1,
+	0x47, 0xdd, 0x83, 0xb7, 0x34, 0x99, 0xda, 0x1e, 0x6c, 0x12, 0x6b, 0xfc, 0x50, 0xf9, 0x1e, 0x69,
+	0xf8, 0x8a, 0xba, 0xcf, 0x82, 0x3f, 0x86, 0x46, 0x49, 0xd4, 0x4d, 0x50, 0xaf, 0x04, 0x0d, 0xbf,
+	0x02, 0xe7, 0x90, 0x0f, 0xf1, 0x16, 0x54, 0xe2, 0x51, 0x8e, 0xa8, 0xc4, 0x23, 0x8c, 0xc1, 0x4d,
+	0xe9, 0xa4, 0xc8, 0x37, 0xe7, 0xf0, 0x27, 0xa8, 0x1e, 0xe4, 0xcd, 0x76, 0xf5, 0x78, 0x99, 0xf4,
+	0xad, 0x6d, 0xbc, 0x34, 0x10, 0xd1, 0xe9, 0x2c, 0x63, 0xc4, 0xc4, 0xc3, 0x00, 0x5c, 0x6d, 0xe1,
+	0x3a, 0xb8, 0x83, 0x9f, 0x4f, 0xf6, 0x5b, 0x1b, 0xe6, 0x74, 0x7c, 0x74, 0xd4, 0x42, 0xe1, 0x07,
+	0x04, 0x35, 0x3b, 0x1b, 0xf8, 0xcb, 0x25, 0xba, 0xdb, 0xcb, 0x93, 0x53, 0xe2, 0xc3, 0x2f, 0xa1,
+	0x39, 0x9f, 0xcc, 0x53, 0xbe, 0xcf, 0xa5, 0xf2, 0x2b, 0xa6, 0x5b, 0x0f, 0x56, 0x30, 0x83, 0xe5,
+	0x2c, 0xdb, 0xaa, 0x55, 0x6c, 0xb0, 0x03, 0xed, 0x8f, 0x25, 0x7e, 0xd6, 0xf3, 0x7d, 0xaa, 0xc4,
+	0xc7, 0xd0, 0xd0, 0xa4, 0x2f, 0x69, 0x96, 0xc5, 0xe9, 0x58, 0x3f, 0xe9, 0x6b, 0x2d, 0xd9, 0xf2,
+	0x9a, 0x33, 0xbe, 0x03, 0x35, 0x45, 0xe5, 0xc5, 0xc1, 0x6e, 0xce, 0x9c, 0x5b, 0xe1, 0xb7, 0xe5,
+	0x3f, 0x39, 0x61, 0x32, 0xe3, 0xa9, 0x64, 0xa5, 0x6c, 0xb4, 0x94, 0xfd, 0x2b, 0x34, 0x07, 0x53,
+	0xf9, 0x7a, 0x97, 0x2a, 0x5a, 0xec, 0x83, 0x2f, 0xc0, 0x1d, 0x51, 0x45, 0x7d, 0x64, 0xde, 0xc7,
+	0x8b, 0xf4, 0x8e, 0x89, 0x08, 0x7f, 0x47, 0x8c, 0x7b, 0xdd, 0xbd, 0xba, 0x74, 0xc9, 0xde, 0xf8,
+	0x4e, 0x17, 0xf5, 0x1c, 0xa2, 0x8f, 0xe1, 0x7d, 0x68, 0x0e, 0x78, 0x92, 0x94, 0xb9, 0x37, 0x01,
+	0xa5, 0x46, 0x81, 0x43, 0x50, 0x1a, 0xbe, 0x80, 0xd6, 0x22, 0x21, 0x17, 0x7a, 0xc3, 0xed, 0x6d,
+	0xa8, 0xc6, 0x72, 0xef, 0xf8, 0xb9, 0xb9, 0xbc, 0x4e, 0xac, 0x11, 0xfe, 0x89, 0x00, 0x34, 0xcb,
+	0x3e, 0xa3, 0x23, 0x26, 0xd6, 0x15, 0x8b, 0x03, 0xa8, 0x9f, 0x0b, 0x3e, 0xc9, 0xbb, 0xaf, 0x23,
+	0x73, 0x1b, 0xf7, 0xa0, 0xa9, 0xcf, 0x83, 0xc5, 0xfa, 0x32, 0xa5, 0x78, 0x64, 0xd5, 0x8d, 0x7d,
+	0xb8, 0x65, 0xf9, 0xa4, 0x59, 0x6b, 0x1e, 0x29, 0x4c, 0x7d, 0xaf, 0x60, 0x72, 0x3a, 0x61, 0x66,
+	0x93, 0xd5, 0x49, 0x6e, 0x85, 0xbf, 0x23, 0xa8, 0xbf, 0xe2, 0xfa, 0x3f, 0x7a, 0xce, 0x35, 0xfc,
+	0x2d, 0x13, 0x32, 0xe6, 0x69, 0xae, 0xae, 0x30, 0xf1, 0x3d, 0xf0, 0xc6, 0xb1, 0x7a, 0xc6, 0x27,
+	0x93, 0xb8, 0xd0, 0xb7, 0x70, 0x68, 0x81, 0x66, 0x8d, 0x9f, 0xf1, 0xe4, 0x97, 0x1c, 0xaf, 0x05,
+	0x56, 0xc9, 0xaa, 0xdb, 0x94, 0xc9, 0xa8, 0x9a, 0x0a, 0x56, 0x28, 0x9c, 0xdb, 0x7a, 0x3a, 0x5e,
+	0x30, 0xa5, 0x47, 0xe3, 0x88, 0x8f, 0xe7, 0x9f, 0x80, 0x75, 0xd3, 0xf1, 0x0d, 0xdc, 0x5e, 0xca,
+	0xce, 0x7b, 0xd4, 0x86, 0x6a, 0x12, 0xa7, 0x4c, 0x9a, 0x26, 0x79, 0xc4, 0x1a, 0xdb, 0x7f, 0x55,
+	0xc0, 0xd5, 0x55, 0xe2, 0xa7, 0xd0, 0x28, 0xad, 0x3e, 0x1c, 0xac, 0xdf, 0x87, 0xc1, 0x9d, 0xc8,
+	0x7e, 0xde, 0xa2, 0xe2, 0xf3, 0x16, 0xed, 0xe9, 0xcf, 0x1b, 0x7e, 0x02, 0xf5, 0x62, 0x2c, 0xf1,
+	0xff, 0x0b, 0xfc, 0xca, 0xa0, 0xae, 0x03, 0xf7, 0x10, 0x7e, 0x0a, 0xf5, 0x62, 0xb0, 0x4a, 0xf0,
+	0xe5, 0x59, 0x0c, 0xfc, 0xeb, 0x01, 0x5b, 0x5f, 0x0f, 0x3d, 0x42, 0xf8, 0x11, 0xb8, 0xa6, 0x5d,
+	0x6b, 0x2e, 0x09, 0x5a, 0x05, 0x7a, 0xde, 0xd8, 0xe7, 0xd0, 0x28, 0x3d, 0xd6, 0xa2, 0xec, 0xeb,
+	0xef, 0x1d, 0xdc, 0xfd, 0x68, 0xcc, 0xde, 0xbe, 0xe3, 0xff, 0x7d, 0xd9, 0x41, 0xef, 0x2f, 0x3b,
+	0xe8, 0xc3, 0x65, 0x07, 0xfd, 0x71, 0xd5, 0xd9, 0x78, 0x7f, 0xd5, 0xd9, 0xf8, 0xf7, 0xaa, 0xb3,
+	0x31, 0xac, 0x19, 0x0d, 0xdf, 0xfd, 0x37, 0x00, 0x80, 0xa8, 0x79, 0x0c, 0x44, 0x08, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -686,6 +925,8 @@ type NodeClient interface {
 	CreateTasks(ctx context.Context, in *CreateTasksRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	PushData(ctx context.Context, opts ...grpc.CallOption) (Node_PushDataClient, error)
 	PollData(ctx context.Context, opts ...grpc.CallOption) (Node_PollDataClient, error)
+	Info(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*NodeInfo, error)
+	GetTaskLogs(ctx context.Context, in *GetTaskLogsRequest, opts ...grpc.CallOption) (*GetTaskLogsResponse, error)
 }
 
 type nodeClient struct {
@@ -770,11 +1011,31 @@ func (x *nodePollDataClient) Recv() (*PollDataResponse, error) {
 	return m, nil
 }
 
+func (c *nodeClient) Info(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*NodeInfo, error) {
+	out := new(NodeInfo)
+	err := c.cc.Invoke(ctx, "/lrmrpb.Node/Info", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetTaskLogs(ctx context.Context, in *GetTaskLogsRequest, opts ...grpc.CallOption) (*GetTaskLogsResponse, error) {
+	out := new(GetTaskLogsResponse)
+	err := c.cc.Invoke(ctx, "/lrmrpb.Node/GetTaskLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NodeServer is the server API for Node service.
 type NodeServer interface {
 	CreateTasks(context.Context, *CreateTasksRequest) (*empty.Empty, error)
 	PushData(Node_PushDataServer) error
 	PollData(Node_PollDataServer) error
+	Info(context.Context, *empty.Empty) (*NodeInfo, error)
+	GetTaskLogs(context.Context, *GetTaskLogsRequest) (*GetTaskLogsResponse, error)
 }
 
 // UnimplementedNodeServer can be embedded to have forward compatible implementations.
@@ -790,6 +1051,12 @@ func (*UnimplementedNodeServer) PushData(srv Node_PushDataServer) error {
 func (*UnimplementedNodeServer) PollData(srv Node_PollDataServer) error {
 	return status.Errorf(codes.Unimplemented, "method PollData not implemented")
 }
+func (*UnimplementedNodeServer) Info(ctx context.Context, req *empty.Empty) (*NodeInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Info not implemented")
+}
+func (*UnimplementedNodeServer) GetTaskLogs(ctx context.Context, req *GetTaskLogsRequest) (*GetTaskLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaskLogs not implemented")
+}
 
 func RegisterNodeServer(s *grpc.Server, srv NodeServer) {
 	s.RegisterService(&_Node_serviceDesc, srv)
@@ -865,6 +1132,42 @@ func (x *nodePollDataServer) Recv() (*PollDataRequest, error) {
 	return m, nil
 }
 
+func _Node_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lrmrpb.Node/Info",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).Info(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetTaskLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetTaskLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lrmrpb.Node/GetTaskLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetTaskLogs(ctx, req.(*GetTaskLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Node_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "lrmrpb.Node",
 	HandlerType: (*NodeServer)(nil),
@@ -873,6 +1176,14 @@ var _Node_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CreateTasks",
 			Handler:    _Node_CreateTasks_Handler,
 		},
+		{
+			MethodName: "Info",
+			Handler:    _Node_Info_Handler,
+		},
+		{
+			MethodName: "GetTaskLogs",
+			Handler:    _Node_GetTaskLogs_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -910,6 +1221,46 @@ func (m *CreateTasksRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Params) > 0 {
+		for k := range m.Params {
+			v := m.Params[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintRpc(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintRpc(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintRpc(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if len(m.SideInputs) > 0 {
+		for k := range m.SideInputs {
+			v := m.SideInputs[k]
+			baseI := i
+			if len(v) > 0 {
+				i -= len(v)
+				copy(dAtA[i:], v)
+				i = encodeVarintRpc(dAtA, i, uint64(len(v)))
+				i--
+				dAtA[i] = 0x12
+			}
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintRpc(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintRpc(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
 	if len(m.Broadcasts) > 0 {
 		for k := range m.Broadcasts {
 			v := m.Broadcasts[k]
@@ -1187,6 +1538,18 @@ func (m *PushDataRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Seq != 0 {
+		i = encodeVarintRpc(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.TaskID) > 0 {
+		i -= len(m.TaskID)
+		copy(dAtA[i:], m.TaskID)
+		i = encodeVarintRpc(dAtA, i, uint64(len(m.TaskID)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.Data) > 0 {
 		for iNdEx := len(m.Data) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -1299,6 +1662,32 @@ func (m *DataHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Resume {
+		i--
+		if m.Resume {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.TaskIDs) > 0 {
+		for iNdEx := len(m.TaskIDs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TaskIDs[iNdEx])
+			copy(dAtA[i:], m.TaskIDs[iNdEx])
+			i = encodeVarintRpc(dAtA, i, uint64(len(m.TaskIDs[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.FromPartitionID) > 0 {
+		i -= len(m.FromPartitionID)
+		copy(dAtA[i:], m.FromPartitionID)
+		i = encodeVarintRpc(dAtA, i, uint64(len(m.FromPartitionID)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.FromHost) > 0 {
 		i -= len(m.FromHost)
 		copy(dAtA[i:], m.FromHost)
@@ -1316,21 +1705,134 @@ func (m *DataHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintRpc(dAtA []byte, offset int, v uint64) int {
-	offset -= sovRpc(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *NodeInfo) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *CreateTasksRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *NodeInfo) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NodeInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Features) > 0 {
+		for iNdEx := len(m.Features) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Features[iNdEx])
+			copy(dAtA[i:], m.Features[iNdEx])
+			i = encodeVarintRpc(dAtA, i, uint64(len(m.Features[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if m.ProtocolVersion != 0 {
+		i = encodeVarintRpc(dAtA, i, uint64(m.ProtocolVersion))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.GitCommit) > 0 {
+		i -= len(m.GitCommit)
+		copy(dAtA[i:], m.GitCommit)
+		i = encodeVarintRpc(dAtA, i, uint64(len(m.GitCommit)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Version) > 0 {
+		i -= len(m.Version)
+		copy(dAtA[i:], m.Version)
+		i = encodeVarintRpc(dAtA, i, uint64(len(m.Version)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GetTaskLogsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetTaskLogsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetTaskLogsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.TaskID) > 0 {
+		i -= len(m.TaskID)
+		copy(dAtA[i:], m.TaskID)
+		i = encodeVarintRpc(dAtA, i, uint64(len(m.TaskID)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GetTaskLogsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetTaskLogsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetTaskLogsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Lines) > 0 {
+		for iNdEx := len(m.Lines) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Lines[iNdEx])
+			copy(dAtA[i:], m.Lines[iNdEx])
+			i = encodeVarintRpc(dAtA, i, uint64(len(m.Lines[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintRpc(dAtA []byte, offset int, v uint64) int {
+	offset -= sovRpc(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *CreateTasksRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
 	var l int
 	_ = l
 	if m.Job != nil {
@@ -1369,6 +1871,26 @@ func (m *CreateTasksRequest) Size() (n int) {
 			n += mapEntrySize + 1 + sovRpc(uint64(mapEntrySize))
 		}
 	}
+	if len(m.SideInputs) > 0 {
+		for k, v := range m.SideInputs {
+			_ = k
+			_ = v
+			l = 0
+			if len(v) > 0 {
+				l = 1 + len(v) + sovRpc(uint64(len(v)))
+			}
+			mapEntrySize := 1 + len(k) + sovRpc(uint64(len(k))) + l
+			n += mapEntrySize + 1 + sovRpc(uint64(mapEntrySize))
+		}
+	}
+	if len(m.Params) > 0 {
+		for k, v := range m.Params {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovRpc(uint64(len(k))) + 1 + len(v) + sovRpc(uint64(len(v)))
+			n += mapEntrySize + 1 + sovRpc(uint64(mapEntrySize))
+		}
+	}
 	return n
 }
 
@@ -1463,6 +1985,13 @@ func (m *PushDataRequest) Size() (n int) {
 			n += 1 + l + sovRpc(uint64(l))
 		}
 	}
+	l = len(m.TaskID)
+	if l > 0 {
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	if m.Seq != 0 {
+		n += 1 + sovRpc(uint64(m.Seq))
+	}
 	return n
 }
 
@@ -1510,6 +2039,73 @@ func (m *DataHeader) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRpc(uint64(l))
 	}
+	l = len(m.FromPartitionID)
+	if l > 0 {
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	if len(m.TaskIDs) > 0 {
+		for _, s := range m.TaskIDs {
+			l = len(s)
+			n += 1 + l + sovRpc(uint64(l))
+		}
+	}
+	if m.Resume {
+		n += 2
+	}
+	return n
+}
+
+func (m *NodeInfo) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Version)
+	if l > 0 {
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	l = len(m.GitCommit)
+	if l > 0 {
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	if m.ProtocolVersion != 0 {
+		n += 1 + sovRpc(uint64(m.ProtocolVersion))
+	}
+	if len(m.Features) > 0 {
+		for _, s := range m.Features {
+			l = len(s)
+			n += 1 + l + sovRpc(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *GetTaskLogsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TaskID)
+	if l > 0 {
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	return n
+}
+
+func (m *GetTaskLogsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Lines) > 0 {
+		for _, s := range m.Lines {
+			l = len(s)
+			n += 1 + l + sovRpc(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1846,64 +2442,11 @@ func (m *CreateTasksRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Broadcasts[mapkey] = mapvalue
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipRpc(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthRpc
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthRpc
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *Job) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowRpc
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: Job: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Job: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 7:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SideInputs", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRpc
@@ -1913,41 +2456,941 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthRpc
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthRpc
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Id = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			if m.SideInputs == nil {
+				m.SideInputs = make(map[string][]byte)
 			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowRpc
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
+			var mapkey string
+			mapvalue := []byte{}
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRpc
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRpc
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthRpc
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapbyteLen uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRpc
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapbyteLen |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intMapbyteLen := int(mapbyteLen)
+					if intMapbyteLen < 0 {
+						return ErrInvalidLengthRpc
+					}
+					postbytesIndex := iNdEx + intMapbyteLen
+					if postbytesIndex < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if postbytesIndex > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = make([]byte, mapbyteLen)
+					copy(mapvalue, dAtA[iNdEx:postbytesIndex])
+					iNdEx = postbytesIndex
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRpc(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.SideInputs[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Params", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Params == nil {
+				m.Params = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRpc
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRpc
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthRpc
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRpc
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthRpc
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRpc(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Params[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Job) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Job: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Job: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Input) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Input: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Input: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= Input_Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Output) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Output: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Output: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= Output_Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionToHost", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.PartitionToHost == nil {
+				m.PartitionToHost = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRpc
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRpc
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthRpc
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRpc
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthRpc
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRpc(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthRpc
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.PartitionToHost[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HostMapping) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HostMapping: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HostMapping: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Host", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Host = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TaskID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CreateTaskResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CreateTaskResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CreateTaskResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TaskID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushDataRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PushDataRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PushDataRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data, &lrdd.Row{})
+			if err := m.Data[len(m.Data)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
 			}
 			intStringLen := int(stringLen)
@@ -1961,8 +3404,27 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Name = string(dAtA[iNdEx:postIndex])
+			m.TaskID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRpc(dAtA[iNdEx:])
@@ -1987,7 +3449,7 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Input) Unmarshal(dAtA []byte) error {
+func (m *PollDataRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2010,17 +3472,17 @@ func (m *Input) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Input: wiretype end group for non-group")
+			return fmt.Errorf("proto: PollDataRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Input: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PollDataRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field N", wireType)
 			}
-			m.Type = 0
+			m.N = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRpc
@@ -2030,7 +3492,7 @@ func (m *Input) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= Input_Type(b&0x7F) << shift
+				m.N |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -2059,7 +3521,7 @@ func (m *Input) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Output) Unmarshal(dAtA []byte) error {
+func (m *PollDataResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2082,34 +3544,15 @@ func (m *Output) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Output: wiretype end group for non-group")
+			return fmt.Errorf("proto: PollDataResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Output: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PollDataResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
-			}
-			m.Type = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowRpc
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Type |= Output_Type(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field PartitionToHost", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2136,104 +3579,31 @@ func (m *Output) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.PartitionToHost == nil {
-				m.PartitionToHost = make(map[string]string)
+			m.Data = append(m.Data, &lrdd.Row{})
+			if err := m.Data[len(m.Data)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			var mapkey string
-			var mapvalue string
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowRpc
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsEOF", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
 				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					var stringLenmapkey uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowRpc
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapkey |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapkey := int(stringLenmapkey)
-					if intStringLenmapkey < 0 {
-						return ErrInvalidLengthRpc
-					}
-					postStringIndexmapkey := iNdEx + intStringLenmapkey
-					if postStringIndexmapkey < 0 {
-						return ErrInvalidLengthRpc
-					}
-					if postStringIndexmapkey > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
-					iNdEx = postStringIndexmapkey
-				} else if fieldNum == 2 {
-					var stringLenmapvalue uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowRpc
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapvalue |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapvalue := int(stringLenmapvalue)
-					if intStringLenmapvalue < 0 {
-						return ErrInvalidLengthRpc
-					}
-					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
-					if postStringIndexmapvalue < 0 {
-						return ErrInvalidLengthRpc
-					}
-					if postStringIndexmapvalue > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
-					iNdEx = postStringIndexmapvalue
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipRpc(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if skippy < 0 {
-						return ErrInvalidLengthRpc
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
 			}
-			m.PartitionToHost[mapkey] = mapvalue
-			iNdEx = postIndex
+			m.IsEOF = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRpc(dAtA[iNdEx:])
@@ -2258,7 +3628,7 @@ func (m *Output) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *HostMapping) Unmarshal(dAtA []byte) error {
+func (m *DataHeader) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2281,15 +3651,15 @@ func (m *HostMapping) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: HostMapping: wiretype end group for non-group")
+			return fmt.Errorf("proto: DataHeader: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: HostMapping: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DataHeader: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Host", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2317,11 +3687,75 @@ func (m *HostMapping) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Host = string(dAtA[iNdEx:postIndex])
+			m.TaskID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field FromHost", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FromHost = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FromPartitionID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FromPartitionID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TaskIDs", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2349,8 +3783,28 @@ func (m *HostMapping) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TaskID = string(dAtA[iNdEx:postIndex])
+			m.TaskIDs = append(m.TaskIDs, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resume", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Resume = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRpc(dAtA[iNdEx:])
@@ -2375,7 +3829,7 @@ func (m *HostMapping) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *CreateTaskResponse) Unmarshal(dAtA []byte) error {
+func (m *NodeInfo) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2398,15 +3852,15 @@ func (m *CreateTaskResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: CreateTaskResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: NodeInfo: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CreateTaskResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: NodeInfo: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2434,66 +3888,13 @@ func (m *CreateTaskResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TaskID = string(dAtA[iNdEx:postIndex])
+			m.Version = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipRpc(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthRpc
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthRpc
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *PushDataRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowRpc
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: PushDataRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushDataRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field GitCommit", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRpc
@@ -2503,84 +3904,48 @@ func (m *PushDataRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthRpc
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthRpc
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Data = append(m.Data, &lrdd.Row{})
-			if err := m.Data[len(m.Data)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.GitCommit = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipRpc(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthRpc
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthRpc
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *PollDataRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowRpc
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProtocolVersion", wireType)
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			m.ProtocolVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProtocolVersion |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: PollDataRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PollDataRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field N", wireType)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Features", wireType)
 			}
-			m.N = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRpc
@@ -2590,11 +3955,24 @@ func (m *PollDataRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.N |= int64(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Features = append(m.Features, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRpc(dAtA[iNdEx:])
@@ -2619,7 +3997,7 @@ func (m *PollDataRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PollDataResponse) Unmarshal(dAtA []byte) error {
+func (m *GetTaskLogsRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2642,17 +4020,17 @@ func (m *PollDataResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PollDataResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetTaskLogsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PollDataResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetTaskLogsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRpc
@@ -2662,46 +4040,24 @@ func (m *PollDataResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthRpc
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthRpc
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Data = append(m.Data, &lrdd.Row{})
-			if err := m.Data[len(m.Data)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.TaskID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IsEOF", wireType)
-			}
-			var v int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowRpc
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				v |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			m.IsEOF = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRpc(dAtA[iNdEx:])
@@ -2726,7 +4082,7 @@ func (m *PollDataResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *DataHeader) Unmarshal(dAtA []byte) error {
+func (m *GetTaskLogsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2749,47 +4105,15 @@ func (m *DataHeader) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DataHeader: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetTaskLogsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DataHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetTaskLogsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TaskID", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowRpc
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthRpc
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthRpc
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.TaskID = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field FromHost", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Lines", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2817,7 +4141,7 @@ func (m *DataHeader) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.FromHost = string(dAtA[iNdEx:postIndex])
+			m.Lines = append(m.Lines, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
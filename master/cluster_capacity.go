@@ -0,0 +1,36 @@
+package master
+
+import (
+	"context"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/pkg/errors"
+)
+
+// ClusterInfo is an aggregated capacity of the workers in the cluster.
+type ClusterInfo struct {
+	Workers int
+
+	// Cores is the total number of the executors of the workers.
+	Cores int
+
+	// Memory is the total amount of memory in bytes advertised by the workers.
+	// Workers not advertising their memory are not counted.
+	Memory uint64
+}
+
+// ClusterCapacity sums capacities of the live workers, which can be used to size partition counts of a job
+// before submitting it. Draining workers are not counted since no task is scheduled to them.
+func (m *Master) ClusterCapacity(ctx context.Context) (ClusterInfo, error) {
+	workers, err := m.Cluster.List(ctx, cluster.ListOption{Type: node.Worker})
+	if err != nil {
+		return ClusterInfo{}, errors.WithMessage(err, "list available workers")
+	}
+	info := ClusterInfo{Workers: len(workers)}
+	for _, w := range workers {
+		info.Cores += w.Executors
+		info.Memory += w.Memory
+	}
+	return info, nil
+}
@@ -1,9 +1,13 @@
 package master
 
 import (
+	"sort"
+	"strconv"
 	"sync"
 
 	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/job"
 	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/output"
 	"github.com/ab180/lrmr/partitions"
@@ -13,25 +17,103 @@ import (
 
 const CollectStageName = "_collect"
 
-// collectResultChans stores channel of CollectedResult to gather results from ongoing jobs.
-var collectResultChans sync.Map
+// collectedResults stores collectedResult to gather results from ongoing jobs.
+var collectedResults sync.Map
 
-func prepareCollect(jobID string) {
-	collectResultChans.Store(jobID, make(chan []*lrdd.Row, 1))
+// collectedResult gathers rows of each partition in the final stage of a job.
+type collectedResult struct {
+	partitionIDs []string
+	rows         map[string][]*lrdd.Row
+	mu           sync.Mutex
+	done         chan struct{}
+
+	// retrieved is a set of the partitions retrieved by Master.CollectedResultsOfPartition.
+	retrieved map[string]bool
+}
+
+func prepareCollect(j *job.Job) {
+	if j.GetStage(CollectStageName) == nil {
+		return
+	}
+	var partitionIDs []string
+	for _, a := range j.GetPartitionsOfStage(CollectStageName) {
+		partitionIDs = append(partitionIDs, a.PartitionID)
+	}
+	if j.OrderedCollect {
+		sort.SliceStable(partitionIDs, func(i, k int) bool {
+			return lessPartitionID(partitionIDs[i], partitionIDs[k])
+		})
+	}
+	collectedResults.Store(j.ID, &collectedResult{
+		partitionIDs: partitionIDs,
+		rows:         make(map[string][]*lrdd.Row, len(partitionIDs)),
+		done:         make(chan struct{}),
+		retrieved:    make(map[string]bool),
+	})
 }
 
-func getCollectedResultChan(jobID string) (chan []*lrdd.Row, error) {
-	v, ok := collectResultChans.Load(jobID)
+func getCollectedResult(jobID string) (*collectedResult, error) {
+	v, ok := collectedResults.Load(jobID)
 	if !ok {
 		return nil, errors.Errorf("unknown job: %s", jobID)
 	}
-	return v.(chan []*lrdd.Row), nil
+	return v.(*collectedResult), nil
+}
+
+// add stores rows of the partition. Rows of a partition added again (e.g. by a retried task)
+// replace the previous ones, without counting the partition twice.
+func (c *collectedResult) add(partitionID string, rows []*lrdd.Row) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, seen := c.rows[partitionID]
+	c.rows[partitionID] = rows
+	if !seen && len(c.rows) == len(c.partitionIDs) {
+		close(c.done)
+	}
+}
+
+// retrieve returns rows of the partition, and whether every partition has been retrieved.
+func (c *collectedResult) retrieve(partitionID string) (rows []*lrdd.Row, found, all bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rows, found = c.rows[partitionID]
+	if found {
+		c.retrieved[partitionID] = true
+	}
+	return rows, found, len(c.retrieved) == len(c.partitionIDs)
+}
+
+// all returns collected rows of every partitions, in the order of the partitions.
+func (c *collectedResult) all() (rows []*lrdd.Row) {
+	for _, id := range c.partitionIDs {
+		rows = append(rows, c.rows[id]...)
+	}
+	return rows
+}
+
+// lessPartitionID orders numeric partition IDs numerically before the others, which are ordered lexically.
+func lessPartitionID(a, b string) bool {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if an != bn {
+			return an < bn
+		}
+	case aErr == nil:
+		return true
+	case bErr == nil:
+		return false
+	}
+	return a < b
 }
 
 type Collector struct{}
 
 func (c *Collector) Apply(ctx transformation.Context, in chan *lrdd.Row, _ output.Output) error {
-	resultChan, err := getCollectedResultChan(ctx.JobID())
+	result, err := getCollectedResult(ctx.JobID())
 	if err != nil {
 		return errors.Errorf("unknown job: %s", ctx.JobID())
 	}
@@ -39,11 +121,14 @@ func (c *Collector) Apply(ctx transformation.Context, in chan *lrdd.Row, _ outpu
 	for row := range in {
 		rows = append(rows, row)
 	}
-	resultChan <- rows
-	collectResultChans.Delete(ctx.JobID())
+	result.add(ctx.PartitionID(), rows)
 	return nil
 }
 
+// CollectPartitioner sends rows to the master, keeping partitions of the final stage
+// so that the results can be collected by each partition.
+var _ = serialization.Register(&CollectPartitioner{})
+
 type CollectPartitioner struct{}
 
 func NewCollectPartitioner() partitions.Partitioner {
@@ -52,15 +137,27 @@ func NewCollectPartitioner() partitions.Partitioner {
 
 // PlanNext assigns partition to master.
 func (c CollectPartitioner) PlanNext(numExecutors int) []partitions.Partition {
-	assignToMaster := map[string]string{
-		"Type": string(node.Master),
-	}
 	return []partitions.Partition{
-		{ID: "_collect", AssignmentAffinity: assignToMaster},
+		{ID: "_collect", AssignmentAffinity: assignToMaster()},
 	}
 }
 
-// DeterminePartition always partitions data to "_collect" partition.
-func (c CollectPartitioner) DeterminePartition(partitions.Context, *lrdd.Row, int) (id string, err error) {
-	return "_collect", nil
+// PlanNextFrom assigns partitions with same IDs of the final stage to master.
+func (c CollectPartitioner) PlanNextFrom(current []partitions.Partition) []partitions.Partition {
+	pp := make([]partitions.Partition, len(current))
+	for i, p := range current {
+		pp[i] = partitions.Partition{ID: p.ID, AssignmentAffinity: assignToMaster()}
+	}
+	return pp
+}
+
+// DeterminePartition partitions data to the partition with the same ID of current partition.
+func (c CollectPartitioner) DeterminePartition(ctx partitions.Context, _ *lrdd.Row, _ int) (id string, err error) {
+	return ctx.PartitionID(), nil
+}
+
+func assignToMaster() map[string]string {
+	return map[string]string{
+		"Type": string(node.Master),
+	}
 }
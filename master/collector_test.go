@@ -0,0 +1,73 @@
+package master
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLessPartitionID(t *testing.T) {
+	Convey("Sorting partition IDs", t, func() {
+		ids := []string{"b", "10", "2", "_collect", "0", "a", "1"}
+		sort.SliceStable(ids, func(i, k int) bool { return lessPartitionID(ids[i], ids[k]) })
+
+		Convey("Numeric IDs should be ordered numerically before the others", func() {
+			So(ids, ShouldResemble, []string{"0", "1", "2", "10", "_collect", "a", "b"})
+		})
+	})
+}
+
+func TestCollectedResult(t *testing.T) {
+	Convey("Given a collected result of partitions", t, func() {
+		c := &collectedResult{
+			partitionIDs: []string{"0", "1"},
+			rows:         make(map[string][]*lrdd.Row),
+			done:         make(chan struct{}),
+			retrieved:    make(map[string]bool),
+		}
+
+		Convey("When a partition is added twice", func() {
+			c.add("0", []*lrdd.Row{{Key: "a"}})
+			c.add("0", []*lrdd.Row{{Key: "b"}})
+
+			Convey("It should not be counted twice", func() {
+				select {
+				case <-c.done:
+					So("completed", ShouldBeEmpty)
+				default:
+				}
+				c.add("1", nil)
+				select {
+				case <-c.done:
+				default:
+					So("not completed", ShouldBeEmpty)
+				}
+				So(c.all(), ShouldResemble, []*lrdd.Row{{Key: "b"}})
+			})
+		})
+
+		Convey("When the partitions are retrieved", func() {
+			c.add("0", []*lrdd.Row{{Key: "a"}})
+			c.add("1", []*lrdd.Row{{Key: "b"}})
+
+			Convey("It should tell whether every partition has been retrieved", func() {
+				rows, found, all := c.retrieve("0")
+				So(rows, ShouldResemble, []*lrdd.Row{{Key: "a"}})
+				So(found, ShouldBeTrue)
+				So(all, ShouldBeFalse)
+
+				_, _, all = c.retrieve("0")
+				So(all, ShouldBeFalse)
+
+				_, found, all = c.retrieve("2")
+				So(found, ShouldBeFalse)
+				So(all, ShouldBeFalse)
+
+				_, _, all = c.retrieve("1")
+				So(all, ShouldBeTrue)
+			})
+		})
+	})
+}
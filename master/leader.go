@@ -0,0 +1,119 @@
+package master
+
+import (
+	"context"
+	"time"
+
+	"github.com/ab180/lrmr/job"
+)
+
+// electionName is a name of the leader election among the masters.
+const electionName = "master"
+
+// LeaderElectionOptions configures election of the leader among the masters sharing the coordinator.
+// Only the leader tracks the jobs, and standby masters take over tracking the jobs in flight when the leader dies.
+type LeaderElectionOptions struct {
+	Enabled bool
+
+	// TTL is a lifetime of the leadership without keep-alive. A standby master is elected after the TTL
+	// if the leader dies without resigning.
+	TTL time.Duration `default:"10s"`
+
+	// RetryInterval is a delay before joining the election again after an error.
+	RetryInterval time.Duration `default:"1s"`
+}
+
+// IsLeader returns true if the master is the leader tracking the jobs.
+// Without leader election, every master tracks its own jobs and is regarded as a leader.
+func (m *Master) IsLeader() bool {
+	if !m.opt.LeaderElection.Enabled {
+		return true
+	}
+	return m.isLeader.Load()
+}
+
+// runElection campaigns for the leadership until the context is cancelled. While the master is
+// the leader, it tracks the jobs in flight recovered from the coordinator and the jobs created afterwards.
+func (m *Master) runElection(ctx context.Context) {
+	host := m.executor.Node.Info().Host
+	for ctx.Err() == nil {
+		if err := m.lead(ctx, host); err != nil && ctx.Err() == nil {
+			log.Warn("Leader election of master {} failed, will try again: {}", host, err)
+			select {
+			case <-time.After(m.opt.LeaderElection.RetryInterval):
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// lead campaigns for the leadership and tracks jobs until the leadership is lost or the context is cancelled.
+func (m *Master) lead(ctx context.Context, host string) error {
+	election, err := m.crd.NewElection(ctx, electionName, m.opt.LeaderElection.TTL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		m.demote()
+		if err := election.Close(); err != nil {
+			log.Warn("Failed to resign leadership of master {}: {}", host, err)
+		}
+	}()
+	if err := election.Campaign(ctx, host); err != nil {
+		return err
+	}
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// watch before recovering jobs, so that jobs created in between are not missed
+	createdJobs := m.JobManager.WatchCreatedJobs(leaderCtx)
+	m.isLeader.Store(true)
+	log.Info("Master {} is elected as a leader.", host)
+
+	if err := m.recoverJobs(leaderCtx); err != nil {
+		return err
+	}
+	for {
+		select {
+		case j, ok := <-createdJobs:
+			if !ok {
+				return ctx.Err()
+			}
+			m.trackJob(j)
+		case <-election.Done():
+			log.Warn("Master {} lost its leadership.", host)
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// demote stops the master being the leader, leaving tracking of the jobs to the new leader.
+func (m *Master) demote() {
+	m.trackMu.Lock()
+	m.isLeader.Store(false)
+	m.trackMu.Unlock()
+	m.stopTracking()
+}
+
+// recoverJobs tracks jobs which have not been completed yet.
+func (m *Master) recoverJobs(ctx context.Context) error {
+	jobs, err := m.JobManager.ListJobs(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		status, err := m.JobManager.GetJobStatus(ctx, j.ID)
+		if err != nil {
+			log.Warn("Failed to read status of job {} to recover: {}", j.ID, err)
+			continue
+		}
+		if status.Status == job.Succeeded || status.Status == job.Failed {
+			continue
+		}
+		log.Verbose("Recovered job {} in flight.", j.ID)
+		m.trackJob(j)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package master
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/stage"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMaster_LeaderElection(t *testing.T) {
+	Convey("Given masters with leader election", t, func() {
+		ctx := context.Background()
+		pushedJobs := make(chan string, 10)
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pushedJobs <- r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		}))
+		defer gateway.Close()
+
+		crd := coordinator.NewLocalMemory()
+		newMaster := func() *Master {
+			opt := DefaultOptions()
+			opt.ListenHost = "127.0.0.1:"
+			opt.AdvertisedHost = "127.0.0.1:"
+			opt.LeaderElection.Enabled = true
+			opt.Pushgateway.URL = gateway.URL
+			m, err := New(crd, opt)
+			So(err, ShouldBeNil)
+			m.Start()
+			return m
+		}
+		leader := newMaster()
+		waitForLeadership(leader)
+
+		standby := newMaster()
+		defer standby.Stop()
+		time.Sleep(100 * time.Millisecond)
+
+		So(leader.IsLeader(), ShouldBeTrue)
+		So(standby.IsLeader(), ShouldBeFalse)
+
+		Convey("When the leader dies in the middle of a job", func() {
+			stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+			j, err := standby.JobManager.CreateJob(ctx, "ha-test", stages, nil)
+			So(err, ShouldBeNil)
+
+			leader.Stop()
+			waitForLeadership(standby)
+
+			Convey("The standby should take over tracking of the job", func() {
+				js, err := standby.JobManager.GetJobStatus(ctx, j.ID)
+				So(err, ShouldBeNil)
+				js.Complete(job.Succeeded)
+				So(standby.JobManager.SetJobStatus(ctx, j.ID, js), ShouldBeNil)
+
+				var pushedJobID string
+				select {
+				case pushedJobID = <-pushedJobs:
+				case <-time.After(3 * time.Second):
+				}
+				So(pushedJobID, ShouldEqual, j.ID)
+			})
+		})
+	})
+}
+
+func TestMaster_Demote(t *testing.T) {
+	Convey("Given a leader tracking a job", t, func() {
+		ctx := context.Background()
+		pushedJobs := make(chan string, 10)
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pushedJobs <- r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		}))
+		defer gateway.Close()
+
+		crd := coordinator.NewLocalMemory()
+		jm := job.NewManager(crd)
+		tracker := job.NewJobTracker(crd, jm)
+		defer tracker.Close()
+
+		m := &Master{JobManager: jm, JobTracker: tracker}
+		m.opt.LeaderElection.Enabled = true
+		m.opt.Pushgateway = PushgatewayOptions{URL: gateway.URL, Timeout: time.Second}
+		m.isLeader.Store(true)
+
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+		j, err := jm.CreateJob(ctx, "demote-test", stages, nil)
+		So(err, ShouldBeNil)
+		m.trackJob(j)
+
+		Convey("When the leader is demoted", func() {
+			m.demote()
+
+			Convey("It should stop tracking the job", func() {
+				js, err := jm.GetJobStatus(ctx, j.ID)
+				So(err, ShouldBeNil)
+				js.Complete(job.Succeeded)
+				So(jm.SetJobStatus(ctx, j.ID, js), ShouldBeNil)
+
+				select {
+				case jobID := <-pushedJobs:
+					So(jobID, ShouldBeEmpty)
+				case <-time.After(500 * time.Millisecond):
+				}
+			})
+
+			Convey("It should not track jobs reattached afterwards", func() {
+				_, err := m.Reattach(ctx, j.ID)
+				So(err, ShouldBeNil)
+				_, tracked := m.trackedJobs.Load(j.ID)
+				So(tracked, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func waitForLeadership(m *Master) {
+	deadline := time.Now().Add(3 * time.Second)
+	for !m.IsLeader() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	So(m.IsLeader(), ShouldBeTrue)
+}
@@ -3,7 +3,9 @@ package master
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"path"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,7 +22,10 @@ import (
 	"github.com/ab180/lrmr/worker"
 	"github.com/airbloc/logger"
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var ErrNoAvailableWorkers = errors.New("no available workers")
@@ -35,11 +40,25 @@ type Master struct {
 	JobManager *job.Manager
 	JobTracker *job.Tracker
 
-	opt Options
+	crd          coordinator.Coordinator
+	statusServer *http.Server
+	opt          Options
+
+	// trackedJobs are trackings of the jobs by the master, keyed by job ID.
+	trackedJobs sync.Map
+	trackMu     sync.Mutex
+
+	// rebalancers are functions rescheduling the running jobs on drain, keyed by job ID (see OnRebalance).
+	rebalancers sync.Map
+
+	isLeader     atomic.Bool
+	stopElection context.CancelFunc
+
+	stopCompaction context.CancelFunc
 }
 
 func New(crd coordinator.Coordinator, opt Options) (*Master, error) {
-	c, err := cluster.OpenRemote(crd, cluster.DefaultOptions())
+	c, err := cluster.OpenRemote(crd, opt.RPC)
 	if err != nil {
 		return nil, err
 	}
@@ -51,18 +70,24 @@ func New(crd coordinator.Coordinator, opt Options) (*Master, error) {
 	wopt.AdvertisedHost = opt.AdvertisedHost
 	wopt.Input.MaxRecvSize = opt.Input.MaxRecvSize
 	wopt.Output.BufferLength = opt.Output.BufferLength
+	wopt.Output.BufferBytes = opt.Output.BufferBytes
 	wopt.Output.MaxSendMsgSize = opt.Output.MaxSendMsgSize
+	wopt.Output.MaxConcurrentConnects = opt.Output.MaxConcurrentConnects
+	wopt.Output.Reconnect = opt.Output.Reconnect
+	wopt.Input.ResumeTimeout = opt.Input.ResumeTimeout
+	wopt.RPC = opt.RPC
 	w, err := worker.New(crd, wopt)
 	if err != nil {
 		return nil, errors.Wrap(err, "init master task executor")
 	}
 
-	jm := job.NewManager(crd)
+	jm := job.NewManager(crd, job.WithIDGenerator(opt.IDGenerator))
 	return &Master{
 		executor:   w,
 		Cluster:    c,
 		JobManager: jm,
 		JobTracker: job.NewJobTracker(crd, jm),
+		crd:        crd,
 		opt:        opt,
 	}, nil
 }
@@ -73,6 +98,56 @@ func (m *Master) Start() {
 			log.Error("Failed to start master task executor", err)
 		}
 	}()
+	if m.opt.StatusServerHost != "" {
+		m.statusServer = &http.Server{
+			Addr:    m.opt.StatusServerHost,
+			Handler: &statusServer{jobManager: m.JobManager},
+		}
+		go func() {
+			if err := m.statusServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error("Failed to start status server", err)
+			}
+		}()
+	}
+	if m.opt.LeaderElection.Enabled {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.stopElection = cancel
+		go m.runElection(ctx)
+	}
+	if m.opt.TaskStatusCompaction.Retention > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.stopCompaction = cancel
+		go m.runTaskStatusCompaction(ctx)
+	}
+}
+
+// runTaskStatusCompaction periodically compacts task statuses of the jobs completed before the retention.
+// With leader election, only the leader compacts them.
+func (m *Master) runTaskStatusCompaction(ctx context.Context) {
+	opt := m.opt.TaskStatusCompaction
+	ticker := time.NewTicker(opt.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if m.opt.LeaderElection.Enabled && !m.isLeader.Load() {
+				continue
+			}
+			n, err := m.JobManager.CompactCompletedJobs(ctx, opt.Retention)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Warn("Failed to compact task statuses: {}", err)
+			}
+			if n > 0 {
+				log.Verbose("Compacted task statuses of {} jobs", n)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
 func (m *Master) Workers() ([]WorkerHolder, error) {
@@ -90,6 +165,69 @@ func (m *Master) Workers() ([]WorkerHolder, error) {
 	return wh, nil
 }
 
+// Drain stops scheduling new tasks to the node with given host, which is about to be decommissioned.
+// Running jobs with tasks on the node are rescheduled with the functions registered by OnRebalance:
+// every task of the job, including the ones running or waiting for slots on the node, is canceled
+// and the job runs again on the other nodes. Jobs without the function are left to finish on the node.
+// It returns after the jobs are rescheduled. The node is no longer drained once it unregisters
+// or registers again, or after cluster.Options.DrainTTL.
+func (m *Master) Drain(ctx context.Context, host string) error {
+	if err := m.Cluster.Drain(ctx, host); err != nil {
+		return err
+	}
+	var wg errgroup.Group
+	m.rebalancers.Range(func(_, v interface{}) bool {
+		rb := v.(*rebalancer)
+		if !hasPartitionsOn(rb.job, host) {
+			return true
+		}
+		wg.Go(func() error {
+			log.Info("Rescheduling job {} since its node {} is drained.", rb.job.ID, host)
+			return errors.WithMessagef(rb.reschedule(ctx), "reschedule job %s", rb.job.ID)
+		})
+		return true
+	})
+	return wg.Wait()
+}
+
+// OnRebalance registers a function rescheduling the job on the other nodes when a node running its tasks
+// is drained. The function is expected to abort the job with job.CancelledByRebalance before running it again.
+// It is unregistered after the job completes.
+func (m *Master) OnRebalance(j *job.Job, reschedule func(ctx context.Context) error) {
+	m.rebalancers.Store(j.ID, &rebalancer{job: j, reschedule: reschedule})
+	m.JobTracker.OnJobCompletion(j, func(j *job.Job, _ *job.Status) {
+		m.rebalancers.Delete(j.ID)
+	})
+}
+
+type rebalancer struct {
+	job        *job.Job
+	reschedule func(ctx context.Context) error
+}
+
+// hasPartitionsOn returns true if any partition of the job is assigned to the host.
+func hasPartitionsOn(j *job.Job, host string) bool {
+	for _, assignments := range j.Partitions {
+		for _, a := range assignments {
+			if a.Host == host {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PauseJob pauses the running job. Tasks of the job stop reading their inputs and writing their outputs,
+// without losing rows in flight, until the job is resumed with ResumeJob.
+func (m *Master) PauseJob(ctx context.Context, jobID string) error {
+	return m.JobManager.PauseJob(ctx, jobID)
+}
+
+// ResumeJob resumes the job paused by PauseJob.
+func (m *Master) ResumeJob(ctx context.Context, jobID string) error {
+	return m.JobManager.ResumeJob(ctx, jobID)
+}
+
 func (m *Master) CreateJob(ctx context.Context, name string, plans []partitions.Plan, stages []stage.Stage, opt ...CreateJobOption) (*job.Job, error) {
 	opts := buildCreateJobOptions(opt)
 
@@ -104,8 +242,23 @@ func (m *Master) CreateJob(ctx context.Context, name string, plans []partitions.
 	if len(workers) == 0 {
 		return nil, ErrNoAvailableWorkers
 	}
+	if len(opts.RequiredFeatures) > 0 {
+		if err := m.checkRequiredFeatures(ctx, workers, opts.RequiredFeatures); err != nil {
+			return nil, err
+		}
+	}
 
-	pp, assignments := partitions.Schedule(workers, plans, partitions.WithMaster(m.executor.Node.Info()))
+	scheduleOpts := []partitions.ScheduleOption{partitions.WithMaster(m.executor.Node.Info())}
+	if opts.DeterministicLayout {
+		sort.Slice(workers, func(i, j int) bool { return workers[i].Host < workers[j].Host })
+		scheduleOpts = append(scheduleOpts, partitions.WithoutShufflingNodes())
+	}
+	pp, assignments := partitions.Schedule(workers, plans, scheduleOpts...)
+	if opts.PinnedLayout != nil {
+		if err := partitions.Pin(assignments, opts.PinnedLayout, workers); err != nil {
+			return nil, errors.WithMessage(err, "pin partitions")
+		}
+	}
 	for i, p := range pp {
 		stages[i].Output.Partitioner = p.Partitioner
 
@@ -113,78 +266,400 @@ func (m *Master) CreateJob(ctx context.Context, name string, plans []partitions.
 		log.Verbose("Planned {} partitions on {}/{} (output with {}):\n{}", len(p.Partitions),
 			name, stages[i].Name, partitionerName, assignments[i].Pretty())
 	}
+	for i := 0; i+1 < len(pp); i++ {
+		if err := partitions.CheckNext(pp[i], pp[i+1]); err != nil {
+			return nil, errors.WithMessagef(err, "plan stage %s to %s", stages[i].Name, stages[i+1].Name)
+		}
+	}
 
-	j, err := m.JobManager.CreateJob(ctx, name, stages, assignments)
+	jobOpts := []job.Option{job.WithTaskTimeout(opts.TaskTimeout)}
+	if m.opt.SkewDetection.Factor > 0 {
+		jobOpts = append(jobOpts, job.WithSkewDetection(m.opt.SkewDetection))
+	}
+	if m.opt.RoutingInCoordinator {
+		jobOpts = append(jobOpts, job.WithRoutingInCoordinator())
+	}
+	if opts.PersistOutput {
+		jobOpts = append(jobOpts, job.WithPersistedOutput())
+	}
+	if opts.PersistCompression != job.NoCompression {
+		jobOpts = append(jobOpts, job.WithPersistCompression(opts.PersistCompression))
+	}
+	if opts.InputJobID != "" {
+		jobOpts = append(jobOpts, job.WithInputFromJob(opts.InputJobID))
+	}
+	if opts.CollectAllErrors {
+		jobOpts = append(jobOpts, job.WithCollectAllErrors())
+	}
+	if opts.Provenance {
+		jobOpts = append(jobOpts, job.WithProvenance())
+	}
+	if opts.SkipEmptyPartitions {
+		jobOpts = append(jobOpts, job.WithSkipEmptyPartitions())
+	}
+	if opts.Weight > 0 {
+		jobOpts = append(jobOpts, job.WithWeight(opts.Weight))
+	}
+	if opts.ReassignUnreachable {
+		jobOpts = append(jobOpts, job.WithReassignUnreachable())
+	}
+	if opts.OrderedCollect {
+		jobOpts = append(jobOpts, job.WithOrderedCollect())
+	}
+	if len(opts.Metadata) > 0 {
+		jobOpts = append(jobOpts, job.WithMetadata(opts.Metadata))
+	}
+	if opts.IDGenerator != nil {
+		jobOpts = append(jobOpts, job.WithID(opts.IDGenerator.GenerateID("J")))
+	}
+	j, err := m.JobManager.CreateJob(ctx, name, stages, assignments, jobOpts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "create job")
 	}
+	// if the master is not the leader, the leader would find the job and track it
+	m.trackJob(j)
+	return j, nil
+}
+
+// trackJob logs progress of the job, and pushes its metrics on completion if the Pushgateway is configured.
+// Metrics are pushed in the background, so that a slow Pushgateway would not delay other callbacks of the job.
+// Only the leader tracks jobs, and the tracking stops when the master is demoted.
+func (m *Master) trackJob(j *job.Job) {
+	t := m.startTracking(j)
+	if t == nil {
+		return
+	}
 	m.JobTracker.OnTaskCompletion(j, func(j *job.Job, stageName string, doneCountInStage int) {
+		if !t.active.Load() {
+			return
+		}
 		totalTasks := len(j.GetPartitionsOfStage(stageName))
 		log.Verbose("Task ({}/{}) finished of {}/{}", doneCountInStage, totalTasks, j.ID, stageName)
 	})
 	m.JobTracker.OnStageCompletion(j, func(j *job.Job, stageName string, stageStatus *job.StageStatus) {
+		if !t.active.Load() {
+			return
+		}
 		log.Verbose("Stage {}/{} {}.", j.ID, stageName, stageStatus.Status)
 	})
 	m.JobTracker.OnJobCompletion(j, func(j *job.Job, status *job.Status) {
+		if !t.active.CAS(true, false) {
+			return
+		}
+		m.trackedJobs.Delete(j.ID)
+		if m.opt.Pushgateway.URL != "" {
+			go m.pushMetricsOf(j, status)
+		}
 		log.Info("Job {} {}. Total elapsed {}", j.ID, status.Status, time.Since(j.SubmittedAt))
 		for i, errDesc := range status.Errors {
 			log.Info(" - Error #{} caused by {}: {}", i, errDesc.Task, errDesc.Message)
 		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := m.JobManager.ClearJobPause(ctx, j.ID); err != nil {
+			log.Warn("Failed to clear pause of job {}: {}", j.ID, err)
+		}
+	})
+}
+
+// jobTracking is a tracking of a job by the master. Callbacks of an inactive tracking do nothing,
+// since callbacks registered to the job tracker cannot be removed.
+type jobTracking struct {
+	active atomic.Bool
+}
+
+// startTracking returns a new tracking of the job, or nil if the master is not the leader or already tracks the job.
+func (m *Master) startTracking(j *job.Job) *jobTracking {
+	m.trackMu.Lock()
+	defer m.trackMu.Unlock()
+
+	if !m.IsLeader() {
+		return nil
+	}
+	t := &jobTracking{}
+	t.active.Store(true)
+	if _, tracked := m.trackedJobs.LoadOrStore(j.ID, t); tracked {
+		return nil
+	}
+	return t
+}
+
+// stopTracking stops tracking every job, e.g. after the master is demoted. The new leader takes them over.
+func (m *Master) stopTracking() {
+	m.trackMu.Lock()
+	defer m.trackMu.Unlock()
+
+	m.trackedJobs.Range(func(jobID, t interface{}) bool {
+		t.(*jobTracking).active.Store(false)
+		m.trackedJobs.Delete(jobID)
+		return true
 	})
+}
+
+// Reattach reads the job from the coordinator and tracks it, so that a new driver can wait for the job submitted
+// by another driver (e.g. before it crashed). The job is tracked only if the master is the leader.
+func (m *Master) Reattach(ctx context.Context, jobID string) (*job.Job, error) {
+	j, err := m.JobManager.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "get job %s", jobID)
+	}
+	m.JobTracker.AddJob(j)
+	m.trackJob(j)
 	return j, nil
 }
 
-// StartTasks create tasks to the nodes with the plan.
-func (m *Master) StartJob(ctx context.Context, j *job.Job, broadcasts map[string][]byte) error {
-	prepareCollect(j.ID)
+// StartTasks create tasks to the nodes with the plan. Each stage receives only the side inputs it declares.
+// If the job is created with WithReassignUnreachable, partitions on unreachable hosts are reassigned to other hosts.
+func (m *Master) StartJob(ctx context.Context, j *job.Job, broadcasts, sideInputs map[string][]byte, params map[string]string, opts ...StartJobOption) error {
+	opt := buildStartJobOptions(opts)
+	prepareCollect(j)
 	marshalledJob := pbtypes.MustMarshalJSON(j)
+	unreachable := make(map[string]bool)
+	progress := newSubmissionProgress(j, opt.SubmissionProgress)
+	ramp := newTaskRamp(m.opt.SlowStart.InitialTasks)
 
 	// initialize tasks reversely, so that outputs can be connected with next stage
 	for i := len(j.Stages) - 1; i >= 1; i-- {
 		s := j.Stages[i]
-		reqTmpl := lrmrpb.CreateTasksRequest{
-			Job:   marshalledJob,
-			Stage: s.Name,
-			Input: []*lrmrpb.Input{
-				{Type: lrmrpb.Input_PUSH},
-			},
-			Output: &lrmrpb.Output{
-				Type: lrmrpb.Output_PUSH,
-			},
-			Broadcasts: broadcasts,
-		}
-		if i < len(j.Stages)-1 {
-			reqTmpl.Output.PartitionToHost = j.Partitions[i+1].ToMap()
-		} else {
-			reqTmpl.Output.PartitionToHost = make(map[string]string, 0)
+		reqTmpl, err := m.createTasksRequestOf(ctx, j, i, marshalledJob, broadcasts, sideInputs, params)
+		if err != nil {
+			return err
 		}
 
 		t := log.Timer()
-		wg, wctx := errgroup.WithContext(ctx)
-		for h, ps := range j.Partitions[i].GroupIDsByHost() {
-			host, partitionIDs := h, ps
-
-			wg.Go(func() error {
-				conn, err := m.Cluster.Connect(wctx, host)
+		idsByHost := j.Partitions[i].GroupIDsByHost()
+		reassigned := false
+		for {
+			if j.ReassignUnreachable && len(unreachable) > 0 {
+				moved, err := reassignPartitions(j, i, unreachable)
 				if err != nil {
-					return errors.Wrapf(err, "dial %s for stage %s", host, s.Name)
+					return errors.WithMessagef(err, "reassign partitions of stage %s", s.Name)
 				}
-				req := reqTmpl
-				req.PartitionIDs = partitionIDs
-				if _, err := lrmrpb.NewNodeClient(conn).CreateTasks(wctx, &req); err != nil {
-					return errors.Wrapf(err, "call CreateTask on %s", host)
+				if len(moved) > 0 {
+					reassigned = true
+					for host := range unreachable {
+						delete(idsByHost, host)
+					}
+					for host, ids := range moved {
+						idsByHost[host] = append(idsByHost[host], ids...)
+					}
 				}
-				return nil
-			})
+			}
+			failedHosts, err := m.createTasksGradually(ctx, reqTmpl, s.Name, idsByHost, j.ReassignUnreachable, progress, ramp)
+			if err != nil {
+				return err
+			}
+			if len(failedHosts) == 0 {
+				break
+			}
+			// retry only the partitions of the unreachable hosts, which are reassigned on the next round
+			idsByHost = make(map[string][]string)
+			for _, host := range failedHosts {
+				log.Warn("Host {} is unreachable. Reassigning its partitions of {}/{}.", host, j.ID, s.Name)
+				unreachable[host] = true
+			}
 		}
-		if err := wg.Wait(); err != nil {
-			return err
+		if reassigned {
+			if err := m.JobManager.UpdatePartitions(ctx, j); err != nil {
+				return errors.WithMessage(err, "update reassigned partitions")
+			}
+			marshalledJob = pbtypes.MustMarshalJSON(j)
 		}
 		t.End("Initialized stage {}/{}", j.ID, s.Name)
 	}
 	return nil
 }
 
+// createTasksRequestOf returns a template of the requests creating tasks of the stage, without partition IDs.
+func (m *Master) createTasksRequestOf(ctx context.Context, j *job.Job, stageIdx int, marshalledJob *pbtypes.JSON, broadcasts, sideInputs map[string][]byte, params map[string]string) (lrmrpb.CreateTasksRequest, error) {
+	s := j.Stages[stageIdx]
+	req := lrmrpb.CreateTasksRequest{
+		Job:   jobOfStage(j, stageIdx, marshalledJob),
+		Stage: s.Name,
+		Input: []*lrmrpb.Input{
+			{Type: lrmrpb.Input_PUSH},
+		},
+		Output: &lrmrpb.Output{
+			Type: lrmrpb.Output_PUSH,
+		},
+		Broadcasts: broadcasts,
+		Params:     params,
+	}
+	if len(s.SideInputs) > 0 {
+		req.SideInputs = make(map[string][]byte, len(s.SideInputs))
+		for _, name := range s.SideInputs {
+			req.SideInputs[name] = sideInputs[name]
+		}
+	}
+	routing, err := m.outputRoutingOf(ctx, j, stageIdx)
+	if err != nil {
+		return req, errors.WithMessagef(err, "route output of stage %s", s.Name)
+	}
+	req.Output.PartitionToHost = routing
+	return req, nil
+}
+
+// jobOfStage returns the job sent with the requests creating tasks of the stage. If the routing is stored in
+// the coordinator, assignments of the other stages are left out, since the tasks only need the ones of their stage.
+func jobOfStage(j *job.Job, stageIdx int, marshalledJob *pbtypes.JSON) *pbtypes.JSON {
+	if !j.RoutingInCoordinator {
+		return marshalledJob
+	}
+	stageJob := *j
+	stageJob.Partitions = make([]partitions.Assignments, len(j.Partitions))
+	stageJob.Partitions[stageIdx] = j.Partitions[stageIdx]
+	return pbtypes.MustMarshalJSON(&stageJob)
+}
+
+// outputRoutingOf returns hosts of the partitions which the tasks of the stage output to, sent with the requests
+// creating the tasks. If the job reads the routing from the coordinator, it is stored there and an empty routing
+// is returned instead. Since the partitions of the next stage are created earlier, the routing is final here.
+func (m *Master) outputRoutingOf(ctx context.Context, j *job.Job, stageIdx int) (map[string]string, error) {
+	if stageIdx == len(j.Stages)-1 {
+		return make(map[string]string, 0), nil
+	}
+	next := j.Partitions[stageIdx+1]
+	if !j.RoutingInCoordinator {
+		return next.ToMap(), nil
+	}
+	if err := m.JobManager.SaveRouting(ctx, j.ID, j.Stages[stageIdx+1].Name, next); err != nil {
+		return nil, err
+	}
+	return make(map[string]string, 0), nil
+}
+
+// createTasksGradually runs createTasks in the rounds split by the ramp. Hosts found unreachable
+// in a round are skipped in the following rounds.
+func (m *Master) createTasksGradually(ctx context.Context, reqTmpl lrmrpb.CreateTasksRequest, stageName string, idsByHost map[string][]string, tolerateUnreachable bool, progress *submissionProgress, ramp *taskRamp) (unreachable []string, err error) {
+	failed := make(map[string]bool)
+	for _, round := range ramp.split(idsByHost) {
+		for host := range failed {
+			delete(round, host)
+		}
+		hosts, err := m.createTasks(ctx, reqTmpl, stageName, round, tolerateUnreachable, progress)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			failed[host] = true
+			unreachable = append(unreachable, host)
+		}
+	}
+	return unreachable, nil
+}
+
+// createTasks creates tasks of the partitions on each host. If tolerateUnreachable is set,
+// hosts which can't be reached are returned instead of failing.
+func (m *Master) createTasks(ctx context.Context, reqTmpl lrmrpb.CreateTasksRequest, stageName string, idsByHost map[string][]string, tolerateUnreachable bool, progress *submissionProgress) (unreachable []string, err error) {
+	var mu sync.Mutex
+	wg, wctx := errgroup.WithContext(ctx)
+	for h, ps := range idsByHost {
+		host, partitionIDs := h, ps
+
+		wg.Go(func() error {
+			conn, err := m.Cluster.Connect(wctx, host)
+			if err != nil {
+				if tolerateUnreachable && wctx.Err() == nil {
+					mu.Lock()
+					unreachable = append(unreachable, host)
+					mu.Unlock()
+					return nil
+				}
+				return errors.Wrapf(err, "dial %s for stage %s", host, stageName)
+			}
+			req := reqTmpl
+			req.PartitionIDs = partitionIDs
+			if _, err := lrmrpb.NewNodeClient(conn).CreateTasks(wctx, &req); err != nil {
+				if tolerateUnreachable && status.Code(err) == codes.Unavailable {
+					mu.Lock()
+					unreachable = append(unreachable, host)
+					mu.Unlock()
+					return nil
+				}
+				return errors.Wrapf(err, "call CreateTask on %s", host)
+			}
+			progress.add(len(partitionIDs))
+			return nil
+		})
+	}
+	if err := wg.Wait(); err != nil {
+		return nil, err
+	}
+	return unreachable, nil
+}
+
+// reassignPartitions moves partitions of the stage on the unreachable hosts to the reachable host having
+// the least partitions in the stage, and returns the moved partition IDs grouped by their new hosts.
+// Hosts of the other stages are also considered, except the master collecting results.
+func reassignPartitions(j *job.Job, stageIdx int, unreachable map[string]bool) (map[string][]string, error) {
+	load := make(map[string]int)
+	for i, aa := range j.Partitions {
+		if i == 0 || j.Stages[i].Name == CollectStageName {
+			continue
+		}
+		for _, a := range aa {
+			if _, ok := load[a.Host]; !ok && !unreachable[a.Host] {
+				load[a.Host] = 0
+			}
+		}
+	}
+	for _, a := range j.Partitions[stageIdx] {
+		if !unreachable[a.Host] {
+			load[a.Host]++
+		}
+	}
+
+	moved := make(map[string][]string)
+	aa := j.Partitions[stageIdx]
+	for k, a := range aa {
+		if !unreachable[a.Host] {
+			continue
+		}
+		target := ""
+		for host, n := range load {
+			if target == "" || n < load[target] || (n == load[target] && host < target) {
+				target = host
+			}
+		}
+		if target == "" {
+			return nil, errors.Errorf("no reachable host for partition %s", a.PartitionID)
+		}
+		aa[k].Host = target
+		load[target]++
+		moved[target] = append(moved[target], a.PartitionID)
+	}
+	return moved, nil
+}
+
+// TaskLogs fetches recent lines logged by the task of the job from the worker which ran the task.
+// The task ID is in the form of job.TaskID, which is also used in job.Error.
+func (m *Master) TaskLogs(ctx context.Context, j *job.Job, taskID string) ([]string, error) {
+	ref, err := job.ParseTaskID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	var host string
+	for _, a := range j.GetPartitionsOfStage(ref.StageName) {
+		if a.PartitionID == ref.PartitionID {
+			host = a.Host
+			break
+		}
+	}
+	if host == "" {
+		return nil, errors.Errorf("task %s not found in job %s", taskID, j.ID)
+	}
+	conn, err := m.Cluster.Connect(ctx, host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dial %s", host)
+	}
+	resp, err := lrmrpb.NewNodeClient(conn).GetTaskLogs(ctx, &lrmrpb.GetTaskLogsRequest{TaskID: taskID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "call GetTaskLogs on %s", host)
+	}
+	return resp.Lines, nil
+}
+
 func (m *Master) OpenInputWriter(ctx context.Context, j *job.Job, stageName string, input partitions.Partitioner) (output.Output, error) {
 	targets := j.GetPartitionsOfStage(stageName)
 	outs := make(map[string]output.Output, len(targets))
@@ -195,12 +670,14 @@ func (m *Master) OpenInputWriter(ctx context.Context, j *job.Job, stageName stri
 		cancelJobCtx()
 	})
 
+	const inputPartitionID = "0"
+
 	var wg errgroup.Group
 	for _, t := range targets {
 		assigned := t
 		wg.Go(func() error {
 			taskID := path.Join(j.ID, stageName, assigned.PartitionID)
-			out, err := output.OpenPushStream(jobCtx, m.Cluster, m.Node, assigned.Host, taskID)
+			out, err := output.OpenPushStream(jobCtx, m.Cluster, m.Node, inputPartitionID, assigned.Host, taskID, m.opt.Output.Reconnect)
 			if err != nil {
 				return errors.Wrapf(err, "connect %s", assigned.Host)
 			}
@@ -213,28 +690,102 @@ func (m *Master) OpenInputWriter(ctx context.Context, j *job.Job, stageName stri
 	if err := wg.Wait(); err != nil {
 		return nil, err
 	}
-	out := output.NewWriter("0", input, outs)
+	out := output.NewWriter(inputPartitionID, input, outs, output.WithNilRowPolicy(m.opt.Output.NilRows))
 	return out, nil
 }
 
-func (m *Master) CollectedResults(jobID string) ([]*lrdd.Row, error) {
-	watchCtx, cancel := context.WithCancel(context.Background())
+// CollectedResults waits for the results of the job, and returns them. If the context is cancelled,
+// it stops waiting without aborting the job, and the results are discarded after the job completes.
+// The results are released once returned, or on failure of the job.
+func (m *Master) CollectedResults(ctx context.Context, jobID string) ([]*lrdd.Row, error) {
+	result, err := m.waitForCollectedResult(ctx, jobID)
+	if err != nil {
+		m.releaseCollectedResult(ctx, jobID)
+		return nil, err
+	}
+	collectedResults.Delete(jobID)
+	return result.all(), nil
+}
+
+// CollectedResultsOfPartition returns collected results from given partition in the final stage.
+// Unlike CollectedResults, the results are kept until every partition is retrieved, so that other partitions
+// can be retrieved. If the context is cancelled, the results are discarded after the job completes
+// like CollectedResults.
+func (m *Master) CollectedResultsOfPartition(ctx context.Context, jobID, partitionID string) ([]*lrdd.Row, error) {
+	result, err := m.waitForCollectedResult(ctx, jobID)
+	if err != nil {
+		m.releaseCollectedResult(ctx, jobID)
+		return nil, err
+	}
+	rows, found, all := result.retrieve(partitionID)
+	if all {
+		collectedResults.Delete(jobID)
+	}
+	if !found {
+		return nil, errors.Errorf("partition %s not found in job %s", partitionID, jobID)
+	}
+	return rows, nil
+}
+
+// releaseCollectedResult releases the results of the job after waiting for them failed. The results are
+// discarded right away if the job failed, or after it completes if the waiting has been cancelled.
+func (m *Master) releaseCollectedResult(ctx context.Context, jobID string) {
+	if ctx.Err() != nil {
+		go m.discardCollectedResult(jobID)
+		return
+	}
+	collectedResults.Delete(jobID)
+}
+
+func (m *Master) waitForCollectedResult(ctx context.Context, jobID string) (*collectedResult, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	resultChan, err := getCollectedResultChan(jobID)
+	result, err := getCollectedResult(jobID)
 	if err != nil {
 		return nil, err
 	}
 	select {
-	case result := <-resultChan:
+	case <-result.done:
+		// failed tasks also close their outputs, so the collection can complete before the error is watched
+		errs, err := m.JobManager.GetJobErrors(ctx, jobID)
+		if err != nil {
+			return nil, errors.Wrap(err, "get job errors")
+		}
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
 		return result, nil
 
-	case err := <-m.JobManager.WatchJobErrors(watchCtx, jobID):
+	case err, ok := <-m.JobManager.WatchJobErrors(watchCtx, jobID):
+		if !ok {
+			return nil, ctx.Err()
+		}
 		return nil, err
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
 }
 
+// discardCollectedResult releases the results of the job after it completes.
+func (m *Master) discardCollectedResult(jobID string) {
+	_, _ = m.waitForCollectedResult(context.Background(), jobID)
+	collectedResults.Delete(jobID)
+}
+
 func (m *Master) Stop() {
+	if m.stopElection != nil {
+		m.stopElection()
+	}
+	if m.stopCompaction != nil {
+		m.stopCompaction()
+	}
+	if m.statusServer != nil {
+		if err := m.statusServer.Close(); err != nil {
+			log.Error("Failed to close status server", err)
+		}
+	}
 	if err := m.executor.Close(); err != nil {
 		log.Error("failed to close worker")
 	}
@@ -243,3 +794,30 @@ func (m *Master) Stop() {
 		log.Error("Failed to close connections to cluster", err)
 	}
 }
+
+// submissionProgress counts tasks created by StartJob, reporting them to the callback of WithSubmissionProgress.
+type submissionProgress struct {
+	created int
+	total   int
+	fn      func(created, total int)
+	mu      sync.Mutex
+}
+
+func newSubmissionProgress(j *job.Job, fn func(created, total int)) *submissionProgress {
+	total := 0
+	for _, aa := range j.Partitions[1:] {
+		total += len(aa)
+	}
+	return &submissionProgress{total: total, fn: fn}
+}
+
+func (p *submissionProgress) add(n int) {
+	if p.fn == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.created += n
+	p.fn(p.created, p.total)
+}
@@ -0,0 +1,82 @@
+package master
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/internal/pbtypes"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMaster_OutputRouting(t *testing.T) {
+	Convey("Given a job with a wide shuffle", t, func() {
+		const numPartitions = 1000
+		shuffled := make(partitions.Assignments, numPartitions)
+		for i := range shuffled {
+			shuffled[i] = partitions.Assignment{PartitionID: strconv.Itoa(i), Host: "host" + strconv.Itoa(i%4)}
+		}
+		j := &job.Job{
+			ID: "J",
+			Stages: []stage.Stage{
+				{Name: "_input"},
+				{Name: "map"},
+				{Name: "reduce"},
+			},
+			Partitions: []partitions.Assignments{
+				{{PartitionID: "_input", Host: "master"}},
+				{{PartitionID: "0", Host: "host0"}, {PartitionID: "1", Host: "host1"}},
+				shuffled,
+			},
+		}
+		m := &Master{JobManager: job.NewManager(coordinator.NewLocalMemory())}
+
+		Convey("By default, the routing should be sent with the requests", func() {
+			routing, err := m.outputRoutingOf(context.Background(), j, 1)
+			So(err, ShouldBeNil)
+			So(routing, ShouldResemble, shuffled.ToMap())
+		})
+
+		Convey("When the routing is stored in the coordinator", func() {
+			j.RoutingInCoordinator = true
+			routing, err := m.outputRoutingOf(context.Background(), j, 1)
+			So(err, ShouldBeNil)
+
+			Convey("Requests should not carry the routing", func() {
+				So(routing, ShouldBeEmpty)
+			})
+
+			Convey("Requests should not carry assignments of the other stages", func() {
+				marshalled := pbtypes.MustMarshalJSON(j)
+				req, err := m.createTasksRequestOf(context.Background(), j, 1, marshalled, nil, nil, nil)
+				So(err, ShouldBeNil)
+
+				j.RoutingInCoordinator = false
+				reqWithRouting, err := m.createTasksRequestOf(context.Background(), j, 1, marshalled, nil, nil, nil)
+				So(err, ShouldBeNil)
+				So(req.Size(), ShouldBeLessThan, reqWithRouting.Size()/10)
+
+				sent := new(job.Job)
+				So(req.Job.UnmarshalJSON(sent), ShouldBeNil)
+				So(sent.GetPartitionsOfStage("map"), ShouldResemble, j.Partitions[1])
+				So(sent.GetPartitionsOfStage("reduce"), ShouldBeEmpty)
+			})
+
+			Convey("Workers should read the routing of the next stage from the coordinator", func() {
+				stored, err := m.JobManager.GetRouting(context.Background(), j.ID, "reduce")
+				So(err, ShouldBeNil)
+				So(stored.ToMap(), ShouldResemble, shuffled.ToMap())
+			})
+		})
+
+		Convey("The last stage should have no routing", func() {
+			routing, err := m.outputRoutingOf(context.Background(), j, 2)
+			So(err, ShouldBeNil)
+			So(routing, ShouldBeEmpty)
+		})
+	})
+}
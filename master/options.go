@@ -1,7 +1,10 @@
 package master
 
 import (
+	"time"
+
 	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/job"
 	"github.com/ab180/lrmr/output"
 	"github.com/creasty/defaults"
 )
@@ -12,11 +15,57 @@ type Options struct {
 
 	CollectQueueSize int `default:"1000"`
 
+	// IDGenerator overrides the ID scheme of the jobs. Random hex ID is used by default.
+	// Sessions can override it for their own jobs with lrmr.WithIDGenerator.
+	IDGenerator job.IDGenerator `default:"-"`
+
 	RPC   cluster.Options
 	Input struct {
 		MaxRecvSize int `default:"67108864"`
+
+		// ResumeTimeout is a duration to wait for a broken input stream to be resumed by its sender.
+		ResumeTimeout time.Duration `default:"5s"`
 	}
 	Output output.Options
+
+	// Pushgateway configures pushing metrics of the jobs at their completion, which is useful
+	// for batch-style deployments without a scrape endpoint.
+	Pushgateway PushgatewayOptions
+
+	// LeaderElection configures election of the leader among the masters sharing the coordinator.
+	LeaderElection LeaderElectionOptions
+
+	// TaskStatusCompaction configures deleting statuses of the tasks of completed jobs, which slow down
+	// scans of the coordinator as they accumulate. Compacted jobs keep their summaries (see job.Summary).
+	TaskStatusCompaction struct {
+		// Retention is a duration to keep the task statuses after the job completes. Zero disables the compaction.
+		Retention time.Duration `default:"0"`
+
+		// Interval is an interval between sweeps of the completed jobs.
+		Interval time.Duration `default:"1m"`
+	}
+
+	// SlowStart configures ramping up creation of the tasks of a job, which smooths the spike of load
+	// on the coordinator and the network from launching thousands of tasks at once.
+	SlowStart struct {
+		// InitialTasks is the number of tasks created in the first round. The number doubles in each
+		// following round until the remaining tasks are created at once. Zero disables the slow-start.
+		InitialTasks int `default:"0"`
+	}
+
+	// SkewDetection configures warning about stages of the jobs with a partition receiving far more rows than
+	// the median of the stage. Detected skews are logged and reported as "<stage>/SkewRatio" metrics of the jobs.
+	SkewDetection job.SkewDetection
+
+	// RoutingInCoordinator stores the output routing of each stage of the jobs in the coordinator once, and lets
+	// the workers read it on creating the tasks, instead of sending it with every request creating the tasks.
+	// It shrinks the requests of wide shuffles whose routing covers thousands of partitions. The requests carry
+	// assignments of their stage only, and each of them costs the worker a read from the coordinator.
+	RoutingInCoordinator bool `default:"false"`
+
+	// StatusServerHost is an address to serve read-only JSON status of the jobs (e.g. localhost:7601).
+	// The status server is disabled if it is empty.
+	StatusServerHost string
 }
 
 func DefaultOptions() (o Options) {
@@ -27,7 +76,22 @@ func DefaultOptions() (o Options) {
 }
 
 type CreateJobOptions struct {
-	NodeSelector map[string]string
+	NodeSelector        map[string]string
+	TaskTimeout         time.Duration
+	PersistOutput       bool
+	PersistCompression  job.Compression
+	InputJobID          string
+	CollectAllErrors    bool
+	Provenance          bool
+	SkipEmptyPartitions bool
+	RequiredFeatures    []string
+	Weight              int
+	PinnedLayout        map[string]string
+	ReassignUnreachable bool
+	DeterministicLayout bool
+	OrderedCollect      bool
+	Metadata            map[string]string
+	IDGenerator         job.IDGenerator
 }
 
 type CreateJobOption func(o *CreateJobOptions)
@@ -38,9 +102,142 @@ func WithNodeSelector(ns map[string]string) CreateJobOption {
 	}
 }
 
+// WithTaskTimeout aborts tasks of the job which do not make progress for given duration.
+func WithTaskTimeout(d time.Duration) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.TaskTimeout = d
+	}
+}
+
+// WithPersistedOutput keeps output of the final stage in the workers, so that it can be read by other jobs.
+func WithPersistedOutput() CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.PersistOutput = true
+	}
+}
+
+// WithPersistCompression compresses the persisted output of the job with given algorithm.
+func WithPersistCompression(c job.Compression) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.PersistCompression = c
+	}
+}
+
+// WithInputFromJob reads the persisted output of given job as the input of the job.
+func WithInputFromJob(jobID string) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.InputJobID = jobID
+	}
+}
+
+// WithCollectAllErrors makes the job to wait for every task and collect all errors before failing.
+func WithCollectAllErrors() CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.CollectAllErrors = true
+	}
+}
+
+// WithProvenance makes rows of the job to carry their lineage.
+func WithProvenance() CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.Provenance = true
+	}
+}
+
+// WithSkipEmptyPartitions completes tasks of the job without running their transformations
+// if their partitions receive no rows, reducing overhead of sparse data.
+func WithSkipEmptyPartitions() CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.SkipEmptyPartitions = true
+	}
+}
+
+// WithReassignUnreachable reassigns partitions on unreachable hosts to other live hosts
+// on starting the job, instead of failing the job.
+func WithReassignUnreachable() CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.ReassignUnreachable = true
+	}
+}
+
+// WithOrderedCollect makes collected rows of the job to be returned in the order of the partition IDs.
+func WithOrderedCollect() CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.OrderedCollect = true
+	}
+}
+
+// WithMetadata attaches business metadata to the job, which can be used to filter the jobs
+// with job.Manager.ListJobsByMetadata.
+func WithMetadata(md map[string]string) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.Metadata = md
+	}
+}
+
+// WithWeight gives the job given share of task slots relative to other concurrent jobs,
+// on workers with fair scheduling.
+func WithWeight(w int) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.Weight = w
+	}
+}
+
+// WithPinnedLayout assigns the partitions to the hosts in the layout keyed by partition IDs, instead of scheduling them.
+// It is meant for reproducible tests and benchmarks. The job is refused if any of the hosts is not available.
+func WithPinnedLayout(layout map[string]string) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.PinnedLayout = layout
+	}
+}
+
+// WithDeterministicLayout schedules partitions to the workers sorted by their hosts, instead of shuffling them.
+// Two runs of the same job on the same set of workers would have an identical layout of the partitions.
+func WithDeterministicLayout() CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.DeterministicLayout = true
+	}
+}
+
+// WithRequiredFeatures refuses to create the job if any of the workers does not support the features
+// listed in the version package, which can happen while workers are being upgraded.
+func WithRequiredFeatures(features ...string) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.RequiredFeatures = append(o.RequiredFeatures, features...)
+	}
+}
+
+// WithIDGenerator generates ID of the job with given generator, instead of Options.IDGenerator of the master.
+func WithIDGenerator(g job.IDGenerator) CreateJobOption {
+	return func(o *CreateJobOptions) {
+		o.IDGenerator = g
+	}
+}
+
 func buildCreateJobOptions(opts []CreateJobOption) (o CreateJobOptions) {
 	for _, optFn := range opts {
 		optFn(&o)
 	}
 	return o
 }
+
+type StartJobOptions struct {
+	SubmissionProgress func(created, total int)
+}
+
+type StartJobOption func(o *StartJobOptions)
+
+// WithSubmissionProgress calls the function whenever tasks of the job are created on a worker, with the number of
+// tasks created so far and the total number of tasks. The function is called sequentially with increasing numbers.
+func WithSubmissionProgress(fn func(created, total int)) StartJobOption {
+	return func(o *StartJobOptions) {
+		o.SubmissionProgress = fn
+	}
+}
+
+func buildStartJobOptions(opts []StartJobOption) (o StartJobOptions) {
+	for _, optFn := range opts {
+		optFn(&o)
+	}
+	return o
+}
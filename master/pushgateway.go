@@ -0,0 +1,126 @@
+package master
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/pkg/errors"
+)
+
+const metricPrefix = "lrmr_"
+
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// pushMetricsOf pushes metrics of the completed job to the Prometheus Pushgateway.
+// Failures are only logged, so that it does not affect the result of the job.
+func (m *Master) pushMetricsOf(j *job.Job, status *job.Status) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.opt.Pushgateway.Timeout)
+	defer cancel()
+
+	statuses, err := m.JobManager.ListTaskStatusesInJob(ctx, j.ID)
+	if err != nil {
+		log.Warn("Failed to read metrics of job {} to push: {}", j.ID, err)
+		return
+	}
+	metrics, err := job.AggregateMetrics(statuses)
+	if err != nil {
+		log.Warn("Failed to aggregate metrics of job {} to push: {}", j.ID, err)
+		return
+	}
+	if err := pushMetrics(ctx, m.opt.Pushgateway.URL, j, status, metrics); err != nil {
+		log.Warn("Failed to push metrics of job {}: {}", j.ID, err)
+	}
+}
+
+// pushMetrics sends metrics to the Pushgateway grouped by the name and ID of the job.
+func pushMetrics(ctx context.Context, gatewayURL string, j *job.Job, status *job.Status, metrics job.Metrics) error {
+	body := new(bytes.Buffer)
+	writeGauge(body, "job_succeeded", "Whether the job succeeded (1) or failed (0).", boolToFloat(status.Status == job.Succeeded))
+	if status.CompletedAt != nil {
+		elapsed := status.CompletedAt.Sub(j.SubmittedAt)
+		writeGauge(body, "job_duration_seconds", "Elapsed time of the job.", elapsed.Seconds())
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// different metric names can be sanitized into a same one
+	sanitized := make(map[string]float64)
+	var sanitizedNames []string
+	for _, name := range names {
+		s := invalidMetricNameChars.ReplaceAllString(name, "_")
+		if _, ok := sanitized[s]; !ok {
+			sanitizedNames = append(sanitizedNames, s)
+		}
+		sanitized[s] += float64(metrics[name])
+	}
+	for _, name := range sanitizedNames {
+		writeGauge(body, name, "", sanitized[name])
+	}
+
+	pushURL := strings.TrimSuffix(gatewayURL, "/") + "/metrics/job" + encodeLabelValue(j.Name) + "/job_id" + encodeLabelValue(j.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL, body)
+	if err != nil {
+		return errors.Wrap(err, "create request")
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "push to %s", gatewayURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("unexpected status from %s: %s", gatewayURL, resp.Status)
+	}
+	return nil
+}
+
+// writeGauge writes a metric family with a single gauge in Prometheus text exposition format.
+func writeGauge(buf *bytes.Buffer, name, help string, value float64) {
+	name = metricPrefix + name
+	if help != "" {
+		_, _ = fmt.Fprintf(buf, "# HELP %s %s\n", name, help)
+	}
+	_, _ = fmt.Fprintf(buf, "# TYPE %s gauge\n%s %g\n", name, name, value)
+}
+
+// encodeLabelValue encodes a label value into the path of the grouping key. Values which cannot be
+// used as a path segment are encoded in base64, as the Pushgateway specifies.
+func encodeLabelValue(v string) string {
+	if v == "" {
+		return "@base64/="
+	}
+	if strings.ContainsAny(v, "/%") {
+		return "@base64/" + base64.URLEncoding.EncodeToString([]byte(v))
+	}
+	return "/" + url.PathEscape(v)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// PushgatewayOptions configures pushing metrics of the jobs to the Prometheus Pushgateway.
+type PushgatewayOptions struct {
+	// URL is an address of the Pushgateway (e.g. http://pushgateway:9091).
+	// Metrics are not pushed if the URL is empty.
+	URL string
+
+	Timeout time.Duration `default:"5s"`
+}
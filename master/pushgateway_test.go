@@ -0,0 +1,107 @@
+package master
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/stage"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPushMetrics(t *testing.T) {
+	Convey("Given a Pushgateway", t, func() {
+		var (
+			method, path, body string
+			statusCode         = http.StatusOK
+		)
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			method, path, body = r.Method, r.URL.EscapedPath(), string(data)
+			w.WriteHeader(statusCode)
+		}))
+		defer gateway.Close()
+
+		submittedAt := time.Now()
+		completedAt := submittedAt.Add(3 * time.Second)
+		j := &job.Job{ID: "J1234", Name: "daily-report", SubmittedAt: submittedAt}
+		status := &job.Status{}
+		status.Status = job.Succeeded
+		status.CompletedAt = &completedAt
+		metrics := job.Metrics{
+			"Files":                 3,
+			"map0/1/InputRows":      10,
+			"map0/2/InputRows":      20,
+			"Decoded Rows (approx)": 30,
+		}
+
+		Convey("Metrics of the job should be pushed, grouped by the job name and ID", func() {
+			err := pushMetrics(context.Background(), gateway.URL+"/", j, status, metrics)
+			So(err, ShouldBeNil)
+			So(method, ShouldEqual, http.MethodPut)
+			So(path, ShouldEqual, "/metrics/job/daily-report/job_id/J1234")
+
+			So(body, ShouldContainSubstring, "# TYPE lrmr_job_succeeded gauge\nlrmr_job_succeeded 1\n")
+			So(body, ShouldContainSubstring, "# TYPE lrmr_job_duration_seconds gauge\nlrmr_job_duration_seconds 3\n")
+			So(body, ShouldContainSubstring, "# TYPE lrmr_Files gauge\nlrmr_Files 3\n")
+			So(body, ShouldContainSubstring, "# TYPE lrmr_map0_1_InputRows gauge\nlrmr_map0_1_InputRows 10\n")
+			So(body, ShouldContainSubstring, "# TYPE lrmr_map0_2_InputRows gauge\nlrmr_map0_2_InputRows 20\n")
+			So(body, ShouldContainSubstring, "# TYPE lrmr_Decoded_Rows__approx_ gauge\nlrmr_Decoded_Rows__approx_ 30\n")
+		})
+
+		Convey("Job names not usable as a path segment should be encoded in base64", func() {
+			j.Name = "reports/daily"
+			So(pushMetrics(context.Background(), gateway.URL, j, status, metrics), ShouldBeNil)
+			So(path, ShouldEqual, "/metrics/job@base64/cmVwb3J0cy9kYWlseQ==/job_id/J1234")
+		})
+
+		Convey("An error should be returned if the Pushgateway rejects", func() {
+			statusCode = http.StatusBadRequest
+			So(pushMetrics(context.Background(), gateway.URL, j, status, metrics), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestMaster_PushMetricsOnCompletion(t *testing.T) {
+	Convey("Given a master pushing metrics to a slow Pushgateway", t, func() {
+		ctx := context.Background()
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(2 * time.Second)
+		}))
+		defer gateway.Close()
+
+		crd := coordinator.NewLocalMemory()
+		jm := job.NewManager(crd)
+		tracker := job.NewJobTracker(crd, jm)
+		defer tracker.Close()
+
+		m := &Master{JobManager: jm, JobTracker: tracker}
+		m.opt.Pushgateway = PushgatewayOptions{URL: gateway.URL, Timeout: 5 * time.Second}
+
+		j, err := jm.CreateJob(ctx, "test", []stage.Stage{{Name: "_input"}, {Name: "map0"}}, nil)
+		So(err, ShouldBeNil)
+
+		Convey("Other callbacks of the job should not wait for the push", func() {
+			m.trackJob(j)
+			completed := make(chan struct{})
+			tracker.OnJobCompletion(j, func(*job.Job, *job.Status) {
+				close(completed)
+			})
+
+			js := job.Status{}
+			js.Complete(job.Succeeded)
+			So(jm.SetJobStatus(ctx, j.ID, js), ShouldBeNil)
+
+			select {
+			case <-completed:
+			case <-time.After(time.Second):
+				So("callback is blocked by the push", ShouldBeEmpty)
+			}
+		})
+	})
+}
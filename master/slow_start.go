@@ -0,0 +1,56 @@
+package master
+
+import "sort"
+
+// taskRamp splits creation of the tasks of a job into rounds growing exponentially, starting from
+// Options.SlowStart.InitialTasks. The size of the round is kept across the stages of the job.
+type taskRamp struct {
+	window int
+}
+
+// newTaskRamp returns nil if the slow-start is disabled.
+func newTaskRamp(initialTasks int) *taskRamp {
+	if initialTasks <= 0 {
+		return nil
+	}
+	return &taskRamp{window: initialTasks}
+}
+
+// split divides the partitions grouped by their hosts into rounds of creating tasks. Partitions of a round
+// are taken from the hosts evenly. A nil ramp creates every task in a single round.
+func (r *taskRamp) split(idsByHost map[string][]string) (rounds []map[string][]string) {
+	if r == nil {
+		return []map[string][]string{idsByHost}
+	}
+	hosts := make([]string, 0, len(idsByHost))
+	remaining := make(map[string][]string, len(idsByHost))
+	total := 0
+	for host, ids := range idsByHost {
+		hosts = append(hosts, host)
+		remaining[host] = ids
+		total += len(ids)
+	}
+	sort.Strings(hosts)
+
+	for total > 0 {
+		round := make(map[string][]string)
+		for n := 0; n < r.window && total > 0; {
+			for _, host := range hosts {
+				if n == r.window {
+					break
+				}
+				ids := remaining[host]
+				if len(ids) == 0 {
+					continue
+				}
+				round[host] = append(round[host], ids[0])
+				remaining[host] = ids[1:]
+				n++
+				total--
+			}
+		}
+		rounds = append(rounds, round)
+		r.window *= 2
+	}
+	return rounds
+}
@@ -0,0 +1,65 @@
+package master
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTaskRamp(t *testing.T) {
+	idsByHost := map[string][]string{
+		"host1": {"0", "2", "4", "6", "8", "10", "12", "14", "16", "18"},
+		"host2": {"1", "3", "5", "7", "9", "11", "13", "15", "17", "19"},
+	}
+
+	Convey("Given a ramp with slow-start", t, func() {
+		ramp := newTaskRamp(1)
+
+		Convey("Tasks should be created in rounds doubling their sizes", func() {
+			rounds := ramp.split(idsByHost)
+			So(roundSizes(rounds), ShouldResemble, []int{1, 2, 4, 8, 5})
+
+			Convey("Partitions should be taken from the hosts evenly", func() {
+				So(rounds[2], ShouldResemble, map[string][]string{
+					"host1": {"4", "6"},
+					"host2": {"3", "5"},
+				})
+			})
+
+			Convey("Every partition should be created once", func() {
+				created := make(map[string][]string)
+				for _, round := range rounds {
+					for host, ids := range round {
+						created[host] = append(created[host], ids...)
+					}
+				}
+				So(created, ShouldResemble, idsByHost)
+			})
+
+			Convey("Following stages should be created with the ramped-up concurrency", func() {
+				So(roundSizes(ramp.split(idsByHost)), ShouldResemble, []int{20})
+			})
+		})
+	})
+
+	Convey("Given a ramp without slow-start", t, func() {
+		ramp := newTaskRamp(0)
+
+		Convey("Tasks should be created all at once", func() {
+			rounds := ramp.split(idsByHost)
+			So(rounds, ShouldHaveLength, 1)
+			So(rounds[0], ShouldResemble, idsByHost)
+		})
+	})
+}
+
+func roundSizes(rounds []map[string][]string) (sizes []int) {
+	for _, round := range rounds {
+		n := 0
+		for _, ids := range round {
+			n += len(ids)
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes
+}
@@ -0,0 +1,165 @@
+package master
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/job"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// statusServer serves status of the jobs in JSON. It is read-only.
+//   - GET /jobs: lists active jobs. Completed jobs are also listed with ?all=true.
+//   - GET /jobs/{id}: returns status and progress of the job.
+//   - GET /jobs/{id}/errors: returns errors occurred in the job.
+//   - GET /jobs/{id}/stages/{stage}/partitions: returns statuses of the partitions in the stage.
+type statusServer struct {
+	jobManager *job.Manager
+}
+
+// jobSummary is a status and progress of a job.
+type jobSummary struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Status      job.RunningState    `json:"status"`
+	SubmittedAt time.Time           `json:"submittedAt"`
+	CompletedAt *time.Time          `json:"completedAt,omitempty"`
+	Stages      []job.StageProgress `json:"stages"`
+	NumErrors   int                 `json:"numErrors"`
+}
+
+func (s *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("only GET is allowed"))
+		return
+	}
+	frags := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if frags[0] != "jobs" {
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("unknown path: %s", r.URL.Path))
+		return
+	}
+	switch {
+	case len(frags) == 1:
+		s.listJobs(w, r)
+	case len(frags) == 2:
+		s.getJob(w, r, frags[1])
+	case len(frags) == 3 && frags[2] == "errors":
+		s.getJobErrors(w, r, frags[1])
+	case len(frags) == 5 && frags[2] == "stages" && frags[4] == "partitions":
+		s.listStagePartitions(w, r, frags[1], frags[3])
+	default:
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("unknown path: %s", r.URL.Path))
+	}
+}
+
+func (s *statusServer) listJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.jobManager.ListJobs(r.Context(), "")
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrap(err, "list jobs"))
+		return
+	}
+	includeCompleted := r.URL.Query().Get("all") == "true"
+
+	summaries := make([]*jobSummary, 0, len(jobs))
+	for _, j := range jobs {
+		summary, err := s.summarize(r.Context(), j)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !includeCompleted && summary.CompletedAt != nil {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *statusServer) getJob(w http.ResponseWriter, r *http.Request, jobID string) {
+	j, err := s.jobManager.GetJob(r.Context(), jobID)
+	if err != nil {
+		writeJobQueryError(w, jobID, err)
+		return
+	}
+	summary, err := s.summarize(r.Context(), j)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *statusServer) getJobErrors(w http.ResponseWriter, r *http.Request, jobID string) {
+	if _, err := s.jobManager.GetJob(r.Context(), jobID); err != nil {
+		writeJobQueryError(w, jobID, err)
+		return
+	}
+	errs, err := s.jobManager.GetJobErrors(r.Context(), jobID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrapf(err, "get errors of job %s", jobID))
+		return
+	}
+	writeJSON(w, http.StatusOK, errs)
+}
+
+func (s *statusServer) listStagePartitions(w http.ResponseWriter, r *http.Request, jobID, stageName string) {
+	j, err := s.jobManager.GetJob(r.Context(), jobID)
+	if err != nil {
+		writeJobQueryError(w, jobID, err)
+		return
+	}
+	if j.GetStage(stageName) == nil {
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("stage %s not found in job %s", stageName, jobID))
+		return
+	}
+	partitions, err := s.jobManager.ListStagePartitions(r.Context(), jobID, stageName)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrapf(err, "list partitions of %s/%s", jobID, stageName))
+		return
+	}
+	writeJSON(w, http.StatusOK, partitions)
+}
+
+func (s *statusServer) summarize(ctx context.Context, j *job.Job) (*jobSummary, error) {
+	status, err := s.jobManager.GetJobStatus(ctx, j.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get status of job %s", j.ID)
+	}
+	stages, err := s.jobManager.GetStageProgresses(ctx, j)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get progress of job %s", j.ID)
+	}
+	return &jobSummary{
+		ID:          j.ID,
+		Name:        j.Name,
+		Status:      status.Status,
+		SubmittedAt: j.SubmittedAt,
+		CompletedAt: status.CompletedAt,
+		Stages:      stages,
+		NumErrors:   len(status.Errors),
+	}, nil
+}
+
+func writeJobQueryError(w http.ResponseWriter, jobID string, err error) {
+	if errors.Cause(err) == coordinator.ErrNotFound {
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("job %s not found", jobID))
+		return
+	}
+	writeJSONError(w, http.StatusInternalServerError, errors.Wrapf(err, "get job %s", jobID))
+}
+
+func writeJSONError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := jsoniter.NewEncoder(w).Encode(v); err != nil {
+		log.Warn("Failed to write response of status server: {}", err)
+	}
+}
@@ -0,0 +1,129 @@
+package master
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStatusServer(t *testing.T) {
+	Convey("Given a status server with a running job and a failed job", t, func() {
+		ctx := context.Background()
+		crd := coordinator.NewLocalMemory()
+		jm := job.NewManager(crd)
+
+		stages := []stage.Stage{{Name: "_input"}, {Name: "map0"}}
+		assignments := []partitions.Assignments{
+			{{PartitionID: "0", Host: "localhost"}},
+			{{PartitionID: "0", Host: "localhost"}, {PartitionID: "1", Host: "localhost"}},
+		}
+		running, err := jm.CreateJob(ctx, "running", stages, assignments)
+		So(err, ShouldBeNil)
+		failed, err := jm.CreateJob(ctx, "failed", stages, assignments)
+		So(err, ShouldBeNil)
+		reportTask(ctx, crd, jm, running, &stages[1], "0", nil)
+		reportTask(ctx, crd, jm, failed, &stages[1], "0", errors.New("boom"))
+
+		srv := httptest.NewServer(&statusServer{jobManager: jm})
+		defer srv.Close()
+
+		Convey("Listing jobs should return only active jobs", func() {
+			var jobs []jobSummary
+			So(getJSON(srv.URL+"/jobs", http.StatusOK, &jobs), ShouldBeNil)
+			So(jobs, ShouldHaveLength, 1)
+			So(jobs[0].ID, ShouldEqual, running.ID)
+			So(jobs[0].Status, ShouldEqual, job.Starting)
+			So(jobs[0].Stages, ShouldResemble, []job.StageProgress{
+				{Name: "map0", TotalTasks: 2, DoneTasks: 1},
+			})
+
+			Convey("Completed jobs should be also listed with ?all=true", func() {
+				So(getJSON(srv.URL+"/jobs?all=true", http.StatusOK, &jobs), ShouldBeNil)
+				So(jobs, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("Getting a job should return its status and progress", func() {
+			var j jobSummary
+			So(getJSON(srv.URL+"/jobs/"+failed.ID, http.StatusOK, &j), ShouldBeNil)
+			So(j.Name, ShouldEqual, "failed")
+			So(j.Status, ShouldEqual, job.Failed)
+			So(j.CompletedAt, ShouldNotBeNil)
+			So(j.NumErrors, ShouldEqual, 1)
+			So(j.Stages, ShouldResemble, []job.StageProgress{
+				{Name: "map0", TotalTasks: 2, DoneTasks: 1, FailedTasks: 1},
+			})
+		})
+
+		Convey("Getting errors of a job should return errors occurred in its tasks", func() {
+			var errs []job.Error
+			So(getJSON(srv.URL+"/jobs/"+failed.ID+"/errors", http.StatusOK, &errs), ShouldBeNil)
+			So(errs, ShouldHaveLength, 1)
+			So(errs[0].Task, ShouldEqual, failed.ID+"/map0/0")
+			So(errs[0].Message, ShouldEqual, "boom")
+		})
+
+		Convey("Listing partitions of a stage should return their states", func() {
+			var partitions []job.PartitionStatus
+			So(getJSON(srv.URL+"/jobs/"+running.ID+"/stages/map0/partitions", http.StatusOK, &partitions), ShouldBeNil)
+			So(partitions, ShouldHaveLength, 2)
+			So(partitions[0].Task.PartitionID, ShouldEqual, "0")
+			So(partitions[0].State, ShouldEqual, job.PartitionDone)
+			So(partitions[1].Task.PartitionID, ShouldEqual, "1")
+			So(partitions[1].State, ShouldEqual, job.PartitionPending)
+		})
+
+		Convey("Unknown jobs should respond 404", func() {
+			So(getJSON(srv.URL+"/jobs/J-unknown", http.StatusNotFound, nil), ShouldBeNil)
+			So(getJSON(srv.URL+"/jobs/J-unknown/errors", http.StatusNotFound, nil), ShouldBeNil)
+			So(getJSON(srv.URL+"/jobs/J-unknown/stages/map0/partitions", http.StatusNotFound, nil), ShouldBeNil)
+			So(getJSON(srv.URL+"/jobs/"+running.ID+"/stages/unknown/partitions", http.StatusNotFound, nil), ShouldBeNil)
+		})
+
+		Convey("Requests other than GET should be rejected", func() {
+			resp, err := http.Post(srv.URL+"/jobs", "application/json", nil)
+			So(err, ShouldBeNil)
+			_ = resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, http.StatusMethodNotAllowed)
+		})
+	})
+}
+
+// reportTask creates a task of the job and reports its completion. The task fails if err is non-nil.
+func reportTask(ctx context.Context, crd coordinator.Coordinator, jm *job.Manager, j *job.Job, s *stage.Stage, partitionID string, err error) {
+	task := job.NewTask(partitionID, node.New("localhost", node.Worker), j.ID, s)
+	status, cerr := jm.CreateTask(ctx, task)
+	So(cerr, ShouldBeNil)
+
+	reporter := job.NewTaskReporter(ctx, crd, j, task.ID(), status)
+	if err != nil {
+		So(reporter.ReportFailure(err), ShouldBeNil)
+		return
+	}
+	So(reporter.ReportSuccess(), ShouldBeNil)
+}
+
+func getJSON(url string, expectedCode int, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expectedCode {
+		return errors.Errorf("expected status %d, got %s", expectedCode, resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	return jsoniter.NewDecoder(resp.Body).Decode(v)
+}
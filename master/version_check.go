@@ -0,0 +1,134 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/ab180/lrmr/version"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrFeatureNotSupported is returned when creating a job requiring a feature which a worker lacks.
+var ErrFeatureNotSupported = errors.New("feature not supported by worker")
+
+// VersionMismatch describes a worker running a different version from the master.
+type VersionMismatch struct {
+	Host string
+	Info *lrmrpb.NodeInfo
+
+	// MissingFeatures are features supported by the master but not by the worker.
+	MissingFeatures []string
+}
+
+func (v VersionMismatch) String() string {
+	return fmt.Sprintf("%s runs %s (%s, protocol v%d) missing features %v",
+		v.Host, v.Info.Version, v.Info.GitCommit, v.Info.ProtocolVersion, v.MissingFeatures)
+}
+
+// CheckWorkerVersions polls versions of every worker in the cluster, and returns workers running a different
+// version from the master. Workers too old to report their version are also returned with the unknown version.
+func (m *Master) CheckWorkerVersions(ctx context.Context) ([]VersionMismatch, error) {
+	workers, err := m.Cluster.List(ctx, cluster.ListOption{Type: node.Worker})
+	if err != nil {
+		return nil, errors.WithMessage(err, "list available workers")
+	}
+	infos, err := m.workerInfos(ctx, workers)
+	if err != nil {
+		return nil, err
+	}
+	var mismatches []VersionMismatch
+	for host, info := range infos {
+		if mismatch, ok := checkVersion(host, info); !ok {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].Host < mismatches[j].Host
+	})
+	return mismatches, nil
+}
+
+// checkRequiredFeatures returns ErrFeatureNotSupported if any of the workers lacks the features.
+func (m *Master) checkRequiredFeatures(ctx context.Context, workers []*node.Node, features []string) error {
+	infos, err := m.workerInfos(ctx, workers)
+	if err != nil {
+		return err
+	}
+	for _, w := range workers {
+		info := infos[w.Host]
+		if missing := missingFeatures(info, features); len(missing) > 0 {
+			return errors.Wrapf(ErrFeatureNotSupported, "%v on %s (version %s)", missing, w.Host, info.Version)
+		}
+	}
+	return nil
+}
+
+// workerInfos calls Node.Info RPC of the workers, keyed by their hosts.
+func (m *Master) workerInfos(ctx context.Context, workers []*node.Node) (map[string]*lrmrpb.NodeInfo, error) {
+	infos := make(map[string]*lrmrpb.NodeInfo, len(workers))
+	var lock sync.Mutex
+
+	wg, wctx := errgroup.WithContext(ctx)
+	for _, w := range workers {
+		host := w.Host
+		wg.Go(func() error {
+			conn, err := m.Cluster.Connect(wctx, host)
+			if err != nil {
+				return errors.Wrapf(err, "dial %s", host)
+			}
+			info, err := lrmrpb.NewNodeClient(conn).Info(wctx, &empty.Empty{})
+			if status.Code(err) == codes.Unimplemented {
+				// workers older than Node.Info RPC
+				info, err = &lrmrpb.NodeInfo{Version: "unknown", GitCommit: "unknown"}, nil
+			}
+			if err != nil {
+				return errors.Wrapf(err, "get info of %s", host)
+			}
+			lock.Lock()
+			infos[host] = info
+			lock.Unlock()
+			return nil
+		})
+	}
+	if err := wg.Wait(); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// checkVersion returns false with the mismatch if the worker runs a different version from the master.
+func checkVersion(host string, info *lrmrpb.NodeInfo) (VersionMismatch, bool) {
+	mismatch := VersionMismatch{
+		Host:            host,
+		Info:            info,
+		MissingFeatures: missingFeatures(info, version.Features()),
+	}
+	ok := info.Version == version.Version &&
+		info.GitCommit == version.GitCommit &&
+		info.ProtocolVersion == version.ProtocolVersion &&
+		len(mismatch.MissingFeatures) == 0
+	return mismatch, ok
+}
+
+// missingFeatures returns the features not supported by the worker.
+func missingFeatures(info *lrmrpb.NodeInfo, features []string) (missing []string) {
+	supported := make(map[string]bool, len(info.Features))
+	for _, f := range info.Features {
+		supported[f] = true
+	}
+	for _, f := range features {
+		if !supported[f] {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,50 @@
+package master
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/ab180/lrmr/version"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheckVersion(t *testing.T) {
+	Convey("Given a worker running the same version", t, func() {
+		info := &lrmrpb.NodeInfo{
+			Version:         version.Version,
+			GitCommit:       version.GitCommit,
+			ProtocolVersion: version.ProtocolVersion,
+			Features:        version.Features(),
+		}
+
+		Convey("It should not be reported as a mismatch", func() {
+			_, ok := checkVersion("worker1", info)
+			So(ok, ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a worker running an older version", t, func() {
+		info := &lrmrpb.NodeInfo{
+			Version:         "v0.0.1",
+			GitCommit:       "abcdef",
+			ProtocolVersion: version.ProtocolVersion,
+			Features:        []string{version.MuxPushStream},
+		}
+
+		Convey("It should be reported with its missing features", func() {
+			mismatch, ok := checkVersion("worker1", info)
+			So(ok, ShouldBeFalse)
+			So(mismatch.Host, ShouldEqual, "worker1")
+			So(mismatch.MissingFeatures, ShouldNotContain, version.MuxPushStream)
+			So(mismatch.MissingFeatures, ShouldContain, version.SideInputs)
+		})
+	})
+
+	Convey("Given a worker too old to report its version", t, func() {
+		info := &lrmrpb.NodeInfo{Version: "unknown"}
+
+		Convey("Every feature should be missing", func() {
+			So(missingFeatures(info, version.Features()), ShouldResemble, version.Features())
+		})
+	})
+}
@@ -0,0 +1,28 @@
+package master
+
+import (
+	"context"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/pkg/errors"
+)
+
+// WarmUp connects every worker in the cluster ahead of time, so that the first job would not pay
+// the cost of dialing the workers. It returns errors of the workers failed to connect, by their hosts.
+// Workers can also warm up connections between themselves with worker.Worker.WarmUp.
+func (m *Master) WarmUp(ctx context.Context) (unreachable map[string]error, err error) {
+	workers, err := m.Cluster.List(ctx, cluster.ListOption{Type: node.Worker})
+	if err != nil {
+		return nil, errors.WithMessage(err, "list available workers")
+	}
+	hosts := make([]string, len(workers))
+	for i, w := range workers {
+		hosts[i] = w.Host
+	}
+	unreachable = cluster.WarmUp(ctx, m.Cluster, hosts)
+	for host, err := range unreachable {
+		log.Warn("Failed to warm up connection to {}: {}", host, err)
+	}
+	return unreachable, nil
+}
@@ -0,0 +1,175 @@
+package lrmr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var _ = RegisterTypes(NaturalOrdering{}, NumericOrdering{}, reverseOrdering{}, multiFieldOrdering{}, orderingSorter{})
+
+// Ordering compares keys of rows. Compare returns a negative number if a < b,
+// zero if a == b, and a positive number if a > b.
+// Custom orderings need to be registered with RegisterTypes to be used on workers.
+type Ordering interface {
+	Compare(a, b string) int
+}
+
+// NaturalOrdering orders keys in byte-wise lexical order.
+type NaturalOrdering struct{}
+
+func (NaturalOrdering) Compare(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// NumericOrdering orders keys by their numeric values. Non-numeric keys are placed after
+// numeric keys, in byte-wise lexical order.
+type NumericOrdering struct{}
+
+func (NumericOrdering) Compare(a, b string) int {
+	an, aErr := strconv.ParseFloat(a, 64)
+	bn, bErr := strconv.ParseFloat(b, 64)
+	switch {
+	case aErr != nil && bErr != nil:
+		return strings.Compare(a, b)
+	case aErr != nil:
+		return 1
+	case bErr != nil:
+		return -1
+	case an < bn:
+		return -1
+	case an > bn:
+		return 1
+	}
+	return 0
+}
+
+type reverseOrdering struct {
+	ordering Ordering
+}
+
+// Reverse returns an Ordering in reverse order of given ordering.
+func Reverse(o Ordering) Ordering {
+	return reverseOrdering{o}
+}
+
+func (r reverseOrdering) Compare(a, b string) int {
+	return r.ordering.Compare(b, a)
+}
+
+func (r reverseOrdering) nestedUserTypes() []interface{} {
+	return []interface{}{r.ordering}
+}
+
+func (r reverseOrdering) MarshalJSON() ([]byte, error) {
+	return serialization.SerializeStruct(r.ordering)
+}
+
+func (r *reverseOrdering) UnmarshalJSON(data []byte) error {
+	v, err := serialization.DeserializeStruct(data)
+	if err != nil {
+		return err
+	}
+	r.ordering = v.(Ordering)
+	return nil
+}
+
+type multiFieldOrdering struct {
+	separator string
+	fields    []Ordering
+}
+
+// MultiField returns an Ordering for keys composed of multiple fields joined with the separator.
+// Each field is compared with the ordering in the same position, and the next field is only
+// compared if the previous fields are equal. Fields exceeding the number of orderings are
+// compared in NaturalOrdering.
+func MultiField(separator string, fields ...Ordering) Ordering {
+	return multiFieldOrdering{separator: separator, fields: fields}
+}
+
+func (m multiFieldOrdering) Compare(a, b string) int {
+	aFields := strings.Split(a, m.separator)
+	bFields := strings.Split(b, m.separator)
+	for i := 0; i < len(aFields) && i < len(bFields); i++ {
+		var o Ordering = NaturalOrdering{}
+		if i < len(m.fields) {
+			o = m.fields[i]
+		}
+		if c := o.Compare(aFields[i], bFields[i]); c != 0 {
+			return c
+		}
+	}
+	return len(aFields) - len(bFields)
+}
+
+func (m multiFieldOrdering) nestedUserTypes() []interface{} {
+	nested := make([]interface{}, len(m.fields))
+	for i, f := range m.fields {
+		nested[i] = f
+	}
+	return nested
+}
+
+func (m multiFieldOrdering) MarshalJSON() ([]byte, error) {
+	fields := make([]jsoniter.RawMessage, len(m.fields))
+	for i, f := range m.fields {
+		data, err := serialization.SerializeStruct(f)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = data
+	}
+	return jsoniter.Marshal(struct {
+		Separator string
+		Fields    []jsoniter.RawMessage
+	}{m.separator, fields})
+}
+
+func (m *multiFieldOrdering) UnmarshalJSON(data []byte) error {
+	var desc struct {
+		Separator string
+		Fields    []jsoniter.RawMessage
+	}
+	if err := jsoniter.Unmarshal(data, &desc); err != nil {
+		return err
+	}
+	m.separator = desc.Separator
+	m.fields = make([]Ordering, len(desc.Fields))
+	for i, f := range desc.Fields {
+		v, err := serialization.DeserializeStruct(f)
+		if err != nil {
+			return err
+		}
+		m.fields[i] = v.(Ordering)
+	}
+	return nil
+}
+
+// orderingSorter implements Sorter comparing keys of rows with the Ordering.
+type orderingSorter struct {
+	ordering Ordering
+}
+
+func (o orderingSorter) IsLessThan(a, b *lrdd.Row) bool {
+	return o.ordering.Compare(a.Key, b.Key) < 0
+}
+
+func (o orderingSorter) nestedUserTypes() []interface{} {
+	return []interface{}{o.ordering}
+}
+
+func (o orderingSorter) MarshalJSON() ([]byte, error) {
+	return serialization.SerializeStruct(o.ordering)
+}
+
+func (o *orderingSorter) UnmarshalJSON(data []byte) error {
+	v, err := serialization.DeserializeStruct(data)
+	if err != nil {
+		return err
+	}
+	o.ordering = v.(Ordering)
+	return nil
+}
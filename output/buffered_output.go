@@ -5,29 +5,55 @@ import (
 	"github.com/pkg/errors"
 )
 
-// BufferedOutput wraps Output with buffering.
+// BufferedOutput wraps Output with buffering. Rows in the buffer are written to the output at once,
+// which forms a message on push streams. The buffer is handed over to the output on each flush, so that
+// the output can keep the written rows (e.g. for resending them) without copying.
 type BufferedOutput struct {
 	buf    []*lrdd.Row
 	offset int
 	output Output
+
+	// maxBytes limits the total size of the buffered rows. Zero means no limit.
+	maxBytes int
+	bytes    int
 }
 
-func NewBufferedOutput(output Output, size int) *BufferedOutput {
+type BufferedOutputOption func(b *BufferedOutput)
+
+// WithMaxBufferBytes flushes the buffer before the total size of the buffered rows exceeds given bytes.
+// A row larger than the limit is written alone.
+func WithMaxBufferBytes(n int) BufferedOutputOption {
+	return func(b *BufferedOutput) {
+		b.maxBytes = n
+	}
+}
+
+// NewBufferedOutput creates BufferedOutput flushing the buffer once given number of rows are buffered.
+func NewBufferedOutput(output Output, size int, opts ...BufferedOutputOption) *BufferedOutput {
 	if size == 0 {
 		panic("buffer size cannot be 0.")
 	}
-	return &BufferedOutput{
+	b := &BufferedOutput{
 		output: output,
 		buf:    make([]*lrdd.Row, size),
 	}
+	for _, optFn := range opts {
+		optFn(b)
+	}
+	return b
 }
 
 func (b *BufferedOutput) Write(d ...*lrdd.Row) error {
 	// log.Verbose("Start write {} rows (Offset: {}/{})", len(d), b.offset, len(b.buf))
 	for len(d) > 0 {
 		writeLen := min(len(d), len(b.buf)-b.offset)
+		if b.maxBytes > 0 {
+			writeLen = b.fit(d[:writeLen])
+		}
 		b.offset += copy(b.buf[b.offset:], d[:writeLen])
-		if b.offset == len(b.buf) {
+
+		// rows left unwritten means that the buffer is full, either in count or size
+		if b.offset == len(b.buf) || writeLen < len(d) {
 			err := b.Flush()
 			if err != nil {
 				return err
@@ -44,10 +70,28 @@ func (b *BufferedOutput) Flush() error {
 	if err := b.output.Write(b.buf[:b.offset]...); err != nil {
 		return err
 	}
+	if b.offset > 0 {
+		b.buf = make([]*lrdd.Row, len(b.buf))
+	}
 	b.offset = 0
+	b.bytes = 0
 	return nil
 }
 
+// fit returns the number of leading rows which can be buffered without exceeding maxBytes,
+// and adds their size to the buffer. A row is always buffered if the buffer is empty.
+func (b *BufferedOutput) fit(rows []*lrdd.Row) (n int) {
+	for _, r := range rows {
+		size := r.Size()
+		if b.bytes+size > b.maxBytes && b.offset+n > 0 {
+			break
+		}
+		b.bytes += size
+		n++
+	}
+	return n
+}
+
 func (b *BufferedOutput) Close() error {
 	if err := b.Flush(); err != nil {
 		return errors.Wrap(err, "flush")
@@ -1,10 +1,14 @@
 package output
 
 import (
-	"github.com/ab180/lrmr/lrdd"
-	. "github.com/smartystreets/goconvey/convey"
+	"fmt"
 	"strconv"
 	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/gogo/protobuf/proto"
+	. "github.com/smartystreets/goconvey/convey"
 )
 
 const bufSize = 10
@@ -79,6 +83,73 @@ func TestBufferedOutput_Write(t *testing.T) {
 	})
 }
 
+func TestBufferedOutput_WithMaxBufferBytes(t *testing.T) {
+	Convey("Given BufferedOutput with a limit of buffer bytes", t, func() {
+		m := &outputMock{}
+		rowSize := lrdd.Value("0").Size()
+		o := NewBufferedOutput(m, bufSize, WithMaxBufferBytes(rowSize*3))
+
+		Convey("It should flush before the size of buffered rows exceeds the limit", func() {
+			it := items(bufSize)
+			So(o.Write(it...), ShouldBeNil)
+			So(o.Flush(), ShouldBeNil)
+
+			So(m.BatchSizes, ShouldResemble, []int{3, 3, 3, 1})
+			So(m.Rows, ShouldResemble, it)
+		})
+
+		Convey("It should be bounded by the buffer size too", func() {
+			o := NewBufferedOutput(m, 2, WithMaxBufferBytes(rowSize*3))
+			So(o.Write(items(5)...), ShouldBeNil)
+			So(o.Flush(), ShouldBeNil)
+			So(m.BatchSizes, ShouldResemble, []int{2, 2, 1})
+		})
+
+		Convey("A row larger than the limit should be written alone", func() {
+			large := lrdd.KeyValue("large", make([]byte, rowSize*5))
+			So(o.Write(items(1)[0], large, items(1)[0]), ShouldBeNil)
+			So(o.Flush(), ShouldBeNil)
+			So(m.BatchSizes, ShouldResemble, []int{1, 1, 1})
+		})
+	})
+}
+
+func BenchmarkBufferedOutput(b *testing.B) {
+	for _, rowSize := range []int{16, 1024} {
+		for _, bufSize := range []int{10, 100, 1000, 10000} {
+			b.Run(fmt.Sprintf("RowSize=%d/BufferLength=%d", rowSize, bufSize), func(b *testing.B) {
+				rows := make([]*lrdd.Row, 10000)
+				for i := range rows {
+					rows[i] = lrdd.KeyValue(strconv.Itoa(i), make([]byte, rowSize))
+				}
+				o := NewBufferedOutput(&marshallingOutput{}, bufSize)
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := o.Write(rows...); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := o.Flush(); err != nil {
+					b.Fatal(err)
+				}
+			})
+		}
+	}
+}
+
+// marshallingOutput forms messages of push streams from the rows, and discards them.
+type marshallingOutput struct{}
+
+func (marshallingOutput) Write(rows ...*lrdd.Row) error {
+	_, err := proto.Marshal(&lrmrpb.PushDataRequest{Data: rows})
+	return err
+}
+
+func (marshallingOutput) Close() error {
+	return nil
+}
+
 func TestBufferedOutput_Flush(t *testing.T) {
 	Convey("Calling Flush to BufferedOutput", t, func() {
 		m := &outputMock{}
@@ -104,6 +175,20 @@ func TestBufferedOutput_Flush(t *testing.T) {
 				So(m.Rows, ShouldHaveLength, bufSize/2)
 			})
 		})
+
+		Convey("Rows kept by the output should not be overwritten by later writes", func() {
+			r := &retainingOutput{}
+			o := NewBufferedOutput(r, bufSize)
+			first, second := items(bufSize/2), items(bufSize)[bufSize/2:]
+			So(o.Write(first...), ShouldBeNil)
+			So(o.Flush(), ShouldBeNil)
+			So(o.Write(second...), ShouldBeNil)
+			So(o.Flush(), ShouldBeNil)
+
+			So(r.batches, ShouldHaveLength, 2)
+			So(r.batches[0], ShouldResemble, first)
+			So(r.batches[1], ShouldResemble, second)
+		})
 	})
 }
 
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/pkg/errors"
+)
+
+// MuxPushStream is a stream shared by outputs to the tasks on a same host. Rows are sent with
+// their destination task, so that a wide shuffle does not open a stream for every partition.
+type MuxPushStream struct {
+	stream *resumableStream
+
+	// mu guards the stream since the outputs can be written and closed concurrently.
+	mu          sync.Mutex
+	openOutputs int
+	onClose     func()
+}
+
+// OpenMuxPushStream opens a stream pushing rows of the partition fromPartitionID to the tasks on the host.
+func OpenMuxPushStream(ctx context.Context, cluster cluster.Cluster, n *node.Node, fromPartitionID, host string, taskIDs []string, opt ReconnectOptions) (*MuxPushStream, error) {
+	header := &lrmrpb.DataHeader{
+		TaskIDs:         taskIDs,
+		FromPartitionID: fromPartitionID,
+	}
+	stream, err := openResumableStream(ctx, cluster, n, host, header, opt)
+	if err != nil {
+		return nil, err
+	}
+	return &MuxPushStream{
+		stream:      stream,
+		openOutputs: len(taskIDs),
+	}, nil
+}
+
+// Output returns an output sending rows to the task through the stream. The stream is closed
+// after outputs for every task given on OpenMuxPushStream are closed.
+func (m *MuxPushStream) Output(taskID string) Output {
+	return &muxOutput{
+		stream: m,
+		taskID: taskID,
+	}
+}
+
+// OnClose registers a function called after the stream is closed.
+func (m *MuxPushStream) OnClose(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onClose = fn
+}
+
+func (m *MuxPushStream) send(taskID string, data []*lrdd.Row) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stream.Send(&lrmrpb.PushDataRequest{Data: data, TaskID: taskID})
+}
+
+func (m *MuxPushStream) release() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.openOutputs--
+	if m.openOutputs > 0 {
+		return nil
+	}
+	err := m.stream.Close()
+	if m.onClose != nil {
+		m.onClose()
+	}
+	return err
+}
+
+// muxOutput is an output to a task through MuxPushStream.
+type muxOutput struct {
+	stream *MuxPushStream
+	taskID string
+	once   sync.Once
+}
+
+func (o *muxOutput) Write(data ...*lrdd.Row) error {
+	return o.stream.send(o.taskID, data)
+}
+
+func (o *muxOutput) Close() (err error) {
+	o.once.Do(func() {
+		err = o.stream.release()
+	})
+	return errors.Wrapf(err, "close stream to %s", o.taskID)
+}
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"context"
+	"net"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/golang/protobuf/ptypes/empty"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+)
+
+func TestMuxPushStream(t *testing.T) {
+	Convey("Given two hosts running many tasks", t, func() {
+		const numPartitions = 32
+		hosts := []*streamCountingNode{startStreamCountingNode(), startStreamCountingNode()}
+		defer func() {
+			for _, h := range hosts {
+				h.srv.Stop()
+			}
+		}()
+		c := &dialingCluster{}
+
+		Convey("When a partition pushes rows to every task through multiplexed streams", func() {
+			var outputs []Output
+			for i, h := range hosts {
+				var taskIDs []string
+				for p := i; p < numPartitions; p += len(hosts) {
+					taskIDs = append(taskIDs, path.Join("J", "map0", strconv.Itoa(p)))
+				}
+				stream, err := OpenMuxPushStream(context.Background(), c, nil, "0", h.addr, taskIDs, DefaultOptions().Reconnect)
+				So(err, ShouldBeNil)
+				for _, taskID := range taskIDs {
+					outputs = append(outputs, stream.Output(taskID))
+				}
+			}
+			for i, out := range outputs {
+				So(out.Write(lrdd.Value(i)), ShouldBeNil)
+			}
+			for _, out := range outputs {
+				So(out.Close(), ShouldBeNil)
+			}
+
+			Convey("Only one stream should be opened for each host", func() {
+				for _, h := range hosts {
+					So(h.waitForStreamEnd(), ShouldBeTrue)
+					So(h.numStreams(), ShouldEqual, 1)
+				}
+			})
+
+			Convey("Every task should receive its rows", func() {
+				received := make(map[string]int)
+				for _, h := range hosts {
+					So(h.waitForStreamEnd(), ShouldBeTrue)
+					for taskID, n := range h.rowsByTask() {
+						received[taskID] += n
+					}
+				}
+				So(received, ShouldHaveLength, numPartitions)
+				for _, n := range received {
+					So(n, ShouldEqual, 1)
+				}
+			})
+		})
+	})
+}
+
+// streamCountingNode is a node counting PushData streams and rows received by tasks.
+type streamCountingNode struct {
+	lrmrpb.UnimplementedNodeServer
+
+	addr      string
+	srv       *grpc.Server
+	streamEnd chan struct{}
+
+	mu      sync.Mutex
+	streams int
+	rows    map[string]int
+}
+
+func startStreamCountingNode() *streamCountingNode {
+	lis, err := net.Listen("tcp", "127.0.0.1:")
+	So(err, ShouldBeNil)
+
+	n := &streamCountingNode{
+		addr:      lis.Addr().String(),
+		srv:       grpc.NewServer(),
+		streamEnd: make(chan struct{}, 1),
+		rows:      make(map[string]int),
+	}
+	lrmrpb.RegisterNodeServer(n.srv, n)
+	go n.srv.Serve(lis)
+	return n
+}
+
+func (n *streamCountingNode) PushData(stream lrmrpb.Node_PushDataServer) error {
+	n.mu.Lock()
+	n.streams++
+	n.mu.Unlock()
+
+	defer func() { n.streamEnd <- struct{}{} }()
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return stream.SendAndClose(&empty.Empty{})
+		}
+		n.mu.Lock()
+		n.rows[req.TaskID] += len(req.Data)
+		n.mu.Unlock()
+	}
+}
+
+func (n *streamCountingNode) waitForStreamEnd() bool {
+	select {
+	case <-n.streamEnd:
+		return true
+	case <-time.After(5 * time.Second):
+		return false
+	}
+}
+
+func (n *streamCountingNode) numStreams() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.streams
+}
+
+func (n *streamCountingNode) rowsByTask() map[string]int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.rows
+}
+
+// dialingCluster is a cluster connecting hosts without the coordinator.
+type dialingCluster struct {
+	cluster.Cluster
+}
+
+func (dialingCluster) Connect(ctx context.Context, host string) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, host, grpc.WithInsecure())
+}
@@ -5,8 +5,32 @@ import (
 )
 
 type Options struct {
-	BufferLength   int `default:"10000"`
-	MaxSendMsgSize int `default:"2147483647"`
+	// BufferLength is the maximum number of rows batched into a message of push streams.
+	BufferLength int `default:"10000"`
+
+	// BufferBytes is the maximum total size of rows batched into a message of push streams,
+	// so that batches of large rows would not hold too much memory. Zero means no limit.
+	BufferBytes int `default:"4194304"`
+
+	// PoolBatches reuses slices batching rows by their partitions across writes of the tasks, reducing
+	// allocations on high-throughput shuffles.
+	PoolBatches bool `default:"true"`
+
+	// NilRows decides whether nil rows emitted by transformations fail the task ("fail") or are skipped ("skip").
+	NilRows NilRowPolicy `default:"fail"`
+
+	// MaxSendMsgSize is the maximum size of messages sent to other nodes in bytes. It is the same as
+	// the default of Input.MaxRecvSize of workers, so that a message which can be sent can be also received.
+	MaxSendMsgSize int `default:"67108864"`
+
+	// MaxConcurrentConnects limits the number of output streams open at the same time in a worker,
+	// so that a burst of tasks with a wide shuffle would not flood the network. A task waits until slots for
+	// all of its streams are free, and holds them until the streams are closed. Since tasks of successive
+	// stages run together, the limit should cover the streams of every stage of a job running in the worker,
+	// or tasks of a stage would wait for tasks of the next stage to finish. Zero means no limit.
+	MaxConcurrentConnects int `default:"0"`
+
+	Reconnect ReconnectOptions
 }
 
 func DefaultOptions() (o Options) {
@@ -5,6 +5,9 @@ import "github.com/ab180/lrmr/lrdd"
 type outputMock struct {
 	Rows []*lrdd.Row
 
+	// BatchSizes are the number of rows written on each call.
+	BatchSizes []int
+
 	Calls struct {
 		Write int
 		Close int
@@ -13,6 +16,7 @@ type outputMock struct {
 
 func (o *outputMock) Write(rows ...*lrdd.Row) error {
 	o.Rows = append(o.Rows, rows...)
+	o.BatchSizes = append(o.BatchSizes, len(rows))
 	o.Calls.Write += 1
 	return nil
 }
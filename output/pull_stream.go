@@ -1,35 +1,82 @@
 package output
 
 import (
+	"context"
+	"io"
+	"sync"
+
 	"github.com/ab180/lrmr/lrdd"
 )
 
+// PullStream keeps rows of a partition until they are dispatched to the polling task.
 type PullStream struct {
 	queue chan *lrdd.Row
+
+	// released is closed when the poller stops reading the partition.
+	released    chan struct{}
+	releaseOnce sync.Once
 }
 
-func NewPullStream(size int) PullStream {
-	return PullStream{
-		queue: make(chan *lrdd.Row, size),
+func NewPullStream(size int) *PullStream {
+	return &PullStream{
+		queue:    make(chan *lrdd.Row, size),
+		released: make(chan struct{}),
 	}
 }
 
-func (p PullStream) Write(row ...*lrdd.Row) error {
+// Write queues the rows until they are dispatched. Rows written after Release are discarded.
+func (p *PullStream) Write(row ...*lrdd.Row) error {
 	for _, r := range row {
-		p.queue <- r
+		select {
+		case <-p.released:
+			return nil
+		default:
+		}
+		select {
+		case p.queue <- r:
+		case <-p.released:
+			return nil
+		}
 	}
 	return nil
 }
 
-func (p PullStream) Dispatch(n int) []*lrdd.Row {
-	rows := make([]*lrdd.Row, n)
-	for i := 0; i < n; i++ {
-		rows[i] = <-p.queue
+// Dispatch returns up to n queued rows, waiting for them to be written. It returns fewer rows if the stream is
+// closed, and io.EOF if no rows are left. If the context is cancelled, it returns the error of the context.
+func (p *PullStream) Dispatch(ctx context.Context, n int) ([]*lrdd.Row, error) {
+	rows := make([]*lrdd.Row, 0, n)
+	for len(rows) < n {
+		select {
+		case r, ok := <-p.queue:
+			if !ok {
+				if len(rows) == 0 {
+					return nil, io.EOF
+				}
+				return rows, nil
+			}
+			rows = append(rows, r)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return rows, nil
+}
+
+// Release discards the queued rows and the rows written afterwards, as the poller has stopped reading them.
+func (p *PullStream) Release() {
+	p.releaseOnce.Do(func() {
+		close(p.released)
+	})
+	for {
+		select {
+		case <-p.queue:
+		default:
+			return
+		}
 	}
-	return rows
 }
 
-func (p PullStream) Close() error {
+func (p *PullStream) Close() error {
 	close(p.queue)
 	return nil
 }
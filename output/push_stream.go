@@ -2,7 +2,6 @@ package output
 
 import (
 	"context"
-	"io"
 
 	"github.com/ab180/lrmr/cluster"
 	"github.com/ab180/lrmr/cluster/node"
@@ -14,23 +13,27 @@ import (
 )
 
 type PushStream struct {
-	stream lrmrpb.Node_PushDataClient
-	conn   io.Closer
+	stream *resumableStream
 }
 
-func OpenPushStream(ctx context.Context, cluster cluster.Cluster, n *node.Node, host, taskID string) (*PushStream, error) {
-	conn, err := cluster.Connect(ctx, host)
+// OpenPushStream opens a stream pushing rows of the partition fromPartitionID to the task on the host.
+func OpenPushStream(ctx context.Context, cluster cluster.Cluster, n *node.Node, fromPartitionID, host, taskID string, opt ReconnectOptions) (*PushStream, error) {
+	header := &lrmrpb.DataHeader{
+		TaskID:          taskID,
+		FromPartitionID: fromPartitionID,
+	}
+	stream, err := openResumableStream(ctx, cluster, n, host, header, opt)
 	if err != nil {
-		return nil, errors.Wrapf(err, "connect %s", host)
+		return nil, err
 	}
+	return &PushStream{stream: stream}, nil
+}
 
-	header := &lrmrpb.DataHeader{
-		TaskID: taskID,
-	}
-	if n != nil {
-		header.FromHost = n.Host
-	} else {
-		header.FromHost = "master"
+// openPushDataStream connects the host and opens a PushData stream with the header.
+func openPushDataStream(ctx context.Context, cluster cluster.Cluster, host string, header *lrmrpb.DataHeader) (lrmrpb.Node_PushDataClient, error) {
+	conn, err := cluster.Connect(ctx, host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connect %s", host)
 	}
 	rawHead, _ := jsoniter.MarshalToString(header)
 	runCtx := metadata.AppendToOutgoingContext(ctx, "dataHeader", rawHead)
@@ -40,10 +43,7 @@ func OpenPushStream(ctx context.Context, cluster cluster.Cluster, n *node.Node,
 	if err != nil {
 		return nil, errors.Wrapf(err, "open stream to %s", host)
 	}
-	return &PushStream{
-		stream: stream,
-		conn:   conn,
-	}, nil
+	return stream, nil
 }
 
 func (p *PushStream) Write(data ...*lrdd.Row) (err error) {
@@ -51,5 +51,5 @@ func (p *PushStream) Write(data ...*lrdd.Row) (err error) {
 }
 
 func (p *PushStream) Close() error {
-	return p.stream.CloseSend()
+	return p.stream.Close()
 }
@@ -0,0 +1,208 @@
+package output
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReconnectOptions configures reconnection of push streams broken by transient network failures.
+type ReconnectOptions struct {
+	// MaxRetries is the maximum number of reconnection attempts on a failure. Zero disables reconnection.
+	MaxRetries int `default:"5"`
+
+	// Interval is an interval between reconnection attempts, growing linearly on each attempt.
+	Interval time.Duration `default:"200ms"`
+
+	// ResendBufferLength is the number of recently sent requests kept for resending them after reconnection.
+	// Requests sent before them can't be recovered if the receiver has not received them.
+	ResendBufferLength int `default:"64"`
+}
+
+// resumableStream is a PushData stream which reconnects the host on a transient failure, and resumes sending
+// from the request next to the last one received by the host.
+type resumableStream struct {
+	ctx     context.Context
+	cluster cluster.Cluster
+	host    string
+	header  *lrmrpb.DataHeader
+	opt     ReconnectOptions
+
+	stream lrmrpb.Node_PushDataClient
+	seq    int64
+
+	// timer measures encoding of the requests if it is carried by the context opening the stream.
+	timer *serialization.Timer
+
+	// closing is set after every request has been sent, and acked is set when the host acknowledges them.
+	closing bool
+	acked   bool
+
+	// sent are requests recently sent, which may have not been received by the host.
+	sent []*lrmrpb.PushDataRequest
+}
+
+func openResumableStream(ctx context.Context, cluster cluster.Cluster, n *node.Node, host string, header *lrmrpb.DataHeader, opt ReconnectOptions) (*resumableStream, error) {
+	if n != nil {
+		header.FromHost = n.Host
+	} else {
+		header.FromHost = "master"
+	}
+	stream, err := openPushDataStream(ctx, cluster, host, header)
+	if err != nil {
+		return nil, err
+	}
+	return &resumableStream{
+		ctx:     ctx,
+		cluster: cluster,
+		host:    host,
+		header:  header,
+		opt:     opt,
+		stream:  stream,
+		timer:   serialization.TimerFrom(ctx),
+	}, nil
+}
+
+// Send sends the request. The request and its rows are kept for resending without being copied,
+// so the caller must not modify them after sending.
+func (s *resumableStream) Send(req *lrmrpb.PushDataRequest) error {
+	s.seq++
+	req.Seq = s.seq
+	if s.opt.MaxRetries > 0 && s.opt.ResendBufferLength > 0 {
+		if len(s.sent) == s.opt.ResendBufferLength {
+			s.sent = s.sent[1:]
+		}
+		s.sent = append(s.sent, req)
+	}
+	if err := s.send(s.stream, req); err != nil {
+		return s.recover(streamError(s.stream, err))
+	}
+	return nil
+}
+
+// Close closes the stream and waits for the host to acknowledge that every request has been received,
+// since requests sent right before a failure can be lost without an error.
+func (s *resumableStream) Close() error {
+	s.closing = true
+	for !s.acked {
+		if err := s.stream.CloseSend(); err != nil {
+			return errors.Wrapf(err, "close stream to %s", s.host)
+		}
+		err := s.stream.RecvMsg(new(empty.Empty))
+		if err == nil || err == io.EOF {
+			s.acked = true
+			break
+		}
+		if s.ctx.Err() != nil {
+			// the stream is no longer needed; e.g. the job has been already completed
+			return nil
+		}
+		if err := s.recover(err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recover reconnects the host if the error is transient, and resends requests not received by the host.
+func (s *resumableStream) recover(err error) error {
+	for attempt := 1; isTransient(err) && attempt <= s.opt.MaxRetries; attempt++ {
+		log.Warn("Stream to {} broken. Reconnecting (attempt {}/{}): {}", s.host, attempt, s.opt.MaxRetries, err)
+		select {
+		case <-time.After(s.opt.Interval * time.Duration(attempt)):
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+		if err = s.resume(); err == nil {
+			return nil
+		}
+	}
+	return errors.Wrapf(err, "push to %s", s.host)
+}
+
+func (s *resumableStream) resume() error {
+	header := *s.header
+	header.Resume = true
+	stream, err := openPushDataStream(s.ctx, s.cluster, s.host, &header)
+	if err != nil {
+		return errors.Cause(err)
+	}
+	md, err := stream.Header()
+	if err != nil {
+		return streamError(stream, err)
+	}
+	entries := md.Get("lastSeq")
+	if len(entries) == 0 {
+		return streamError(stream, io.EOF)
+	}
+	lastSeq, err := strconv.ParseInt(entries[0], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "parse last sequence")
+	}
+	if closed := md.Get("closed"); len(closed) > 0 && closed[0] == "true" {
+		// the host has finished receiving before acknowledging it
+		if s.closing && lastSeq == s.seq {
+			s.acked = true
+			return nil
+		}
+		return errors.Errorf("stream closed by the receiver after %d/%d requests", lastSeq, s.seq)
+	}
+	if lastSeq < s.seq && (len(s.sent) == 0 || s.sent[0].Seq > lastSeq+1) {
+		return errors.Errorf("requests after %d are no longer kept for resending", lastSeq)
+	}
+	for _, req := range s.sent {
+		if req.Seq <= lastSeq {
+			continue
+		}
+		if err := s.send(stream, req); err != nil {
+			return streamError(stream, err)
+		}
+	}
+	s.stream = stream
+	return nil
+}
+
+// send sends the request to the stream, measuring its encoding with the timer.
+func (s *resumableStream) send(stream lrmrpb.Node_PushDataClient, req *lrmrpb.PushDataRequest) error {
+	if s.timer == nil {
+		return stream.Send(req)
+	}
+	return stream.SendMsg(&timedRequest{PushDataRequest: req, timer: s.timer})
+}
+
+// timedRequest is a PushDataRequest measuring the time spent on encoding it, which is done by gRPC on sending it.
+type timedRequest struct {
+	*lrmrpb.PushDataRequest
+	timer *serialization.Timer
+}
+
+func (r *timedRequest) Marshal() ([]byte, error) {
+	defer r.timer.Since(time.Now())
+	return r.PushDataRequest.Marshal()
+}
+
+// streamError returns an actual error of the broken stream, since a send on the stream only returns io.EOF.
+func streamError(stream lrmrpb.Node_PushDataClient, err error) error {
+	if err != io.EOF {
+		return err
+	}
+	if err := stream.RecvMsg(new(empty.Empty)); err != nil && err != io.EOF {
+		return err
+	}
+	return errors.New("stream closed by the receiver")
+}
+
+// isTransient returns true if the error can be recovered by reconnection.
+func isTransient(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
@@ -0,0 +1,187 @@
+package output_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/input"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/ab180/lrmr/output"
+	"github.com/golang/protobuf/ptypes/empty"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+)
+
+func TestPushStream_Reconnect(t *testing.T) {
+	Convey("Given a push stream to a host behind an unstable network", t, func() {
+		const numRows = 100
+
+		reader := input.NewReader(numRows)
+		n := startResumableNode(reader)
+		defer n.srv.Stop()
+		proxy := startCuttableProxy(n.addr)
+		defer proxy.Close()
+
+		opt := output.DefaultOptions().Reconnect
+		opt.Interval = 50 * time.Millisecond
+		stream, err := output.OpenPushStream(context.Background(), &directCluster{}, nil, "0", proxy.addr, "J/map0/0", opt)
+		So(err, ShouldBeNil)
+
+		// rows are sent through a buffer like the workers do
+		out := output.NewBufferedOutput(stream, 8)
+
+		Convey("When the connection is killed and restored in the middle of the stream", func() {
+			for i := 0; i < numRows; i++ {
+				if i == numRows/2 {
+					// let the rows be delivered before the failure. the failure is noticed after more requests
+					// are sent, which need to be resent from the buffer after reconnecting
+					time.Sleep(100 * time.Millisecond)
+					proxy.cut()
+				}
+				So(out.Write(lrdd.Value(i)), ShouldBeNil)
+			}
+			So(out.Close(), ShouldBeNil)
+
+			Convey("Every row should be received once and in order", func() {
+				var received []int
+				timeout := time.After(5 * time.Second)
+			recv:
+				for {
+					select {
+					case rows, ok := <-reader.C:
+						if !ok {
+							break recv
+						}
+						for _, row := range rows {
+							var v int
+							row.UnmarshalValue(&v)
+							received = append(received, v)
+						}
+					case <-timeout:
+						break recv
+					}
+				}
+				So(received, ShouldHaveLength, numRows)
+				for i, v := range received {
+					So(v, ShouldEqual, i)
+				}
+				So(proxy.numConns(), ShouldBeGreaterThan, 1)
+			})
+		})
+	})
+}
+
+// resumableNode is a node receiving a push stream into the reader, which can be resumed.
+type resumableNode struct {
+	lrmrpb.UnimplementedNodeServer
+
+	addr    string
+	srv     *grpc.Server
+	reader  *input.Reader
+	resumer *input.Resumer
+}
+
+func startResumableNode(reader *input.Reader) *resumableNode {
+	lis, err := net.Listen("tcp", "127.0.0.1:")
+	So(err, ShouldBeNil)
+
+	n := &resumableNode{
+		addr:    lis.Addr().String(),
+		srv:     grpc.NewServer(),
+		reader:  reader,
+		resumer: input.NewResumer(2 * time.Second),
+	}
+	lrmrpb.RegisterNodeServer(n.srv, n)
+	go n.srv.Serve(lis)
+	return n
+}
+
+func (n *resumableNode) PushData(stream lrmrpb.Node_PushDataServer) error {
+	h, err := lrmrpb.DataHeaderFromMetadata(stream)
+	if err != nil {
+		return err
+	}
+	if h.Resume {
+		if err := n.resumer.Resume(stream.Context(), input.StreamKey(h), stream); err != nil {
+			return err
+		}
+	} else if err := input.NewPushStream(n.reader, stream, h, n.resumer).Dispatch(context.Background()); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&empty.Empty{})
+}
+
+// cuttableProxy relays TCP connections to the target, which can be killed at once.
+type cuttableProxy struct {
+	net.Listener
+	addr   string
+	target string
+
+	mu       sync.Mutex
+	conns    []net.Conn
+	accepted int
+}
+
+func startCuttableProxy(target string) *cuttableProxy {
+	lis, err := net.Listen("tcp", "127.0.0.1:")
+	So(err, ShouldBeNil)
+
+	p := &cuttableProxy{Listener: lis, addr: lis.Addr().String(), target: target}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			upstream, err := net.Dial("tcp", target)
+			if err != nil {
+				_ = conn.Close()
+				continue
+			}
+			p.mu.Lock()
+			p.conns = append(p.conns, conn, upstream)
+			p.accepted++
+			p.mu.Unlock()
+
+			go func() { _, _ = io.Copy(upstream, conn) }()
+			go func() { _, _ = io.Copy(conn, upstream) }()
+		}
+	}()
+	return p
+}
+
+// cut kills every connection relayed. New connections are still accepted.
+func (p *cuttableProxy) cut() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.conns = nil
+}
+
+func (p *cuttableProxy) numConns() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.accepted
+}
+
+func (p *cuttableProxy) Close() error {
+	p.cut()
+	return p.Listener.Close()
+}
+
+// directCluster is a cluster connecting hosts without the coordinator.
+type directCluster struct {
+	cluster.Cluster
+}
+
+func (directCluster) Connect(ctx context.Context, host string) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, host, grpc.WithInsecure())
+}
@@ -1,30 +1,78 @@
 package output
 
 import (
+	"context"
+	"sync"
+
 	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/partitions"
 	"github.com/pkg/errors"
 )
 
+// NilRowPolicy decides how the writer handles nil rows, e.g. emitted by a transformation by mistake.
+type NilRowPolicy string
+
+const (
+	// FailOnNilRow fails the write with ErrNilRow. It is the default policy.
+	FailOnNilRow NilRowPolicy = "fail"
+
+	// SkipNilRows drops nil rows, logging them in debug level.
+	SkipNilRows NilRowPolicy = "skip"
+)
+
+// ErrNilRow is returned when writing a nil row with FailOnNilRow.
+var ErrNilRow = errors.New("nil row written to output")
+
 type Writer struct {
 	context     partitions.Context
 	partitioner partitions.Partitioner
 	isPreserved bool
+	nilRows     NilRowPolicy
 
 	// outputs is a mapping of partition ID to an output.
 	outputs map[string]Output
+
+	// pooled reuses the batches of rows partitioned by their partition IDs across writes.
+	pooled bool
+}
+
+type WriterOption func(w *Writer)
+
+// WithPooledBatches reuses slices batching the partitioned rows, taken from a pool shared by the writers.
+// Only the batches written to BufferedOutput, which copies the rows into its buffer, are returned to the pool.
+func WithPooledBatches() WriterOption {
+	return func(w *Writer) {
+		w.pooled = true
+	}
 }
 
-func NewWriter(partitionID string, p partitions.Partitioner, outputs map[string]Output) *Writer {
-	return &Writer{
+// WithNilRowPolicy sets how the writer handles nil rows. FailOnNilRow is used by default.
+func WithNilRowPolicy(p NilRowPolicy) WriterOption {
+	return func(w *Writer) {
+		w.nilRows = p
+	}
+}
+
+func NewWriter(partitionID string, p partitions.Partitioner, outputs map[string]Output, opts ...WriterOption) *Writer {
+	w := &Writer{
 		context:     partitions.NewContext(partitionID),
 		partitioner: p,
 		isPreserved: partitions.IsPreserved(p),
 		outputs:     outputs,
 	}
+	for _, optFn := range opts {
+		optFn(w)
+	}
+	return w
 }
 
+// Write partitions the rows and writes them to the outputs of their partitions.
+// It is safe to be called concurrently if the outputs are.
 func (w *Writer) Write(data ...*lrdd.Row) error {
+	data, err := w.checkNilRows(data)
+	if err != nil {
+		return err
+	}
 	if w.isPreserved {
 		output := w.outputs[w.context.PartitionID()]
 		if output == nil {
@@ -33,7 +81,15 @@ func (w *Writer) Write(data ...*lrdd.Row) error {
 		}
 		return output.Write(data...)
 	}
-	writes := make(map[string][]*lrdd.Row)
+	// batches are taken for each write, so that concurrent writes would not share them
+	var writes map[string][]*lrdd.Row
+	if w.pooled {
+		writes = batchesPool.Get().(map[string][]*lrdd.Row)
+	} else {
+		writes = make(map[string][]*lrdd.Row)
+	}
+	defer w.recycle(writes)
+
 	for _, row := range data {
 		id, err := w.partitioner.DeterminePartition(w.context, row, len(w.outputs))
 		if err != nil {
@@ -43,7 +99,11 @@ func (w *Writer) Write(data ...*lrdd.Row) error {
 			}
 			return err
 		}
-		writes[id] = append(writes[id], row)
+		batch, ok := writes[id]
+		if !ok && w.pooled {
+			batch = getBatch()
+		}
+		writes[id] = append(batch, row)
 	}
 	for id, rows := range writes {
 		out, ok := w.outputs[id]
@@ -57,27 +117,102 @@ func (w *Writer) Write(data ...*lrdd.Row) error {
 	return nil
 }
 
-func (w *Writer) Dispatch(taskID string, n int) ([]*lrdd.Row, error) {
-	o, ok := w.outputs[taskID]
+// checkNilRows returns the rows without nil rows if they are skipped, or ErrNilRow otherwise.
+// The rows are copied only if there is a nil row.
+func (w *Writer) checkNilRows(data []*lrdd.Row) ([]*lrdd.Row, error) {
+	for i, row := range data {
+		if row != nil {
+			continue
+		}
+		if w.nilRows != SkipNilRows {
+			return nil, errors.Wrapf(ErrNilRow, "partition %s", w.context.PartitionID())
+		}
+		nonNil := make([]*lrdd.Row, i, len(data)-1)
+		copy(nonNil, data[:i])
+		for _, r := range data[i+1:] {
+			if r != nil {
+				nonNil = append(nonNil, r)
+			}
+		}
+		log.Debug("Skipped {} nil rows written to partition {}.", len(data)-len(nonNil), w.context.PartitionID())
+		return nonNil, nil
+	}
+	return data, nil
+}
+
+// recycle empties the pooled batches after a write, returning the ones not retained by outputs to the pool.
+func (w *Writer) recycle(writes map[string][]*lrdd.Row) {
+	if !w.pooled {
+		return
+	}
+	for id, rows := range writes {
+		if _, copied := w.outputs[id].(*BufferedOutput); copied {
+			putBatch(rows)
+		}
+		delete(writes, id)
+	}
+	batchesPool.Put(writes)
+}
+
+// batchesPool is a pool of map[string][]*lrdd.Row, holding batches of a write by their partition IDs.
+var batchesPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[string][]*lrdd.Row)
+	},
+}
+
+// batchPool is a pool of *[]*lrdd.Row, partitioning rows on writes.
+var batchPool = sync.Pool{
+	New: func() interface{} {
+		return new([]*lrdd.Row)
+	},
+}
+
+func getBatch() []*lrdd.Row {
+	return *batchPool.Get().(*[]*lrdd.Row)
+}
+
+func putBatch(rows []*lrdd.Row) {
+	// references are cleared so that the pool would not keep the rows alive
+	for i := range rows {
+		rows[i] = nil
+	}
+	rows = rows[:0]
+	batchPool.Put(&rows)
+}
+
+// Dispatch returns up to n rows written to the partition, if its output is a PullStream.
+func (w *Writer) Dispatch(ctx context.Context, partitionID string, n int) ([]*lrdd.Row, error) {
+	o, ok := w.outputs[partitionID]
 	if !ok {
-		return nil, errors.Errorf("unknown task %v", taskID)
+		return nil, errors.Errorf("unknown partition %v", partitionID)
 	}
-	if p, ok := o.(PullStream); ok {
-		return p.Dispatch(n), nil
+	if p, ok := o.(*PullStream); ok {
+		return p.Dispatch(ctx, n)
 	}
 	return nil, nil
 }
 
+// Release frees rows kept for the partition, if its output is a PullStream.
+// Rows written to the partition afterwards are discarded.
+func (w *Writer) Release(partitionID string) {
+	if p, ok := w.outputs[partitionID].(*PullStream); ok {
+		p.Release()
+	}
+}
+
 func (w Writer) NumOutputs() int {
 	return len(w.outputs)
 }
 
+// Close flushes and closes every output. The first error occurred is returned,
+// but the rest of outputs are still closed.
 func (w *Writer) Close() (err error) {
-	for _, out := range w.outputs {
-		if e := out.Close(); e == nil {
-			err = e
+	for id, out := range w.outputs {
+		if e := out.Close(); e != nil && err == nil {
+			err = errors.Wrapf(e, "close output to partition %s", id)
 		}
 	}
 	w.outputs = nil
-	return nil
+	return err
 }
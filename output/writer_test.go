@@ -0,0 +1,212 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriter_WithPooledBatches(t *testing.T) {
+	Convey("Given a writer with pooled batches", t, func() {
+		keys := []string{"a", "b", "c"}
+		buffered := map[string]*outputMock{"a": {}, "b": {}}
+		retaining := &retainingOutput{}
+		outputs := map[string]Output{
+			"a": NewBufferedOutput(buffered["a"], 7),
+			"b": NewBufferedOutput(buffered["b"], 7),
+			"c": retaining,
+		}
+		w := NewWriter("0", partitions.NewFiniteKeyPartitioner(keys), outputs, WithPooledBatches())
+
+		Convey("When writing rows in many batches", func() {
+			const numBatches, batchSize = 50, 9
+			for i := 0; i < numBatches; i++ {
+				var rows []*lrdd.Row
+				for j := 0; j < batchSize; j++ {
+					rows = append(rows, lrdd.KeyValue(keys[(i+j)%len(keys)], i*batchSize+j))
+				}
+				So(w.Write(rows...), ShouldBeNil)
+			}
+			So(w.Close(), ShouldBeNil)
+
+			Convey("Every row should be delivered to its partition in order", func() {
+				received := map[string][]*lrdd.Row{
+					"a": buffered["a"].Rows,
+					"b": buffered["b"].Rows,
+					"c": retaining.rows(),
+				}
+				total := 0
+				for key, rows := range received {
+					prev := -1
+					for _, row := range rows {
+						So(row.Key, ShouldEqual, key)
+
+						var n int
+						row.UnmarshalValue(&n)
+						So(n, ShouldBeGreaterThan, prev)
+						prev = n
+					}
+					total += len(rows)
+				}
+				So(total, ShouldEqual, numBatches*batchSize)
+			})
+
+			Convey("Batches retained by outputs not copying rows should not be reused", func() {
+				for _, batch := range retaining.batches {
+					for _, row := range batch {
+						So(row, ShouldNotBeNil)
+						So(row.Key, ShouldEqual, "c")
+					}
+				}
+			})
+		})
+	})
+}
+
+func TestWriter_ConcurrentWrites(t *testing.T) {
+	Convey("Given a writer with pooled batches", t, func() {
+		keys := []string{"a", "b", "c"}
+		outputs := make(map[string]Output)
+		counters := make(map[string]*countingOutput)
+		for _, key := range keys {
+			counters[key] = &countingOutput{}
+			outputs[key] = counters[key]
+		}
+		w := NewWriter("0", partitions.NewFiniteKeyPartitioner(keys), outputs, WithPooledBatches())
+
+		Convey("Rows written concurrently should be delivered to their partitions", func() {
+			const numWriters, numWrites = 8, 500
+			var wg sync.WaitGroup
+			for i := 0; i < numWriters; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < numWrites; j++ {
+						_ = w.Write(lrdd.KeyValue("a", j), lrdd.KeyValue("b", j), lrdd.KeyValue("c", j))
+					}
+				}()
+			}
+			wg.Wait()
+
+			for _, key := range keys {
+				So(counters[key].count(key), ShouldEqual, numWriters*numWrites)
+			}
+		})
+	})
+}
+
+func BenchmarkWriter_Write(b *testing.B) {
+	const numPartitions, batchSize = 16, 1000
+	rows := make([]*lrdd.Row, batchSize)
+	for i := range rows {
+		rows[i] = lrdd.KeyValue(strconv.Itoa(i), i)
+	}
+	for _, pooled := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Pooled=%v", pooled), func(b *testing.B) {
+			outputs := make(map[string]Output, numPartitions)
+			for i := 0; i < numPartitions; i++ {
+				outputs[strconv.Itoa(i)] = NewBufferedOutput(discardOutput{}, batchSize)
+			}
+			var opts []WriterOption
+			if pooled {
+				opts = append(opts, WithPooledBatches())
+			}
+			w := NewWriter("0", partitions.NewHashKeyPartitioner(), outputs, opts...)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := w.Write(rows...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// retainingOutput keeps the slices given to Write as they are, like LocalPipe does.
+type retainingOutput struct {
+	batches [][]*lrdd.Row
+}
+
+func (r *retainingOutput) Write(rows ...*lrdd.Row) error {
+	r.batches = append(r.batches, rows)
+	return nil
+}
+
+func (r *retainingOutput) rows() (rows []*lrdd.Row) {
+	for _, batch := range r.batches {
+		rows = append(rows, batch...)
+	}
+	return rows
+}
+
+func (r *retainingOutput) Close() error {
+	return nil
+}
+
+// countingOutput counts rows by their keys. It is safe for concurrent use.
+type countingOutput struct {
+	counts map[string]int
+	mu     sync.Mutex
+}
+
+func (c *countingOutput) Write(rows ...*lrdd.Row) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	for _, row := range rows {
+		c.counts[row.Key]++
+	}
+	return nil
+}
+
+func (c *countingOutput) count(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[key]
+}
+
+func (c *countingOutput) Close() error {
+	return nil
+}
+
+type discardOutput struct{}
+
+func (discardOutput) Write(...*lrdd.Row) error { return nil }
+func (discardOutput) Close() error             { return nil }
+
+func TestWriter_WithNilRowPolicy(t *testing.T) {
+	Convey("Given rows with a nil row", t, func() {
+		rows := []*lrdd.Row{lrdd.KeyValue("a", 1), nil, lrdd.KeyValue("b", 2)}
+		out := &outputMock{}
+		outputs := map[string]Output{"a": out, "b": out}
+
+		Convey("Writing them with FailOnNilRow should fail", func() {
+			w := NewWriter("0", partitions.NewFiniteKeyPartitioner([]string{"a", "b"}), outputs, WithNilRowPolicy(FailOnNilRow))
+			err := w.Write(rows...)
+			So(errors.Cause(err), ShouldEqual, ErrNilRow)
+			So(out.Rows, ShouldBeEmpty)
+		})
+
+		Convey("Writing them without a policy should fail", func() {
+			w := NewWriter("0", partitions.NewPreservePartitioner(), map[string]Output{"0": out})
+			So(errors.Cause(w.Write(rows...)), ShouldEqual, ErrNilRow)
+		})
+
+		Convey("Writing them with SkipNilRows should write the others", func() {
+			w := NewWriter("0", partitions.NewFiniteKeyPartitioner([]string{"a", "b"}), outputs, WithNilRowPolicy(SkipNilRows))
+			So(w.Write(rows...), ShouldBeNil)
+			So(out.Rows, ShouldHaveLength, 2)
+			So(rows[1], ShouldBeNil)
+		})
+	})
+}
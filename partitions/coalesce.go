@@ -0,0 +1,59 @@
+package partitions
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr/lrdd"
+)
+
+// CoalescingPartitioner merges partitions of the current stage into fewer partitions of the next stage,
+// to reduce overhead of tasks with tiny inputs. Every row of a partition goes to the same partition,
+// so rows grouped by their keys in the current stage are still grouped in the next stage.
+type CoalescingPartitioner struct {
+	NumPartitions int
+
+	// Targets are IDs of the partitions in the next stage which each partition is merged into.
+	// They are planned by PlanNextFrom.
+	Targets map[string]string
+}
+
+// NewCoalescingPartitioner creates a CoalescingPartitioner merging partitions into given number of partitions.
+func NewCoalescingPartitioner(numPartitions int) Partitioner {
+	if numPartitions < 1 {
+		numPartitions = 1
+	}
+	return &CoalescingPartitioner{NumPartitions: numPartitions}
+}
+
+func (c *CoalescingPartitioner) PlanNext(numExecutors int) []Partition {
+	if numExecutors < c.NumPartitions {
+		return PlanForNumberOf(numExecutors)
+	}
+	return PlanForNumberOf(c.NumPartitions)
+}
+
+// PlanNextFrom merges contiguous partitions of the current stage evenly.
+func (c *CoalescingPartitioner) PlanNextFrom(current []Partition) []Partition {
+	n := c.NumPartitions
+	if n > len(current) {
+		n = len(current)
+	}
+	c.Targets = make(map[string]string, len(current))
+	for i, p := range current {
+		c.Targets[p.ID] = strconv.Itoa(i * n / len(current))
+	}
+	return PlanForNumberOf(n)
+}
+
+func (c *CoalescingPartitioner) DeterminePartition(ctx Context, _ *lrdd.Row, _ int) (id string, err error) {
+	return c.TargetOf(ctx.PartitionID())
+}
+
+// TargetOf returns an ID of the partition in the next stage which given partition is merged into.
+func (c *CoalescingPartitioner) TargetOf(partitionID string) (string, error) {
+	id, ok := c.Targets[partitionID]
+	if !ok {
+		return "", ErrNoOutput
+	}
+	return id, nil
+}
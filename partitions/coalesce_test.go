@@ -0,0 +1,31 @@
+package partitions
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCoalescingPartitioner(t *testing.T) {
+	Convey("Given 10 partitions", t, func() {
+		current := PlanForNumberOf(10)
+
+		Convey("Coalescing them into 4 partitions should merge contiguous partitions evenly", func() {
+			p := NewCoalescingPartitioner(4).(*CoalescingPartitioner)
+			So(p.PlanNextFrom(current), ShouldHaveLength, 4)
+
+			loads := make(map[string]int)
+			for _, c := range current {
+				target, err := p.TargetOf(c.ID)
+				So(err, ShouldBeNil)
+				loads[target]++
+			}
+			So(loads, ShouldResemble, map[string]int{"0": 3, "1": 2, "2": 3, "3": 2})
+		})
+
+		Convey("Coalescing them into more partitions should keep them", func() {
+			p := NewCoalescingPartitioner(20).(*CoalescingPartitioner)
+			So(p.PlanNextFrom(current), ShouldHaveLength, 10)
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package partitions
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHashFunc_Sum64(t *testing.T) {
+	Convey("Hash functions should produce the same hashes with their reference implementations", t, func() {
+		cases := []struct {
+			hash     HashFunc
+			key      string
+			expected uint64
+		}{
+			{Murmur3, "", 0},
+			{Murmur3, "hello", 0x248bfa47},
+			{Murmur3, "The quick brown fox jumps over the lazy dog", 0x2e4ff723},
+			{XXHash, "", 0xef46db3751d8e999},
+			{XXHash, "abc", 0x44bc2cf5ad770999},
+			{XXHash, "The quick brown fox jumps over the lazy dog", 0x0b242d361fda71bc},
+		}
+		for _, c := range cases {
+			sum, err := c.hash.Sum64(c.key)
+			So(err, ShouldBeNil)
+			So(sum, ShouldEqual, c.expected)
+		}
+	})
+
+	Convey("Unknown hash function should fail", t, func() {
+		_, err := HashFunc("sha0").Sum64("key")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestHashKeyPartitioner_WithHash(t *testing.T) {
+	Convey("Given a hash key partitioner with a chosen hash function", t, func() {
+		const numOutputs = 7
+		onMaster := NewHashKeyPartitionerWithHash(Murmur3)
+
+		Convey("When it is reconstructed on a worker", func() {
+			data, err := jsoniter.Marshal(WrapPartitioner(onMaster))
+			So(err, ShouldBeNil)
+
+			var onWorker SerializablePartitioner
+			So(jsoniter.Unmarshal(data, &onWorker), ShouldBeNil)
+
+			Convey("A key should be mapped to the same partition", func() {
+				differsFromDefault := false
+				for i := 0; i < 100; i++ {
+					row := lrdd.KeyValue("key"+strconv.Itoa(i), i)
+					expected, err := onMaster.DeterminePartition(nil, row, numOutputs)
+					So(err, ShouldBeNil)
+
+					actual, err := onWorker.DeterminePartition(nil, row, numOutputs)
+					So(err, ShouldBeNil)
+					So(actual, ShouldEqual, expected)
+
+					byDefault, _ := NewHashKeyPartitioner().DeterminePartition(nil, row, numOutputs)
+					differsFromDefault = differsFromDefault || byDefault != expected
+				}
+				So(differsFromDefault, ShouldBeTrue)
+			})
+		})
+	})
+}
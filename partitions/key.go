@@ -0,0 +1,206 @@
+package partitions
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidKey is returned when a partition ID can't be parsed into a Key.
+var ErrInvalidKey = errors.New("invalid partition key")
+
+// Key is a typed partition key composed of strings, integers, floats and booleans. Since partition IDs are
+// strings throughout the cluster, a key is encoded into a partition ID by ID, which can be parsed back with ParseKey.
+// The encoding is deterministic, so that equal keys always have the same ID.
+type Key struct {
+	parts []interface{}
+}
+
+// NewKey creates a Key with given parts. Integers are stored as int64 (or uint64 for unsigned ones),
+// and floats as float64. It returns an error if a part is not a string, an integer, a float or a bool.
+func NewKey(parts ...interface{}) (Key, error) {
+	if len(parts) == 0 {
+		return Key{}, nil
+	}
+	k := Key{parts: make([]interface{}, len(parts))}
+	for i, p := range parts {
+		switch v := p.(type) {
+		case string, int64, uint64, float64, bool:
+			k.parts[i] = v
+		case int:
+			k.parts[i] = int64(v)
+		case int8:
+			k.parts[i] = int64(v)
+		case int16:
+			k.parts[i] = int64(v)
+		case int32:
+			k.parts[i] = int64(v)
+		case uint:
+			k.parts[i] = uint64(v)
+		case uint8:
+			k.parts[i] = uint64(v)
+		case uint16:
+			k.parts[i] = uint64(v)
+		case uint32:
+			k.parts[i] = uint64(v)
+		case float32:
+			k.parts[i] = float64(v)
+		default:
+			return Key{}, errors.Errorf("unsupported type of partition key part #%d: %T", i, p)
+		}
+	}
+	return k, nil
+}
+
+// MustKey is like NewKey, but panics on unsupported parts.
+func MustKey(parts ...interface{}) Key {
+	k, err := NewKey(parts...)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// Parts returns the parts of the key.
+func (k Key) Parts() []interface{} {
+	return k.parts
+}
+
+// ID encodes the key into a partition ID. Each part is tagged with its type and joined with commas,
+// escaping characters which can't be used in the IDs (e.g. "s:KR,i:2020").
+func (k Key) ID() string {
+	encoded := make([]string, len(k.parts))
+	for i, p := range k.parts {
+		switch v := p.(type) {
+		case string:
+			encoded[i] = "s:" + keyEscaper.Replace(v)
+		case int64:
+			encoded[i] = "i:" + strconv.FormatInt(v, 10)
+		case uint64:
+			encoded[i] = "u:" + strconv.FormatUint(v, 10)
+		case float64:
+			encoded[i] = "f:" + strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			encoded[i] = "b:" + strconv.FormatBool(v)
+		}
+	}
+	return strings.Join(encoded, ",")
+}
+
+func (k Key) String() string {
+	return k.ID()
+}
+
+// Equal returns true if two keys have the same parts.
+func (k Key) Equal(o Key) bool {
+	return k.ID() == o.ID()
+}
+
+// keyEscaper escapes separators of the parts, and slashes which are separators of the keys in the coordinator.
+var keyEscaper = strings.NewReplacer("%", "%25", ",", "%2C", "/", "%2F")
+
+// ParseKey decodes the partition ID encoded by Key.ID.
+func ParseKey(id string) (Key, error) {
+	if id == "" {
+		return Key{}, nil
+	}
+	encoded := strings.Split(id, ",")
+	k := Key{parts: make([]interface{}, len(encoded))}
+	for i, e := range encoded {
+		if len(e) < 2 || e[1] != ':' {
+			return Key{}, errors.Wrapf(ErrInvalidKey, "%q", id)
+		}
+		var err error
+		switch v := e[2:]; e[0] {
+		case 's':
+			k.parts[i], err = url.PathUnescape(v)
+		case 'i':
+			k.parts[i], err = strconv.ParseInt(v, 10, 64)
+		case 'u':
+			k.parts[i], err = strconv.ParseUint(v, 10, 64)
+		case 'f':
+			k.parts[i], err = strconv.ParseFloat(v, 64)
+		case 'b':
+			k.parts[i], err = strconv.ParseBool(v)
+		default:
+			err = errors.Errorf("unknown type tag %q", e[0])
+		}
+		if err != nil {
+			return Key{}, errors.Wrapf(ErrInvalidKey, "%q: %v", id, err)
+		}
+	}
+	return k, nil
+}
+
+func (k Key) MarshalJSON() ([]byte, error) {
+	return jsoniter.Marshal(k.ID())
+}
+
+func (k *Key) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := jsoniter.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	parsed, err := ParseKey(id)
+	if err != nil {
+		return err
+	}
+	*k = parsed
+	return nil
+}
+
+// TypedPartitioner is like Partitioner, but plans and determines partitions with typed keys.
+// It can be used as a Partitioner with WithTypedKeys.
+type TypedPartitioner interface {
+	PlanNextKeys(numExecutors int) []Key
+	DetermineKey(c Context, r *lrdd.Row, numOutputs int) (Key, error)
+}
+
+type typedKeyPartitioner struct {
+	Partitioner TypedPartitioner
+}
+
+// WithTypedKeys adapts the TypedPartitioner into a Partitioner, whose partition IDs are encoded from the keys.
+// Tasks can get the key of their partitions by ParseKey with the partition ID.
+func WithTypedKeys(p TypedPartitioner) Partitioner {
+	return &typedKeyPartitioner{Partitioner: p}
+}
+
+func (t *typedKeyPartitioner) PlanNext(numExecutors int) []Partition {
+	keys := t.Partitioner.PlanNextKeys(numExecutors)
+	planned := make([]Partition, len(keys))
+	for i, k := range keys {
+		planned[i] = Partition{ID: k.ID()}
+	}
+	return planned
+}
+
+func (t *typedKeyPartitioner) DeterminePartition(c Context, r *lrdd.Row, numOutputs int) (id string, err error) {
+	k, err := t.Partitioner.DetermineKey(c, r, numOutputs)
+	if err != nil {
+		return "", err
+	}
+	return k.ID(), nil
+}
+
+func (t typedKeyPartitioner) MarshalJSON() ([]byte, error) {
+	return serialization.SerializeStruct(t.Partitioner)
+}
+
+func (t *typedKeyPartitioner) UnmarshalJSON(data []byte) error {
+	v, err := serialization.DeserializeStruct(data)
+	if err != nil {
+		return err
+	}
+	p, ok := v.(TypedPartitioner)
+	if !ok {
+		return errors.Errorf("%T is not a TypedPartitioner", v)
+	}
+	t.Partitioner = p
+	return nil
+}
@@ -0,0 +1,103 @@
+package partitions
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestKey(t *testing.T) {
+	Convey("Given typed keys", t, func() {
+		keys := []Key{
+			MustKey("KR", 2020),
+			MustKey("US", int64(-1), uint(7), 1.5, true),
+			MustKey("a,b/c%d:e", ""),
+			MustKey(),
+		}
+
+		Convey("They should be parsed back from their IDs", func() {
+			for _, k := range keys {
+				parsed, err := ParseKey(k.ID())
+				So(err, ShouldBeNil)
+				So(parsed.Parts(), ShouldResemble, k.Parts())
+				So(parsed.Equal(k), ShouldBeTrue)
+			}
+		})
+
+		Convey("Their IDs should be deterministic", func() {
+			So(MustKey("KR", 2020).ID(), ShouldEqual, "s:KR,i:2020")
+			So(MustKey("KR", int32(2020)).ID(), ShouldEqual, MustKey("KR", int64(2020)).ID())
+			So(MustKey("2020").ID(), ShouldNotEqual, MustKey(2020).ID())
+		})
+
+		Convey("Their IDs should not contain slashes", func() {
+			So(MustKey("a/b").ID(), ShouldNotContainSubstring, "/")
+		})
+
+		Convey("They should be serialized in JSON as their IDs", func() {
+			data, err := jsoniter.Marshal(keys[0])
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, `"s:KR,i:2020"`)
+
+			var k Key
+			So(jsoniter.Unmarshal(data, &k), ShouldBeNil)
+			So(k.Equal(keys[0]), ShouldBeTrue)
+		})
+	})
+
+	Convey("Creating a key with unsupported type should fail", t, func() {
+		_, err := NewKey([]string{"foo"})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Parsing an invalid ID should fail", t, func() {
+		for _, id := range []string{"KR", "x:1", "i:abc", "s:KR,"} {
+			_, err := ParseKey(id)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestWithTypedKeys(t *testing.T) {
+	Convey("Given a partitioner with composite keys", t, func() {
+		p := WithTypedKeys(&regionYearPartitioner{})
+
+		// partitioners are serialized to the workers
+		data, err := jsoniter.Marshal(WrapPartitioner(p))
+		So(err, ShouldBeNil)
+		var deserialized SerializablePartitioner
+		So(jsoniter.Unmarshal(data, &deserialized), ShouldBeNil)
+
+		Convey("Planned partitions should have IDs of the keys", func() {
+			planned := deserialized.PlanNext(4)
+			So(planned, ShouldHaveLength, 4)
+			for i, k := range (&regionYearPartitioner{}).PlanNextKeys(4) {
+				So(planned[i].ID, ShouldEqual, k.ID())
+			}
+		})
+
+		Convey("Rows should be routed to the partitions of their keys", func() {
+			id, err := deserialized.DeterminePartition(nil, lrdd.KeyValue("US", 2021), 4)
+			So(err, ShouldBeNil)
+
+			k, err := ParseKey(id)
+			So(err, ShouldBeNil)
+			So(k.Parts(), ShouldResemble, []interface{}{"US", int64(2021)})
+		})
+	})
+}
+
+// regionYearPartitioner partitions rows by their keys as regions and their values as years.
+type regionYearPartitioner struct{}
+
+func (r *regionYearPartitioner) PlanNextKeys(int) []Key {
+	return []Key{MustKey("KR", 2020), MustKey("KR", 2021), MustKey("US", 2020), MustKey("US", 2021)}
+}
+
+func (r *regionYearPartitioner) DetermineKey(_ Context, row *lrdd.Row, _ int) (Key, error) {
+	var year int
+	row.UnmarshalValue(&year)
+	return NewKey(row.Key, year)
+}
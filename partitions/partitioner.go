@@ -2,22 +2,41 @@ package partitions
 
 import (
 	"errors"
+	"sort"
 	"strconv"
 
 	"github.com/ab180/lrmr/internal/serialization"
 	"github.com/ab180/lrmr/lrdd"
-	"github.com/segmentio/fasthash/fnv1a"
 )
 
 // ErrNoOutput is returned by Partitioner.DeterminePartition when there's no
 // corresponding partition found with the key of given row.
 var ErrNoOutput = errors.New("no output")
 
+// built-in partitioners are registered, so that jobs using them pass the check of registered types.
+var _ = []serialization.Type{
+	serialization.Register(&FiniteKeyPartitioner{}),
+	serialization.Register(&hashKeyPartitioner{}),
+	serialization.Register(&ShuffledPartitioner{}),
+	serialization.Register(&PreservePartitioner{}),
+	serialization.Register(&masterAssigner{}),
+	serialization.Register(&typedKeyPartitioner{}),
+	serialization.Register(&CoalescingPartitioner{}),
+	serialization.Register(&SkewedKeyPartitioner{}),
+}
+
 type Partitioner interface {
 	PlanNext(numExecutors int) []Partition
 	DeterminePartition(c Context, r *lrdd.Row, numOutputs int) (id string, err error)
 }
 
+// MirroringPartitioner is a Partitioner which plans the next stage with the same set of partitions
+// in the current stage, instead of planning them with the number of executors.
+type MirroringPartitioner interface {
+	Partitioner
+	PlanNextFrom(current []Partition) []Partition
+}
+
 type SerializablePartitioner struct {
 	Partitioner
 }
@@ -33,6 +52,22 @@ func UnwrapPartitioner(p Partitioner) Partitioner {
 	return p
 }
 
+// Wrapped returns the partitioner (or TypedPartitioner) wrapped by given partitioner,
+// or nil if it doesn't wrap any.
+func Wrapped(p interface{}) interface{} {
+	switch w := p.(type) {
+	case SerializablePartitioner:
+		return w.Partitioner
+	case *masterAssigner:
+		return w.Partitioner.Partitioner
+	case masterAssigner:
+		return w.Partitioner.Partitioner
+	case *typedKeyPartitioner:
+		return w.Partitioner
+	}
+	return nil
+}
+
 func (s SerializablePartitioner) MarshalJSON() ([]byte, error) {
 	return serialization.SerializeStruct(s.Partitioner)
 }
@@ -42,10 +77,22 @@ func (s *SerializablePartitioner) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
-	s.Partitioner = v.(Partitioner)
+	if v != nil {
+		s.Partitioner = v.(Partitioner)
+	}
 	return nil
 }
 
+// MarshalBinary encodes the partitioner in JSON, so that non-JSON codecs can
+// keep the type information of the partitioner.
+func (s SerializablePartitioner) MarshalBinary() ([]byte, error) {
+	return s.MarshalJSON()
+}
+
+func (s *SerializablePartitioner) UnmarshalBinary(data []byte) error {
+	return s.UnmarshalJSON(data)
+}
+
 // PlanForNumberOf creates partition for the number of executors.
 // It uses its index number for each partition's ID.
 func PlanForNumberOf(numExecutors int) []Partition {
@@ -73,8 +120,14 @@ func NewFiniteKeyPartitioner(keys []string) Partitioner {
 }
 
 // PlanNext creates partitions for the number of keys. Uses row key as partition ID.
+// Partitions are sorted by the keys, so that they are scheduled in the same order on every run.
 func (f *FiniteKeyPartitioner) PlanNext(int) (partitions []Partition) {
+	keys := make([]string, 0, len(f.KeySet))
 	for key := range f.KeySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
 		partitions = append(partitions, Partition{
 			ID:        key,
 			IsElastic: false,
@@ -91,19 +144,30 @@ func (f *FiniteKeyPartitioner) DeterminePartition(c Context, r *lrdd.Row, numOut
 	return r.Key, nil
 }
 
-type hashKeyPartitioner struct{}
+type hashKeyPartitioner struct {
+	Hash HashFunc `json:"hash,omitempty"`
+}
 
 func NewHashKeyPartitioner() Partitioner {
 	return &hashKeyPartitioner{}
 }
 
+// NewHashKeyPartitionerWithHash creates a partitioner partitioning rows by given hash function of their keys.
+func NewHashKeyPartitionerWithHash(h HashFunc) Partitioner {
+	return &hashKeyPartitioner{Hash: h}
+}
+
 func (h *hashKeyPartitioner) PlanNext(numExecutors int) []Partition {
 	return PlanForNumberOf(numExecutors)
 }
 
 func (h *hashKeyPartitioner) DeterminePartition(c Context, r *lrdd.Row, numOutputs int) (id string, err error) {
-	// uses Fowler–Noll–Vo hash to determine output shard
-	slot := fnv1a.HashString64(r.Key) % uint64(numOutputs)
+	// uses Fowler–Noll–Vo hash to determine output shard by default
+	sum, err := h.Hash.Sum64(r.Key)
+	if err != nil {
+		return "", err
+	}
+	slot := sum % uint64(numOutputs)
 	return strconv.FormatUint(slot, 10), nil
 }
 
@@ -0,0 +1,35 @@
+package partitions
+
+import (
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/pkg/errors"
+)
+
+// ErrPinnedNodeUnavailable is returned when a pinned layout assigns a partition to a node not available.
+var ErrPinnedNodeUnavailable = errors.New("pinned node is unavailable")
+
+// Pin overrides the assignments with the pinned layout, which maps partition IDs to hosts of the nodes,
+// so that the tasks run exactly where specified regardless of the scheduler. Every partition except input and
+// collect ones, which are placed on the master, should be in the layout and assigned to one of given nodes.
+func Pin(aa []Assignments, layout map[string]string, nodes []*node.Node) error {
+	available := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		available[n.Host] = true
+	}
+	for _, assignments := range aa {
+		for i, a := range assignments {
+			if a.PartitionID == InputPartitionID || a.PartitionID == CollectPartitionID {
+				continue
+			}
+			host, ok := layout[a.PartitionID]
+			if !ok {
+				return errors.Errorf("partition %s is not in the pinned layout", a.PartitionID)
+			}
+			if !available[host] {
+				return errors.Wrapf(ErrPinnedNodeUnavailable, "partition %s pinned to %s", a.PartitionID, host)
+			}
+			assignments[i].Host = host
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package partitions
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPin(t *testing.T) {
+	Convey("Given scheduled assignments", t, func() {
+		nodes := []*node.Node{
+			{Host: "localhost:1001", Executors: 2},
+			{Host: "localhost:1002", Executors: 2},
+		}
+		_, aa := Schedule(nodes, []Plan{{}, {DesiredCount: 3}, {DesiredCount: 3}}, WithoutShufflingNodes())
+
+		Convey("Pinning every partition should override the assignments verbatim", func() {
+			layout := map[string]string{
+				"0": "localhost:1002",
+				"1": "localhost:1002",
+				"2": "localhost:1001",
+			}
+			So(Pin(aa, layout, nodes), ShouldBeNil)
+			So(aa[0][0].PartitionID, ShouldEqual, InputPartitionID)
+			for _, assignments := range aa[1:] {
+				So(assignments.ToMap(), ShouldResemble, layout)
+			}
+		})
+
+		Convey("Pinning a partition to an unavailable node should fail", func() {
+			layout := map[string]string{
+				"0": "localhost:1002",
+				"1": "localhost:9999",
+				"2": "localhost:1001",
+			}
+			err := Pin(aa, layout, nodes)
+			So(errors.Cause(err), ShouldEqual, ErrPinnedNodeUnavailable)
+		})
+
+		Convey("Leaving a partition out of the layout should fail", func() {
+			err := Pin(aa, map[string]string{"0": "localhost:1001"}, nodes)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "not in the pinned layout")
+		})
+	})
+}
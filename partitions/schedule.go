@@ -73,19 +73,15 @@ func Schedule(workers []*node.Node, plans []Plan, opt ...ScheduleOption) (pp []P
 		}
 
 		if plan.Partitioner == nil {
-			// sets default partitioner: if adjacent partitions are equal,
-			// it can be preserved. otherwise, it needs to be shuffled.
-			if i > 0 && (i == len(plans)-1 || plan.Equal(plans[i+1])) {
-				plan.Partitioner = NewPreservePartitioner()
-			} else {
-				plan.Partitioner = NewShuffledPartitioner()
-			}
+			plan.Partitioner = DefaultPartitioner(plans, i)
 		}
 		var partitions []Partition
 		if i == 0 {
 			partitions = []Partition{{ID: InputPartitionID}}
 		} else if IsPreserved(plans[i-1].Partitioner) && len(pp) > 0 {
 			partitions = pp[i-1].Partitions
+		} else if mp, ok := UnwrapPartitioner(plans[i-1].Partitioner).(MirroringPartitioner); ok && len(pp) > 0 {
+			partitions = mp.PlanNextFrom(pp[i-1].Partitions)
 		} else {
 			partitions = plans[i-1].Partitioner.PlanNext(numExecutors)
 		}
@@ -123,6 +119,15 @@ func Schedule(workers []*node.Node, plans []Plan, opt ...ScheduleOption) (pp []P
 	return pp, aa
 }
 
+// DefaultPartitioner returns a partitioner for the plan at given index without a partitioner:
+// if adjacent partitions are equal, it can be preserved. otherwise, it needs to be shuffled.
+func DefaultPartitioner(plans []Plan, i int) Partitioner {
+	if i > 0 && (i == len(plans)-1 || plans[i].Equal(plans[i+1])) {
+		return NewPreservePartitioner()
+	}
+	return NewShuffledPartitioner()
+}
+
 func selectNextNode(nn []nodeWithStats, plan *Plan, curSlot int) (selected *nodeWithStats, nextSlot int) {
 	for slot := curSlot; slot < curSlot+len(nn); slot++ {
 		n := &nn[slot%len(nn)]
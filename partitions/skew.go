@@ -0,0 +1,74 @@
+package partitions
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/segmentio/fasthash/fnv1a"
+	"go.uber.org/atomic"
+)
+
+// SkewedKeyPartitioner partitions rows by hash of their keys like hashKeyPartitioner, but splits rows of
+// hot keys over multiple partitions (salting) so that a few keys would not overload their partitions.
+// Rows of the other keys are consolidated to a partition by their hash.
+//
+// A key is considered hot if its frequency exceeds a fair share of a partition, and its rows are spread
+// over as many partitions as its frequency divided by the fair share. Since the rows of the hot keys are
+// no longer grouped in a partition, the results of the partitions need to be merged again by the key.
+type SkewedKeyPartitioner struct {
+	// Frequencies are sampled number of rows by keys. Keys not in the profile are considered cold.
+	Frequencies map[string]int64
+
+	// Spreads are the number of partitions each hot key is spread over, planned by PlanNext.
+	Spreads map[string]int
+
+	// salts are shared by the hot keys, as DeterminePartition can be called concurrently.
+	salts atomic.Uint64
+}
+
+// NewSkewedKeyPartitioner creates a SkewedKeyPartitioner from the sampled key-frequency profile.
+func NewSkewedKeyPartitioner(frequencies map[string]int64) Partitioner {
+	return &SkewedKeyPartitioner{Frequencies: frequencies}
+}
+
+// PlanNext decides how many partitions each hot key is spread over.
+func (s *SkewedKeyPartitioner) PlanNext(numExecutors int) []Partition {
+	s.Spreads = make(map[string]int)
+
+	var total int64
+	for _, freq := range s.Frequencies {
+		total += freq
+	}
+	if total == 0 || numExecutors == 0 {
+		return PlanForNumberOf(numExecutors)
+	}
+	fairShare := float64(total) / float64(numExecutors)
+	for key, freq := range s.Frequencies {
+		spread := int(math.Ceil(float64(freq) / fairShare))
+		if spread > numExecutors {
+			spread = numExecutors
+		}
+		if spread > 1 {
+			s.Spreads[key] = spread
+		}
+	}
+	return PlanForNumberOf(numExecutors)
+}
+
+// DeterminePartition distributes rows of a hot key to the partitions next to the partition
+// of its hash, in round-robin.
+func (s *SkewedKeyPartitioner) DeterminePartition(c Context, r *lrdd.Row, numOutputs int) (id string, err error) {
+	slot := fnv1a.HashString64(r.Key) % uint64(numOutputs)
+	if spread := s.Spreads[r.Key]; spread > 1 {
+		salt := s.salts.Inc() % uint64(spread)
+		slot = (slot + salt) % uint64(numOutputs)
+	}
+	return strconv.FormatUint(slot, 10), nil
+}
+
+// IsSkewed returns true if the partitioner splits rows of a key over multiple partitions.
+func IsSkewed(p Partitioner) bool {
+	_, ok := UnwrapPartitioner(p).(*SkewedKeyPartitioner)
+	return ok
+}
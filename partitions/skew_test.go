@@ -0,0 +1,145 @@
+package partitions
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSkewedKeyPartitioner(t *testing.T) {
+	Convey("Given a heavily skewed key distribution", t, func() {
+		const numOutputs = 8
+
+		// a key has 80% of rows, while the rest are evenly distributed over 100 keys
+		frequencies := map[string]int64{"hot": 8000}
+		for i := 0; i < 100; i++ {
+			frequencies["cold"+strconv.Itoa(i)] = 20
+		}
+		var rows []*lrdd.Row
+		for key, freq := range frequencies {
+			for i := int64(0); i < freq; i++ {
+				rows = append(rows, lrdd.KeyValue(key, i))
+			}
+		}
+
+		Convey("Loads of partitions should be more balanced than hash partitioning", func() {
+			skewed := NewSkewedKeyPartitioner(frequencies)
+			skewed.PlanNext(numOutputs)
+
+			hashLoads := loadsOf(NewHashKeyPartitioner(), rows, numOutputs)
+			skewLoads := loadsOf(skewed, rows, numOutputs)
+
+			So(maxOf(skewLoads), ShouldBeLessThan, maxOf(hashLoads))
+			So(maxOf(skewLoads), ShouldBeLessThan, 2*len(rows)/numOutputs)
+			So(sumOf(skewLoads), ShouldEqual, len(rows))
+		})
+
+		Convey("Rows of a hot key should be spread over multiple partitions", func() {
+			p := NewSkewedKeyPartitioner(frequencies)
+			p.PlanNext(numOutputs)
+
+			hot := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				id, err := p.DeterminePartition(nil, lrdd.KeyValue("hot", i), numOutputs)
+				So(err, ShouldBeNil)
+				hot[id] = true
+			}
+			// 8000 rows over a fair share of 1250 rows
+			So(len(hot), ShouldEqual, 7)
+		})
+
+		Convey("When it is reconstructed on a worker", func() {
+			onMaster := NewSkewedKeyPartitioner(frequencies)
+			onMaster.PlanNext(numOutputs)
+
+			data, err := jsoniter.Marshal(WrapPartitioner(onMaster))
+			So(err, ShouldBeNil)
+
+			var onWorker SerializablePartitioner
+			So(jsoniter.Unmarshal(data, &onWorker), ShouldBeNil)
+
+			Convey("Rows of a hot key should be spread as planned", func() {
+				hot := make(map[string]bool)
+				for i := 0; i < 100; i++ {
+					id, err := onWorker.DeterminePartition(nil, lrdd.KeyValue("hot", i), numOutputs)
+					So(err, ShouldBeNil)
+					hot[id] = true
+				}
+				So(len(hot), ShouldEqual, 7)
+			})
+		})
+
+		Convey("When rows are partitioned concurrently", func() {
+			p := NewSkewedKeyPartitioner(frequencies)
+			p.PlanNext(numOutputs)
+
+			var mu sync.Mutex
+			loads := make([]int, numOutputs)
+			wg := sync.WaitGroup{}
+			for w := 0; w < 4; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 1000; i++ {
+						id, _ := p.DeterminePartition(nil, lrdd.KeyValue("hot", i), numOutputs)
+						slot, _ := strconv.Atoi(id)
+						mu.Lock()
+						loads[slot]++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			Convey("Rows of a hot key should be spread evenly", func() {
+				So(sumOf(loads), ShouldEqual, 4000)
+				for _, load := range loads {
+					So(load, ShouldBeIn, 0, 4000/7, 4000/7+1)
+				}
+			})
+		})
+
+		Convey("Rows of a cold key should be consolidated to a partition", func() {
+			p := NewSkewedKeyPartitioner(frequencies)
+			p.PlanNext(numOutputs)
+
+			expected, _ := NewHashKeyPartitioner().DeterminePartition(nil, lrdd.KeyValue("cold1", 0), numOutputs)
+			for i := 0; i < 20; i++ {
+				id, err := p.DeterminePartition(nil, lrdd.KeyValue("cold1", i), numOutputs)
+				So(err, ShouldBeNil)
+				So(id, ShouldEqual, expected)
+			}
+		})
+	})
+}
+
+func loadsOf(p Partitioner, rows []*lrdd.Row, numOutputs int) []int {
+	loads := make([]int, numOutputs)
+	for _, row := range rows {
+		id, err := p.DeterminePartition(nil, row, numOutputs)
+		So(err, ShouldBeNil)
+		slot, _ := strconv.Atoi(id)
+		loads[slot]++
+	}
+	return loads
+}
+
+func maxOf(nn []int) (max int) {
+	for _, n := range nn {
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+func sumOf(nn []int) (sum int) {
+	for _, n := range nn {
+		sum += n
+	}
+	return sum
+}
@@ -0,0 +1,41 @@
+package partitions
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ErrPartitionMismatch is returned when a stage lacks partitions which the previous stage routes rows to.
+var ErrPartitionMismatch = errors.New("partitions mismatch between stages")
+
+// CheckNext returns ErrPartitionMismatch if the next stage lacks any partition which the partitioner of
+// the current stage can route rows to, since rows to the missing partitions would be lost.
+func CheckNext(cur, next Partitions) error {
+	var expected []Partition
+	p := cur.Partitioner.Partitioner
+	if IsPreserved(p) {
+		expected = cur.Partitions
+	} else if mp, ok := UnwrapPartitioner(p).(MirroringPartitioner); ok {
+		expected = mp.PlanNextFrom(cur.Partitions)
+	} else {
+		expected = p.PlanNext(len(next.Partitions))
+	}
+
+	ids := make(map[string]bool, len(next.Partitions))
+	for _, p := range next.Partitions {
+		ids[p.ID] = true
+	}
+	var missing []string
+	for _, p := range expected {
+		if !ids[p.ID] {
+			missing = append(missing, p.ID)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Wrapf(ErrPartitionMismatch, "%d partitions %v routed by %T are missing in %d partitions of the next stage",
+			len(missing), missing, UnwrapPartitioner(p), len(next.Partitions))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package partitions
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheckNext(t *testing.T) {
+	Convey("Given a stage with 4 partitions", t, func() {
+		current := PlanForNumberOf(4)
+
+		Convey("Preserving them into the same partitions should be valid", func() {
+			So(CheckNext(New(NewPreservePartitioner(), current), New(nil, current)), ShouldBeNil)
+		})
+
+		Convey("Preserving them into fewer partitions should be refused", func() {
+			err := CheckNext(New(NewPreservePartitioner(), current), New(nil, PlanForNumberOf(2)))
+			So(errors.Cause(err), ShouldEqual, ErrPartitionMismatch)
+			So(err.Error(), ShouldContainSubstring, "2 partitions [2 3]")
+		})
+
+		Convey("Shuffling them into any number of partitions should be valid", func() {
+			So(CheckNext(New(NewHashKeyPartitioner(), current), New(nil, PlanForNumberOf(7))), ShouldBeNil)
+		})
+
+		Convey("Shuffling them into partitions with unknown IDs should be refused", func() {
+			next := []Partition{{ID: "0"}, {ID: "1"}, {ID: "foo"}}
+			err := CheckNext(New(NewHashKeyPartitioner(), current), New(nil, next))
+			So(errors.Cause(err), ShouldEqual, ErrPartitionMismatch)
+			So(err.Error(), ShouldContainSubstring, "[2]")
+		})
+
+		Convey("Partitioning them by keys missing in the next stage should be refused", func() {
+			p := NewFiniteKeyPartitioner([]string{"a", "b", "c"})
+			err := CheckNext(New(p, current), New(nil, []Partition{{ID: "a"}, {ID: "b"}}))
+			So(errors.Cause(err), ShouldEqual, ErrPartitionMismatch)
+			So(err.Error(), ShouldContainSubstring, "[c]")
+		})
+
+		Convey("Coalescing them should be valid with the coalesced partitions", func() {
+			p := NewCoalescingPartitioner(2)
+			So(CheckNext(New(p, current), New(nil, PlanForNumberOf(2))), ShouldBeNil)
+		})
+	})
+}
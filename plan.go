@@ -0,0 +1,242 @@
+package lrmr
+
+import (
+	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// ErrPlanNotPortable is returned by Session.ExportPlan if the dataset depends on the driver process,
+// such as Session.FromReader or Dataset.Peek.
+var ErrPlanNotPortable = errors.New("plan is not portable")
+
+// exportedPlan is a portable form of a Dataset. Transformations and partitioners are referenced by their types,
+// so they should be registered with RegisterTypes in the importing process too.
+type exportedPlan struct {
+	Input              exportedInput                  `json:"input"`
+	Stages             []stage.Stage                  `json:"stages"`
+	Plans              []exportedPartitionPlan        `json:"plans"`
+	DefaultPlan        exportedPartitionPlan          `json:"defaultPlan"`
+	NumStages          int                            `json:"numStages"`
+	Persist            bool                           `json:"persist,omitempty"`
+	PersistCompression job.Compression                `json:"persistCompression,omitempty"`
+	SideInputs         map[string]*exportedPlan       `json:"sideInputs,omitempty"`
+	Params             map[string]string              `json:"params,omitempty"`
+	Broadcasts         map[string]jsoniter.RawMessage `json:"broadcasts,omitempty"`
+}
+
+type exportedPartitionPlan struct {
+	Partitioner         partitions.SerializablePartitioner `json:"partitioner"`
+	DesiredCount        int                                `json:"desiredCount,omitempty"`
+	MaxNodes            int                                `json:"maxNodes,omitempty"`
+	ExecutorsPerNode    int                                `json:"executorsPerNode,omitempty"`
+	DesiredNodeAffinity map[string]string                  `json:"desiredNodeAffinity,omitempty"`
+}
+
+// exportedInput is a portable form of the inputs created by the Session.
+type exportedInput struct {
+	Kind          string          `json:"kind"`
+	Rows          []*lrdd.Row     `json:"rows,omitempty"`
+	NumPartitions int             `json:"numPartitions,omitempty"`
+	Path          string          `json:"path,omitempty"`
+	JobID         string          `json:"jobID,omitempty"`
+	Inputs        []exportedInput `json:"inputs,omitempty"`
+}
+
+const (
+	parallelizedInputKind = "parallelized"
+	chunkedInputKind      = "chunked"
+	localInputKind        = "file"
+	jobOutputInputKind    = "jobOutput"
+	unionInputKind        = "union"
+)
+
+// ExportPlan serializes the dataset into a portable blob, including its input, stages, partitioners,
+// side inputs and the parameters and broadcasts of the Session. The blob can be stored and submitted later
+// by another process with Session.ImportPlan. Datasets reading from the driver with Session.FromReader or
+// calling back the driver with Dataset.Peek can't be exported, returning ErrPlanNotPortable.
+func (s *Session) ExportPlan(ds *Dataset) ([]byte, error) {
+	if err := checkRegisteredTypes(ds); err != nil {
+		return nil, err
+	}
+	p, err := exportPlan(ds)
+	if err != nil {
+		return nil, err
+	}
+	p.Params = s.options.Params
+	broadcasts, err := serialization.SerializeBroadcast(s.broadcasts, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "serialize broadcast")
+	}
+	if len(broadcasts) > 0 {
+		p.Broadcasts = make(map[string]jsoniter.RawMessage, len(broadcasts))
+		for k, v := range broadcasts {
+			p.Broadcasts[k] = v
+		}
+	}
+	return jsoniter.Marshal(p)
+}
+
+func exportPlan(ds *Dataset) (*exportedPlan, error) {
+	if len(ds.peeks) > 0 {
+		return nil, errors.Wrap(ErrPlanNotPortable, "peek calls back the driver")
+	}
+	in, err := exportInput(ds.input)
+	if err != nil {
+		return nil, err
+	}
+	p := &exportedPlan{
+		Input:              in,
+		Stages:             ds.stages,
+		Plans:              make([]exportedPartitionPlan, len(ds.plans)),
+		DefaultPlan:        exportPartitionPlan(ds.defaultPlan),
+		NumStages:          ds.NumStages,
+		Persist:            ds.persist,
+		PersistCompression: ds.persistCompression,
+	}
+	for i, plan := range ds.plans {
+		if i == 0 {
+			// the partitioner of the input stage is the input itself
+			plan.Partitioner = nil
+		}
+		p.Plans[i] = exportPartitionPlan(plan)
+	}
+	if len(ds.sideInputs) > 0 {
+		p.SideInputs = make(map[string]*exportedPlan, len(ds.sideInputs))
+		for name, side := range ds.sideInputs {
+			if p.SideInputs[name], err = exportPlan(side); err != nil {
+				return nil, errors.WithMessagef(err, "side input %s", name)
+			}
+		}
+	}
+	return p, nil
+}
+
+func exportPartitionPlan(p partitions.Plan) exportedPartitionPlan {
+	return exportedPartitionPlan{
+		Partitioner:         partitions.WrapPartitioner(p.Partitioner),
+		DesiredCount:        p.DesiredCount,
+		MaxNodes:            p.MaxNodes,
+		ExecutorsPerNode:    p.ExecutorsPerNode,
+		DesiredNodeAffinity: p.DesiredNodeAffinity,
+	}
+}
+
+func exportInput(in InputProvider) (exportedInput, error) {
+	switch in := in.(type) {
+	case *parallelizedInput:
+		return exportedInput{Kind: parallelizedInputKind, Rows: in.data}, nil
+	case *chunkedInput:
+		return exportedInput{Kind: chunkedInputKind, Rows: in.data, NumPartitions: in.NumPartitions}, nil
+	case *localInput:
+		return exportedInput{Kind: localInputKind, Path: in.Path}, nil
+	case *jobOutputInput:
+		return exportedInput{Kind: jobOutputInputKind, JobID: in.JobID}, nil
+	case *unionInput:
+		e := exportedInput{Kind: unionInputKind, Inputs: make([]exportedInput, len(in.inputs))}
+		for i, src := range in.inputs {
+			var err error
+			if e.Inputs[i], err = exportInput(src); err != nil {
+				return exportedInput{}, err
+			}
+		}
+		return e, nil
+	}
+	return exportedInput{}, errors.Wrapf(ErrPlanNotPortable, "input %T is read from the driver", in)
+}
+
+// ImportPlan creates a Dataset from a blob exported by Session.ExportPlan. The parameters and broadcasts in the
+// blob are set to the Session, and those of the Session are kept unless they are overwritten by the blob.
+func (s *Session) ImportPlan(blob []byte) (*Dataset, error) {
+	p := new(exportedPlan)
+	if err := jsoniter.Unmarshal(blob, p); err != nil {
+		return nil, errors.Wrap(err, "unmarshal plan")
+	}
+	ds, err := s.importPlan(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Params) > 0 {
+		WithParams(p.Params)(&s.options)
+	}
+	raw := make(map[string][]byte, len(p.Broadcasts))
+	for k, v := range p.Broadcasts {
+		raw[k] = v
+	}
+	broadcasts, err := serialization.DeserializeBroadcast(raw)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range broadcasts {
+		s.Broadcast(k, v)
+	}
+	return ds, nil
+}
+
+func (s *Session) importPlan(p *exportedPlan) (*Dataset, error) {
+	in, err := importInput(p.Input)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Plans) != len(p.Stages) || len(p.Stages) == 0 {
+		return nil, errors.Errorf("plan has %d stages but %d partition plans", len(p.Stages), len(p.Plans))
+	}
+	ds := newDataset(s, in)
+	ds.stages = p.Stages
+	ds.plans = make([]partitions.Plan, len(p.Plans))
+	for i, plan := range p.Plans {
+		ds.plans[i] = importPartitionPlan(plan)
+	}
+	ds.plans[0].Partitioner = in
+	ds.defaultPlan = importPartitionPlan(p.DefaultPlan)
+	ds.NumStages = p.NumStages
+	ds.persist = p.Persist
+	ds.persistCompression = p.PersistCompression
+
+	if len(p.SideInputs) > 0 {
+		ds.sideInputs = make(map[string]*Dataset, len(p.SideInputs))
+		for name, side := range p.SideInputs {
+			if ds.sideInputs[name], err = s.importPlan(side); err != nil {
+				return nil, errors.WithMessagef(err, "side input %s", name)
+			}
+		}
+	}
+	return ds, nil
+}
+
+func importPartitionPlan(p exportedPartitionPlan) partitions.Plan {
+	return partitions.Plan{
+		Partitioner:         p.Partitioner.Partitioner,
+		DesiredCount:        p.DesiredCount,
+		MaxNodes:            p.MaxNodes,
+		ExecutorsPerNode:    p.ExecutorsPerNode,
+		DesiredNodeAffinity: p.DesiredNodeAffinity,
+	}
+}
+
+func importInput(e exportedInput) (InputProvider, error) {
+	switch e.Kind {
+	case parallelizedInputKind:
+		return &parallelizedInput{data: e.Rows}, nil
+	case chunkedInputKind:
+		return newChunkedInput(e.Rows, e.NumPartitions), nil
+	case localInputKind:
+		return &localInput{Path: e.Path}, nil
+	case jobOutputInputKind:
+		return &jobOutputInput{JobID: e.JobID}, nil
+	case unionInputKind:
+		inputs := make([]InputProvider, len(e.Inputs))
+		for i, src := range e.Inputs {
+			var err error
+			if inputs[i], err = importInput(src); err != nil {
+				return nil, err
+			}
+		}
+		return &unionInput{inputs: inputs}, nil
+	}
+	return nil, errors.Errorf("unknown input %q", e.Kind)
+}
@@ -0,0 +1,105 @@
+package lrmr
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RateLimit paces rows written to a sink, for sinks backed by external systems with a rate cap (e.g. an API
+// limited in QPS). Since a task waiting for the limit does not consume its input, upstream tasks are also
+// slowed down by the backpressure.
+type RateLimit struct {
+	// RowsPerSecond is the maximum rate of the rows written to the sink.
+	RowsPerSecond float64 `json:"rowsPerSecond"`
+
+	// Burst is the maximum number of rows written at once after the writes have been idle. It is 1 if not positive.
+	Burst int `json:"burst,omitempty"`
+
+	// PerTask applies the limit to each task writing to the sink. Otherwise, the limit is for the whole stage,
+	// divided evenly across its tasks.
+	PerTask bool `json:"perTask,omitempty"`
+}
+
+// limiterFor creates a token bucket of the task, which is one of numTasks tasks writing to the sink.
+func (l RateLimit) limiterFor(numTasks int) *tokenBucket {
+	rate, burst := l.RowsPerSecond, float64(l.Burst)
+	if !l.PerTask && numTasks > 1 {
+		rate /= float64(numTasks)
+		burst = math.Floor(burst / float64(numTasks))
+	}
+	return newTokenBucket(rate, math.Max(burst, 1))
+}
+
+// tokenBucket allows taking a token at the rate, holding at most burst tokens. It is not safe for concurrent use.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token, blocking until it is available or the context is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return nil
+	}
+	delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		// the token refilled during the delay is taken right away
+		b.tokens = 0
+		b.last = now.Add(delay)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WriteOptions configures writing rows of a dataset to a sink.
+type WriteOptions struct {
+	// RateLimit paces the rows written to the sink. The rows are written as fast as possible if it is nil.
+	RateLimit *RateLimit
+
+	// err is an error of invalid options, returned before writing any rows.
+	err error
+}
+
+type WriteOption func(o *WriteOptions)
+
+// WithRateLimit paces the rows written to the sink to the limit. RowsPerSecond of the limit should be positive,
+// otherwise writing fails before running the job.
+func WithRateLimit(l RateLimit) WriteOption {
+	return func(o *WriteOptions) {
+		if !(l.RowsPerSecond > 0) {
+			o.err = errors.Errorf("RowsPerSecond of the rate limit should be positive, but got %v", l.RowsPerSecond)
+			return
+		}
+		o.RateLimit = &l
+	}
+}
+
+func buildWriteOptions(opts []WriteOption) (o WriteOptions, err error) {
+	for _, optFn := range opts {
+		optFn(&o)
+	}
+	return o, o.err
+}
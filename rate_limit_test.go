@@ -0,0 +1,74 @@
+package lrmr
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRateLimit(t *testing.T) {
+	Convey("Given a rate limit of the stage", t, func() {
+		l := RateLimit{RowsPerSecond: 100, Burst: 8}
+
+		Convey("It should be divided across the tasks", func() {
+			b := l.limiterFor(4)
+			So(b.rate, ShouldEqual, 25)
+			So(b.burst, ShouldEqual, 2)
+
+			Convey("Burst should be at least a row", func() {
+				So(l.limiterFor(16).burst, ShouldEqual, 1)
+			})
+		})
+
+		Convey("It should be applied to each task if PerTask is set", func() {
+			l.PerTask = true
+			b := l.limiterFor(4)
+			So(b.rate, ShouldEqual, 100)
+			So(b.burst, ShouldEqual, 8)
+		})
+	})
+
+	Convey("Given a token bucket", t, func() {
+		ctx := context.Background()
+		b := newTokenBucket(200, 5)
+
+		Convey("Tokens should be taken at the rate after the burst", func() {
+			start := time.Now()
+			for i := 0; i < 45; i++ {
+				So(b.wait(ctx), ShouldBeNil)
+			}
+			// 5 tokens are taken at once, and the other 40 are refilled in 200ms
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 190*time.Millisecond)
+			So(time.Since(start), ShouldBeLessThan, 400*time.Millisecond)
+		})
+
+		Convey("Waiting should be canceled with the context", func() {
+			b = newTokenBucket(0.1, 1)
+			So(b.wait(ctx), ShouldBeNil)
+
+			cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			So(b.wait(cctx), ShouldResemble, context.DeadlineExceeded)
+		})
+	})
+
+	Convey("Given a rate limit without a positive rate", t, func() {
+		Convey("Building the write options should fail", func() {
+			for _, rate := range []float64{0, -1, math.NaN()} {
+				_, err := buildWriteOptions([]WriteOption{WithRateLimit(RateLimit{RowsPerSecond: rate})})
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+
+	Convey("Given a rate limit with a positive rate", t, func() {
+		Convey("It should be set to the write options", func() {
+			o, err := buildWriteOptions([]WriteOption{WithRateLimit(RateLimit{RowsPerSecond: 10})})
+			So(err, ShouldBeNil)
+			So(o.RateLimit.RowsPerSecond, ShouldEqual, 10)
+		})
+	})
+}
@@ -13,16 +13,44 @@ import (
 	"github.com/pkg/errors"
 )
 
+var _ = RegisterTypes(&persistedOutputReader{})
+
 var (
 	Aborted = errors.New("job aborted")
 )
 
+// RunningJob is a job submitted by the driver. If the job runs again, either rescheduled on other nodes
+// (see master.Master.Drain) or retried after a retryable failure (see SessionOptions.MaxRetries),
+// Job is replaced with the one running again, and waiting for the job follows it.
 type RunningJob struct {
 	*job.Job
 	Master *master.Master
 
 	finalStatus *job.Status
 	statusMu    sync.RWMutex
+
+	peeks    map[string]func(*lrdd.Row)
+	peekOnce sync.Once
+
+	// reattached is set if the job has been submitted by another driver (see Session.Reattach).
+	reattached bool
+
+	// rerun submits the dataset of the job again. It is nil if the input of the dataset can't be fed again.
+	rerun func() (*job.Job, error)
+
+	// maxRetries is the number of times the job can be retried after retryable failures, and retries
+	// is the number of times it has been retried.
+	maxRetries int
+	retries    int
+
+	// reschedulings are reschedulings of the jobs aborted or failed to run again, keyed by IDs of the jobs.
+	reschedulings map[string]*rescheduling
+	rescheduleMu  sync.Mutex
+}
+
+type rescheduling struct {
+	done chan struct{}
+	err  error
 }
 
 func (r *RunningJob) Status() job.RunningState {
@@ -35,17 +63,89 @@ func (r *RunningJob) Status() job.RunningState {
 	return r.finalStatus.Status
 }
 
+// Metrics returns metrics aggregated over the tasks of the job, summed unless they are added
+// with another aggregation (see Context.AddMetric). While the job is running,
+// it contains the progress reported by the running tasks. Skew ratios of the skewed stages are also included.
 func (r *RunningJob) Metrics() (job.Metrics, error) {
+	metrics, err := r.taskMetrics()
+	if err != nil {
+		return nil, err
+	}
+	skews, err := r.StageSkews(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return metrics.Assign(job.SkewMetrics(skews)), nil
+}
+
+func (r *RunningJob) taskMetrics() (job.Metrics, error) {
 	statuses, err := r.Master.JobManager.ListTaskStatusesInJob(context.TODO(), r.Job.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "list task status")
 	}
+	if len(statuses) == 0 {
+		// task statuses can be compacted after the job completes
+		if summary, err := r.Master.JobManager.GetJobSummary(context.TODO(), r.Job.ID); err == nil {
+			return summary.Metrics, nil
+		}
+	}
 
-	metric := make(job.Metrics)
-	for _, status := range statuses {
-		metric = metric.Sum(status.Metrics)
+	return job.AggregateMetrics(statuses)
+}
+
+// StageSkews returns stages of the job with a partition receiving far more rows than the others,
+// detected as configured in master.Options.SkewDetection.
+func (r *RunningJob) StageSkews(ctx context.Context) ([]job.StageSkew, error) {
+	return r.Master.JobManager.ListStageSkews(ctx, r.Job.ID)
+}
+
+// DriverOutputs returns rows emitted to the driver with Context.EmitToDriver by the succeeded tasks of the job.
+// Rows of a task are available after the task completes.
+func (r *RunningJob) DriverOutputs() ([]*lrdd.Row, error) {
+	return r.Master.JobManager.ListDriverOutputs(context.TODO(), r.Job.ID)
+}
+
+// StageDurations returns wall-clock spans of the stages, from the earliest start of their tasks to the latest
+// completion. Unlike Metrics, which are counters summed over the tasks, they show which stage made the job slow.
+func (r *RunningJob) StageDurations() (map[string]job.StageDuration, error) {
+	durations, err := r.Master.JobManager.GetStageDurations(context.TODO(), r.Job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(durations) == 0 {
+		if summary, err := r.Master.JobManager.GetJobSummary(context.TODO(), r.Job.ID); err == nil {
+			return summary.StageDurations, nil
+		}
 	}
-	return metric, nil
+	return durations, nil
+}
+
+// TaskLogs returns recent lines logged by the task with transformation.Context.Logger.
+// It is useful to look into a failed task with the ID of the task in job.Error.
+func (r *RunningJob) TaskLogs(ctx context.Context, taskID string) ([]string, error) {
+	return r.Master.TaskLogs(ctx, r.Job, taskID)
+}
+
+// QuarantinedRows returns rows quarantined by the succeeded tasks of the job (e.g. with QuarantineInvalidRows).
+func (r *RunningJob) QuarantinedRows(ctx context.Context) ([]job.QuarantinedRow, error) {
+	return r.Master.JobManager.ListQuarantinedRows(ctx, r.Job.ID)
+}
+
+// DroppedPartitions returns partitions whose tasks have failed within the failure tolerance of their stages
+// (see Dataset.WithFailureTolerance). Rows of the partitions are missing from the results.
+func (r *RunningJob) DroppedPartitions(ctx context.Context) ([]job.DroppedPartition, error) {
+	return r.Master.JobManager.ListDroppedPartitions(ctx, r.Job.ID)
+}
+
+// Pause stops tasks of the job from making progress until Resume is called.
+// Rows in flight are kept, so the job completes with the same result after resumed.
+func (r *RunningJob) Pause(ctx context.Context) error {
+	return r.Master.PauseJob(ctx, r.Job.ID)
+}
+
+// Resume resumes the job paused by Pause.
+func (r *RunningJob) Resume(ctx context.Context) error {
+	return r.Master.ResumeJob(ctx, r.Job.ID)
 }
 
 func (r *RunningJob) Wait() error {
@@ -55,35 +155,275 @@ func (r *RunningJob) Wait() error {
 	return r.WaitWithContext(ctx)
 }
 
+// WaitWithContext waits for the job to complete. If the context is cancelled, the job is aborted.
 func (r *RunningJob) WaitWithContext(ctx context.Context) error {
-	jobWaitChan := make(chan struct{}, 1)
-	r.Master.JobTracker.OnJobCompletion(r.Job, func(j *job.Job, status *job.Status) {
-		r.statusMu.Lock()
-		r.finalStatus = status
-		r.statusMu.Unlock()
-
-		jobWaitChan <- struct{}{}
-		r.logMetrics()
-	})
+	err := r.wait(ctx)
+	if err != nil && err == ctx.Err() {
+		log.Info("Canceling jobs")
+		reason := job.CancelledByUser
+		if err == context.DeadlineExceeded {
+			reason = job.CancelledByTimeout
+		}
+		_ = r.AbortWithReason(ctx, reason)
+	}
+	return err
+}
 
-	select {
-	case <-jobWaitChan:
+// wait waits for the job to complete, or the context to be cancelled.
+func (r *RunningJob) wait(ctx context.Context) error {
+	for {
+		j := r.current()
+		jobWaitChan := make(chan struct{}, 1)
+		r.Master.JobTracker.OnJobCompletion(j, func(j *job.Job, status *job.Status) {
+			r.statusMu.Lock()
+			if r.Job.ID == j.ID {
+				r.finalStatus = status
+			}
+			r.statusMu.Unlock()
+
+			jobWaitChan <- struct{}{}
+			r.logMetrics()
+		})
+
+		select {
+		case <-jobWaitChan:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if rescheduled, err := r.waitForRescheduling(ctx, j.ID); rescheduled {
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if r.Status() == job.Failed {
+			if retried, err := r.retryFailed(ctx, j, r.finalStatus.Errors); retried {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if j.CollectAllErrors {
+				return job.Errors(r.finalStatus.Errors)
+			}
 			return r.finalStatus.Errors[0]
 		}
-	case <-ctx.Done():
-		log.Info("Canceling jobs")
-		_ = r.AbortWithContext(ctx)
-		return ctx.Err()
+		r.runPeeks(ctx)
+		return nil
+	}
+}
+
+// current returns the job, which can be replaced by rescheduling.
+func (r *RunningJob) current() *job.Job {
+	r.statusMu.RLock()
+	defer r.statusMu.RUnlock()
+	return r.Job
+}
+
+// reschedule aborts the job with job.CancelledByRebalance, and submits it again to run on the nodes
+// available now. It is called by the master when a node running the tasks of the job is drained.
+func (r *RunningJob) reschedule(ctx context.Context) error {
+	j := r.current()
+	js, err := r.Master.JobManager.GetJobStatus(ctx, j.ID)
+	if err != nil {
+		return errors.WithMessage(err, "get job status")
+	}
+	if js.Status == job.Succeeded || js.Status == job.Failed {
+		return nil
+	}
+	r.rescheduleMu.Lock()
+	rs, started := r.startRescheduling(j.ID)
+	r.rescheduleMu.Unlock()
+	if !started {
+		return nil
+	}
+	if err := r.AbortWithReason(ctx, job.CancelledByRebalance); !errors.Is(err, Aborted) {
+		rs.err = errors.WithMessage(err, "abort for rescheduling")
+		close(rs.done)
+		return rs.err
+	}
+	return r.runAgain(rs, j)
+}
+
+// retryFailed runs the failed job again if any retry remains and every error of the job is retryable by its class
+// (see job.ErrorClass.Retryable). It returns false if the job is not run again.
+func (r *RunningJob) retryFailed(ctx context.Context, j *job.Job, errs []job.Error) (bool, error) {
+	if r.rerun == nil || len(errs) == 0 {
+		return false, nil
+	}
+	for _, e := range errs {
+		if !e.Retryable() {
+			return false, nil
+		}
+	}
+	r.rescheduleMu.Lock()
+	if _, ok := r.reschedulings[j.ID]; ok {
+		// another waiter is retrying the job
+		r.rescheduleMu.Unlock()
+		return r.waitForRescheduling(ctx, j.ID)
 	}
+	if r.retries >= r.maxRetries {
+		r.rescheduleMu.Unlock()
+		return false, nil
+	}
+	r.retries++
+	retries := r.retries
+	rs, _ := r.startRescheduling(j.ID)
+	r.rescheduleMu.Unlock()
+
+	log.Warn("Job {} failed with retryable errors. Retrying ({}/{})", j.ID, retries, r.maxRetries)
+	return true, r.runAgain(rs, j)
+}
+
+// startRescheduling returns a new rescheduling of the job, or false if the job is already being rescheduled.
+// It must be called with rescheduleMu held.
+func (r *RunningJob) startRescheduling(jobID string) (*rescheduling, bool) {
+	if r.reschedulings == nil {
+		r.reschedulings = make(map[string]*rescheduling)
+	}
+	if rs, ok := r.reschedulings[jobID]; ok {
+		return rs, false
+	}
+	rs := &rescheduling{done: make(chan struct{})}
+	r.reschedulings[jobID] = rs
+	return rs, true
+}
+
+// runAgain submits the aborted or failed job again, and replaces the job with the new one.
+func (r *RunningJob) runAgain(rs *rescheduling, j *job.Job) error {
+	defer close(rs.done)
+
+	next, err := r.rerun()
+	if err != nil {
+		rs.err = errors.WithMessagef(err, "run job %s again", j.ID)
+		return rs.err
+	}
+	log.Info("Job {} runs again as {}.", j.ID, next.ID)
+
+	r.statusMu.Lock()
+	r.Job = next
+	r.finalStatus = nil
+	r.statusMu.Unlock()
+	r.Master.OnRebalance(next, r.reschedule)
 	return nil
 }
 
+// waitForRescheduling waits for the job with given ID to be submitted again if it has been aborted for rescheduling.
+// It returns false if the job is not being rescheduled.
+func (r *RunningJob) waitForRescheduling(ctx context.Context, jobID string) (bool, error) {
+	r.rescheduleMu.Lock()
+	rs := r.reschedulings[jobID]
+	r.rescheduleMu.Unlock()
+	if rs == nil {
+		return false, nil
+	}
+	select {
+	case <-rs.done:
+		return true, rs.err
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
 func (r *RunningJob) Collect() ([]*lrdd.Row, error) {
-	r.Master.JobTracker.OnJobCompletion(r.Job, func(j *job.Job, status *job.Status) {
-		r.logMetrics()
-	})
-	return r.Master.CollectedResults(r.Job.ID)
+	return r.CollectWithContext(context.Background())
+}
+
+// CollectWithContext is like Collect, but stops waiting for the results when the context is cancelled.
+// Unlike WaitWithContext, the job is not aborted and keeps running; its results are discarded.
+func (r *RunningJob) CollectWithContext(ctx context.Context) ([]*lrdd.Row, error) {
+	if r.reattached {
+		return r.collectReattached(ctx)
+	}
+	if r.Job.CollectAllErrors {
+		// errors are available after every task completes
+		if err := r.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		j := r.current()
+		r.Master.JobTracker.OnJobCompletion(j, func(j *job.Job, status *job.Status) {
+			r.logMetrics()
+		})
+		rows, err := r.Master.CollectedResults(ctx, j.ID)
+		if err != nil {
+			rescheduled, rerr := r.waitForRescheduling(ctx, j.ID)
+			if !rescheduled {
+				rescheduled, rerr = r.retryFailedCollect(ctx, j, err)
+			}
+			if rescheduled {
+				if rerr != nil {
+					return nil, rerr
+				}
+				continue
+			}
+			return nil, err
+		}
+		r.runPeeks(ctx)
+		return rows, nil
+	}
+}
+
+// retryFailedCollect is like retryFailed, for the job whose collection failed with the error.
+func (r *RunningJob) retryFailedCollect(ctx context.Context, j *job.Job, err error) (bool, error) {
+	var jobErr job.Error
+	if !errors.As(err, &jobErr) {
+		return false, nil
+	}
+	errs, getErr := r.Master.JobManager.GetJobErrors(ctx, j.ID)
+	if getErr != nil || len(errs) == 0 {
+		errs = []job.Error{jobErr}
+	}
+	return r.retryFailed(ctx, j, errs)
+}
+
+// collectReattached waits for the reattached job, and reads its persisted output.
+func (r *RunningJob) collectReattached(ctx context.Context) ([]*lrdd.Row, error) {
+	if r.Job.GetStage(master.CollectStageName) != nil {
+		return nil, errors.Wrapf(ErrResultsNotRecoverable, "job %s is run for collect by another driver", r.Job.ID)
+	}
+	if !r.Job.PersistOutput {
+		return nil, errors.Wrapf(ErrResultsNotRecoverable, "job %s is not run with Dataset.Persist", r.Job.ID)
+	}
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+	// the persisted output is loaded by the tasks of the stage reading it, which is not the collect stage
+	return NewSession(ctx, r.Master).FromJobOutput(r.Job.ID).Do(&persistedOutputReader{}).Collect()
+}
+
+// persistedOutputReader passes the rows of the persisted output through.
+type persistedOutputReader struct{}
+
+func (p *persistedOutputReader) Transform(_ Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for row := range in {
+		emit(row)
+	}
+	return nil
+}
+
+// CollectPartition returns collected results only from given partition in the final stage.
+func (r *RunningJob) CollectPartition(partitionID string) ([]*lrdd.Row, error) {
+	return r.CollectPartitionWithContext(context.Background(), partitionID)
+}
+
+// CollectPartitionWithContext is like CollectPartition, but stops waiting when the context is cancelled.
+func (r *RunningJob) CollectPartitionWithContext(ctx context.Context, partitionID string) ([]*lrdd.Row, error) {
+	if r.reattached {
+		return nil, errors.Wrapf(ErrResultsNotRecoverable, "partitions of job %s are collected by another driver", r.Job.ID)
+	}
+	found := false
+	for _, a := range r.Job.GetPartitionsOfStage(master.CollectStageName) {
+		if a.PartitionID == partitionID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("partition %s not found in job %s", partitionID, r.Job.ID)
+	}
+	return r.Master.CollectedResultsOfPartition(ctx, r.Job.ID, partitionID)
 }
 
 func (r *RunningJob) Abort() error {
@@ -93,14 +433,25 @@ func (r *RunningJob) Abort() error {
 	return r.AbortWithContext(ctx)
 }
 
+// AbortWithContext aborts the job by the user.
 func (r *RunningJob) AbortWithContext(ctx context.Context) error {
+	return r.AbortWithReason(ctx, job.CancelledByUser)
+}
+
+// AbortWithReason aborts the job, recording why it is aborted. The reason can be read from the final status
+// of the job with CancelReason, or from its errors with job.CancelReasonOf and the CancelReason of job.Error.
+func (r *RunningJob) AbortWithReason(ctx context.Context, reason job.CancelReason) error {
 	ref := job.TaskID{
 		JobID:       r.Job.ID,
 		StageName:   "__input",
 		PartitionID: "__master",
 	}
 	reporter := job.NewTaskReporter(ctx, r.Master.Cluster.States(), r.Job, ref, job.NewTaskStatus())
-	if err := reporter.ReportFailure(Aborted); err != nil {
+	if err := reporter.ReportFailure(job.WithCancelReason(Aborted, reason)); err != nil {
+		return errors.Wrap(err, "abort")
+	}
+	// tasks parked by the pause need to go on to complete the job
+	if err := r.Master.JobManager.ClearJobPause(ctx, r.Job.ID); err != nil {
 		return errors.Wrap(err, "abort")
 	}
 
@@ -110,7 +461,39 @@ func (r *RunningJob) AbortWithContext(ctx context.Context) error {
 		cancel()
 	})
 	<-jobWaitCtx.Done()
-	return Aborted
+	return job.WithCancelReason(Aborted, reason)
+}
+
+// CancelReason returns why the job has been aborted, or job.NotCancelled if it has not been aborted
+// or has not completed yet.
+func (r *RunningJob) CancelReason() job.CancelReason {
+	r.statusMu.RLock()
+	defer r.statusMu.RUnlock()
+
+	if r.finalStatus == nil {
+		return job.NotCancelled
+	}
+	return r.finalStatus.CancelReason()
+}
+
+// runPeeks calls the callbacks of Dataset.Peek with rows sampled from their stages, in the order of the stages.
+func (r *RunningJob) runPeeks(ctx context.Context) {
+	r.peekOnce.Do(func() {
+		for _, s := range r.Job.Stages {
+			fn, ok := r.peeks[s.Name]
+			if !ok {
+				continue
+			}
+			rows, err := r.Master.JobManager.ListPeekedRows(ctx, r.Job.ID, s.Name)
+			if err != nil {
+				log.Warn("Failed to read peeked rows of stage {}: {}", s.Name, err)
+				continue
+			}
+			for _, row := range rows {
+				fn(row)
+			}
+		}
+	})
 }
 
 func (r *RunningJob) logMetrics() {
@@ -2,15 +2,26 @@ package lrmr
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/job"
 	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/master"
 	"github.com/goombaio/namegenerator"
 	"github.com/pkg/errors"
 )
 
+var (
+	// ErrOutputNotPersisted is returned when reading output of a job which was not run with Dataset.Persist.
+	ErrOutputNotPersisted = errors.New("output of the job was not persisted")
+
+	// ErrResultsNotRecoverable is returned when collecting a reattached job whose results are only kept
+	// by its previous driver.
+	ErrResultsNotRecoverable = errors.New("results of the job are not recoverable by another driver")
+)
+
 type Session struct {
 	ctx        context.Context
 	master     *master.Master
@@ -33,12 +44,84 @@ func (s *Session) Parallelize(val interface{}) *Dataset {
 	return newDataset(s, in)
 }
 
+// ParallelizeN creates new Dataset from given value, splitting it into numPartitions partitions.
+// Elements are divided into contiguous chunks in their order. If the number of elements is not
+// divisible by numPartitions, the remainder is distributed one by one to the first partitions;
+// for example, 10 elements in 4 partitions are split into the chunks of 3, 3, 2 and 2.
+// numPartitions less than 1 is considered as 1.
+func (s *Session) ParallelizeN(val interface{}, numPartitions int) *Dataset {
+	if numPartitions < 1 {
+		numPartitions = 1
+	}
+	in := newChunkedInput(lrdd.From(val), numPartitions)
+	return newDataset(s, in)
+}
+
 // FromFile creates new Dataset by reading files under given path.
 func (s *Session) FromFile(path string) *Dataset {
 	in := &localInput{Path: path}
 	return newDataset(s, in)
 }
 
+// FromReader creates new Dataset by streaming the content of given reader from the driver.
+// The content is read in chunks of whole lines, and each chunk is converted into rows with split.
+// Since every row is pushed by the single driver, the throughput is limited by the driver;
+// it is suitable for small inputs like stdin, not for a distributed source.
+func (s *Session) FromReader(r io.Reader, split func([]byte) []*lrdd.Row) *Dataset {
+	in := &readerInput{r: r, split: split}
+	return newDataset(s, in)
+}
+
+// TextFile creates new Dataset of lines in the files under given path, which must be readable from the workers.
+// Files compressed with gzip or bzip2 are decompressed transparently. Each file is read by a single task
+// as a whole, since compressed files can't be split into blocks.
+func (s *Session) TextFile(path string) *Dataset {
+	return s.FromFile(path).Do(&textFileReader{})
+}
+
+// FromJobOutput creates new Dataset by reading output of the final stage of a completed job,
+// which must be run with Dataset.Persist. The output is read by the workers where it is persisted,
+// without passing through the master.
+func (s *Session) FromJobOutput(jobID string) *Dataset {
+	in := &jobOutputInput{JobID: jobID}
+	return newDataset(s, in)
+}
+
+// Union creates new Dataset from rows of the sources, which are datasets created by the Session without any
+// transformations (e.g. Parallelize and FromFile). Rows of every source are fed into the partitions of a single
+// input stage, spread like Parallelize regardless of the partitioning of each source. Since the persisted output
+// of a job is not fed by the driver, FromJobOutput can't be a source. It panics if any source is not eligible.
+func (s *Session) Union(sources ...*Dataset) *Dataset {
+	inputs := make([]InputProvider, len(sources))
+	for i, src := range sources {
+		if len(src.stages) > 1 {
+			panic("lrmr: source of Union should not have any transformations")
+		}
+		if _, ok := src.input.(*jobOutputInput); ok {
+			panic("lrmr: output of a job can't be a source of Union")
+		}
+		inputs[i] = src.input
+	}
+	return newDataset(s, &unionInput{inputs: inputs})
+}
+
+// Reattach returns a RunningJob of the job submitted by another driver, e.g. one crashed while the job
+// was running on the workers. The job can be waited for and its metrics can be read. Results of jobs run with
+// Dataset.Persist can be collected with RunningJob.Collect after reattaching. Since results collected with
+// Dataset.RunForCollect are only kept by the driver which ran the job, RunningJob.Collect and
+// RunningJob.CollectPartition of such jobs return ErrResultsNotRecoverable without waiting for the job.
+func (s *Session) Reattach(jobID string) (*RunningJob, error) {
+	j, err := s.master.Reattach(s.ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &RunningJob{
+		Master:     s.master,
+		Job:        j,
+		reattached: true,
+	}, nil
+}
+
 // Broadcast shares given value across the cluster. The data broadcasted this way
 // is cached in serialized form and deserialized before running each task.
 func (s *Session) Broadcast(key string, val interface{}) {
@@ -46,6 +129,27 @@ func (s *Session) Broadcast(key string, val interface{}) {
 }
 
 func (s *Session) Run(ds *Dataset) (*RunningJob, error) {
+	j, err := s.submit(ds)
+	if err != nil {
+		return nil, err
+	}
+	r := &RunningJob{
+		Master: s.master,
+		Job:    j,
+		peeks:  ds.peeks,
+
+		maxRetries: s.options.MaxRetries,
+	}
+	if isReplayable(ds.input) {
+		// the job can be run again, since its input can be fed again
+		r.rerun = func() (*job.Job, error) { return s.submit(ds) }
+		s.master.OnRebalance(j, r.reschedule)
+	}
+	return r, nil
+}
+
+// submit creates and starts a job running the dataset, and feeds the input to the job.
+func (s *Session) submit(ds *Dataset) (*job.Job, error) {
 	timer := log.Timer()
 
 	jobName := s.options.Name
@@ -59,20 +163,86 @@ func (s *Session) Run(ds *Dataset) (*RunningJob, error) {
 		defer cancel()
 	}
 
+	if err := checkRegisteredTypes(ds); err != nil {
+		return nil, err
+	}
+
 	var createJobOptions []master.CreateJobOption
+	if in, ok := ds.input.(*jobOutputInput); ok {
+		locations, err := s.master.JobManager.ListPersistedOutputs(ctx, in.JobID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read persisted output of job %s", in.JobID)
+		}
+		if len(locations) == 0 {
+			return nil, errors.Wrapf(ErrOutputNotPersisted, "job %s", in.JobID)
+		}
+		in.locations = locations
+		createJobOptions = append(createJobOptions, master.WithInputFromJob(in.JobID))
+	}
+	if ds.persist {
+		createJobOptions = append(createJobOptions, master.WithPersistedOutput())
+		if ds.persistCompression != job.NoCompression {
+			createJobOptions = append(createJobOptions, master.WithPersistCompression(ds.persistCompression))
+		}
+	}
+	if s.options.CollectAllErrors {
+		createJobOptions = append(createJobOptions, master.WithCollectAllErrors())
+	}
+	if s.options.Provenance {
+		createJobOptions = append(createJobOptions, master.WithProvenance())
+	}
+	if s.options.SkipEmptyPartitions {
+		createJobOptions = append(createJobOptions, master.WithSkipEmptyPartitions())
+	}
 	if s.options.NodeSelector != nil {
 		createJobOptions = append(createJobOptions, master.WithNodeSelector(s.options.NodeSelector))
 	}
+	if len(s.options.RequiredFeatures) > 0 {
+		createJobOptions = append(createJobOptions, master.WithRequiredFeatures(s.options.RequiredFeatures...))
+	}
+	if s.options.TaskTimeout > 0 {
+		createJobOptions = append(createJobOptions, master.WithTaskTimeout(s.options.TaskTimeout))
+	}
+	if s.options.Weight > 0 {
+		createJobOptions = append(createJobOptions, master.WithWeight(s.options.Weight))
+	}
+	if s.options.ReassignUnreachable {
+		createJobOptions = append(createJobOptions, master.WithReassignUnreachable())
+	}
+	if s.options.DeterministicLayout {
+		createJobOptions = append(createJobOptions, master.WithDeterministicLayout())
+	}
+	if s.options.OrderedCollect {
+		createJobOptions = append(createJobOptions, master.WithOrderedCollect())
+	}
+	if len(s.options.JobMetadata) > 0 {
+		createJobOptions = append(createJobOptions, master.WithMetadata(s.options.JobMetadata))
+	}
+	if s.options.PinnedLayout != nil {
+		createJobOptions = append(createJobOptions, master.WithPinnedLayout(s.options.PinnedLayout))
+	}
+	if s.options.IDGenerator != nil {
+		createJobOptions = append(createJobOptions, master.WithIDGenerator(s.options.IDGenerator))
+	}
+	// side inputs are collected before the job, since its tasks need them from the beginning
+	sideInputs, err := collectSideInputs(ds)
+	if err != nil {
+		return nil, err
+	}
 	j, err := s.master.CreateJob(ctx, jobName, ds.plans, ds.stages, createJobOptions...)
 	if err != nil {
 		return nil, err
 	}
 
-	broadcast, err := serialization.SerializeBroadcast(s.broadcasts)
+	broadcast, err := serialization.SerializeBroadcast(s.broadcasts, s.options.BroadcastCompressionThreshold)
 	if err != nil {
 		return nil, errors.Wrap(err, "serialize broadcast")
 	}
-	if err := s.master.StartJob(ctx, j, broadcast); err != nil {
+	var startJobOptions []master.StartJobOption
+	if s.options.SubmissionProgress != nil {
+		startJobOptions = append(startJobOptions, master.WithSubmissionProgress(s.options.SubmissionProgress))
+	}
+	if err := s.master.StartJob(ctx, j, broadcast, sideInputs, s.options.Params, startJobOptions...); err != nil {
 		return nil, errors.WithMessage(err, "assign task")
 	}
 
@@ -87,9 +257,21 @@ func (s *Session) Run(ds *Dataset) (*RunningJob, error) {
 		return nil, errors.Wrap(err, "close input")
 	}
 	timer.End("Job creation completed. Now running...")
+	return j, nil
+}
 
-	return &RunningJob{
-		Master: s.master,
-		Job:    j,
-	}, nil
+// collectSideInputs runs side input datasets of the dataset, and serializes their results.
+func collectSideInputs(ds *Dataset) (map[string][]byte, error) {
+	sideInputs := make(map[string][]byte, len(ds.sideInputs))
+	for name, side := range ds.sideInputs {
+		rows, err := side.Collect()
+		if err != nil {
+			return nil, errors.Wrapf(err, "collect side input %s", name)
+		}
+		sideInputs[name], err = serialization.SerializeSideInput(rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sideInputs, nil
 }
@@ -1,11 +1,87 @@
 package lrmr
 
-import "time"
+import (
+	"time"
+
+	"github.com/ab180/lrmr/job"
+)
 
 type SessionOptions struct {
 	Name         string
 	Timeout      time.Duration
 	NodeSelector map[string]string
+
+	// TaskTimeout aborts a task which does not make any progress, such as consuming input rows
+	// or calling Context.Heartbeat, for the duration. Zero means no timeout.
+	TaskTimeout time.Duration
+
+	// CollectAllErrors makes a job to wait for every task and collect all errors before failing,
+	// instead of failing on the first error. Errors are returned in job.Errors.
+	CollectAllErrors bool
+
+	// Provenance makes each row to carry its lineage, which are the stage, partition and index
+	// where the row is derived from, for debugging. It is disabled by default due to the overhead.
+	Provenance bool
+
+	// SkipEmptyPartitions completes tasks without running their transformations if their partitions receive
+	// no rows, reducing overhead of sparse data. Transformations emitting rows without any input
+	// (e.g. counting rows of a partition) would not emit them on empty partitions.
+	SkipEmptyPartitions bool
+
+	// RequiredFeatures refuses to run a job if any of the workers does not support the features,
+	// listed in the version package.
+	RequiredFeatures []string
+
+	// Weight is a share of task slots given to the jobs relative to other jobs running concurrently,
+	// on workers with worker.FairScheduling. By default, every job has the weight of 1.
+	Weight int
+
+	// PinnedLayout assigns partitions of the jobs to the hosts of the workers, keyed by partition IDs, instead of
+	// scheduling them. It is meant for reproducible tests and benchmarks.
+	PinnedLayout map[string]string
+
+	// ReassignUnreachable reassigns partitions on a worker which is unreachable on starting the jobs
+	// to other workers, instead of failing the jobs. Workers becoming unreachable later still fail the jobs.
+	ReassignUnreachable bool
+
+	// DeterministicLayout schedules partitions of the jobs to the workers in the order of their hosts, so that
+	// two runs of a job over identical input on the same set of workers have identical partition layouts.
+	// Rows are routed to the partitions deterministically regardless of the option.
+	DeterministicLayout bool
+
+	// OrderedCollect makes Collect to return rows in the order of the partition IDs, and rows of a partition in the
+	// order they are emitted, so that results of the jobs over identical input are identical regardless of
+	// the scheduling. Numeric partition IDs come first in numerical order (e.g. "2" before "10"), followed by
+	// the others in lexical order. Since the master holds every collected row until the last partition
+	// completes with or without the option, it costs no extra memory or latency beyond sorting the partition IDs
+	// once. It is meant for tests and display; use SortByKey if the results should be ordered by their contents.
+	OrderedCollect bool
+
+	// SubmissionProgress is called while the tasks of the jobs are being created on the workers, with the number
+	// of tasks created so far and the total number of tasks (e.g. to show "creating tasks: 340/1000").
+	SubmissionProgress func(created, total int)
+
+	// JobMetadata is business metadata attached to the jobs (e.g. owner, pipeline name, run ID).
+	// It is stored along the jobs and can be used to filter them with job.Manager.ListJobsByMetadata.
+	JobMetadata map[string]string
+
+	// BroadcastCompressionThreshold compresses broadcasts of the jobs whose serialized sizes are larger than
+	// the threshold in bytes, shrinking requests creating the tasks. Smaller broadcasts are sent as they are
+	// to avoid the overhead. Zero disables the compression.
+	BroadcastCompressionThreshold int
+
+	// MaxRetries runs a failed job again up to the number of times, if every error of the job is retryable
+	// by its class (see job.Classify and job.ErrorClass.Retryable). Unclassified errors are not retried.
+	// Jobs whose inputs can't be fed again (e.g. Session.FromReader) are never retried.
+	MaxRetries int
+
+	// IDGenerator overrides the ID scheme of the jobs, which is master.Options.IDGenerator by default.
+	// IDs of the tasks are derived from the IDs of their jobs.
+	IDGenerator job.IDGenerator
+
+	// Params are parameters of the jobs which every task can read with Context.Param.
+	// Unlike broadcasts, they are sent to the workers as they are, without serialization.
+	Params map[string]string
 }
 
 type SessionOption func(o *SessionOptions)
@@ -28,6 +104,120 @@ func WithNodeSelector(selector map[string]string) SessionOption {
 	}
 }
 
+func WithTaskTimeout(d time.Duration) SessionOption {
+	return func(o *SessionOptions) {
+		o.TaskTimeout = d
+	}
+}
+
+func WithCollectAllErrors() SessionOption {
+	return func(o *SessionOptions) {
+		o.CollectAllErrors = true
+	}
+}
+
+func WithProvenance() SessionOption {
+	return func(o *SessionOptions) {
+		o.Provenance = true
+	}
+}
+
+func WithSkipEmptyPartitions() SessionOption {
+	return func(o *SessionOptions) {
+		o.SkipEmptyPartitions = true
+	}
+}
+
+func WithWeight(w int) SessionOption {
+	return func(o *SessionOptions) {
+		o.Weight = w
+	}
+}
+
+func WithReassignUnreachable() SessionOption {
+	return func(o *SessionOptions) {
+		o.ReassignUnreachable = true
+	}
+}
+
+func WithDeterministicLayout() SessionOption {
+	return func(o *SessionOptions) {
+		o.DeterministicLayout = true
+	}
+}
+
+func WithPinnedLayout(layout map[string]string) SessionOption {
+	return func(o *SessionOptions) {
+		o.PinnedLayout = layout
+	}
+}
+
+func WithRequiredFeatures(features ...string) SessionOption {
+	return func(o *SessionOptions) {
+		o.RequiredFeatures = append(o.RequiredFeatures, features...)
+	}
+}
+
+// WithOrderedCollect makes Collect to return rows in the order of the partition IDs.
+func WithOrderedCollect() SessionOption {
+	return func(o *SessionOptions) {
+		o.OrderedCollect = true
+	}
+}
+
+// WithSubmissionProgress calls the function with the number of created tasks and the total number of tasks
+// while the jobs are being submitted.
+func WithSubmissionProgress(fn func(created, total int)) SessionOption {
+	return func(o *SessionOptions) {
+		o.SubmissionProgress = fn
+	}
+}
+
+// WithJobMetadata attaches the metadata to the jobs. It can be called multiple times to add more keys.
+func WithJobMetadata(md map[string]string) SessionOption {
+	return func(o *SessionOptions) {
+		if o.JobMetadata == nil {
+			o.JobMetadata = make(map[string]string, len(md))
+		}
+		for k, v := range md {
+			o.JobMetadata[k] = v
+		}
+	}
+}
+
+// WithBroadcastCompression compresses broadcasts larger than the threshold in bytes.
+func WithBroadcastCompression(threshold int) SessionOption {
+	return func(o *SessionOptions) {
+		o.BroadcastCompressionThreshold = threshold
+	}
+}
+
+// WithParams sets parameters of the jobs, which are read with Context.Param in the transformations.
+func WithParams(params map[string]string) SessionOption {
+	return func(o *SessionOptions) {
+		if o.Params == nil {
+			o.Params = make(map[string]string, len(params))
+		}
+		for k, v := range params {
+			o.Params[k] = v
+		}
+	}
+}
+
+// WithIDGenerator generates IDs of the jobs with given generator (see SessionOptions.IDGenerator).
+func WithIDGenerator(g job.IDGenerator) SessionOption {
+	return func(o *SessionOptions) {
+		o.IDGenerator = g
+	}
+}
+
+// WithMaxRetries retries failed jobs up to n times if their errors are retryable (see SessionOptions.MaxRetries).
+func WithMaxRetries(n int) SessionOption {
+	return func(o *SessionOptions) {
+		o.MaxRetries = n
+	}
+}
+
 func buildSessionOptions(opts []SessionOption) (o SessionOptions) {
 	for _, optFn := range opts {
 		optFn(&o)
@@ -0,0 +1,287 @@
+package lrmr
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+
+	"github.com/ab180/lrmr/internal/columnar"
+	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/transformation"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+var _ = RegisterTypes(&fileSink{})
+
+// Sink is a destination of the rows in the final stage. Implement it to write
+// results of a dataset into arbitrary destinations (e.g. databases, object stores).
+//
+// Each task of the final stage works on its own copy of the sink deserialized on the worker, so Close is called
+// once per task after its RowWriter is closed, not once per stage. Resources shared by the whole stage
+// (e.g. committing a transaction or a manifest of the written files) should be finalized by the driver
+// after Dataset.WriteTo returns.
+type Sink interface {
+	// Open opens a RowWriter for given partition. It is called once in each task of the final stage.
+	Open(partitionID string) (RowWriter, error)
+
+	// Close releases resources of the sink held by the task. It is called in each task of the final stage
+	// after its RowWriter is closed, even if writing the rows has failed.
+	Close() error
+}
+
+type RowWriter interface {
+	Write(rows ...*lrdd.Row) error
+	Close() error
+}
+
+// EncodingSink is a Sink receiving the rows encoded in its preferred format (e.g. JSON for REST APIs), instead of
+// the rows. The rows are encoded by the tasks of the final stage, regardless of the encoding used between the stages.
+type EncodingSink interface {
+	Sink
+
+	// Encoding returns the encoding of the rows written to the sink.
+	Encoding() RowEncoding
+
+	// OpenEncoded opens an EncodedRowWriter for given partition. It is called instead of Open.
+	OpenEncoded(partitionID string) (EncodedRowWriter, error)
+}
+
+type EncodedRowWriter interface {
+	WriteEncoded(rows ...[]byte) error
+	Close() error
+}
+
+// RowEncoding encodes a row into bytes written to an EncodingSink.
+type RowEncoding interface {
+	Encode(row *lrdd.Row) ([]byte, error)
+}
+
+var (
+	// JSONEncoding encodes the rows into JSON objects of lrdd.Row, whose values are base64-encoded.
+	JSONEncoding RowEncoding = jsonRowEncoding{}
+
+	// ProtobufEncoding encodes the rows into Protocol Buffers messages of lrdd.Row.
+	ProtobufEncoding RowEncoding = protobufRowEncoding{}
+)
+
+type jsonRowEncoding struct{}
+
+func (jsonRowEncoding) Encode(row *lrdd.Row) ([]byte, error) {
+	return jsoniter.Marshal(row)
+}
+
+type protobufRowEncoding struct{}
+
+func (protobufRowEncoding) Encode(row *lrdd.Row) ([]byte, error) {
+	return row.Marshal()
+}
+
+type sinkTransformation struct {
+	sink      Sink
+	rateLimit *RateLimit
+}
+
+func (s *sinkTransformation) Apply(ctx transformation.Context, in chan *lrdd.Row, _ output.Output) error {
+	if es, ok := s.sink.(EncodingSink); ok {
+		return s.applyEncoded(ctx, es, in)
+	}
+	w, err := s.sink.Open(ctx.PartitionID())
+	if err != nil {
+		return errors.Wrapf(err, "open sink for partition %s", ctx.PartitionID())
+	}
+	pace := s.pacer(ctx)
+	for row := range in {
+		err := pace()
+		if err == nil {
+			err = w.Write(row)
+		}
+		if err != nil {
+			_ = w.Close()
+			_ = s.sink.Close()
+			return errors.Wrapf(err, "write to sink for partition %s", ctx.PartitionID())
+		}
+	}
+	if err := w.Close(); err != nil {
+		_ = s.sink.Close()
+		return errors.Wrapf(err, "close sink for partition %s", ctx.PartitionID())
+	}
+	return s.sink.Close()
+}
+
+func (s *sinkTransformation) applyEncoded(ctx transformation.Context, sink EncodingSink, in chan *lrdd.Row) error {
+	enc := sink.Encoding()
+	w, err := sink.OpenEncoded(ctx.PartitionID())
+	if err != nil {
+		return errors.Wrapf(err, "open sink for partition %s", ctx.PartitionID())
+	}
+	pace := s.pacer(ctx)
+	for row := range in {
+		data, err := enc.Encode(row)
+		if err == nil {
+			err = pace()
+		}
+		if err == nil {
+			err = w.WriteEncoded(data)
+		}
+		if err != nil {
+			_ = w.Close()
+			_ = sink.Close()
+			return errors.Wrapf(err, "write to sink for partition %s", ctx.PartitionID())
+		}
+	}
+	if err := w.Close(); err != nil {
+		_ = sink.Close()
+		return errors.Wrapf(err, "close sink for partition %s", ctx.PartitionID())
+	}
+	return sink.Close()
+}
+
+// pacer returns a function blocking until a row can be written under the rate limit.
+func (s *sinkTransformation) pacer(ctx transformation.Context) func() error {
+	if s.rateLimit == nil {
+		return func() error { return nil }
+	}
+	limiter := s.rateLimit.limiterFor(ctx.NumPartitions())
+	return func() error {
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+		// waiting for the limit is not counted as the task being stuck
+		ctx.Heartbeat()
+		return nil
+	}
+}
+
+func (s *sinkTransformation) userType() interface{} {
+	return s.sink
+}
+
+type serializedSinkTransformation struct {
+	Sink      jsoniter.RawMessage `json:"sink"`
+	RateLimit *RateLimit          `json:"rateLimit,omitempty"`
+}
+
+func (s *sinkTransformation) MarshalJSON() ([]byte, error) {
+	sink, err := serialization.SerializeStruct(s.sink)
+	if err != nil {
+		return nil, err
+	}
+	return jsoniter.Marshal(serializedSinkTransformation{Sink: sink, RateLimit: s.rateLimit})
+}
+
+func (s *sinkTransformation) UnmarshalJSON(data []byte) error {
+	var st serializedSinkTransformation
+	if err := jsoniter.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	sink, err := serialization.DeserializeStruct(st.Sink)
+	if err != nil {
+		return err
+	}
+	s.sink = sink.(Sink)
+	s.rateLimit = st.RateLimit
+	return nil
+}
+
+type fileSink struct {
+	Dir      string
+	Columnar bool
+}
+
+// NewFileSink creates a Sink writing rows of each partition into a file named after the partition ID
+// under given directory. The rows are written in JSON lines format.
+func NewFileSink(dir string) Sink {
+	return &fileSink{Dir: dir}
+}
+
+// NewColumnarFileSink is like NewFileSink, but writes the rows in an internal columnar format which
+// is more compact than JSON lines. Rows of a partition are buffered in memory until the partition ends.
+// The files can be read with ReadColumnarFile; their layout is not guaranteed across versions.
+func NewColumnarFileSink(dir string) Sink {
+	return &fileSink{Dir: dir, Columnar: true}
+}
+
+func (f *fileSink) Open(partitionID string) (RowWriter, error) {
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(filepath.Join(f.Dir, partitionID))
+	if err != nil {
+		return nil, err
+	}
+	if f.Columnar {
+		return &columnarFileRowWriter{file: file}, nil
+	}
+	return &fileRowWriter{file: file, buf: bufio.NewWriter(file)}, nil
+}
+
+func (f *fileSink) Close() error {
+	return nil
+}
+
+type fileRowWriter struct {
+	file *os.File
+	buf  *bufio.Writer
+}
+
+func (f *fileRowWriter) Write(rows ...*lrdd.Row) error {
+	for _, row := range rows {
+		line, err := jsoniter.Marshal(row)
+		if err != nil {
+			return errors.Wrap(err, "marshal row")
+		}
+		if _, err := f.buf.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fileRowWriter) Close() error {
+	if err := f.buf.Flush(); err != nil {
+		_ = f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
+
+type columnarFileRowWriter struct {
+	file *os.File
+	rows []*lrdd.Row
+}
+
+func (c *columnarFileRowWriter) Write(rows ...*lrdd.Row) error {
+	c.rows = append(c.rows, rows...)
+	return nil
+}
+
+func (c *columnarFileRowWriter) Close() error {
+	buf := bufio.NewWriter(c.file)
+	if err := columnar.Encode(buf, c.rows); err != nil {
+		_ = c.file.Close()
+		return errors.Wrap(err, "encode rows")
+	}
+	if err := buf.Flush(); err != nil {
+		_ = c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
+
+// ReadColumnarFile reads rows from a file written by the sink created with NewColumnarFileSink.
+func ReadColumnarFile(path string) ([]*lrdd.Row, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows, err := columnar.Decode(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read %s", path)
+	}
+	return rows, nil
+}
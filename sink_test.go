@@ -0,0 +1,77 @@
+package lrmr
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	jsoniter "github.com/json-iterator/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFileSink(t *testing.T) {
+	Convey("Given a file sink", t, func() {
+		dir, err := ioutil.TempDir("", "lrmr-sink")
+		So(err, ShouldBeNil)
+		Reset(func() { _ = os.RemoveAll(dir) })
+
+		tf := &sinkTransformation{sink: NewFileSink(dir)}
+
+		Convey("Rows should be written in the file of the partition", func() {
+			in := make(chan *lrdd.Row, 3)
+			for _, row := range lrdd.From([]string{"foo", "bar", "baz"}) {
+				in <- row
+			}
+			close(in)
+			So(tf.Apply(stubContext{context.Background()}, in, nil), ShouldBeNil)
+
+			file, err := os.Open(filepath.Join(dir, "0"))
+			So(err, ShouldBeNil)
+			defer file.Close()
+
+			var values []string
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				row := new(lrdd.Row)
+				So(jsoniter.Unmarshal(scanner.Bytes(), row), ShouldBeNil)
+
+				var v string
+				row.UnmarshalValue(&v)
+				values = append(values, v)
+			}
+			So(values, ShouldResemble, []string{"foo", "bar", "baz"})
+		})
+	})
+
+	Convey("Given a columnar file sink", t, func() {
+		dir, err := ioutil.TempDir("", "lrmr-sink")
+		So(err, ShouldBeNil)
+		Reset(func() { _ = os.RemoveAll(dir) })
+
+		tf := &sinkTransformation{sink: NewColumnarFileSink(dir)}
+
+		Convey("Rows should be read back from the file of the partition", func() {
+			in := make(chan *lrdd.Row, 3)
+			for _, row := range lrdd.From([]string{"foo", "bar", "baz"}) {
+				in <- row
+			}
+			close(in)
+			So(tf.Apply(stubContext{context.Background()}, in, nil), ShouldBeNil)
+
+			rows, err := ReadColumnarFile(filepath.Join(dir, "0"))
+			So(err, ShouldBeNil)
+
+			var values []string
+			for _, row := range rows {
+				var v string
+				row.UnmarshalValue(&v)
+				values = append(values, v)
+			}
+			So(values, ShouldResemble, []string{"foo", "bar", "baz"})
+		})
+	})
+}
@@ -15,6 +15,16 @@ type Stage struct {
 	// Function is a transformation the stage executes.
 	Function transformation.Serializable `json:"function"`
 
+	// SideInputs are names of side inputs the transformation can look up.
+	SideInputs []string `json:"sideInputs,omitempty"`
+
+	// Peek is the number of output rows sampled from each partition for debugging. Zero disables sampling.
+	Peek int `json:"peek,omitempty"`
+
+	// FailureTolerance is the maximum fraction of the partitions which can fail without failing the stage.
+	// Rows of the failed partitions are dropped from the output. Zero fails the stage on any failure.
+	FailureTolerance float64 `json:"failureTolerance,omitempty"`
+
 	Output Output
 }
 
@@ -35,6 +45,36 @@ func (s *Stage) SetOutputTo(dest Stage) {
 type Input struct {
 	Stage string             `json:"stage"`
 	Type  serialization.Type `json:"type"`
+
+	// MergeSortedBy is the ordering of the keys which rows from each upstream partition are sorted with.
+	// If set, the rows from the upstream partitions are merged in the order instead of the arrival order.
+	MergeSortedBy *SerializableOrdering `json:"mergeSortedBy,omitempty"`
+}
+
+// KeyOrdering compares keys of rows. Compare returns a negative number if a < b,
+// zero if a == b, and a positive number if a > b.
+type KeyOrdering interface {
+	Compare(a, b string) int
+}
+
+// SerializableOrdering wraps a KeyOrdering to be serialized with its type.
+type SerializableOrdering struct {
+	KeyOrdering
+}
+
+func (s SerializableOrdering) MarshalJSON() ([]byte, error) {
+	return serialization.SerializeStruct(s.KeyOrdering)
+}
+
+func (s *SerializableOrdering) UnmarshalJSON(data []byte) error {
+	v, err := serialization.DeserializeStruct(data)
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		s.KeyOrdering = v.(KeyOrdering)
+	}
+	return nil
 }
 
 func InputFrom(s Stage) Input {
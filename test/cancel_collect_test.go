@@ -0,0 +1,36 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCancelCollect(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When cancelling the collection of a slow job", func() {
+			j, err := SlowTask(cluster.Session, time.Second, 0).RunForCollect()
+			So(err, ShouldBeNil)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			startedAt := time.Now()
+			_, err = j.CollectWithContext(ctx)
+
+			Convey("Collect should return promptly", func() {
+				So(err, ShouldResemble, context.DeadlineExceeded)
+				So(time.Since(startedAt), ShouldBeLessThan, 500*time.Millisecond)
+			})
+
+			Convey("The job should not fail", func() {
+				So(j.Wait(), ShouldBeNil)
+				So(j.Status(), ShouldEqual, job.Succeeded)
+			})
+		})
+	}))
+}
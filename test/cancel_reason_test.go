@@ -0,0 +1,57 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCancelReason(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		for _, reason := range []job.CancelReason{
+			job.CancelledByUser,
+			job.CancelledByTimeout,
+			job.CancelledByRebalance,
+			job.CancelledByDeadNode,
+		} {
+			reason := reason
+			Convey("When a job is aborted by "+string(reason), func() {
+				j, err := SlowTask(cluster.Session, time.Second, 100*time.Millisecond).Run()
+				So(err, ShouldBeNil)
+
+				err = j.AbortWithReason(context.Background(), reason)
+				So(errors.Is(err, lrmr.Aborted), ShouldBeTrue)
+				So(job.CancelReasonOf(err), ShouldEqual, reason)
+
+				Convey("The reason should be found in the final status", func() {
+					_ = j.Wait()
+					So(j.CancelReason(), ShouldEqual, reason)
+				})
+
+				Convey("The reason should be found in the errors of the job", func() {
+					errs, err := cluster.Master().JobManager.GetJobErrors(context.Background(), j.ID)
+					So(err, ShouldBeNil)
+					So(errs, ShouldHaveLength, 1)
+					So(errs[0].CancelReason, ShouldEqual, reason)
+					So(errors.Is(errs[0], lrmr.Aborted), ShouldBeTrue)
+				})
+			})
+		}
+
+		Convey("When a job fails without being aborted", func() {
+			j, err := FailingJob(cluster.Session).Run()
+			So(err, ShouldBeNil)
+
+			Convey("It should not have any reason of cancellation", func() {
+				So(j.Wait(), ShouldNotBeNil)
+				So(j.CancelReason(), ShouldEqual, job.NotCancelled)
+			})
+		})
+	}))
+}
@@ -0,0 +1,38 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr/master"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClusterCapacity(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(3, func(cluster *integration.LocalCluster) {
+		Convey("Capacities of the workers should be summed", func() {
+			info, err := cluster.Master().ClusterCapacity(context.Background())
+			So(err, ShouldBeNil)
+			So(info, ShouldResemble, master.ClusterInfo{
+				Workers: 3,
+				Cores:   6,
+				Memory:  3 << 30,
+			})
+		})
+
+		Convey("When a worker is draining", func() {
+			So(cluster.DrainWorker(0), ShouldBeNil)
+
+			Convey("It should not be counted", func() {
+				info, err := cluster.Master().ClusterCapacity(context.Background())
+				So(err, ShouldBeNil)
+				So(info, ShouldResemble, master.ClusterInfo{
+					Workers: 2,
+					Cores:   4,
+					Memory:  2 << 30,
+				})
+			})
+		})
+	}))
+}
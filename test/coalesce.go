@@ -0,0 +1,32 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&partitionRowCounter{})
+
+// CoalescedCount counts rows of 16 small partitions in 4 coalesced partitions.
+func CoalescedCount(sess *lrmr.Session) *lrmr.Dataset {
+	data := make([]int, 64)
+	for i := range data {
+		data[i] = i
+	}
+	return sess.ParallelizeN(data, 16).
+		Map(&Multiply{}).
+		Coalesce(4).
+		Do(&partitionRowCounter{})
+}
+
+// partitionRowCounter emits the number of rows in its partition, keyed by the partition ID.
+type partitionRowCounter struct{}
+
+func (p *partitionRowCounter) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	count := 0
+	for range in {
+		count++
+	}
+	emit(lrdd.KeyValue(ctx.PartitionID(), count))
+	return nil
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCoalesce(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When coalescing many small partitions", func() {
+			ds := CoalescedCount(cluster.Session)
+
+			Convey("They should be consumed by fewer tasks without losing rows", func() {
+				rows, err := ds.Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 4)
+
+				total := 0
+				for _, row := range rows {
+					// each task consumes 4 partitions of 4 rows
+					So(testutils.IntValue(row), ShouldEqual, 16)
+					total += testutils.IntValue(row)
+				}
+				So(total, ShouldEqual, 64)
+			})
+		})
+	}))
+}
@@ -0,0 +1,22 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(PartitionFailingStage{})
+
+// PartitionFailingStage fails on every partition after consuming its input.
+type PartitionFailingStage struct{}
+
+func (f PartitionFailingStage) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for range in {
+	}
+	return errors.Errorf("partition %s failed", ctx.PartitionID())
+}
+
+func CollectAllErrors(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8}, 4).Do(PartitionFailingStage{})
+}
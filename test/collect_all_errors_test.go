@@ -0,0 +1,30 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectAllErrors(t *testing.T) {
+	Convey("Running a job failing on every partition with collecting all errors", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		ds := CollectAllErrors(cluster.Session)
+
+		Convey("Errors of every partition should be reported on Wait", func() {
+			j, err := ds.Run()
+			So(err, ShouldBeNil)
+
+			err = j.Wait()
+			So(err, ShouldHaveSameTypeAs, job.Errors{})
+			So(err.(job.Errors), ShouldHaveLength, 4)
+		})
+		Convey("Errors of every partition should be reported on Collect", func() {
+			_, err := ds.Collect()
+			So(err, ShouldHaveSameTypeAs, job.Errors{})
+			So(err.(job.Errors), ShouldHaveLength, 4)
+		})
+	}, lrmr.WithCollectAllErrors()))
+}
@@ -0,0 +1,19 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+)
+
+// scoreRecord is a row value of CollectColumns.
+type scoreRecord struct {
+	Name  string
+	Score int
+}
+
+func CollectColumns(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize([]scoreRecord{
+		{Name: "foo", Score: 1},
+		{Name: "bar", Score: 2},
+		{Name: "baz", Score: 3},
+	}).Map(NopMapper())
+}
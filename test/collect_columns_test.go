@@ -0,0 +1,34 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectColumns(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When collecting results of a job into columns", func() {
+			j, err := CollectColumns(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+
+			b, err := j.CollectColumns(lrmr.ColumnSchema{
+				{Name: "Name", Type: lrmr.StringColumn},
+				{Name: "Score", Type: lrmr.Int64Column},
+			})
+			So(err, ShouldBeNil)
+
+			Convey("Each column should have values of the rows", func() {
+				So(b.NumRows, ShouldEqual, 3)
+
+				scores := make(map[string]int64)
+				for i, name := range b.Columns[0].([]string) {
+					scores[name] = b.Columns[1].([]int64)[i]
+				}
+				So(scores, ShouldResemble, map[string]int64{"foo": 1, "bar": 2, "baz": 3})
+			})
+		})
+	}))
+}
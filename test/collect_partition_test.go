@@ -0,0 +1,37 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectPartition(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When running stage with custom partitioner", func() {
+			j, err := PartitionerWithNodeAffinityTest(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+
+			Convey("It should only return results from given partition", func() {
+				for _, partitionID := range []string{"key1-1", "key1-2", "key2-1", "key2-2"} {
+					rows, err := j.CollectPartition(partitionID)
+					So(err, ShouldBeNil)
+					So(rows, ShouldHaveLength, 1)
+					So(testutils.StringValue(rows[0]), ShouldEqual, partitionID)
+				}
+
+				Convey("The results should be released after every partition is retrieved", func() {
+					_, err := j.CollectPartition("key1-1")
+					So(err, ShouldNotBeNil)
+				})
+			})
+
+			Convey("It should return error for unknown partition", func() {
+				_, err := j.CollectPartition("key3-1")
+				So(err, ShouldNotBeNil)
+			})
+		})
+	}))
+}
@@ -26,3 +26,11 @@ func (cnt *counter) Reduce(c lrmr.Context, prev interface{}, cur *lrdd.Row) (nex
 	cnt.value = prev.(uint64) + 1
 	return cnt.value, nil
 }
+
+// MergePartial sums counts of a key counted in multiple partitions.
+func (cnt *counter) MergePartial(c lrmr.Context, prev interface{}, partial *lrdd.Row) (next interface{}, err error) {
+	var partialCount uint64
+	partial.UnmarshalValue(&partialCount)
+	cnt.value = prev.(uint64) + partialCount
+	return cnt.value, nil
+}
@@ -0,0 +1,23 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+)
+
+// rowsPerPartition is the number of rows planned for each partition of the deferred stage.
+const rowsPerPartition = 25
+
+// DeferredRepartition decides the number of partitions of the second stage by the row count of the first stage.
+func DeferredRepartition(sess *lrmr.Session) *lrmr.DeferredDataset {
+	data := make([]int, 100)
+	for i := 0; i < len(data); i++ {
+		data[i] = i + 1
+	}
+	return sess.Parallelize(data).
+		Map(&Multiply{}).
+		Then(func(next *lrmr.Dataset, out lrmr.OutputSummary) *lrmr.Dataset {
+			return next.Map(&Multiply{}).
+				Repartition(out.Rows / rowsPerPartition).
+				Do(&taskIntrospector{})
+		})
+}
@@ -0,0 +1,21 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeferredStage(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When building a stage from the output of the preceding stage", func() {
+			rows, err := DeferredRepartition(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Partition count of the stage should be derived from the row count", func() {
+				So(rows, ShouldHaveLength, 100/rowsPerPartition)
+			})
+		})
+	}))
+}
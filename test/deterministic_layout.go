@@ -0,0 +1,34 @@
+package test
+
+import (
+	"sort"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&partitionContents{})
+
+// partitionContents emits keys of the rows in its partition, with the number of the worker running it.
+type partitionContents struct{}
+
+func (p *partitionContents) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	var keys []string
+	for row := range in {
+		keys = append(keys, row.Key)
+	}
+	sort.Strings(keys)
+	emit(lrdd.KeyValue(ctx.PartitionID(), map[string]interface{}{
+		"worker": ctx.WorkerLocalOption("No"),
+		"keys":   keys,
+	}))
+	return nil
+}
+
+func DeterministicLayout(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize(map[string][]int{
+		"a": {1}, "b": {2}, "c": {3}, "d": {4}, "e": {5}, "f": {6}, "g": {7}, "h": {8},
+	}).
+		GroupByKey().
+		Do(&partitionContents{})
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeterministicLayout(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(3, func(cluster *integration.LocalCluster) {
+		Convey("When running the same job twice with WithDeterministicLayout", func() {
+			first, firstContents := runForPartitionContents(cluster.Session)
+			second, secondContents := runForPartitionContents(cluster.Session)
+
+			Convey("Partitions should be assigned to the same hosts", func() {
+				So(second.Partitions[1:], ShouldResemble, first.Partitions[1:])
+			})
+
+			Convey("Each partition should have the same rows on the same worker", func() {
+				So(firstContents, ShouldNotBeEmpty)
+				So(secondContents, ShouldResemble, firstContents)
+			})
+		})
+	}, lrmr.WithDeterministicLayout()))
+}
+
+func runForPartitionContents(sess *lrmr.Session) (*lrmr.RunningJob, map[string]map[string]interface{}) {
+	j, err := DeterministicLayout(sess).RunForCollect()
+	So(err, ShouldBeNil)
+
+	rows, err := j.Collect()
+	So(err, ShouldBeNil)
+
+	contents := make(map[string]map[string]interface{})
+	for _, row := range rows {
+		var c map[string]interface{}
+		row.UnmarshalValue(&c)
+		contents[row.Key] = c
+	}
+	return j, contents
+}
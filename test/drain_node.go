@@ -0,0 +1,28 @@
+package test
+
+import (
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&workerNumberEmitter{})
+
+// workerNumberEmitter emits the number of the worker which each partition runs on, after given delay.
+type workerNumberEmitter struct {
+	Delay time.Duration
+}
+
+func (w *workerNumberEmitter) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for range in {
+	}
+	time.Sleep(w.Delay)
+	emit(lrdd.KeyValue(ctx.PartitionID(), ctx.WorkerLocalOption("No")))
+	return nil
+}
+
+func DrainNode(sess *lrmr.Session, delay time.Duration) *lrmr.Dataset {
+	return sess.Parallelize([]int{1, 2, 3, 4, 5}).
+		Do(&workerNumberEmitter{Delay: delay})
+}
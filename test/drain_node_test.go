@@ -0,0 +1,46 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDrainNode(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When a worker is drained while running a job", func() {
+			j, err := DrainNode(cluster.Session, 500*time.Millisecond).RunForCollect()
+			So(err, ShouldBeNil)
+			So(j.GetPartitionsOfStage("workerNumberEmitter0").GroupIDsByHost(), ShouldHaveLength, 2)
+
+			submittedID := j.ID
+			time.Sleep(100 * time.Millisecond)
+			So(cluster.DrainWorker(0), ShouldBeNil)
+
+			Convey("The running job should complete after being rescheduled on the remaining worker", func() {
+				rows, err := j.Collect()
+				So(err, ShouldBeNil)
+				So(j.ID, ShouldNotEqual, submittedID)
+				So(rows, ShouldHaveLength, len(j.GetPartitionsOfStage("workerNumberEmitter0")))
+				for _, row := range rows {
+					var workerNo int
+					row.UnmarshalValue(&workerNo)
+					So(workerNo, ShouldEqual, 2)
+				}
+
+				Convey("Jobs submitted later should run only on the remaining worker", func() {
+					rows, err := DrainNode(cluster.Session, 0).Collect()
+					So(err, ShouldBeNil)
+					So(rows, ShouldNotBeEmpty)
+					for _, row := range rows {
+						var workerNo int
+						row.UnmarshalValue(&workerNo)
+						So(workerNo, ShouldEqual, 2)
+					}
+				})
+			})
+		})
+	}))
+}
@@ -0,0 +1,44 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/testutils"
+)
+
+var _ = lrmr.RegisterTypes(&DoubleEmitter{})
+
+// DoubleEmitter emits each input multiplied by 2, with either single or batched emits.
+type DoubleEmitter struct {
+	Batched   bool
+	BatchSize int
+}
+
+func (d *DoubleEmitter) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	var batch []*lrdd.Row
+	for row := range in {
+		out := lrdd.Value(testutils.IntValue(row) * 2)
+		if !d.Batched {
+			emit(out)
+			continue
+		}
+		batch = append(batch, out)
+		if len(batch) >= d.BatchSize {
+			ctx.EmitBatch(batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		ctx.EmitBatch(batch)
+	}
+	return nil
+}
+
+func EmitWith(sess *lrmr.Session, batched bool) *lrmr.Dataset {
+	data := make([]int, 1000)
+	for i := 0; i < len(data); i++ {
+		data[i] = i + 1
+	}
+	return sess.Parallelize(data).
+		Do(&DoubleEmitter{Batched: batched, BatchSize: 64})
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEmitBatch(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When emitting rows in batch", func() {
+			batchedRows, err := EmitWith(cluster.Session, true).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("It should produce identical results with emitting rows one by one", func() {
+				singleRows, err := EmitWith(cluster.Session, false).Collect()
+				So(err, ShouldBeNil)
+
+				So(batchedRows, ShouldHaveLength, 1000)
+				So(sortedIntValues(batchedRows), ShouldResemble, sortedIntValues(singleRows))
+			})
+		})
+	}))
+}
+
+func sortedIntValues(rows []*lrdd.Row) []int {
+	values := make([]int, len(rows))
+	for i, row := range rows {
+		values[i] = testutils.IntValue(row)
+	}
+	sort.Ints(values)
+	return values
+}
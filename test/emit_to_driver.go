@@ -0,0 +1,26 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&partitionSummarizer{})
+
+// partitionSummarizer passes rows through, and emits the number of rows in its partition to the driver.
+type partitionSummarizer struct{}
+
+func (p *partitionSummarizer) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	count := 0
+	for row := range in {
+		emit(row)
+		count++
+	}
+	ctx.EmitToDriver(lrdd.KeyValue(ctx.PartitionID(), count))
+	return nil
+}
+
+func EmitToDriver(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 4).
+		Do(&partitionSummarizer{})
+}
@@ -0,0 +1,35 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEmitToDriver(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When each task emits a summary to the driver", func() {
+			j, err := EmitToDriver(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+
+			rows, err := j.Collect()
+			So(err, ShouldBeNil)
+			So(rows, ShouldHaveLength, 10)
+
+			Convey("The driver should read the summary of every task", func() {
+				summaries, err := j.DriverOutputs()
+				So(err, ShouldBeNil)
+				So(summaries, ShouldHaveLength, 4)
+
+				total := 0
+				for _, row := range summaries {
+					var count int
+					row.UnmarshalValue(&count)
+					total += count
+				}
+				So(total, ShouldEqual, 10)
+			})
+		})
+	}))
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestErrorDetail(t *testing.T) {
+	Convey("Given an aborted job", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		j, err := SlowTask(cluster.Session, 3*time.Second, 100*time.Millisecond).Run()
+		So(err, ShouldBeNil)
+		So(errors.Is(j.Abort(), lrmr.Aborted), ShouldBeTrue)
+
+		Convey("Errors read from the coordinator should be detected as Aborted", func() {
+			errs, err := cluster.Master().JobManager.GetJobErrors(context.Background(), j.ID)
+			So(err, ShouldBeNil)
+			So(errs, ShouldNotBeEmpty)
+			So(errors.Is(errs[0], lrmr.Aborted), ShouldBeTrue)
+
+			details, err := cluster.Master().JobManager.GetJobErrorDetails(context.Background(), j.ID)
+			So(err, ShouldBeNil)
+			So(details[errs[0].Task].Message, ShouldEqual, lrmr.Aborted.Error())
+		})
+	}))
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExportPlan(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		exporter := lrmr.NewSession(context.Background(), cluster.Master(),
+			lrmr.WithParams(map[string]string{"date": "2021-03-01"}))
+		importer := lrmr.NewSession(context.Background(), cluster.Master(), lrmr.WithTimeout(30*time.Second))
+
+		Convey("When a multi-stage plan is exported and imported by another session", func() {
+			blob, err := exporter.ExportPlan(Map(exporter))
+			So(err, ShouldBeNil)
+
+			ds, err := importer.ImportPlan(blob)
+			So(err, ShouldBeNil)
+
+			Convey("The imported plan should run identically", func() {
+				rows, err := ds.Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 1000)
+
+				sum := 0
+				for _, row := range rows {
+					sum += testutils.IntValue(row)
+				}
+				So(sum, ShouldEqual, 8*1000*1001/2)
+			})
+		})
+
+		Convey("When a plan reading parameters is exported", func() {
+			blob, err := exporter.ExportPlan(ParamTagged(exporter))
+			So(err, ShouldBeNil)
+
+			ds, err := importer.ImportPlan(blob)
+			So(err, ShouldBeNil)
+
+			Convey("The parameters should be imported along the plan", func() {
+				rows, err := ds.Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 8)
+				for _, row := range rows {
+					So(row.Key, ShouldEqual, "2021-03-01")
+				}
+			})
+		})
+
+		Convey("When a plan reading from the driver is exported", func() {
+			ds := exporter.FromReader(strings.NewReader("a\nb\n"), func(chunk []byte) []*lrdd.Row {
+				return []*lrdd.Row{lrdd.Value(string(chunk))}
+			})
+			_, err := exporter.ExportPlan(ds.Map(&Multiply{}))
+
+			Convey("It should fail as not portable", func() {
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrPlanNotPortable)
+			})
+		})
+	}))
+}
@@ -0,0 +1,32 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/testutils"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(&midPartitionFailer{})
+
+// midPartitionFailer fails the task with Context.Fail on the row having FailAt in the middle of the partition.
+type midPartitionFailer struct {
+	FailAt int
+}
+
+func (m *midPartitionFailer) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for row := range in {
+		if testutils.IntValue(row) == m.FailAt {
+			ctx.Fail(job.Classify(errors.Errorf("unrecoverable row %d", m.FailAt), job.UserError))
+			return errors.New("this error should be ignored")
+		}
+		emit(row)
+	}
+	return nil
+}
+
+// FailMidPartition runs a stage failing on given row.
+func FailMidPartition(sess *lrmr.Session, failAt int) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 1).Do(&midPartitionFailer{FailAt: failAt})
+}
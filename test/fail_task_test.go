@@ -0,0 +1,29 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFailTask(t *testing.T) {
+	Convey("Running a job whose transformation calls Context.Fail in the middle of a partition", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		j, err := FailMidPartition(cluster.Session, 5).Run()
+		So(err, ShouldBeNil)
+
+		err = j.Wait()
+
+		Convey("The task should fail with the exact error given to Fail", func() {
+			So(err, ShouldHaveSameTypeAs, job.Error{})
+			So(err.(job.Error).Message, ShouldEqual, "unrecoverable row 5")
+		})
+
+		Convey("The error should keep its classification", func() {
+			So(err, ShouldHaveSameTypeAs, job.Error{})
+			So(err.(job.Error).Class, ShouldEqual, job.UserError)
+			So(err.(job.Error).Retryable(), ShouldBeFalse)
+		})
+	}))
+}
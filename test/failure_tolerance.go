@@ -0,0 +1,65 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(&partitionFailer{}, &modKeyer{})
+
+// partitionFailer fails tasks of given partitions in the middle of their input, after emitting FailAfter rows.
+// Rows of the others are passed through.
+type partitionFailer struct {
+	FailingPartitions []string
+	FailAfter         int
+}
+
+func (p *partitionFailer) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	failing := false
+	for _, id := range p.FailingPartitions {
+		if ctx.PartitionID() == id {
+			failing = true
+		}
+	}
+	emitted := 0
+	for row := range in {
+		if failing && emitted == p.FailAfter {
+			return errors.Errorf("partition %s is broken", ctx.PartitionID())
+		}
+		emit(row)
+		emitted++
+	}
+	return nil
+}
+
+// modKeyer keys rows with their integer values modulo N.
+type modKeyer struct {
+	N int
+}
+
+func (m *modKeyer) Map(_ lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	var n int
+	row.UnmarshalValue(&n)
+	return lrdd.KeyValue(strconv.Itoa(n%m.N), n), nil
+}
+
+// FailureTolerance runs a stage tolerating a fifth of 10 partitions to fail, with given partitions failing
+// in the middle of 1000 rows of their inputs shuffled from an upstream stage.
+func FailureTolerance(sess *lrmr.Session, failingPartitions ...string) *lrmr.Dataset {
+	data := make([]int, 10000)
+	for i := range data {
+		data[i] = i
+	}
+	keys := make([]string, 10)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return sess.ParallelizeN(data, 10).
+		Map(&modKeyer{N: len(keys)}).
+		GroupByKnownKeys(keys).
+		Do(&partitionFailer{FailingPartitions: failingPartitions, FailAfter: 10}).
+		WithFailureTolerance(0.2)
+}
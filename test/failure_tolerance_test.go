@@ -0,0 +1,60 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFailureTolerance(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When partitions fail within the failure tolerance of the stage", func() {
+			j, err := FailureTolerance(cluster.Session, "3", "7").RunForCollect()
+			So(err, ShouldBeNil)
+
+			rows, err := j.Collect()
+
+			Convey("The job should complete without any rows of the failed partitions", func() {
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 8000)
+				for _, row := range rows {
+					So(row.Key, ShouldNotBeIn, "3", "7")
+				}
+				So(j.Wait(), ShouldBeNil)
+				So(j.Status(), ShouldEqual, job.Succeeded)
+			})
+
+			Convey("The failed partitions should be reported as dropped", func() {
+				dropped, err := j.DroppedPartitions(context.TODO())
+				So(err, ShouldBeNil)
+				So(dropped, ShouldHaveLength, 2)
+
+				var ids []string
+				for _, d := range dropped {
+					So(d.StageName, ShouldEqual, "partitionFailer1")
+					So(d.Error.Message, ShouldContainSubstring, "is broken")
+					ids = append(ids, d.PartitionID)
+				}
+				So(ids, ShouldContain, "3")
+				So(ids, ShouldContain, "7")
+			})
+		})
+
+		Convey("When more partitions fail than the failure tolerance", func() {
+			j, err := FailureTolerance(cluster.Session, "1", "3", "7").RunForCollect()
+			So(err, ShouldBeNil)
+
+			_, err = j.Collect()
+
+			Convey("The job should fail", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "is broken")
+				So(j.Wait(), ShouldNotBeNil)
+				So(j.Status(), ShouldEqual, job.Failed)
+			})
+		})
+	}))
+}
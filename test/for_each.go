@@ -0,0 +1,42 @@
+package test
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/testutils"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(&invocationRecorder{})
+
+// invocations are the number of times each row is handled by invocationRecorder, by the values of the rows.
+// It is shared by the workers running in the same process with the test.
+var invocations sync.Map
+
+// invocationRecorder records handled rows in invocations. It fails on the row with the value of FailOn, if set.
+type invocationRecorder struct {
+	FailOn int
+}
+
+func (r *invocationRecorder) ForEach(_ lrmr.Context, row *lrdd.Row) error {
+	n := testutils.IntValue(row)
+	if r.FailOn != 0 && n == r.FailOn {
+		return errors.Errorf("failed on %d", n)
+	}
+	count, _ := invocations.LoadOrStore(strconv.Itoa(n), new(int32))
+	*count.(*int32)++
+	return nil
+}
+
+func ForEach(sess *lrmr.Session, failOn int) (int, error) {
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i + 1
+	}
+	return sess.ParallelizeN(data, 4).
+		Map(&Multiply{}).
+		ForEach(&invocationRecorder{FailOn: failOn})
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestForEach(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		invocations = sync.Map{}
+
+		Convey("When running a function on each row", func() {
+			handled, err := ForEach(cluster.Session, 0)
+			So(err, ShouldBeNil)
+
+			Convey("Every row should be handled exactly once across the partitions", func() {
+				So(handled, ShouldEqual, 100)
+				for i := 1; i <= 100; i++ {
+					count, ok := invocations.Load(strconv.Itoa(i * 2))
+					So(ok, ShouldBeTrue)
+					So(*count.(*int32), ShouldEqual, 1)
+				}
+			})
+		})
+
+		Convey("When the function fails on a row", func() {
+			_, err := ForEach(cluster.Session, 50)
+
+			Convey("The error should be reported to the driver", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "failed on 50")
+			})
+		})
+	}))
+}
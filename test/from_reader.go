@@ -0,0 +1,34 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&upperCase{})
+
+// upperCase converts string rows to upper case.
+type upperCase struct{}
+
+func (u *upperCase) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	var s string
+	row.UnmarshalValue(&s)
+	return lrdd.Value(strings.ToUpper(s)), nil
+}
+
+// splitLines converts each line of the chunk into a row.
+func splitLines(chunk []byte) (rows []*lrdd.Row) {
+	for _, line := range bytes.Split(bytes.TrimSuffix(chunk, []byte("\n")), []byte("\n")) {
+		rows = append(rows, lrdd.Value(string(line)))
+	}
+	return rows
+}
+
+func FromReader(sess *lrmr.Session, r io.Reader) *lrmr.Dataset {
+	return sess.FromReader(r, splitLines).
+		Map(&upperCase{})
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFromReader(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When streaming input from a reader on the driver", func() {
+			buf := bytes.NewBufferString("foo\nbar\nbaz\n")
+			rows, err := FromReader(cluster.Session, buf).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Every line should reach the first stage", func() {
+				var lines []string
+				for _, row := range rows {
+					var s string
+					row.UnmarshalValue(&s)
+					lines = append(lines, s)
+				}
+				sort.Strings(lines)
+				So(lines, ShouldResemble, []string{"BAR", "BAZ", "FOO"})
+			})
+		})
+	}))
+}
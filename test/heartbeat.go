@@ -0,0 +1,39 @@
+package test
+
+import (
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&slowTransformer{})
+
+// slowTransformer spends given duration after consuming inputs, calling Context.Heartbeat
+// in every HeartbeatInterval if it's set.
+type slowTransformer struct {
+	Duration          time.Duration
+	HeartbeatInterval time.Duration
+}
+
+func (s *slowTransformer) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for range in {
+	}
+	if s.HeartbeatInterval == 0 {
+		time.Sleep(s.Duration)
+		return nil
+	}
+	deadline := time.Now().Add(s.Duration)
+	for time.Now().Before(deadline) {
+		time.Sleep(s.HeartbeatInterval)
+		ctx.Heartbeat()
+	}
+	return nil
+}
+
+// SlowTask runs a slow stage, followed by a stage waiting for the slow stage.
+func SlowTask(sess *lrmr.Session, duration, heartbeatInterval time.Duration) *lrmr.Dataset {
+	return sess.Parallelize([]int{1, 2, 3}).
+		Do(&slowTransformer{Duration: duration, HeartbeatInterval: heartbeatInterval}).
+		Do(&slowTransformer{})
+}
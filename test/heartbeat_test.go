@@ -0,0 +1,29 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHeartbeat(t *testing.T) {
+	Convey("Given running nodes with task timeout", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("A slow task calling Heartbeat should not be aborted", func() {
+			j, err := SlowTask(cluster.Session, 1500*time.Millisecond, 100*time.Millisecond).Run()
+			So(err, ShouldBeNil)
+			So(j.Wait(), ShouldBeNil)
+		})
+
+		Convey("A slow task without Heartbeat should be aborted", func() {
+			j, err := SlowTask(cluster.Session, 1500*time.Millisecond, 0).Run()
+			So(err, ShouldBeNil)
+
+			err = j.Wait()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "task timed out")
+		})
+	}, lrmr.WithTaskTimeout(500*time.Millisecond)))
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/atomic"
+)
+
+func TestSessionIDGenerator(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		ctx := context.Background()
+
+		Convey("When a job is submitted with a session-level ID generator", func() {
+			gen := &sequentialIDGenerator{run: time.Now().UnixNano()}
+			sess := lrmr.NewSession(ctx, cluster.Master(), lrmr.WithIDGenerator(gen))
+
+			j, err := Map(sess).Run()
+			So(err, ShouldBeNil)
+			So(j.Wait(), ShouldBeNil)
+
+			Convey("The job should be identified by the generated ID", func() {
+				So(j.Job.ID, ShouldEqual, gen.idOf(1))
+
+				_, err := cluster.Master().JobManager.GetJob(ctx, gen.idOf(1))
+				So(err, ShouldBeNil)
+			})
+
+			Convey("IDs of the tasks should be derived from the generated ID", func() {
+				var numTasks int
+				for i, s := range j.Job.Stages[1:] {
+					for _, a := range j.Job.Partitions[i+1] {
+						ref := job.TaskID{JobID: gen.idOf(1), StageName: s.Name, PartitionID: a.PartitionID}
+						_, err := cluster.Master().JobManager.GetTaskStatus(ctx, ref)
+						So(err, ShouldBeNil)
+						numTasks++
+					}
+				}
+				So(numTasks, ShouldBeGreaterThan, 0)
+
+				statuses, err := cluster.Master().JobManager.ListTaskStatusesInJob(ctx, gen.idOf(1))
+				So(err, ShouldBeNil)
+				So(statuses, ShouldHaveLength, numTasks)
+			})
+
+			Convey("Jobs of other sessions should not use the generator", func() {
+				other, err := Map(cluster.Session).Run()
+				So(err, ShouldBeNil)
+				So(other.Wait(), ShouldBeNil)
+				So(other.Job.ID, ShouldNotStartWith, fmt.Sprintf("J-%d-", gen.run))
+				So(gen.count.Load(), ShouldEqual, 1)
+			})
+		})
+	}))
+}
+
+// sequentialIDGenerator generates IDs numbered in the order of generation, which are unique per its run.
+type sequentialIDGenerator struct {
+	run   int64
+	count atomic.Int64
+}
+
+func (s *sequentialIDGenerator) GenerateID(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, s.run, s.count.Inc())
+}
+
+func (s *sequentialIDGenerator) idOf(n int64) string {
+	return fmt.Sprintf("J-%d-%d", s.run, n)
+}
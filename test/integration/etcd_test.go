@@ -107,6 +107,41 @@ func TestEtcd_Transaction(t *testing.T) {
 	}))
 }
 
+func TestEtcd_Failover(t *testing.T) {
+	RunOnIntegrationTest(t)
+	Convey("Given an etcd cluster with one of the endpoints unreachable", t, func() {
+		testNs := fmt.Sprintf("lrmr_test_%s/", funk.RandomString(10))
+		unreachableEndpoint := "127.0.0.1:1"
+
+		etcd, err := coordinator.NewEtcd(
+			[]string{unreachableEndpoint, "127.0.0.1:2379"},
+			testNs,
+			coordinator.WithEndpointHealthCheck(100*time.Millisecond, 100*time.Millisecond),
+		)
+		So(err, ShouldBeNil)
+		Reset(func() {
+			_, err := etcd.Delete(testutils.ContextWithTimeout(), "")
+			So(err, ShouldBeNil)
+			So(etcd.Close(), ShouldBeNil)
+		})
+
+		Convey("Operations should succeed via other endpoints", func() {
+			for i := 0; i < 10; i++ {
+				So(etcd.Put(testutils.ContextWithTimeout(), "key", i), ShouldBeNil)
+
+				var value int
+				So(etcd.Get(testutils.ContextWithTimeout(), "key", &value), ShouldBeNil)
+				So(value, ShouldEqual, i)
+			}
+
+			Convey("Unhealthy endpoint should be excluded", func() {
+				time.Sleep(500 * time.Millisecond)
+				So(etcd.(*coordinator.Etcd).Client.Endpoints(), ShouldNotContain, unreachableEndpoint)
+			})
+		})
+	})
+}
+
 func WithEtcd(fn func(etcd coordinator.Coordinator)) func() {
 	return func() {
 		rand.Seed(time.Now().Unix())
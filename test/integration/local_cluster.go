@@ -18,6 +18,7 @@ type LocalCluster struct {
 	crd     coordinator.Coordinator
 	master  *master.Master
 	workers []*worker.Worker
+	options []lrmr.SessionOption
 	testCtx C
 }
 
@@ -39,6 +40,7 @@ func WithLocalCluster(numWorkers int, fn func(c *LocalCluster), options ...lrmr.
 			opt.ListenHost = "127.0.0.1:"
 			opt.AdvertisedHost = "127.0.0.1:"
 			opt.Concurrency = 2
+			opt.Memory = 1 << 30
 			opt.NodeTags["No"] = strconv.Itoa(i + 1)
 
 			w, err := worker.New(crd, opt)
@@ -70,13 +72,52 @@ func WithLocalCluster(numWorkers int, fn func(c *LocalCluster), options ...lrmr.
 			crd:     crd,
 			master:  m,
 			workers: workers,
+			options: options,
 		}
 
 		fn(c)
 	}
 }
 
+// Master returns the master of the cluster.
+func (lc *LocalCluster) Master() *master.Master {
+	return lc.master
+}
+
+// Workers returns the workers of the cluster.
+func (lc *LocalCluster) Workers() []*worker.Worker {
+	return lc.workers
+}
+
+// DrainWorker marks the worker with given index as draining.
+func (lc *LocalCluster) DrainWorker(i int) error {
+	return lc.master.Drain(context.Background(), lc.workers[i].Node.Info().Host)
+}
+
+// StopWorker stops serving the worker with given index while keeping it registered,
+// which emulates a worker unreachable from the other nodes.
+func (lc *LocalCluster) StopWorker(i int) {
+	lc.workers[i].RPCServer.Stop()
+}
+
 func (lc *LocalCluster) EmulateMasterFailure(old *lrmr.RunningJob) (new *lrmr.RunningJob) {
+	newMaster := lc.replaceMaster()
+	newJob := &lrmr.RunningJob{
+		Job:    old.Job,
+		Master: newMaster,
+	}
+	newMaster.JobTracker.AddJob(old.Job)
+	return newJob
+}
+
+// RestartDriver emulates a restart of the driver process, replacing the master and the session with new ones.
+// Handles of the running jobs are lost, so they need to be reattached with Session.Reattach.
+func (lc *LocalCluster) RestartDriver() {
+	newMaster := lc.replaceMaster()
+	lc.Session = lrmr.NewSession(context.Background(), newMaster, lc.options...)
+}
+
+func (lc *LocalCluster) replaceMaster() *master.Master {
 	lc.master.Stop()
 
 	opt := master.DefaultOptions()
@@ -88,12 +129,6 @@ func (lc *LocalCluster) EmulateMasterFailure(old *lrmr.RunningJob) (new *lrmr.Ru
 	}
 
 	newMaster.Start()
-	newJob := &lrmr.RunningJob{
-		Job:    old.Job,
-		Master: newMaster,
-	}
-	newMaster.JobTracker.AddJob(old.Job)
 	lc.master = newMaster
-
-	return newJob
+	return newMaster
 }
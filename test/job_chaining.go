@@ -0,0 +1,42 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+)
+
+// PersistedMap multiplies numbers and keeps the result in the workers.
+func PersistedMap(sess *lrmr.Session) *lrmr.Dataset {
+	data := make([]int, 100)
+	for i := 0; i < len(data); i++ {
+		data[i] = i + 1
+	}
+	return sess.Parallelize(data).
+		Map(&Multiply{}).
+		Persist()
+}
+
+// CompressedPersistedMap is PersistedMap keeping the result compressed.
+func CompressedPersistedMap(sess *lrmr.Session) *lrmr.Dataset {
+	return PersistedMap(sess).PersistCompressed(job.GzipCompression)
+}
+
+// BrokenPersistedJob keeps its output in the workers, while a partition fails in the middle of its input.
+func BrokenPersistedJob(sess *lrmr.Session) *lrmr.Dataset {
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+	keys := []string{"0", "1", "2", "3"}
+	return sess.Parallelize(data).
+		Map(&modKeyer{N: len(keys)}).
+		GroupByKnownKeys(keys).
+		Do(&partitionFailer{FailingPartitions: []string{"3"}, FailAfter: 10}).
+		Persist()
+}
+
+// MapFromJobOutput multiplies numbers in the persisted output of the job again.
+func MapFromJobOutput(sess *lrmr.Session, jobID string) *lrmr.Dataset {
+	return sess.FromJobOutput(jobID).
+		Map(&Multiply{})
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJobChaining(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When running a job with persisted output", func() {
+			first, err := PersistedMap(cluster.Session).Run()
+			So(err, ShouldBeNil)
+			So(first.Wait(), ShouldBeNil)
+
+			Convey("Another job should read its output directly", func() {
+				rows, err := MapFromJobOutput(cluster.Session, first.ID).Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 100)
+
+				var numbers []int
+				for _, row := range rows {
+					numbers = append(numbers, testutils.IntValue(row))
+				}
+				sort.Ints(numbers)
+				for i, n := range numbers {
+					So(n, ShouldEqual, (i+1)*4)
+				}
+			})
+		})
+
+		Convey("When running a job with compressed persisted output", func() {
+			first, err := CompressedPersistedMap(cluster.Session).Run()
+			So(err, ShouldBeNil)
+			So(first.Wait(), ShouldBeNil)
+
+			Convey("Another job should read its decompressed output", func() {
+				rows, err := MapFromJobOutput(cluster.Session, first.ID).Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 100)
+
+				var numbers []int
+				for _, row := range rows {
+					numbers = append(numbers, testutils.IntValue(row))
+				}
+				sort.Ints(numbers)
+				for i, n := range numbers {
+					So(n, ShouldEqual, (i+1)*4)
+				}
+			})
+		})
+
+		Convey("When a job with persisted output fails", func() {
+			first, err := BrokenPersistedJob(cluster.Session).Run()
+			So(err, ShouldBeNil)
+			So(first.Wait(), ShouldNotBeNil)
+
+			Convey("Its output should not be kept", func() {
+				var locations partitions.Assignments
+				for i := 0; i < 100; i++ {
+					locations, err = cluster.Master().JobManager.ListPersistedOutputs(context.Background(), first.ID)
+					if err != nil || len(locations) == 0 {
+						break
+					}
+					time.Sleep(30 * time.Millisecond)
+				}
+				So(err, ShouldBeNil)
+				So(locations, ShouldBeEmpty)
+
+				_, err := MapFromJobOutput(cluster.Session, first.ID).Run()
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrOutputNotPersisted)
+			})
+		})
+
+		Convey("When running a job without persisted output", func() {
+			first, err := Map(cluster.Session).Run()
+			So(err, ShouldBeNil)
+			So(first.Wait(), ShouldBeNil)
+
+			Convey("Reading its output should fail", func() {
+				_, err := MapFromJobOutput(cluster.Session, first.ID).Run()
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrOutputNotPersisted)
+			})
+		})
+	}))
+}
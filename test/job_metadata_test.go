@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJobMetadata(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		ctx := context.Background()
+
+		Convey("When jobs are submitted with metadata", func() {
+			runJob := func(pipeline string) *lrmr.RunningJob {
+				sess := lrmr.NewSession(ctx, cluster.Master(),
+					lrmr.WithJobMetadata(map[string]string{"owner": "data-team"}),
+					lrmr.WithJobMetadata(map[string]string{"pipeline": pipeline}))
+
+				j, err := Map(sess).Run()
+				So(err, ShouldBeNil)
+				So(j.Wait(), ShouldBeNil)
+				return j
+			}
+			daily := runJob("daily")
+			hourly := runJob("hourly")
+
+			Convey("The metadata should be stored along the job", func() {
+				j, err := cluster.Master().JobManager.GetJob(ctx, daily.Job.ID)
+				So(err, ShouldBeNil)
+				So(j.Metadata, ShouldResemble, map[string]string{"owner": "data-team", "pipeline": "daily"})
+			})
+
+			Convey("Jobs should be filtered by their metadata", func() {
+				jobs, err := cluster.Master().JobManager.ListJobsByMetadata(ctx, "pipeline", "hourly")
+				So(err, ShouldBeNil)
+				So(jobs, ShouldHaveLength, 1)
+				So(jobs[0].ID, ShouldEqual, hourly.Job.ID)
+
+				jobs, err = cluster.Master().JobManager.ListJobsByMetadata(ctx, "owner", "data-team")
+				So(err, ShouldBeNil)
+				So(jobs, ShouldHaveLength, 2)
+
+				jobs, err = cluster.Master().JobManager.ListJobsByMetadata(ctx, "owner", "unknown")
+				So(err, ShouldBeNil)
+				So(jobs, ShouldBeEmpty)
+			})
+
+			Convey("Jobs without metadata should not be matched", func() {
+				j, err := Map(cluster.Session).Run()
+				So(err, ShouldBeNil)
+				So(j.Wait(), ShouldBeNil)
+
+				jobs, err := cluster.Master().JobManager.ListJobsByMetadata(ctx, "owner", "data-team")
+				So(err, ShouldBeNil)
+				So(jobs, ShouldHaveLength, 2)
+			})
+		})
+	}))
+}
@@ -0,0 +1,28 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/transformation"
+)
+
+var _ = lrmr.RegisterTypes(&partitionSizeReporter{})
+
+// partitionSizeReporter emits number of rows in its partition, reporting the largest one as a metric.
+type partitionSizeReporter struct{}
+
+func (p *partitionSizeReporter) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	count := 0
+	for range in {
+		count++
+	}
+	ctx.AddMetric("MaxPartitionSize", count, transformation.MaxMetric)
+	ctx.AddMetric("TotalRows", count)
+	emit(lrdd.KeyValue(ctx.PartitionID(), count))
+	return nil
+}
+
+func MetricAggregation(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}).
+		Do(&partitionSizeReporter{})
+}
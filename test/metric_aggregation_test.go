@@ -0,0 +1,36 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetricAggregation(t *testing.T) {
+	Convey("Given a job reporting a metric aggregated by max", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		j, err := MetricAggregation(cluster.Session).Run()
+		So(err, ShouldBeNil)
+		So(j.Wait(), ShouldBeNil)
+
+		Convey("It should report the maximum over the tasks, not the sum", func() {
+			m, err := j.Metrics()
+			So(err, ShouldBeNil)
+
+			numTasks, maxSize := 0, 0
+			for name, rows := range m {
+				if !strings.HasPrefix(name, "partitionSizeReporter0/") || !strings.HasSuffix(name, "/InputRows") {
+					continue
+				}
+				numTasks++
+				if rows > maxSize {
+					maxSize = rows
+				}
+			}
+			So(numTasks, ShouldBeGreaterThan, 1)
+			So(m["MaxPartitionSize"], ShouldEqual, maxSize)
+			So(m["TotalRows"], ShouldEqual, 13)
+		})
+	}))
+}
@@ -0,0 +1,27 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&partitionTagger{})
+
+// partitionTagger keys rows with the ID of their partition.
+type partitionTagger struct{}
+
+func (p *partitionTagger) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for row := range in {
+		emit(&lrdd.Row{Key: ctx.PartitionID(), Value: row.Value})
+	}
+	return nil
+}
+
+func OrderedCollect(sess *lrmr.Session) *lrmr.Dataset {
+	data := make([]int, 200)
+	for i := range data {
+		data[i] = i
+	}
+	return sess.ParallelizeN(data, 12).
+		Do(&partitionTagger{})
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOrderedCollect(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(3, func(cluster *integration.LocalCluster) {
+		Convey("When collecting the same job repeatedly with WithOrderedCollect", func() {
+			var runs [][]string
+			for i := 0; i < 3; i++ {
+				rows, err := OrderedCollect(cluster.Session).Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 200)
+
+				var run []string
+				for _, row := range rows {
+					run = append(run, row.Key+"/"+strconv.Itoa(testutils.IntValue(row)))
+				}
+				runs = append(runs, run)
+			}
+
+			Convey("Rows should be returned in the same order", func() {
+				So(runs[1], ShouldResemble, runs[0])
+				So(runs[2], ShouldResemble, runs[0])
+			})
+
+			Convey("Rows should be ordered by their partition IDs", func() {
+				rows, err := OrderedCollect(cluster.Session).Collect()
+				So(err, ShouldBeNil)
+				for i := 1; i < len(rows); i++ {
+					prev, _ := strconv.Atoi(rows[i-1].Key)
+					cur, _ := strconv.Atoi(rows[i].Key)
+					So(prev, ShouldBeLessThanOrEqualTo, cur)
+					if prev == cur {
+						// in the order they are emitted
+						So(testutils.IntValue(rows[i-1]), ShouldBeLessThan, testutils.IntValue(rows[i]))
+					}
+				}
+			})
+		})
+	}, lrmr.WithOrderedCollect()))
+}
@@ -0,0 +1,25 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&rowCounter{})
+
+// rowCounter emits number of rows in its partition.
+type rowCounter struct{}
+
+func (r *rowCounter) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	count := 0
+	for range in {
+		count++
+	}
+	emit(lrdd.KeyValue(ctx.PartitionID(), count))
+	return nil
+}
+
+func ParallelizeN(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 4).
+		Do(&rowCounter{})
+}
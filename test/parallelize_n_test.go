@@ -0,0 +1,34 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParallelizeN(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When parallelizing 10 elements into 4 partitions", func() {
+			j, err := ParallelizeN(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+
+			Convey("It should run 4 input tasks", func() {
+				So(j.GetPartitionsOfStage("rowCounter0"), ShouldHaveLength, 4)
+			})
+
+			Convey("Elements should be evenly distributed with remainder on the first partitions", func() {
+				rows, err := j.Collect()
+				So(err, ShouldBeNil)
+
+				counts := make(map[string]int)
+				for _, row := range rows {
+					var count int
+					row.UnmarshalValue(&count)
+					counts[row.Key] = count
+				}
+				So(counts, ShouldResemble, map[string]int{"0": 3, "1": 3, "2": 2, "3": 2})
+			})
+		})
+	}))
+}
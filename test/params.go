@@ -0,0 +1,20 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&paramTagger{})
+
+// ParamTagged tags numbers with the "date" parameter of the job.
+func ParamTagged(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8}, 4).
+		Map(&paramTagger{})
+}
+
+type paramTagger struct{}
+
+func (p *paramTagger) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	return lrdd.KeyValue(ctx.Param("date"), ctx.Param("unknown")), nil
+}
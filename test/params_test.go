@@ -0,0 +1,27 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParams(t *testing.T) {
+	Convey("Given running nodes with job parameters", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When reading the parameters in a transformation", func() {
+			rows, err := ParamTagged(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Every task should read the parameters", func() {
+				So(rows, ShouldHaveLength, 8)
+				for _, row := range rows {
+					So(row.Key, ShouldEqual, "2021-03-01")
+					So(testutils.StringValue(row), ShouldBeEmpty)
+				}
+			})
+		})
+	}, lrmr.WithParams(map[string]string{"date": "2021-03-01"})))
+}
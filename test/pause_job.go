@@ -0,0 +1,34 @@
+package test
+
+import (
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"go.uber.org/atomic"
+)
+
+var _ = lrmr.RegisterTypes(&slowMapper{})
+
+// mappedRows counts rows mapped by slowMapper in the local cluster.
+var mappedRows atomic.Int64
+
+// slowMapper passes through rows after given delay.
+type slowMapper struct {
+	Delay time.Duration
+}
+
+func (s *slowMapper) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	time.Sleep(s.Delay)
+	mappedRows.Inc()
+	return row, nil
+}
+
+func PauseJob(sess *lrmr.Session, numRows int) *lrmr.Dataset {
+	data := make([]int, numRows)
+	for i := range data {
+		data[i] = i + 1
+	}
+	return sess.ParallelizeN(data, 2).
+		Map(&slowMapper{Delay: 20 * time.Millisecond})
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPauseJob(t *testing.T) {
+	Convey("Given a running job", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		const numRows = 100
+		mappedRows.Store(0)
+
+		j, err := PauseJob(cluster.Session, numRows).RunForCollect()
+		So(err, ShouldBeNil)
+		for mappedRows.Load() < 5 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		Convey("When the job is paused", func() {
+			So(j.Pause(context.TODO()), ShouldBeNil)
+
+			// rows being mapped on pausing are let to finish
+			time.Sleep(300 * time.Millisecond)
+			paused := mappedRows.Load()
+
+			Convey("It should not make progress", func() {
+				time.Sleep(500 * time.Millisecond)
+				So(mappedRows.Load(), ShouldEqual, paused)
+				So(paused, ShouldBeLessThan, numRows)
+
+				Convey("After resumed, it should complete without losing rows", func() {
+					So(j.Resume(context.TODO()), ShouldBeNil)
+
+					rows, err := j.Collect()
+					So(err, ShouldBeNil)
+					So(rows, ShouldHaveLength, numRows)
+					So(mappedRows.Load(), ShouldEqual, numRows)
+				})
+			})
+		})
+	}))
+}
+
+func TestPauseJob_LongerThanTaskTimeout(t *testing.T) {
+	Convey("Given a running job with a task timeout", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		const numRows = 100
+		mappedRows.Store(0)
+
+		j, err := PauseJob(cluster.Session, numRows).RunForCollect()
+		So(err, ShouldBeNil)
+		for mappedRows.Load() < 5 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		Convey("When the job is paused longer than the timeout", func() {
+			So(j.Pause(context.TODO()), ShouldBeNil)
+			time.Sleep(time.Second)
+			So(j.Resume(context.TODO()), ShouldBeNil)
+
+			Convey("Paused tasks should not be timed out", func() {
+				rows, err := j.Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, numRows)
+			})
+		})
+
+		Convey("When the job is aborted while paused", func() {
+			So(j.Pause(context.TODO()), ShouldBeNil)
+			So(j.Abort(), ShouldNotBeNil)
+
+			Convey("The job should not be left paused", func() {
+				var paused bool
+				for i := 0; i < 100; i++ {
+					if paused, err = cluster.Master().JobManager.IsJobPaused(context.TODO(), j.ID); err != nil || !paused {
+						break
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				So(err, ShouldBeNil)
+				So(paused, ShouldBeFalse)
+			})
+		})
+	}, lrmr.WithTaskTimeout(300*time.Millisecond)))
+}
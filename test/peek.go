@@ -0,0 +1,18 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+// PeekedMultiply multiplies numbers in 4 partitions, peeking n rows of each partition after the first Map.
+func PeekedMultiply(sess *lrmr.Session, n int, fn func(*lrdd.Row)) *lrmr.Dataset {
+	data := make([]int, 64)
+	for i := range data {
+		data[i] = i + 1
+	}
+	return sess.ParallelizeN(data, 4).
+		Map(&Multiply{}).
+		Peek(n, fn).
+		Map(&Multiply{})
+}
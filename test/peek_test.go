@@ -0,0 +1,51 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPeek(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When peeking rows in the middle of a dataset", func() {
+			var (
+				peeked []*lrdd.Row
+				mu     sync.Mutex
+			)
+			ds := PeekedMultiply(cluster.Session, 2, func(row *lrdd.Row) {
+				mu.Lock()
+				defer mu.Unlock()
+				peeked = append(peeked, row)
+			})
+			rows, err := ds.Collect()
+			So(err, ShouldBeNil)
+
+			Convey("The callback should see up to n rows of each partition", func() {
+				mu.Lock()
+				defer mu.Unlock()
+				So(peeked, ShouldHaveLength, 2*4)
+				for _, row := range peeked {
+					// rows after the first Map are even numbers in [2, 128]
+					n := testutils.IntValue(row)
+					So(n%2, ShouldEqual, 0)
+					So(n, ShouldBeBetweenOrEqual, 2, 128)
+				}
+			})
+
+			Convey("The output should not be affected", func() {
+				So(rows, ShouldHaveLength, 64)
+				sum := 0
+				for _, row := range rows {
+					sum += testutils.IntValue(row)
+				}
+				// 4 * (1 + 2 + ... + 64)
+				So(sum, ShouldEqual, 4*64*65/2)
+			})
+		})
+	}))
+}
@@ -0,0 +1,23 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&peerLister{})
+
+// peerLister emits hosts of the sibling partitions seen by each partition.
+type peerLister struct{}
+
+func (p *peerLister) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for range in {
+	}
+	emit(lrdd.KeyValue(ctx.PartitionID(), ctx.PeerPartitions()))
+	return nil
+}
+
+func PeerPartitions(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8}, 4).
+		Do(&peerLister{})
+}
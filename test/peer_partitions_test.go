@@ -0,0 +1,41 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPeerPartitions(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When tasks look up their peer partitions", func() {
+			rows, err := PeerPartitions(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+			So(rows, ShouldHaveLength, 4)
+
+			workerHosts := make(map[string]bool)
+			for _, w := range cluster.Workers() {
+				workerHosts[w.Node.Info().Host] = true
+			}
+
+			Convey("Every task should enumerate the hosts of every partition of the stage", func() {
+				var first map[string]string
+				for _, row := range rows {
+					var peers map[string]string
+					row.UnmarshalValue(&peers)
+
+					So(peers, ShouldHaveLength, 4)
+					So(peers, ShouldContainKey, row.Key)
+					for _, host := range peers {
+						So(workerHosts, ShouldContainKey, host)
+					}
+					if first == nil {
+						first = peers
+					}
+					So(peers, ShouldResemble, first)
+				}
+			})
+		})
+	}))
+}
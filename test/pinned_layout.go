@@ -0,0 +1,22 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&workerReporter{})
+
+// workerReporter emits the number of the worker (set as "No" in the worker local options) by partition IDs.
+type workerReporter struct{}
+
+func (w *workerReporter) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for range in {
+	}
+	emit(lrdd.KeyValue(ctx.PartitionID(), ctx.WorkerLocalOption("No")))
+	return nil
+}
+
+func PinnedLayout(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8}, 4).Do(&workerReporter{})
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPinnedLayout(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		hostOf := func(i int) string {
+			return cluster.Workers()[i].Node.Info().Host
+		}
+
+		Convey("Running a job with a pinned layout", func() {
+			layout := map[string]string{
+				"0": hostOf(1),
+				"1": hostOf(1),
+				"2": hostOf(0),
+				"3": hostOf(1),
+			}
+			sess := lrmr.NewSession(context.Background(), cluster.Master(), lrmr.WithPinnedLayout(layout))
+			rows, err := PinnedLayout(sess).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Tasks should run exactly on the pinned workers", func() {
+				ranOn := make(map[string]int)
+				for _, row := range rows {
+					var no int
+					row.UnmarshalValue(&no)
+					ranOn[row.Key] = no
+				}
+				So(ranOn, ShouldResemble, map[string]int{"0": 2, "1": 2, "2": 1, "3": 2})
+			})
+		})
+
+		Convey("Running a job pinned to an unavailable node should fail", func() {
+			layout := map[string]string{
+				"0": hostOf(0),
+				"1": hostOf(1),
+				"2": "127.0.0.1:1",
+				"3": hostOf(1),
+			}
+			sess := lrmr.NewSession(context.Background(), cluster.Master(), lrmr.WithPinnedLayout(layout))
+			_, err := PinnedLayout(sess).Run()
+			So(errors.Cause(err), ShouldEqual, partitions.ErrPinnedNodeUnavailable)
+		})
+	}))
+}
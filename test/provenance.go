@@ -0,0 +1,29 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/testutils"
+)
+
+var _ = lrmr.RegisterTypes(&keyByRemainder{})
+
+// keyByRemainder keys input by its remainder divided by Divisor.
+type keyByRemainder struct {
+	Divisor int
+}
+
+func (k *keyByRemainder) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	n := testutils.IntValue(row)
+	return lrdd.KeyValue(strconv.Itoa(n%k.Divisor), n), nil
+}
+
+func Provenance(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5, 6, 7, 8}, 2).
+		Map(&Multiply{}).
+		Map(&keyByRemainder{Divisor: 4}).
+		GroupByKey().
+		Reduce(Count())
+}
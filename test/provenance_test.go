@@ -0,0 +1,32 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProvenance(t *testing.T) {
+	Convey("Given a job with provenance enabled", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When running a chain of maps and a shuffle", func() {
+			rows, err := Provenance(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+			So(rows, ShouldHaveLength, 2)
+
+			Convey("Each result should carry lineage of every input row reduced into it", func() {
+				origins := make(map[lrdd.Lineage]bool)
+				for _, row := range rows {
+					So(row.Lineage, ShouldHaveLength, 4)
+					for _, l := range row.Lineage {
+						So(l.Stage, ShouldEqual, "Multiply0")
+						origins[*l] = true
+					}
+				}
+				So(origins, ShouldHaveLength, 8)
+			})
+		})
+	}, lrmr.WithProvenance()))
+}
@@ -0,0 +1,13 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+)
+
+// UnreachableWorker shuffles rows of four keys into the tasks emitting the number of their workers.
+func UnreachableWorker(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize(map[string][]int{"a": {1}, "b": {2}, "c": {3}, "d": {4}}).
+		Map(NopMapper()).
+		GroupByKnownKeys([]string{"a", "b", "c", "d"}).
+		Do(&workerNumberEmitter{})
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReassignUnreachable(t *testing.T) {
+	Convey("Given running nodes with an unreachable worker", t, integration.WithLocalCluster(3, func(cluster *integration.LocalCluster) {
+		cluster.StopWorker(0)
+		unreachableHost := cluster.Workers()[0].Node.Info().Host
+
+		Convey("When running a job with WithReassignUnreachable", func() {
+			j, err := UnreachableWorker(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+
+			Convey("Partitions should be reassigned to the other workers", func() {
+				for _, aa := range j.Partitions {
+					for _, a := range aa {
+						So(a.Host, ShouldNotEqual, unreachableHost)
+					}
+				}
+			})
+
+			Convey("The job should complete on the other workers", func() {
+				rows, err := j.Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 4)
+				for _, row := range rows {
+					var workerNo int
+					row.UnmarshalValue(&workerNo)
+					So(workerNo, ShouldNotEqual, 1)
+				}
+			})
+		})
+
+		Convey("When running a job without the option", func() {
+			sess := lrmr.NewSession(context.Background(), cluster.Master())
+			_, err := UnreachableWorker(sess).RunForCollect()
+
+			Convey("It should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	}, lrmr.WithReassignUnreachable()))
+}
@@ -0,0 +1,18 @@
+package test
+
+import (
+	"time"
+
+	"github.com/ab180/lrmr"
+)
+
+// SlowPersistedMap persists rows passed through slowMapper, which keeps the job running for a while.
+func SlowPersistedMap(sess *lrmr.Session) *lrmr.Dataset {
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i + 1
+	}
+	return sess.ParallelizeN(data, 2).
+		Map(&slowMapper{Delay: 5 * time.Millisecond}).
+		Persist()
+}
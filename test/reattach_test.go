@@ -0,0 +1,75 @@
+package test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReattach(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When the driver restarts while a persisted job is running", func() {
+			old, err := SlowPersistedMap(cluster.Session).Run()
+			So(err, ShouldBeNil)
+			So(old.Status(), ShouldEqual, job.Running)
+
+			cluster.RestartDriver()
+
+			Convey("A new driver should reattach to the job and collect its results", func() {
+				j, err := cluster.Session.Reattach(old.Job.ID)
+				So(err, ShouldBeNil)
+				So(j.Job.Stages, ShouldHaveLength, len(old.Job.Stages))
+				So(j.Job.Stages[1].Name, ShouldEqual, old.Job.Stages[1].Name)
+
+				So(j.Wait(), ShouldBeNil)
+				So(j.Status(), ShouldEqual, job.Succeeded)
+
+				rows, err := j.Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 100)
+
+				var numbers []int
+				for _, row := range rows {
+					numbers = append(numbers, testutils.IntValue(row))
+				}
+				sort.Ints(numbers)
+				for i, n := range numbers {
+					So(n, ShouldEqual, i+1)
+				}
+			})
+		})
+
+		Convey("When the driver restarts after running a job for collect", func() {
+			old, err := Map(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+			So(old.Wait(), ShouldBeNil)
+
+			cluster.RestartDriver()
+
+			Convey("Its results should not be collected by a new driver", func() {
+				j, err := cluster.Session.Reattach(old.Job.ID)
+				So(err, ShouldBeNil)
+
+				_, err = j.Collect()
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrResultsNotRecoverable)
+
+				_, err = j.CollectPartition("0")
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrResultsNotRecoverable)
+			})
+		})
+
+		Convey("When reattaching to an unknown job", func() {
+			_, err := cluster.Session.Reattach("unknown")
+
+			Convey("It should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	}))
+}
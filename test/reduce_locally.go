@@ -0,0 +1,19 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+// ReduceLocallyInput is rows of 10 keys spread over the partitions, where the key "k<n>" has n+1 rows.
+func ReduceLocallyInput(sess *lrmr.Session) *lrmr.Dataset {
+	var rows []*lrdd.Row
+	for i := 0; i < 10; i++ {
+		for j := 0; j <= i; j++ {
+			rows = append(rows, lrdd.KeyValue("k"+strconv.Itoa(i), j))
+		}
+	}
+	return sess.ParallelizeN(rows, 4)
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReduceByKeyLocally(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When reducing rows by their keys locally", func() {
+			results, err := ReduceLocallyInput(cluster.Session).ReduceByKeyLocally(Count().(lrmr.PartialReducer))
+			So(err, ShouldBeNil)
+
+			Convey("It should produce the same results as reducing with a shuffle", func() {
+				rows, err := ReduceLocallyInput(cluster.Session).GroupByKey().Reduce(Count()).Collect()
+				So(err, ShouldBeNil)
+				So(results, ShouldHaveLength, len(rows))
+
+				for _, row := range rows {
+					var expected, actual uint64
+					row.UnmarshalValue(&expected)
+
+					local, ok := results[row.Key]
+					So(ok, ShouldBeTrue)
+					local.UnmarshalValue(&actual)
+					So(actual, ShouldEqual, expected)
+				}
+				var k9 uint64
+				results["k9"].UnmarshalValue(&k9)
+				So(k9, ShouldEqual, 10)
+			})
+		})
+	}))
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"sync"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(&classifiedFailer{})
+
+// jobsByTag are IDs of the jobs which classifiedFailer has run in, by its tag.
+var (
+	jobsByTag   = make(map[string][]string)
+	jobsByTagMu sync.Mutex
+)
+
+// classifiedFailer fails with an error of the class in the first job of its tag, and passes rows through in later jobs.
+type classifiedFailer struct {
+	Tag   string
+	Class job.ErrorClass
+}
+
+func (c *classifiedFailer) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	jobsByTagMu.Lock()
+	jobs := jobsByTag[c.Tag]
+	if len(jobs) == 0 || jobs[len(jobs)-1] != ctx.JobID() {
+		jobs = append(jobs, ctx.JobID())
+		jobsByTag[c.Tag] = jobs
+	}
+	first := jobs[0] == ctx.JobID()
+	jobsByTagMu.Unlock()
+
+	for row := range in {
+		if first {
+			return job.Classify(errors.New("failed in the first run"), c.Class)
+		}
+		emit(row)
+	}
+	return nil
+}
+
+// RetryFailedJob returns a dataset whose job fails with an error of the class in its first run.
+func RetryFailedJob(sess *lrmr.Session, tag string, class job.ErrorClass) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4}, 2).
+		Do(&classifiedFailer{Tag: tag, Class: class})
+}
+
+// NumRunsOf returns the number of jobs which the dataset of RetryFailedJob has run in with the tag.
+func NumRunsOf(tag string) int {
+	jobsByTagMu.Lock()
+	defer jobsByTagMu.Unlock()
+	return len(jobsByTag[tag])
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryFailedJob(t *testing.T) {
+	Convey("Given running nodes with a session retrying failed jobs", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When a job fails with a user error", func() {
+			tag := "user-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+			_, err := RetryFailedJob(cluster.Session, tag, job.UserError).Collect()
+
+			Convey("It should not be retried", func() {
+				So(err, ShouldHaveSameTypeAs, job.Error{})
+				So(err.(job.Error).Class, ShouldEqual, job.UserError)
+				So(NumRunsOf(tag), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When a job fails with a transient error", func() {
+			tag := "transient-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+			j, err := RetryFailedJob(cluster.Session, tag, job.TransientError).RunForCollect()
+			So(err, ShouldBeNil)
+			rows, err := j.Collect()
+
+			Convey("It should be retried and succeed", func() {
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 4)
+				So(NumRunsOf(tag), ShouldEqual, 2)
+			})
+
+			Convey("Waiting for the job should succeed", func() {
+				So(j.Wait(), ShouldBeNil)
+				So(j.Status(), ShouldEqual, job.Succeeded)
+			})
+		})
+	}, lrmr.WithMaxRetries(1)))
+}
@@ -0,0 +1,28 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(&numericValidator{})
+
+// ValidatedNumbers validates that every row is a number, handling malformed rows by the policy.
+func ValidatedNumbers(sess *lrmr.Session, policy lrmr.InvalidRowPolicy) *lrmr.Dataset {
+	return sess.Parallelize([]string{"1", "2", "three", "4", "five"}).
+		ValidateSchema(&numericValidator{}, policy)
+}
+
+type numericValidator struct{}
+
+func (n *numericValidator) Validate(row *lrdd.Row) error {
+	var s string
+	row.UnmarshalValue(&s)
+	if _, err := strconv.Atoi(s); err != nil {
+		return errors.Errorf("%q is not a number", s)
+	}
+	return nil
+}
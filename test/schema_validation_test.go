@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateSchema(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When validating malformed rows with FailOnInvalidRow", func() {
+			j, err := ValidatedNumbers(cluster.Session, lrmr.FailOnInvalidRow).Run()
+			So(err, ShouldBeNil)
+
+			Convey("The job should fail on the malformed rows", func() {
+				err := j.Wait()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "is not a number")
+				So(err.(job.Error).Class, ShouldEqual, job.UserError)
+			})
+		})
+
+		Convey("When validating malformed rows with QuarantineInvalidRows", func() {
+			j, err := ValidatedNumbers(cluster.Session, lrmr.QuarantineInvalidRows).RunForCollect()
+			So(err, ShouldBeNil)
+			rows, err := j.Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Only valid rows should be in the output", func() {
+				So(rows, ShouldHaveLength, 3)
+			})
+
+			Convey("Malformed rows should be quarantined with the validation errors", func() {
+				quarantined, err := j.QuarantinedRows(context.Background())
+				So(err, ShouldBeNil)
+				So(quarantined, ShouldHaveLength, 2)
+
+				var values, reasons []string
+				for _, q := range quarantined {
+					values = append(values, testutils.StringValue(q.Row))
+					reasons = append(reasons, q.Reason)
+				}
+				sort.Strings(values)
+				sort.Strings(reasons)
+				So(values, ShouldResemble, []string{"five", "three"})
+				So(reasons, ShouldResemble, []string{`"five" is not a number`, `"three" is not a number`})
+
+				// metrics are reported after the results are delivered
+				So(j.Wait(), ShouldBeNil)
+				m, err := j.Metrics()
+				So(err, ShouldBeNil)
+				So(m["QuarantinedRows"], ShouldEqual, 2)
+			})
+		})
+	}))
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/testutils"
+)
+
+var _ = lrmr.RegisterTypes(&Padder{})
+
+// Padder pads rows into large values keyed by their remainders, so that shuffling them is dominated by serialization.
+type Padder struct{}
+
+func (p *Padder) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	n := testutils.IntValue(row)
+	return lrdd.KeyValue(strconv.Itoa(n%10), strings.Repeat("x", 4096)), nil
+}
+
+func PaddedRows(sess *lrmr.Session) *lrmr.Dataset {
+	data := make([]int, 2000)
+	for i := range data {
+		data[i] = i + 1
+	}
+	return sess.Parallelize(data).
+		Map(&Padder{}).
+		GroupByKey().
+		Reduce(Count())
+}
@@ -0,0 +1,25 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSerializationTime(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When shuffling large rows", func() {
+			j, err := PaddedRows(cluster.Session).Run()
+			So(err, ShouldBeNil)
+			So(j.Wait(), ShouldBeNil)
+
+			Convey("Time spent on serialization should be reported for the stage", func() {
+				metrics, err := j.Metrics()
+				So(err, ShouldBeNil)
+				So(metrics[job.SerializationTimeMetric("Padder0")], ShouldBeGreaterThan, 0)
+			})
+		})
+	}))
+}
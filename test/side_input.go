@@ -0,0 +1,49 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(&digitNamer{}, &digitNameJoiner{})
+
+var digitNames = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+
+// SideInputJoin joins numbers with names of their last digits in a side input.
+func SideInputJoin(sess *lrmr.Session, n int) *lrmr.Dataset {
+	numbers := make([]int, n)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	names := sess.Parallelize([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}).Map(&digitNamer{})
+	return sess.ParallelizeN(numbers, 4).
+		Map(&digitNameJoiner{}).
+		WithSideInput("digitNames", names)
+}
+
+type digitNamer struct{}
+
+func (d *digitNamer) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	var digit int
+	row.UnmarshalValue(&digit)
+	return lrdd.KeyValue(strconv.Itoa(digit), digitNames[digit]), nil
+}
+
+type digitNameJoiner struct{}
+
+func (d *digitNameJoiner) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	var n int
+	row.UnmarshalValue(&n)
+
+	digit := strconv.Itoa(n % 10)
+	nameRow, ok := ctx.SideInput("digitNames")[digit]
+	if !ok {
+		return nil, errors.Errorf("no name for digit %s", digit)
+	}
+	var name string
+	nameRow.UnmarshalValue(&name)
+	return lrdd.KeyValue(name, n), nil
+}
@@ -0,0 +1,30 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSideInput(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When joining a stream against a side input", func() {
+			ds := SideInputJoin(cluster.Session, 1000)
+
+			Convey("Every row should be joined with the side input", func() {
+				rows, err := ds.Collect()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 1000)
+
+				groups := testutils.GroupRowsByKey(rows)
+				So(groups, ShouldHaveLength, 10)
+				for _, row := range groups["seven"] {
+					So(testutils.IntValue(row)%10, ShouldEqual, 7)
+				}
+				So(groups["seven"], ShouldHaveLength, 100)
+			})
+		})
+	}))
+}
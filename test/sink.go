@@ -0,0 +1,111 @@
+package test
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&mockSink{}, &mockEncodingSink{})
+
+// mockSinkRecords stores rows written to mockSink by its ID.
+var mockSinkRecords sync.Map
+
+type mockSink struct {
+	ID               string
+	FailingPartition string
+}
+
+func (m *mockSink) Open(partitionID string) (lrmr.RowWriter, error) {
+	return &mockRowWriter{sink: m, partitionID: partitionID}, nil
+}
+
+func (m *mockSink) Close() error {
+	return nil
+}
+
+type mockRowWriter struct {
+	sink        *mockSink
+	partitionID string
+}
+
+func (w *mockRowWriter) Write(rows ...*lrdd.Row) error {
+	if w.partitionID == w.sink.FailingPartition {
+		return errors.New("sink failure")
+	}
+	records, _ := mockSinkRecords.LoadOrStore(w.sink.ID, &mockSinkRecord{})
+	records.(*mockSinkRecord).add(rows)
+	return nil
+}
+
+func (w *mockRowWriter) Close() error {
+	return nil
+}
+
+type mockSinkRecord struct {
+	rows []*lrdd.Row
+	mu   sync.Mutex
+}
+
+func (r *mockSinkRecord) add(rows []*lrdd.Row) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows = append(r.rows, rows...)
+}
+
+// mockEncodingSinkRecords stores encoded rows written to mockEncodingSink by its ID.
+var mockEncodingSinkRecords sync.Map
+
+// mockEncodingSink receives rows encoded in JSON or Protocol Buffers.
+type mockEncodingSink struct {
+	ID       string
+	Protobuf bool
+}
+
+func (m *mockEncodingSink) Encoding() lrmr.RowEncoding {
+	if m.Protobuf {
+		return lrmr.ProtobufEncoding
+	}
+	return lrmr.JSONEncoding
+}
+
+func (m *mockEncodingSink) Open(string) (lrmr.RowWriter, error) {
+	return nil, errors.New("rows should be written encoded")
+}
+
+func (m *mockEncodingSink) OpenEncoded(string) (lrmr.EncodedRowWriter, error) {
+	return m, nil
+}
+
+func (m *mockEncodingSink) WriteEncoded(rows ...[]byte) error {
+	records, _ := mockEncodingSinkRecords.LoadOrStore(m.ID, &mockEncodingSinkRecord{})
+	records.(*mockEncodingSinkRecord).add(rows)
+	return nil
+}
+
+func (m *mockEncodingSink) Close() error {
+	return nil
+}
+
+type mockEncodingSinkRecord struct {
+	rows [][]byte
+	mu   sync.Mutex
+}
+
+func (r *mockEncodingSinkRecord) add(rows [][]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows = append(r.rows, rows...)
+}
+
+func WriteToSink(sess *lrmr.Session, sink lrmr.Sink, opts ...lrmr.WriteOption) error {
+	data := make([]int, 1000)
+	for i := 0; i < len(data); i++ {
+		data[i] = i + 1
+	}
+	return sess.Parallelize(data).
+		Map(NopMapper()).
+		WriteTo(sink, opts...)
+}
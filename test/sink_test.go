@@ -0,0 +1,92 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/integration"
+	jsoniter "github.com/json-iterator/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSink(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When writing rows to a sink", func() {
+			err := WriteToSink(cluster.Session, &mockSink{ID: "TestSink"})
+			So(err, ShouldBeNil)
+
+			Convey("Every row should be written exactly once", func() {
+				records, ok := mockSinkRecords.Load("TestSink")
+				So(ok, ShouldBeTrue)
+
+				rows := records.(*mockSinkRecord).rows
+				So(sortedIntValues(rows), ShouldHaveLength, 1000)
+				for i, n := range sortedIntValues(rows) {
+					So(n, ShouldEqual, i+1)
+				}
+			})
+		})
+
+		Convey("When writing rows to a sink with a rate limit", func() {
+			start := time.Now()
+			err := WriteToSink(cluster.Session, &mockSink{ID: "TestSink_RateLimit"},
+				lrmr.WithRateLimit(lrmr.RateLimit{RowsPerSecond: 2000, Burst: 10}))
+			So(err, ShouldBeNil)
+			elapsed := time.Since(start)
+
+			Convey("Rows should be written under the limit", func() {
+				records, ok := mockSinkRecords.Load("TestSink_RateLimit")
+				So(ok, ShouldBeTrue)
+				So(records.(*mockSinkRecord).rows, ShouldHaveLength, 1000)
+
+				// 10 rows of the burst are written at once
+				So(elapsed, ShouldBeGreaterThanOrEqualTo, 990*time.Second/2000)
+			})
+		})
+
+		Convey("When writing rows to sinks with their own encodings", func() {
+			So(WriteToSink(cluster.Session, &mockEncodingSink{ID: "TestSink_JSON"}), ShouldBeNil)
+			So(WriteToSink(cluster.Session, &mockEncodingSink{ID: "TestSink_Protobuf", Protobuf: true}), ShouldBeNil)
+
+			decodedRowsOf := func(id string, decode func([]byte, *lrdd.Row) error) []*lrdd.Row {
+				records, ok := mockEncodingSinkRecords.Load(id)
+				So(ok, ShouldBeTrue)
+
+				var rows []*lrdd.Row
+				for _, data := range records.(*mockEncodingSinkRecord).rows {
+					row := new(lrdd.Row)
+					So(decode(data, row), ShouldBeNil)
+					rows = append(rows, row)
+				}
+				return rows
+			}
+
+			Convey("Each sink should receive rows in its encoding", func() {
+				jsonRows := decodedRowsOf("TestSink_JSON", func(data []byte, row *lrdd.Row) error {
+					return jsoniter.Unmarshal(data, row)
+				})
+				protobufRows := decodedRowsOf("TestSink_Protobuf", func(data []byte, row *lrdd.Row) error {
+					return row.Unmarshal(data)
+				})
+
+				for _, rows := range [][]*lrdd.Row{jsonRows, protobufRows} {
+					So(sortedIntValues(rows), ShouldHaveLength, 1000)
+					for i, n := range sortedIntValues(rows) {
+						So(n, ShouldEqual, i+1)
+					}
+				}
+			})
+		})
+
+		Convey("When the sink fails on a partition", func() {
+			err := WriteToSink(cluster.Session, &mockSink{ID: "TestSink_Failure", FailingPartition: "0"})
+
+			Convey("The error should be propagated", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "sink failure")
+			})
+		})
+	}))
+}
@@ -0,0 +1,33 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/partitions"
+)
+
+var _ = lrmr.RegisterTypes(&hotPartitioner{})
+
+// hotPartitioner sends most of the rows to the "hot" partition.
+type hotPartitioner struct{}
+
+func (hotPartitioner) DeterminePartition(_ partitions.Context, r *lrdd.Row, _ int) (string, error) {
+	return r.Key, nil
+}
+
+func (hotPartitioner) PlanNext(int) []partitions.Partition {
+	return []partitions.Partition{{ID: "hot"}, {ID: "cold1"}, {ID: "cold2"}, {ID: "cold3"}}
+}
+
+// SkewedPartitions is a dataset where the "hot" partition receives 100x more rows than the others.
+func SkewedPartitions(sess *lrmr.Session) *lrmr.Dataset {
+	in := map[string][]int{
+		"hot":   make([]int, 10000),
+		"cold1": make([]int, 100),
+		"cold2": make([]int, 100),
+		"cold3": make([]int, 100),
+	}
+	return sess.Parallelize(in).
+		PartitionedBy(&hotPartitioner{}).
+		Map(NopMapper())
+}
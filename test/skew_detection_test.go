@@ -0,0 +1,55 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSkewDetection(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When a partition receives far more rows than the others", func() {
+			j, err := SkewedPartitions(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+			So(j.Wait(), ShouldBeNil)
+
+			Convey("The skew should be detected", func() {
+				skews, err := j.StageSkews(context.TODO())
+				So(err, ShouldBeNil)
+
+				var skew *job.StageSkew
+				for i := range skews {
+					if skews[i].StageName == "nopMapper0" {
+						skew = &skews[i]
+					}
+				}
+				So(skew, ShouldNotBeNil)
+				So(skew.PartitionID, ShouldEqual, "hot")
+				So(skew.Rows, ShouldEqual, 10000)
+				So(skew.MedianRows, ShouldEqual, 100)
+				So(skew.Ratio, ShouldBeGreaterThanOrEqualTo, 4)
+
+				Convey("It should be reported as a metric of the stage", func() {
+					metrics, err := j.Metrics()
+					So(err, ShouldBeNil)
+					So(metrics[job.SkewRatioMetric("nopMapper0")], ShouldEqual, int(skew.Ratio))
+				})
+			})
+		})
+
+		Convey("When rows are evenly distributed", func() {
+			j, err := ParallelizeN(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+			So(j.Wait(), ShouldBeNil)
+
+			Convey("No skew should be detected", func() {
+				skews, err := j.StageSkews(context.TODO())
+				So(err, ShouldBeNil)
+				So(skews, ShouldBeEmpty)
+			})
+		})
+	}))
+}
@@ -0,0 +1,28 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+// SkewedKeyFrequencies is a key-frequency profile of SkewedCount, where a key has most of the rows.
+var SkewedKeyFrequencies = map[string]int64{
+	"hot":   1000,
+	"cold0": 10,
+	"cold1": 10,
+	"cold2": 10,
+}
+
+func SkewedCount(sess *lrmr.Session) *lrmr.Dataset {
+	var rows []*lrdd.Row
+	for key, freq := range SkewedKeyFrequencies {
+		for i := int64(0); i < freq; i++ {
+			rows = append(rows, lrdd.KeyValue(key, strconv.FormatInt(i, 10)))
+		}
+	}
+	return sess.Parallelize(rows).
+		GroupByKeyWithSkew(SkewedKeyFrequencies).
+		Reduce(Count())
+}
@@ -0,0 +1,27 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSkewedCount(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When reducing rows grouped by skewed keys", func() {
+			rows, err := SkewedCount(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Partial results of hot keys should be merged into one", func() {
+				res := testutils.GroupRowsByKey(rows)
+				So(res, ShouldHaveLength, len(SkewedKeyFrequencies))
+				for key, freq := range SkewedKeyFrequencies {
+					So(res[key], ShouldHaveLength, 1)
+					So(testutils.IntValue(res[key][0]), ShouldEqual, freq)
+				}
+			})
+		})
+	}))
+}
@@ -0,0 +1,12 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+)
+
+// SparsePartitions routes every row to one of four known keys, leaving three partitions empty.
+func SparsePartitions(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize(map[string][]int{"a": {1, 2, 3}}).
+		GroupByKnownKeys([]string{"a", "b", "c", "d"}).
+		Do(&taskIntrospector{})
+}
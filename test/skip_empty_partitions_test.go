@@ -0,0 +1,34 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSkipEmptyPartitions(t *testing.T) {
+	Convey("Running a job with sparse output", t, func() {
+		Convey("Tasks of empty partitions should be skipped with SkipEmptyPartitions", integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+			j, err := SparsePartitions(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+
+			rows, err := j.Collect()
+			So(err, ShouldBeNil)
+			So(rows, ShouldHaveLength, 1)
+			So(rows[0].Key, ShouldEqual, "a")
+
+			So(j.Wait(), ShouldBeNil)
+			m, err := j.Metrics()
+			So(err, ShouldBeNil)
+			So(m["SkippedEmptyTasks"], ShouldEqual, 3)
+		}, lrmr.WithSkipEmptyPartitions()))
+
+		Convey("Tasks of empty partitions should run by default", integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+			rows, err := SparsePartitions(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+			So(rows, ShouldHaveLength, 4)
+		}))
+	})
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&passThrough{})
+
+// passThrough emits rows as they are.
+type passThrough struct{}
+
+func (p *passThrough) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	return row, nil
+}
+
+func SortByKey(sess *lrmr.Session, o lrmr.Ordering) *lrmr.Dataset {
+	data := map[string]int{
+		"100": 1,
+		"9":   2,
+		"10":  3,
+		"1":   4,
+		"25":  5,
+	}
+	return sess.Parallelize(data).
+		Repartition(1).
+		SortByKey(o)
+}
+
+func SortByMultiFieldKey(sess *lrmr.Session, o lrmr.Ordering) *lrmr.Dataset {
+	data := map[string]int{
+		"b-10": 1,
+		"a-9":  2,
+		"b-9":  3,
+		"a-10": 4,
+	}
+	return sess.Parallelize(data).
+		Repartition(1).
+		SortByKey(o)
+}
+
+// SortMergeByKey returns a dataset sorting numeric keys in each of several partitions,
+// and merging the sorted partitions into a single partition of the next stage.
+func SortMergeByKey(sess *lrmr.Session) *lrmr.Dataset {
+	data := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		data[strconv.Itoa((i*37)%200)] = i
+	}
+	return sess.Parallelize(data).
+		Repartition(4).
+		SortMergeByKey(lrmr.NumericOrdering{}).
+		Repartition(1).
+		Map(&passThrough{})
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSortByKey(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When sorting with a custom ordering", func() {
+			rows, err := SortByKey(cluster.Session, lrmr.NumericOrdering{}).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("It should sort keys in the ordering", func() {
+				So(keysOf(rows), ShouldResemble, []string{"1", "9", "10", "25", "100"})
+			})
+		})
+
+		Convey("When sorting with a reversed ordering", func() {
+			rows, err := SortByKey(cluster.Session, lrmr.Reverse(lrmr.NumericOrdering{})).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("It should sort keys in reverse order", func() {
+				So(keysOf(rows), ShouldResemble, []string{"100", "25", "10", "9", "1"})
+			})
+		})
+
+		Convey("When sorting with a multi-field ordering", func() {
+			o := lrmr.MultiField("-", lrmr.Reverse(lrmr.NaturalOrdering{}), lrmr.NumericOrdering{})
+			rows, err := SortByMultiFieldKey(cluster.Session, o).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("It should sort keys by each field", func() {
+				So(keysOf(rows), ShouldResemble, []string{"b-9", "b-10", "a-9", "a-10"})
+			})
+		})
+
+		Convey("When merging partitions sorted in several tasks", func() {
+			rows, err := SortMergeByKey(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("It should merge the rows of the partitions in the ordering", func() {
+				expected := make([]string, 200)
+				for i := range expected {
+					expected[i] = strconv.Itoa(i)
+				}
+				So(keysOf(rows), ShouldResemble, expected)
+			})
+		})
+	}))
+}
+
+func keysOf(rows []*lrdd.Row) []string {
+	keys := make([]string, len(rows))
+	for i, row := range rows {
+		keys[i] = row.Key
+	}
+	return keys
+}
@@ -0,0 +1,29 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStageDurations(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When a job with a slow stage completes", func() {
+			j, err := SlowTask(cluster.Session, 300*time.Millisecond, 0).Run()
+			So(err, ShouldBeNil)
+			So(j.Wait(), ShouldBeNil)
+
+			Convey("The slow stage should take longer than its tasks", func() {
+				durations, err := j.StageDurations()
+				So(err, ShouldBeNil)
+
+				slow, ok := durations["slowTransformer0"]
+				So(ok, ShouldBeTrue)
+				So(slow.CompletedAt, ShouldNotBeNil)
+				So(slow.Duration(), ShouldBeGreaterThanOrEqualTo, 300*time.Millisecond)
+			})
+		})
+	}))
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSubmissionProgress(t *testing.T) {
+	var (
+		created []int
+		totals  []int
+		mu      sync.Mutex
+	)
+	recordProgress := func(c, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, c)
+		totals = append(totals, total)
+	}
+
+	Convey("Given running nodes", t, integration.WithLocalCluster(3, func(cluster *integration.LocalCluster) {
+		Convey("When submitting a job", func() {
+			mu.Lock()
+			created, totals = nil, nil
+			mu.Unlock()
+
+			j, err := ParallelizeN(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+			_, err = j.Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Progress should be reported monotonically up to the total number of tasks", func() {
+				expectedTotal := 0
+				for _, aa := range j.Partitions[1:] {
+					expectedTotal += len(aa)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				So(created, ShouldNotBeEmpty)
+				for i := range created {
+					So(totals[i], ShouldEqual, expectedTotal)
+					if i > 0 {
+						So(created[i], ShouldBeGreaterThan, created[i-1])
+					}
+				}
+				So(created[len(created)-1], ShouldEqual, expectedTotal)
+			})
+		})
+	}, lrmr.WithSubmissionProgress(recordProgress)))
+}
@@ -0,0 +1,23 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+)
+
+var _ = lrmr.RegisterTypes(&taskIntrospector{})
+
+// taskIntrospector emits information of the task which it is running as.
+type taskIntrospector struct{}
+
+func (t *taskIntrospector) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for range in {
+	}
+	emit(lrdd.KeyValue(ctx.PartitionID(), []string{ctx.StageName(), ctx.JobID()}))
+	return nil
+}
+
+func TaskIntrospection(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize([]int{1, 2, 3, 4, 5}).
+		Do(&taskIntrospector{})
+}
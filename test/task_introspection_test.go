@@ -0,0 +1,32 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTaskIntrospection(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When running a transformation", func() {
+			j, err := TaskIntrospection(cluster.Session).RunForCollect()
+			So(err, ShouldBeNil)
+
+			Convey("Context should describe the task which it is running as", func() {
+				rows, err := j.Collect()
+				So(err, ShouldBeNil)
+
+				partitions := j.GetPartitionsOfStage("taskIntrospector0")
+				So(rows, ShouldHaveLength, len(partitions))
+				for _, row := range rows {
+					So(partitions.ToMap(), ShouldContainKey, row.Key)
+
+					var stageAndJob []string
+					row.UnmarshalValue(&stageAndJob)
+					So(stageAndJob, ShouldResemble, []string{"taskIntrospector0", j.ID})
+				}
+			})
+		})
+	}))
+}
@@ -0,0 +1,26 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+var _ = lrmr.RegisterTypes(&loggingFailer{})
+
+// LoggingFailure fails after logging the number of rows it has received.
+func LoggingFailure(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.ParallelizeN([]int{1, 2, 3, 4, 5}, 1).
+		Do(&loggingFailer{})
+}
+
+type loggingFailer struct{}
+
+func (l *loggingFailer) Transform(ctx lrmr.Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	count := 0
+	for range in {
+		count++
+	}
+	ctx.Logger().Info("Received {} rows", count)
+	return errors.New("failed on purpose")
+}
@@ -0,0 +1,29 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTaskLogs(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When a task fails after logging", func() {
+			j, err := LoggingFailure(cluster.Session).Run()
+			So(err, ShouldBeNil)
+
+			err = j.Wait()
+			So(err, ShouldHaveSameTypeAs, job.Error{})
+
+			Convey("Logs of the task should be retrievable with its error", func() {
+				lines, err := j.TaskLogs(context.Background(), err.(job.Error).Task)
+				So(err, ShouldBeNil)
+				So(lines, ShouldHaveLength, 1)
+				So(lines[0], ShouldEndWith, "INFO Received 5 rows")
+			})
+		})
+	}))
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/partitions"
+)
+
+var _ = lrmr.RegisterTypes(&regionYearPartitioner{}, &partitionKeyChecker{})
+
+// regionYearPartitioner partitions rows by composite keys of their keys as regions and their values as years.
+type regionYearPartitioner struct{}
+
+func (r *regionYearPartitioner) PlanNextKeys(int) []partitions.Key {
+	var keys []partitions.Key
+	for _, region := range []string{"KR", "US"} {
+		for year := 2019; year <= 2021; year++ {
+			keys = append(keys, partitions.MustKey(region, year))
+		}
+	}
+	return keys
+}
+
+func (r *regionYearPartitioner) DetermineKey(_ partitions.Context, row *lrdd.Row, _ int) (partitions.Key, error) {
+	var year int
+	row.UnmarshalValue(&year)
+	return partitions.NewKey(row.Key, year)
+}
+
+// partitionKeyChecker emits whether each row belongs to the key of its partition, keyed by the partition ID.
+type partitionKeyChecker struct{}
+
+func (p *partitionKeyChecker) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	k, err := partitions.ParseKey(ctx.PartitionID())
+	if err != nil {
+		return nil, err
+	}
+	var year int
+	row.UnmarshalValue(&year)
+	parts := k.Parts()
+	return lrdd.KeyValue(ctx.PartitionID(), parts[0] == row.Key && parts[1] == int64(year)), nil
+}
+
+func TypedKeys(sess *lrmr.Session) *lrmr.Dataset {
+	var rows []*lrdd.Row
+	for i := 0; i < 60; i++ {
+		region := []string{"KR", "US"}[i%2]
+		rows = append(rows, lrdd.KeyValue(region, 2019+i%3))
+	}
+	return sess.Parallelize(rows).
+		PartitionedBy(partitions.WithTypedKeys(&regionYearPartitioner{})).
+		Map(&partitionKeyChecker{})
+}
@@ -0,0 +1,30 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTypedKeys(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When partitioning rows with composite keys", func() {
+			rows, err := TypedKeys(cluster.Session).Collect()
+			So(err, ShouldBeNil)
+			So(rows, ShouldHaveLength, 60)
+
+			Convey("Each row should be routed to the partition of its key", func() {
+				partitionIDs := make(map[string]int)
+				for _, row := range rows {
+					var matched bool
+					row.UnmarshalValue(&matched)
+					So(matched, ShouldBeTrue)
+					partitionIDs[row.Key]++
+				}
+				So(partitionIDs, ShouldHaveLength, 6)
+				So(partitionIDs["s:KR,i:2019"], ShouldEqual, 10)
+			})
+		})
+	}))
+}
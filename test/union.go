@@ -0,0 +1,11 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+)
+
+// UnionFilesAndValues merges paths of the files under the directory and the values into a single input stage.
+func UnionFilesAndValues(sess *lrmr.Session, dir string, values []string) *lrmr.Dataset {
+	return sess.Union(sess.FromFile(dir), sess.Parallelize(values)).
+		Map(NopMapper())
+}
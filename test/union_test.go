@@ -0,0 +1,51 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnion(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		dir, err := ioutil.TempDir("", "lrmr-union")
+		So(err, ShouldBeNil)
+		Reset(func() { _ = os.RemoveAll(dir) })
+
+		var files []string
+		for _, name := range []string{"a.txt", "b.txt"} {
+			path := filepath.Join(dir, name)
+			So(ioutil.WriteFile(path, []byte(name), 0644), ShouldBeNil)
+			files = append(files, path)
+		}
+
+		Convey("When a file source and an in-memory source are unioned", func() {
+			rows, err := UnionFilesAndValues(cluster.Session, dir, []string{"foo", "bar", "baz"}).Collect()
+			So(err, ShouldBeNil)
+
+			Convey("Rows of both sources should appear in the first stage", func() {
+				var values []string
+				for _, row := range rows {
+					var v string
+					row.UnmarshalValue(&v)
+					values = append(values, v)
+				}
+				expected := append([]string{"foo", "bar", "baz"}, files...)
+				sort.Strings(values)
+				sort.Strings(expected)
+				So(values, ShouldResemble, expected)
+			})
+		})
+
+		Convey("Sources with transformations should be refused", func() {
+			So(func() {
+				cluster.Session.Union(cluster.Session.Parallelize([]string{"foo"}).Map(NopMapper()))
+			}, ShouldPanic)
+		})
+	}))
+}
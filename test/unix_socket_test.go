@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/master"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/worker"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnixSocket(t *testing.T) {
+	Convey("Given nodes listening on unix sockets", t, func() {
+		dir, err := ioutil.TempDir("", "lrmr")
+		So(err, ShouldBeNil)
+		crd := integration.ProvideEtcd()
+
+		wopt := worker.DefaultOptions()
+		wopt.ListenHost = "unix://" + filepath.Join(dir, "worker.sock")
+		wopt.AdvertisedHost = "127.0.0.1:"
+		wopt.Concurrency = 2
+		w, err := worker.New(crd, wopt)
+		So(err, ShouldBeNil)
+		go w.Start()
+
+		// wait for workers to register themselves
+		time.Sleep(200 * time.Millisecond)
+
+		mopt := master.DefaultOptions()
+		mopt.ListenHost = "unix://" + filepath.Join(dir, "master.sock")
+		mopt.AdvertisedHost = mopt.ListenHost
+		m, err := master.New(crd, mopt)
+		So(err, ShouldBeNil)
+		m.Start()
+
+		Reset(func() {
+			So(w.Close(), ShouldBeNil)
+			m.Stop()
+			So(os.RemoveAll(dir), ShouldBeNil)
+		})
+
+		Convey("When running Map", func() {
+			sess := lrmr.NewSession(context.Background(), m, lrmr.WithTimeout(30*time.Second))
+			rows, err := Map(sess).Collect()
+
+			Convey("It should run without error", func() {
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 1000)
+			})
+
+			Convey("Nodes should advertise their unix sockets", func() {
+				So(w.Node.Info().Host, ShouldEqual, wopt.ListenHost)
+			})
+		})
+	})
+}
@@ -0,0 +1,44 @@
+package test
+
+import (
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/partitions"
+)
+
+// unregisteredMapper is intentionally not registered with lrmr.RegisterTypes.
+type unregisteredMapper struct{}
+
+func (u *unregisteredMapper) Map(ctx lrmr.Context, row *lrdd.Row) (*lrdd.Row, error) {
+	return row, nil
+}
+
+func UnregisteredType(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize([]int{1, 2, 3}).
+		Map(&unregisteredMapper{})
+}
+
+// unregisteredOrdering is intentionally not registered with lrmr.RegisterTypes.
+type unregisteredOrdering struct{}
+
+func (unregisteredOrdering) Compare(a, b string) int {
+	return lrmr.NaturalOrdering{}.Compare(a, b)
+}
+
+// UnregisteredNestedType uses an unregistered type nested in a registered one.
+func UnregisteredNestedType(sess *lrmr.Session) *lrmr.Dataset {
+	return SortByKey(sess, lrmr.Reverse(unregisteredOrdering{}))
+}
+
+// unregisteredPartitioner is intentionally not registered with lrmr.RegisterTypes.
+type unregisteredPartitioner struct {
+	partitions.PreservePartitioner
+}
+
+// UnregisteredPartitioner uses an unregistered partitioner wrapped by a built-in one.
+func UnregisteredPartitioner(sess *lrmr.Session) *lrmr.Dataset {
+	return sess.Parallelize([]int{1, 2, 3}).
+		Map(&Multiply{}).
+		PartitionedBy(partitions.WithAssignmentToMaster(&unregisteredPartitioner{})).
+		Map(&Multiply{})
+}
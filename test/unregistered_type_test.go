@@ -0,0 +1,42 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnregisteredType(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("When running a job with a type not registered", func() {
+			_, err := UnregisteredType(cluster.Session).Run()
+
+			Convey("It should be rejected with an error naming the type", func() {
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrNotRegistered)
+				So(err.Error(), ShouldContainSubstring, "github.com/ab180/lrmr/test.unregisteredMapper")
+				So(err.Error(), ShouldContainSubstring, "unregisteredMapper0")
+			})
+		})
+
+		Convey("When running a job with a type not registered nested in a registered type", func() {
+			_, err := UnregisteredNestedType(cluster.Session).Run()
+
+			Convey("It should be rejected with an error naming the nested type", func() {
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrNotRegistered)
+				So(err.Error(), ShouldContainSubstring, "github.com/ab180/lrmr/test.unregisteredOrdering")
+			})
+		})
+
+		Convey("When running a job with a partitioner not registered", func() {
+			_, err := UnregisteredPartitioner(cluster.Session).Run()
+
+			Convey("It should be rejected with an error naming the partitioner", func() {
+				So(errors.Cause(err), ShouldEqual, lrmr.ErrNotRegistered)
+				So(err.Error(), ShouldContainSubstring, "github.com/ab180/lrmr/test.unregisteredPartitioner")
+			})
+		})
+	}))
+}
@@ -0,0 +1,36 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/master"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/version"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWorkerVersions(t *testing.T) {
+	Convey("Given running nodes of the same version", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("No version mismatch should be reported", func() {
+			mismatches, err := cluster.Master().CheckWorkerVersions(context.Background())
+			So(err, ShouldBeNil)
+			So(mismatches, ShouldBeEmpty)
+		})
+
+		Convey("A job requiring supported features should run", func() {
+			ds := Map(cluster.Session)
+			_, err := ds.Collect()
+			So(err, ShouldBeNil)
+		})
+	}, lrmr.WithRequiredFeatures(version.SideInputs)))
+
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		Convey("A job requiring a feature unknown to workers should be refused", func() {
+			_, err := Map(cluster.Session).Run()
+			So(errors.Cause(err), ShouldEqual, master.ErrFeatureNotSupported)
+		})
+	}, lrmr.WithRequiredFeatures("featureFromFuture")))
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/test/integration"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestWarmUp(t *testing.T) {
+	Convey("Given running nodes", t, integration.WithLocalCluster(2, func(cluster *integration.LocalCluster) {
+		ctx := context.Background()
+		m := cluster.Master()
+
+		Convey("When warming up connections", func() {
+			unreachable, err := m.WarmUp(ctx)
+			So(err, ShouldBeNil)
+			So(unreachable, ShouldBeEmpty)
+
+			for _, w := range cluster.Workers() {
+				unreachable, err := w.WarmUp(ctx)
+				So(err, ShouldBeNil)
+				So(unreachable, ShouldBeEmpty)
+			}
+
+			workers, err := m.Workers()
+			So(err, ShouldBeNil)
+			warmed := make(map[string]*grpc.ClientConn)
+			for _, w := range workers {
+				conn, err := m.Cluster.Connect(ctx, w.Host)
+				So(err, ShouldBeNil)
+				warmed[w.Host] = conn
+			}
+
+			Convey("Connections should be established ahead of a job", func() {
+				for _, conn := range warmed {
+					So(conn.GetState(), ShouldEqual, connectivity.Ready)
+				}
+			})
+
+			Convey("Connections should be reused by the job", func() {
+				_, err := Map(cluster.Session).Collect()
+				So(err, ShouldBeNil)
+
+				for host, conn := range warmed {
+					after, err := m.Cluster.Connect(ctx, host)
+					So(err, ShouldBeNil)
+					So(after, ShouldEqual, conn)
+				}
+			})
+		})
+
+		Convey("When a worker is unreachable", func() {
+			reg, err := m.Cluster.Register(ctx, &node.Node{Host: "127.0.0.1:1", Type: node.Worker})
+			So(err, ShouldBeNil)
+			defer reg.Unregister()
+
+			Convey("It should be reported on warming up", func() {
+				unreachable, err := m.WarmUp(ctx)
+				So(err, ShouldBeNil)
+				So(unreachable, ShouldHaveLength, 1)
+				So(unreachable, ShouldContainKey, "127.0.0.1:1")
+			})
+		})
+	}))
+}
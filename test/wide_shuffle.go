@@ -0,0 +1,19 @@
+package test
+
+import (
+	"strconv"
+
+	"github.com/ab180/lrmr"
+)
+
+// WideShuffle counts values of 200 keys shuffled into 64 partitions.
+func WideShuffle(sess *lrmr.Session) *lrmr.Dataset {
+	d := make(map[string][]string, 200)
+	for i := 0; i < 200; i++ {
+		d[strconv.Itoa(i)] = []string{"a", "b", "c"}
+	}
+	return sess.Parallelize(d).
+		Repartition(64).
+		GroupByKey().
+		Reduce(Count())
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr"
+	"github.com/ab180/lrmr/master"
+	"github.com/ab180/lrmr/test/integration"
+	"github.com/ab180/lrmr/test/testutils"
+	"github.com/ab180/lrmr/worker"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWideShuffle_RoutingInCoordinator(t *testing.T) {
+	Convey("Given a master storing routing in the coordinator", t, func() {
+		crd := integration.ProvideEtcd()
+
+		workers := make([]*worker.Worker, 2)
+		for i := range workers {
+			opt := worker.DefaultOptions()
+			opt.ListenHost = "127.0.0.1:"
+			opt.AdvertisedHost = "127.0.0.1:"
+			opt.Concurrency = 2
+			w, err := worker.New(crd, opt)
+			So(err, ShouldBeNil)
+			go w.Start()
+			workers[i] = w
+		}
+
+		// wait for workers to register themselves
+		time.Sleep(200 * time.Millisecond)
+
+		mopt := master.DefaultOptions()
+		mopt.ListenHost = "127.0.0.1:"
+		mopt.AdvertisedHost = "127.0.0.1:"
+		mopt.RoutingInCoordinator = true
+		m, err := master.New(crd, mopt)
+		So(err, ShouldBeNil)
+		m.Start()
+
+		Reset(func() {
+			for _, w := range workers {
+				So(w.Close(), ShouldBeNil)
+			}
+			m.Stop()
+		})
+
+		Convey("When running a wide shuffle", func() {
+			sess := lrmr.NewSession(context.Background(), m, lrmr.WithTimeout(30*time.Second))
+			rows, err := WideShuffle(sess).Collect()
+
+			Convey("Rows should be routed to their partitions", func() {
+				So(err, ShouldBeNil)
+				res := testutils.GroupRowsByKey(rows)
+				So(res, ShouldHaveLength, 200)
+				for _, r := range res {
+					So(r, ShouldHaveLength, 1)
+					So(testutils.IntValue(r[0]), ShouldEqual, 3)
+				}
+			})
+		})
+	})
+}
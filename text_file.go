@@ -0,0 +1,84 @@
+package lrmr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+var _ = RegisterTypes(&textFileReader{})
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+)
+
+// textFileReader emits lines of the files whose paths are given as its input. Files compressed with gzip or bzip2
+// are detected by their headers and decompressed while reading.
+type textFileReader struct{}
+
+func (t *textFileReader) Transform(ctx Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	for row := range in {
+		var path string
+		row.UnmarshalValue(&path)
+
+		if err := readLines(path, func(line string) { emit(lrdd.Value(line)) }); err != nil {
+			return errors.Wrapf(err, "read %s", path)
+		}
+		ctx.AddMetric("Files", 1)
+	}
+	return nil
+}
+
+// readLines calls fn with each line of the file, without line endings.
+func readLines(path string, fn func(line string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "open")
+	}
+	defer f.Close()
+
+	r, err := decompressed(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	lines := bufio.NewReader(r)
+	for {
+		line, err := lines.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(line) > 0 {
+			fn(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// decompressed returns a reader decompressing r if it starts with a header of gzip or bzip2.
+func decompressed(r *bufio.Reader) (io.Reader, error) {
+	header, err := r.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "read gzip header")
+		}
+		return zr, nil
+	case bytes.HasPrefix(header, bzip2Magic) && len(header) == 4 && header[3] >= '1' && header[3] <= '9':
+		return bzip2.NewReader(r), nil
+	}
+	return r, nil
+}
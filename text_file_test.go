@@ -0,0 +1,71 @@
+package lrmr
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ab180/lrmr/lrdd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const textFileContent = "first line\nsecond line\r\nlast line"
+
+// bzip2TextFile is textFileContent compressed with bzip2, since compress/bzip2 can't compress.
+const bzip2TextFile = "425a683931415926535949786410000006d180001240002f259c00200022329a01ea6d42980003987a3d9419b485be0aa146eda8f8bb9229c284824bc32080"
+
+func TestTextFileReader(t *testing.T) {
+	Convey("Given plain and compressed text files", t, func() {
+		dir, err := ioutil.TempDir("", "lrmr-textfile-")
+		So(err, ShouldBeNil)
+		Reset(func() { _ = os.RemoveAll(dir) })
+
+		plainPath := filepath.Join(dir, "plain.txt")
+		So(ioutil.WriteFile(plainPath, []byte(textFileContent), 0644), ShouldBeNil)
+
+		gzipPath := filepath.Join(dir, "compressed.txt.gz")
+		f, err := os.Create(gzipPath)
+		So(err, ShouldBeNil)
+		zw := gzip.NewWriter(f)
+		_, err = zw.Write([]byte(textFileContent))
+		So(err, ShouldBeNil)
+		So(zw.Close(), ShouldBeNil)
+		So(f.Close(), ShouldBeNil)
+
+		bzip2Path := filepath.Join(dir, "compressed.txt.bz2")
+		bz, err := hex.DecodeString(bzip2TextFile)
+		So(err, ShouldBeNil)
+		So(ioutil.WriteFile(bzip2Path, bz, 0644), ShouldBeNil)
+
+		Convey("Reading them should emit identical lines", func() {
+			expected := []string{"first line", "second line", "last line"}
+			for _, path := range []string{plainPath, gzipPath, bzip2Path} {
+				So(readTextFiles(path), ShouldResemble, expected)
+			}
+		})
+
+		Convey("Reading a mix of them should emit lines of every file", func() {
+			So(readTextFiles(plainPath, gzipPath, bzip2Path), ShouldHaveLength, 9)
+		})
+	})
+}
+
+func readTextFiles(paths ...string) (lines []string) {
+	in := make(chan *lrdd.Row, len(paths))
+	for _, path := range paths {
+		in <- lrdd.Value(path)
+	}
+	close(in)
+
+	err := (&textFileReader{}).Transform(stubContext{context.Background()}, in, func(row *lrdd.Row) {
+		var line string
+		row.UnmarshalValue(&line)
+		lines = append(lines, line)
+	})
+	So(err, ShouldBeNil)
+	return lines
+}
@@ -1,15 +1,90 @@
 package transformation
 
-import "context"
+import (
+	"context"
+	"math/rand"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/airbloc/logger"
+)
 
 type Context interface {
 	context.Context
 
 	Broadcast(key string) interface{}
+
+	// SideInput returns rows of the side input declared by the stage, by their keys.
+	// It returns nil if the stage has not declared the side input.
+	SideInput(name string) map[string]*lrdd.Row
+
+	// Param returns a parameter of the job set with lrmr.WithParams. It returns empty string if the parameter is not set.
+	Param(key string) string
 	WorkerLocalOption(key string) interface{}
+
+	// PartitionID, StageName and JobID describe the task which current transformation is running as.
 	PartitionID() string
+	StageName() string
 	JobID() string
 
-	AddMetric(name string, delta int)
+	// NumPartitions returns the number of partitions of the stage, which is the number of tasks
+	// running the transformation across the cluster.
+	NumPartitions() int
+
+	// PeerPartitions returns hosts of the workers running the partitions of the stage, including the current one,
+	// keyed by their partition IDs, for locality-aware decisions. The returned map is a copy which can be modified.
+	// It returns nil in the driver.
+	PeerPartitions() map[string]string
+
+	// AddMetric adds the value to the metric, which is aggregated by sum unless an aggregation is given
+	// (e.g. MaxMetric). A metric should be aggregated the same way in every task, or the job fails.
+	AddMetric(name string, delta int, agg ...MetricAggregation)
 	SetMetric(name string, val int)
+
+	// Heartbeat notifies that the task is still making progress, resetting the timeout of the task.
+	// Transformations spending a long time without consuming input rows should call it periodically.
+	Heartbeat()
+
+	// Provenance returns true if rows of the job carry their lineage. Rows emitted by Map, FlatMap, Reduce
+	// and Combine inherit lineage of their input rows. Other transformations need to use lrdd.Row.InheritLineage.
+	Provenance() bool
+
+	// TempDir returns a scratch directory of the task, which is removed after the task finishes or fails.
+	TempDir() (string, error)
+
+	// Quarantine excludes the row from the output, keeping it with the reason. Rows quarantined by succeeded tasks
+	// can be read with RunningJob.QuarantinedRows. Only a limited number of rows are kept per task.
+	Quarantine(row *lrdd.Row, reason error)
+
+	// EmitToDriver sends the row to the driver apart from the output, e.g. a summary of the partition.
+	// Rows emitted by succeeded tasks can be read with RunningJob.DriverOutputs. Only a limited number of rows
+	// are kept per task, so it is not meant for the results of the job.
+	EmitToDriver(row *lrdd.Row)
+
+	// EmitBatch emits multiple rows at once in lrmr.Transformer, which reduces the overhead of emitting rows
+	// one by one in high-throughput stages. Other transformations write rows to their outputs instead,
+	// and calling it in them fails the task.
+	EmitBatch(rows []*lrdd.Row)
+
+	// Fail reports the task as failed with the error immediately, and stops feeding input rows to the transformation.
+	// The error can be classified with job.Classify to decide whether the task can be retried. The transformation
+	// should return after calling it, and its returned value is ignored.
+	Fail(err error)
+
+	// SaveState keeps the value of the key in the state of the task, which is stored in the cluster and survives
+	// reruns of the partition within the job (e.g. retries), so that a rerun can skip work done by previous attempts.
+	// Since an attempt can fail after doing the work but before saving the state, the work is still done
+	// at least once, and should be idempotent (e.g. writes to an idempotent sink).
+	SaveState(key string, value interface{}) error
+
+	// LoadState reads the value of the key saved by SaveState in the current or previous attempts of the task
+	// into valuePtr. It returns false if the key has not been saved.
+	LoadState(key string, valuePtr interface{}) (bool, error)
+
+	// Rand returns a random number generator of the task, seeded from the job ID and the partition ID so that
+	// reruns of the partition (e.g. retries) behave identically. It is not safe for concurrent use.
+	Rand() *rand.Rand
+
+	// Logger returns a logger of the task. Recent lines logged with it are kept in the worker,
+	// so that they can be fetched with the errors of the task (e.g. RunningJob.TaskLogs).
+	Logger() logger.Logger
 }
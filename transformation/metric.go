@@ -0,0 +1,19 @@
+package transformation
+
+// MetricAggregation is how values of a metric are aggregated within a task and over the tasks of the job.
+type MetricAggregation string
+
+const (
+	// SumMetric adds up the values. It is the default aggregation of metrics.
+	SumMetric MetricAggregation = "sum"
+
+	// MaxMetric and MinMetric keep the largest and the smallest value, respectively.
+	MaxMetric MetricAggregation = "max"
+	MinMetric MetricAggregation = "min"
+
+	// AvgMetric averages the values over the number of values added.
+	AvgMetric MetricAggregation = "avg"
+
+	// LastMetric keeps the latest value. Over the tasks, the value of the task completed last is kept.
+	LastMetric MetricAggregation = "last"
+)
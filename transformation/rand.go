@@ -0,0 +1,16 @@
+package transformation
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// NewPartitionRand returns a random number generator seeded from the job and the partition,
+// so that a partition gets the same random sequence whenever it is run in the job.
+func NewPartitionRand(jobID, partitionID string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(jobID))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(partitionID))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
@@ -0,0 +1,30 @@
+package transformation
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewPartitionRand(t *testing.T) {
+	Convey("Given random generators of partitions", t, func() {
+		sequenceOf := func(jobID, partitionID string) (seq []int64) {
+			r := NewPartitionRand(jobID, partitionID)
+			for i := 0; i < 100; i++ {
+				seq = append(seq, r.Int63())
+			}
+			return seq
+		}
+
+		Convey("Two runs of a partition should produce identical sequences", func() {
+			So(sequenceOf("J1", "0"), ShouldResemble, sequenceOf("J1", "0"))
+			So(sequenceOf("J1", "1"), ShouldResemble, sequenceOf("J1", "1"))
+		})
+
+		Convey("Different partitions or jobs should produce different sequences", func() {
+			So(sequenceOf("J1", "0"), ShouldNotResemble, sequenceOf("J1", "1"))
+			So(sequenceOf("J1", "0"), ShouldNotResemble, sequenceOf("J2", "0"))
+			So(sequenceOf("J1", "10"), ShouldNotResemble, sequenceOf("J11", "0"))
+		})
+	})
+}
@@ -29,6 +29,16 @@ func (s *Serializable) UnmarshalJSON(d []byte) error {
 	return nil
 }
 
+// MarshalBinary encodes the transformation in JSON, so that non-JSON codecs can
+// keep the type information of the transformation.
+func (s Serializable) MarshalBinary() ([]byte, error) {
+	return s.MarshalJSON()
+}
+
+func (s *Serializable) UnmarshalBinary(d []byte) error {
+	return s.UnmarshalJSON(d)
+}
+
 func NameOf(tf Transformation) string {
 	if s, ok := tf.(Serializable); ok {
 		return NameOf(s.Transformation)
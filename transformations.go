@@ -4,22 +4,99 @@ import (
 	"context"
 	"reflect"
 	"sort"
+	"time"
 
 	"github.com/ab180/lrmr/internal/serialization"
+	"github.com/ab180/lrmr/job"
 	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/partitions"
 	"github.com/ab180/lrmr/transformation"
 	"github.com/jinzhu/copier"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 )
 
+// ErrNotRegistered is returned when a job uses a type which is not registered with RegisterTypes.
+var ErrNotRegistered = errors.New("type not registered")
+
+// RegisterTypes registers types of given values, so that they can be deserialized on the workers.
+// Every type used in transformations needs to be registered before running a job.
 func RegisterTypes(tfs ...interface{}) interface{} {
 	for _, tf := range tfs {
-		serialization.TypeOf(tf)
+		serialization.Register(tf)
+	}
+	return nil
+}
+
+// userTypeWrapper is implemented by transformations wrapping a user-defined type.
+type userTypeWrapper interface {
+	userType() interface{}
+}
+
+// nestedUserTypes is implemented by user-defined types wrapping other user-defined types (e.g. Reverse),
+// which also need to be registered to be deserialized on the workers.
+type nestedUserTypes interface {
+	nestedUserTypes() []interface{}
+}
+
+// checkRegisteredTypes returns ErrNotRegistered if a user-defined type used in the stages of the dataset,
+// including the types nested in them and the partitioners of the stages, is not registered.
+func checkRegisteredTypes(ds *Dataset) error {
+	for _, s := range ds.stages {
+		w, ok := s.Function.Transformation.(userTypeWrapper)
+		if !ok {
+			continue
+		}
+		if typ, ok := unregisteredType(w.userType()); ok {
+			return errors.Wrapf(ErrNotRegistered, "%s used in stage %s (call lrmr.RegisterTypes with it)", typ, s.Name)
+		}
+	}
+	// the partitioner of the input stage is the input itself, which is only used in the driver
+	for i := 1; i < len(ds.plans) && i < len(ds.stages); i++ {
+		if typ, ok := unregisteredPartitioner(ds.plans[i].Partitioner); ok {
+			return errors.Wrapf(ErrNotRegistered, "%s used in the output partitioner of stage %s "+
+				"(call lrmr.RegisterTypes with it)", typ, ds.stages[i].Name)
+		}
 	}
 	return nil
 }
 
+// unregisteredType returns the type of given value or of a value nested in it, which is not registered.
+func unregisteredType(v interface{}) (serialization.Type, bool) {
+	if v == nil {
+		return serialization.Type{}, false
+	}
+	typ := serialization.TypeOf(v)
+	if !serialization.IsRegistered(typ) {
+		return typ, true
+	}
+	if n, ok := v.(nestedUserTypes); ok {
+		for _, nested := range n.nestedUserTypes() {
+			if typ, ok := unregisteredType(nested); ok {
+				return typ, true
+			}
+		}
+	}
+	return serialization.Type{}, false
+}
+
+// unregisteredPartitioner is like unregisteredType, but also checks the partitioners wrapped by given partitioner.
+func unregisteredPartitioner(p interface{}) (serialization.Type, bool) {
+	if p == nil {
+		return serialization.Type{}, false
+	}
+	if sp, ok := p.(partitions.SerializablePartitioner); ok {
+		return unregisteredPartitioner(sp.Partitioner)
+	}
+	if typ, ok := unregisteredType(p); ok {
+		return typ, true
+	}
+	return unregisteredPartitioner(partitions.Wrapped(p))
+}
+
+// Transformer transforms rows from the input and emits them to the output.
+// Context.EmitBatch can be used for emitting multiple rows at once in high-throughput stages.
 type Transformer interface {
 	Transform(ctx Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error
 }
@@ -32,12 +109,15 @@ func (t transformerTransformation) Apply(ctx transformation.Context, in chan *lr
 	childCtx, cancel := contextWithCancel(ctx)
 	defer cancel()
 
-	emit := func(row *lrdd.Row) {
-		if emitErr = out.Write(row); emitErr != nil {
+	emitBatch := func(rows []*lrdd.Row) {
+		if emitErr = out.Write(rows...); emitErr != nil {
 			cancel()
 		}
 	}
-	if err := t.transformer.Transform(childCtx, in, emit); err != nil {
+	emit := func(row *lrdd.Row) {
+		emitBatch([]*lrdd.Row{row})
+	}
+	if err := t.transformer.Transform(contextWithBatchEmitter(childCtx, emitBatch), in, emit); err != nil {
 		if errors.Cause(err) == context.Canceled && emitErr != nil {
 			return emitErr
 		}
@@ -55,6 +135,10 @@ func (t *transformerTransformation) UnmarshalJSON(d []byte) error {
 	return nil
 }
 
+func (t transformerTransformation) userType() interface{} {
+	return t.transformer
+}
+
 func (t transformerTransformation) MarshalJSON() ([]byte, error) {
 	return serialization.SerializeStruct(t.transformer)
 }
@@ -79,6 +163,77 @@ func (f filterTransformation) Apply(ctx transformation.Context, in chan *lrdd.Ro
 	return nil
 }
 
+// SchemaValidator checks whether a row conforms to the expected schema.
+type SchemaValidator interface {
+	Validate(*lrdd.Row) error
+}
+
+// InvalidRowPolicy decides how rows failed on schema validation are handled.
+type InvalidRowPolicy int
+
+const (
+	// FailOnInvalidRow fails the task on the first invalid row.
+	FailOnInvalidRow InvalidRowPolicy = iota
+
+	// QuarantineInvalidRows excludes invalid rows from the output, keeping them with the validation errors.
+	// They can be read with RunningJob.QuarantinedRows after the job completes.
+	QuarantineInvalidRows
+)
+
+// validateTransformation filters rows by SchemaValidator, handling invalid rows by the policy.
+type validateTransformation struct {
+	validator SchemaValidator
+	policy    InvalidRowPolicy
+}
+
+func (v *validateTransformation) Apply(ctx transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	for row := range in {
+		if err := v.validator.Validate(row); err != nil {
+			if v.policy == FailOnInvalidRow {
+				return job.Classify(errors.Wrapf(err, "invalid row with key %q", row.Key), job.UserError)
+			}
+			ctx.Quarantine(row, err)
+			continue
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validateTransformation) userType() interface{} {
+	return v.validator
+}
+
+func (v *validateTransformation) MarshalJSON() ([]byte, error) {
+	validator, err := serialization.SerializeStruct(v.validator)
+	if err != nil {
+		return nil, err
+	}
+	return jsoniter.Marshal(struct {
+		Validator jsoniter.RawMessage
+		Policy    InvalidRowPolicy
+	}{validator, v.policy})
+}
+
+func (v *validateTransformation) UnmarshalJSON(data []byte) error {
+	var desc struct {
+		Validator jsoniter.RawMessage
+		Policy    InvalidRowPolicy
+	}
+	if err := jsoniter.Unmarshal(data, &desc); err != nil {
+		return err
+	}
+	validator, err := serialization.DeserializeStruct(desc.Validator)
+	if err != nil {
+		return err
+	}
+	v.validator = validator.(SchemaValidator)
+	v.policy = desc.Policy
+	return nil
+}
+
 type Mapper interface {
 	Map(Context, *lrdd.Row) (*lrdd.Row, error)
 }
@@ -88,11 +243,15 @@ type mapTransformation struct {
 }
 
 func (m *mapTransformation) Apply(ctx transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	provenance := ctx.Provenance()
 	for row := range in {
 		outRow, err := m.mapper.Map(ctx, row)
 		if err != nil {
 			return err
 		}
+		if provenance {
+			inheritLineage(outRow, row)
+		}
 		if err := out.Write(outRow); err != nil {
 			return err
 		}
@@ -100,6 +259,10 @@ func (m *mapTransformation) Apply(ctx transformation.Context, in chan *lrdd.Row,
 	return nil
 }
 
+func (m *mapTransformation) userType() interface{} {
+	return m.mapper
+}
+
 func (m *mapTransformation) MarshalJSON() ([]byte, error) {
 	return serialization.SerializeStruct(m.mapper)
 }
@@ -113,6 +276,48 @@ func (m *mapTransformation) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ForEacher handles each row on the workers for its side effects, e.g. writing to a cache local to the node.
+// See Dataset.ForEach.
+type ForEacher interface {
+	ForEach(Context, *lrdd.Row) error
+}
+
+// forEachMetric is a metric of the number of rows handled by ForEacher.
+const forEachMetric = "ForEachRows"
+
+type forEachTransformation struct {
+	forEacher ForEacher
+}
+
+func (f *forEachTransformation) Apply(ctx transformation.Context, in chan *lrdd.Row, _ output.Output) error {
+	handled := 0
+	for row := range in {
+		if err := f.forEacher.ForEach(ctx, row); err != nil {
+			return err
+		}
+		handled++
+	}
+	ctx.AddMetric(forEachMetric, handled)
+	return nil
+}
+
+func (f *forEachTransformation) userType() interface{} {
+	return f.forEacher
+}
+
+func (f *forEachTransformation) MarshalJSON() ([]byte, error) {
+	return serialization.SerializeStruct(f.forEacher)
+}
+
+func (f *forEachTransformation) UnmarshalJSON(data []byte) error {
+	forEacher, err := serialization.DeserializeStruct(data)
+	if err != nil {
+		return err
+	}
+	f.forEacher = forEacher.(ForEacher)
+	return nil
+}
+
 type FlatMapper interface {
 	FlatMap(Context, *lrdd.Row) ([]*lrdd.Row, error)
 }
@@ -122,11 +327,17 @@ type flatMapTransformation struct {
 }
 
 func (f *flatMapTransformation) Apply(ctx transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	provenance := ctx.Provenance()
 	for row := range in {
 		outRows, err := f.flatMapper.FlatMap(ctx, row)
 		if err != nil {
 			return err
 		}
+		if provenance {
+			for _, outRow := range outRows {
+				inheritLineage(outRow, row)
+			}
+		}
 		if err := out.Write(outRows...); err != nil {
 			return err
 		}
@@ -134,6 +345,10 @@ func (f *flatMapTransformation) Apply(ctx transformation.Context, in chan *lrdd.
 	return nil
 }
 
+func (f *flatMapTransformation) userType() interface{} {
+	return f.flatMapper
+}
+
 func (f *flatMapTransformation) MarshalJSON() ([]byte, error) {
 	return serialization.SerializeStruct(f.flatMapper)
 }
@@ -177,6 +392,10 @@ func (s *sortTransformation) Swap(i, j int) {
 	s.rows[i], s.rows[j] = s.rows[j], s.rows[i]
 }
 
+func (s *sortTransformation) userType() interface{} {
+	return s.sorter
+}
+
 func (s *sortTransformation) MarshalJSON() ([]byte, error) {
 	return serialization.SerializeStruct(s.sorter)
 }
@@ -203,9 +422,11 @@ type combinerTransformation struct {
 func (f *combinerTransformation) Apply(c transformation.Context, in chan *lrdd.Row, out output.Output) error {
 	combiners := make(map[string]Combiner)
 	state := make(map[string]interface{})
+	lineage := newLineageByKey(c)
 
 	for row := range in {
 		ctx := replacePartitionKey(c, row.Key)
+		lineage.add(row)
 
 		combiner := combiners[row.Key]
 		if combiner == nil {
@@ -227,6 +448,7 @@ func (f *combinerTransformation) Apply(c transformation.Context, in chan *lrdd.R
 	rows := make([]*lrdd.Row, len(state))
 	for key, finalVal := range state {
 		rows[i] = lrdd.KeyValue(key, finalVal)
+		lineage.set(rows[i])
 		i++
 	}
 	return out.Write(rows...)
@@ -241,6 +463,10 @@ func (f *combinerTransformation) instantiateCombiner() Combiner {
 	return c.(Combiner)
 }
 
+func (f *combinerTransformation) userType() interface{} {
+	return f.combinerPrototype
+}
+
 func (f *combinerTransformation) MarshalJSON() ([]byte, error) {
 	return serialization.SerializeStruct(f.combinerPrototype)
 }
@@ -266,9 +492,11 @@ type reduceTransformation struct {
 func (f *reduceTransformation) Apply(c transformation.Context, in chan *lrdd.Row, out output.Output) error {
 	reducers := make(map[string]Reducer)
 	state := make(map[string]interface{})
+	lineage := newLineageByKey(c)
 
 	for row := range in {
 		ctx := replacePartitionKey(c, row.Key)
+		lineage.add(row)
 		prev := state[row.Key]
 		if reducers[row.Key] == nil {
 			reducers[row.Key] = f.instantiateReducer()
@@ -285,6 +513,7 @@ func (f *reduceTransformation) Apply(c transformation.Context, in chan *lrdd.Row
 	rows := make([]*lrdd.Row, len(state))
 	for key, finalVal := range state {
 		rows[i] = lrdd.KeyValue(key, finalVal)
+		lineage.set(rows[i])
 		i++
 	}
 	return out.Write(rows...)
@@ -299,6 +528,10 @@ func (f *reduceTransformation) instantiateReducer() Reducer {
 	return r.(Reducer)
 }
 
+func (f *reduceTransformation) userType() interface{} {
+	return f.reducerPrototype
+}
+
 func (f *reduceTransformation) MarshalJSON() ([]byte, error) {
 	return serialization.SerializeStruct(f.reducerPrototype)
 }
@@ -312,11 +545,238 @@ func (f *reduceTransformation) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// minStateExpiryInterval is the minimum interval of checking expired state, which keeps tiny TTLs
+// from spinning the ticker.
+const minStateExpiryInterval = 10 * time.Millisecond
+
+// expiringReduceTransformation is a reduceTransformation evicting state of the keys not updated within the TTL,
+// so that its memory is bounded on unbounded inputs.
+type expiringReduceTransformation struct {
+	reduce      reduceTransformation
+	ttl         time.Duration
+	emitExpired bool
+}
+
+func (f *expiringReduceTransformation) Apply(c transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	reducers := make(map[string]Reducer)
+	state := make(map[string]interface{})
+	updatedAt := make(map[string]time.Time)
+	lineage := newLineageByKey(c)
+
+	interval := f.ttl / 2
+	if interval < minStateExpiryInterval {
+		interval = minStateExpiryInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case row, ok := <-in:
+			if !ok {
+				rows := make([]*lrdd.Row, 0, len(state))
+				for key, finalVal := range state {
+					rows = append(rows, lrdd.KeyValue(key, finalVal))
+					lineage.set(rows[len(rows)-1])
+				}
+				return out.Write(rows...)
+			}
+			ctx := replacePartitionKey(c, row.Key)
+			lineage.add(row)
+			prev := state[row.Key]
+			if reducers[row.Key] == nil {
+				reducers[row.Key] = f.reduce.instantiateReducer()
+				prev = reducers[row.Key].InitialValue()
+			}
+			next, err := reducers[row.Key].Reduce(ctx, prev, row)
+			if err != nil {
+				return err
+			}
+			state[row.Key] = next
+			updatedAt[row.Key] = time.Now()
+
+		case now := <-ticker.C:
+			var expired []*lrdd.Row
+			for key, t := range updatedAt {
+				if now.Sub(t) < f.ttl {
+					continue
+				}
+				if f.emitExpired {
+					expired = append(expired, lrdd.KeyValue(key, state[key]))
+					lineage.set(expired[len(expired)-1])
+				}
+				delete(reducers, key)
+				delete(state, key)
+				delete(updatedAt, key)
+				lineage.remove(key)
+			}
+			if err := out.Write(expired...); err != nil {
+				return err
+			}
+			c.SetMetric("LiveKeys", len(state))
+		}
+	}
+}
+
+func (f *expiringReduceTransformation) userType() interface{} {
+	return f.reduce.reducerPrototype
+}
+
+func (f *expiringReduceTransformation) MarshalJSON() ([]byte, error) {
+	reducer, err := f.reduce.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return jsoniter.Marshal(struct {
+		Reducer     jsoniter.RawMessage
+		TTL         time.Duration
+		EmitExpired bool
+	}{reducer, f.ttl, f.emitExpired})
+}
+
+func (f *expiringReduceTransformation) UnmarshalJSON(data []byte) error {
+	var desc struct {
+		Reducer     jsoniter.RawMessage
+		TTL         time.Duration
+		EmitExpired bool
+	}
+	if err := jsoniter.Unmarshal(data, &desc); err != nil {
+		return err
+	}
+	f.ttl = desc.TTL
+	f.emitExpired = desc.EmitExpired
+	return f.reduce.UnmarshalJSON(desc.Reducer)
+}
+
+// PartialReducer is a Reducer whose partial results, reduced from a part of rows with the same key,
+// can be merged. It is required for reducing hot keys split by Dataset.GroupByKeyWithSkew.
+type PartialReducer interface {
+	Reducer
+
+	// MergePartial merges a partial result, which is a final value of Reduce, into the previous value
+	// started from InitialValue.
+	MergePartial(ctx Context, prev interface{}, partial *lrdd.Row) (next interface{}, err error)
+}
+
+// mergeReduceTransformation merges partial results of PartialReducer by the keys.
+type mergeReduceTransformation struct {
+	reducerPrototype PartialReducer
+}
+
+func (f *mergeReduceTransformation) Apply(c transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	merger := newPartialMerger(f.reducerPrototype)
+	lineage := newLineageByKey(c)
+
+	for row := range in {
+		lineage.add(row)
+		if err := merger.merge(replacePartitionKey(c, row.Key), row); err != nil {
+			return err
+		}
+	}
+	rows := merger.results()
+	for _, row := range rows {
+		lineage.set(row)
+	}
+	return out.Write(rows...)
+}
+
+func (f *mergeReduceTransformation) userType() interface{} {
+	return f.reducerPrototype
+}
+
+func (f *mergeReduceTransformation) MarshalJSON() ([]byte, error) {
+	return serialization.SerializeStruct(f.reducerPrototype)
+}
+
+func (f *mergeReduceTransformation) UnmarshalJSON(data []byte) error {
+	v, err := serialization.DeserializeStruct(data)
+	if err != nil {
+		return err
+	}
+	f.reducerPrototype = v.(PartialReducer)
+	return nil
+}
+
+// partialMerger merges partial results of PartialReducer by the keys, with a reducer instantiated for each key.
+type partialMerger struct {
+	reduce   *reduceTransformation
+	reducers map[string]PartialReducer
+	state    map[string]interface{}
+}
+
+func newPartialMerger(r PartialReducer) *partialMerger {
+	return &partialMerger{
+		reduce:   &reduceTransformation{reducerPrototype: r},
+		reducers: make(map[string]PartialReducer),
+		state:    make(map[string]interface{}),
+	}
+}
+
+// merge merges a partial result into the value of its key. ctx should be bound to the key of the row.
+func (m *partialMerger) merge(ctx Context, partial *lrdd.Row) error {
+	prev := m.state[partial.Key]
+	if m.reducers[partial.Key] == nil {
+		m.reducers[partial.Key] = m.reduce.instantiateReducer().(PartialReducer)
+		prev = m.reducers[partial.Key].InitialValue()
+	}
+	next, err := m.reducers[partial.Key].MergePartial(ctx, prev, partial)
+	if err != nil {
+		return err
+	}
+	m.state[partial.Key] = next
+	return nil
+}
+
+// results returns the merged values of the keys.
+func (m *partialMerger) results() []*lrdd.Row {
+	rows := make([]*lrdd.Row, 0, len(m.state))
+	for key, val := range m.state {
+		rows = append(rows, lrdd.KeyValue(key, val))
+	}
+	return rows
+}
+
 type partitionKeyContext struct {
 	Context
 	partitionKey string
 }
 
+// inheritLineage makes the output row to inherit lineage of the input row it derived from,
+// unless the output row is the input row itself or already has its own lineage.
+func inheritLineage(outRow, inRow *lrdd.Row) {
+	if outRow != inRow && len(outRow.Lineage) == 0 {
+		outRow.InheritLineage(inRow)
+	}
+}
+
+// lineageByKey merges lineage of the rows with a same key, if provenance is enabled in the job.
+type lineageByKey map[string][]*lrdd.Lineage
+
+func newLineageByKey(ctx transformation.Context) lineageByKey {
+	if !ctx.Provenance() {
+		return nil
+	}
+	return make(lineageByKey)
+}
+
+func (l lineageByKey) add(row *lrdd.Row) {
+	if l == nil {
+		return
+	}
+	l[row.Key] = append(l[row.Key], row.Lineage...)
+}
+
+func (l lineageByKey) set(row *lrdd.Row) {
+	if l == nil {
+		return
+	}
+	row.Lineage = l[row.Key]
+}
+
+func (l lineageByKey) remove(key string) {
+	delete(l, key)
+}
+
 func replacePartitionKey(old Context, key string) (new Context) {
 	return &partitionKeyContext{
 		Context:      old,
@@ -0,0 +1,224 @@
+package lrmr
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/transformation"
+	"github.com/airbloc/logger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func BenchmarkTransformer_Emit(b *testing.B) {
+	benchmarkTransformer(b, &emitBenchmarker{})
+}
+
+func BenchmarkTransformer_EmitBatch(b *testing.B) {
+	benchmarkTransformer(b, &emitBenchmarker{batchSize: 100})
+}
+
+func benchmarkTransformer(b *testing.B, t Transformer) {
+	rows := lrdd.From(make([]int, 1000))
+	out := output.NewWriter("0", partitions.NewHashKeyPartitioner(), map[string]output.Output{
+		"0": nopOutput{},
+		"1": nopOutput{},
+	})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		in := make(chan *lrdd.Row, len(rows))
+		for _, row := range rows {
+			in <- row
+		}
+		close(in)
+		if err := (transformerTransformation{t}).Apply(stubContext{context.Background()}, in, out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type emitBenchmarker struct {
+	batchSize int
+}
+
+func (e *emitBenchmarker) Transform(ctx Context, in chan *lrdd.Row, emit func(*lrdd.Row)) error {
+	if e.batchSize == 0 {
+		for row := range in {
+			emit(row)
+		}
+		return nil
+	}
+	batch := make([]*lrdd.Row, 0, e.batchSize)
+	for row := range in {
+		batch = append(batch, row)
+		if len(batch) == e.batchSize {
+			ctx.EmitBatch(batch)
+			batch = batch[:0]
+		}
+	}
+	ctx.EmitBatch(batch)
+	return nil
+}
+
+type nopOutput struct{}
+
+func (nopOutput) Write(...*lrdd.Row) error { return nil }
+func (nopOutput) Close() error             { return nil }
+
+type stubContext struct {
+	context.Context
+}
+
+func (stubContext) Broadcast(string) interface{}          { return nil }
+func (stubContext) SideInput(string) map[string]*lrdd.Row { return nil }
+func (stubContext) Param(string) string                   { return "" }
+func (stubContext) WorkerLocalOption(string) interface{}  { return nil }
+func (stubContext) Heartbeat()                            {}
+func (stubContext) PartitionID() string                   { return "0" }
+func (stubContext) StageName() string                     { return "stub0" }
+func (stubContext) JobID() string                         { return "J" }
+func (stubContext) NumPartitions() int                    { return 1 }
+func (stubContext) PeerPartitions() map[string]string     { return nil }
+func (stubContext) SetMetric(string, int)                 {}
+func (stubContext) TempDir() (string, error)              { return "", nil }
+func (stubContext) Provenance() bool                      { return false }
+func (stubContext) Logger() logger.Logger                 { return log }
+func (stubContext) Quarantine(*lrdd.Row, error)           {}
+func (stubContext) EmitToDriver(*lrdd.Row)                {}
+func (stubContext) EmitBatch([]*lrdd.Row)                 {}
+func (stubContext) Fail(error)                            {}
+func (stubContext) Rand() *rand.Rand                      { return transformation.NewPartitionRand("J", "0") }
+
+func (stubContext) AddMetric(string, int, ...transformation.MetricAggregation) {}
+func (stubContext) SaveState(string, interface{}) error                        { return nil }
+func (stubContext) LoadState(string, interface{}) (bool, error)                { return false, nil }
+
+func TestExpiringReduceTransformation(t *testing.T) {
+	Convey("Given a reduce with state TTL", t, func() {
+		const (
+			ttl      = 100 * time.Millisecond
+			idleKeys = 100
+		)
+		out := &recordingOutput{}
+		ctx := &metricRecordingContext{stubContext: stubContext{context.Background()}}
+		in := make(chan *lrdd.Row)
+
+		run := func(emitExpired bool) chan error {
+			f := &expiringReduceTransformation{reduce: reduceTransformation{&rowCounter{}}, ttl: ttl, emitExpired: emitExpired}
+			done := make(chan error, 1)
+			go func() { done <- f.Apply(ctx, in, out) }()
+
+			for i := 0; i < idleKeys; i++ {
+				in <- lrdd.KeyValue(fmt.Sprintf("idle%d", i), 1)
+			}
+			// keep one key active until the idle keys expire
+			for deadline := time.Now().Add(4 * ttl); time.Now().Before(deadline); {
+				in <- lrdd.KeyValue("active", 1)
+				time.Sleep(ttl / 10)
+			}
+			return done
+		}
+
+		Convey("When keys go idle with emitting expired state", func() {
+			done := run(true)
+
+			Convey("Their state should be evicted and emitted", func() {
+				So(out.keys(), ShouldHaveLength, idleKeys)
+				So(ctx.lastMetric("LiveKeys"), ShouldEqual, 1)
+
+				close(in)
+				So(<-done, ShouldBeNil)
+				So(out.keys(), ShouldHaveLength, idleKeys+1)
+				So(out.keys(), ShouldContain, "active")
+			})
+		})
+
+		Convey("When keys go idle without emitting expired state", func() {
+			done := run(false)
+
+			Convey("Their state should be discarded", func() {
+				So(out.keys(), ShouldBeEmpty)
+				So(ctx.lastMetric("LiveKeys"), ShouldEqual, 1)
+
+				close(in)
+				So(<-done, ShouldBeNil)
+				So(out.keys(), ShouldResemble, []string{"active"})
+			})
+		})
+	})
+
+	Convey("Given a reduce with a TTL shorter than a tick", t, func() {
+		out := &recordingOutput{}
+		ctx := &metricRecordingContext{stubContext: stubContext{context.Background()}}
+		in := make(chan *lrdd.Row, 1)
+		f := &expiringReduceTransformation{reduce: reduceTransformation{&rowCounter{}}, ttl: time.Nanosecond, emitExpired: true}
+
+		Convey("It should reduce the rows without a panic", func() {
+			in <- lrdd.KeyValue("key", 1)
+			close(in)
+			So(func() { So(f.Apply(ctx, in, out), ShouldBeNil) }, ShouldNotPanic)
+			So(out.keys(), ShouldResemble, []string{"key"})
+		})
+	})
+}
+
+// rowCounter counts rows of each key.
+type rowCounter struct{}
+
+func (rowCounter) InitialValue() interface{} {
+	return 0
+}
+
+func (rowCounter) Reduce(_ Context, prev interface{}, _ *lrdd.Row) (interface{}, error) {
+	return prev.(int) + 1, nil
+}
+
+type recordingOutput struct {
+	rows []*lrdd.Row
+	mu   sync.Mutex
+}
+
+func (r *recordingOutput) Write(rows ...*lrdd.Row) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows = append(r.rows, rows...)
+	return nil
+}
+
+func (r *recordingOutput) Close() error { return nil }
+
+func (r *recordingOutput) keys() (keys []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, row := range r.rows {
+		keys = append(keys, row.Key)
+	}
+	return keys
+}
+
+type metricRecordingContext struct {
+	stubContext
+	metrics map[string]int
+	mu      sync.Mutex
+}
+
+func (c *metricRecordingContext) SetMetric(name string, val int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.metrics == nil {
+		c.metrics = make(map[string]int)
+	}
+	c.metrics[name] = val
+}
+
+func (c *metricRecordingContext) lastMetric(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metrics[name]
+}
@@ -0,0 +1,28 @@
+// Package version describes the version of lrmr running on a node, which is reported through Node.Info RPC
+// so that the master can check compatibility of the workers during a rolling upgrade.
+package version
+
+// Version and GitCommit can be overridden on build with:
+//
+//	-ldflags "-X github.com/ab180/lrmr/version.Version=v1.2.3 -X github.com/ab180/lrmr/version.GitCommit=abcdef"
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+)
+
+// ProtocolVersion is a version of the RPC protocol between nodes. It increases on a breaking change,
+// and nodes with different protocol versions can't run a job together.
+const ProtocolVersion = 1
+
+// Features which may not be supported by workers running an older version.
+const (
+	MuxPushStream       = "muxPushStream"
+	ResumablePushStream = "resumablePushStream"
+	SideInputs          = "sideInputs"
+	PersistCompression  = "persistCompression"
+)
+
+// Features returns features supported by this build.
+func Features() []string {
+	return []string{MuxPushStream, ResumablePushStream, SideInputs, PersistCompression}
+}
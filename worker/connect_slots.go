@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// connectSlots limits the number of output streams open at the same time in the worker.
+// Slots for the streams of a task are acquired at once, so that tasks holding a part of their slots
+// never wait for each other. A task with more streams than the limit takes every slot.
+type connectSlots struct {
+	sem  *semaphore.Weighted
+	size int64
+}
+
+func newConnectSlots(size int) *connectSlots {
+	return &connectSlots{
+		sem:  semaphore.NewWeighted(int64(size)),
+		size: int64(size),
+	}
+}
+
+// acquire waits for slots of n streams. The returned function releases the slots,
+// and it is safe to be called more than once.
+func (s *connectSlots) acquire(ctx context.Context, n int) (release func(), err error) {
+	weight := int64(n)
+	if weight > s.size {
+		weight = s.size
+	}
+	if err := s.sem.Acquire(ctx, weight); err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.sem.Release(weight) })
+	}, nil
+}
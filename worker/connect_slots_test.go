@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConnectSlots(t *testing.T) {
+	Convey("Given connect slots of size 2", t, func() {
+		slots := newConnectSlots(2)
+
+		Convey("When a task holds slots of all its streams", func() {
+			release, err := slots.acquire(context.Background(), 2)
+			So(err, ShouldBeNil)
+
+			Convey("Another task should wait until the streams are closed", func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				_, err := slots.acquire(ctx, 1)
+				So(err, ShouldNotBeNil)
+
+				release()
+				release()
+				_, err = slots.acquire(context.Background(), 2)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When a task has more streams than the limit", func() {
+			release, err := slots.acquire(context.Background(), 5)
+			So(err, ShouldBeNil)
+
+			Convey("It should take every slot", func() {
+				So(slots.sem.TryAcquire(1), ShouldBeFalse)
+				release()
+				So(slots.sem.TryAcquire(2), ShouldBeTrue)
+			})
+		})
+	})
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"sync"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+)
+
+// heldOutput keeps rows written by a task of a stage with failure tolerance until the task succeeds,
+// so that a partition dropped in the middle would not deliver a part of its rows.
+type heldOutput struct {
+	output.Output
+
+	rows []*lrdd.Row
+	mu   sync.Mutex
+}
+
+func newHeldOutput(out output.Output) *heldOutput {
+	return &heldOutput{Output: out}
+}
+
+func (h *heldOutput) Write(rows ...*lrdd.Row) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rows = append(h.rows, rows...)
+	return nil
+}
+
+// release writes the held rows to the output.
+func (h *heldOutput) release() error {
+	h.mu.Lock()
+	rows := h.rows
+	h.rows = nil
+	h.mu.Unlock()
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return h.Output.Write(rows...)
+}
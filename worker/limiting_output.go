@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+)
+
+// ErrEmitLimitExceeded is raised when a task emits more rows than Options.MaxEmittedRowsPerTask.
+var ErrEmitLimitExceeded = errors.New("emit limit exceeded")
+
+// limitingOutput fails writes once more than max rows are written to the output.
+type limitingOutput struct {
+	output.Output
+
+	max     int64
+	written atomic.Int64
+	err     error
+}
+
+func newLimitingOutput(out output.Output, max int, stageName, partitionID string) *limitingOutput {
+	return &limitingOutput{
+		Output: out,
+		max:    int64(max),
+		err:    errors.Wrapf(ErrEmitLimitExceeded, "stage %s, partition %s emitted more than %d rows", stageName, partitionID, max),
+	}
+}
+
+func (l *limitingOutput) Write(rows ...*lrdd.Row) error {
+	if l.written.Add(int64(len(rows))) > l.max {
+		return l.err
+	}
+	return l.Output.Write(rows...)
+}
+
+// exceeded returns the error if the limit is exceeded, even if the transformation ignored the error from Write.
+func (l *limitingOutput) exceeded() error {
+	if l.written.Load() > l.max {
+		return l.err
+	}
+	return nil
+}
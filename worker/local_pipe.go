@@ -8,11 +8,13 @@ import (
 
 type LocalPipe struct {
 	reader *input.Reader
+	source string
 }
 
-func NewLocalPipe(r *input.Reader) *LocalPipe {
-	l := &LocalPipe{reader: r}
-	r.Add(l)
+// NewLocalPipe creates a pipe sending rows of the source partition to the reader of a task on the same node.
+func NewLocalPipe(r *input.Reader, source string) *LocalPipe {
+	l := &LocalPipe{reader: r, source: source}
+	r.Add(source, l)
 	return l
 }
 
@@ -21,12 +23,12 @@ func (l *LocalPipe) CloseWithStatus(s job.Status) error {
 }
 
 func (l *LocalPipe) Write(rows ...*lrdd.Row) error {
-	l.reader.C <- rows
+	l.reader.Write(l.source, rows)
 	return nil
 }
 
 func (l *LocalPipe) Close() error {
-	l.reader.Done()
+	l.reader.Done(l.source)
 	l.reader = nil
 	return nil
 }
@@ -2,13 +2,17 @@ package worker
 
 import (
 	"runtime"
+	"time"
 
+	"github.com/ab180/lrmr/cluster"
 	"github.com/ab180/lrmr/cluster/node"
 	"github.com/ab180/lrmr/output"
 	"github.com/creasty/defaults"
 )
 
 type Options struct {
+	// ListenHost is an address to bind the RPC server. A path of Unix domain socket
+	// can be also used with "unix://" prefix (e.g. unix:///var/run/lrmr.sock).
 	ListenHost     string `default:"127.0.0.1:7466"`
 	AdvertisedHost string `default:"127.0.0.1:7466"`
 
@@ -16,15 +20,63 @@ type Options struct {
 	// By default, it will be number of CPUs in the machine.
 	Concurrency int `default:"-"`
 
+	// Memory is the amount of memory in bytes advertised to the cluster, which is reported by
+	// master.Master.ClusterCapacity for drivers to size their jobs. Zero means unknown.
+	Memory uint64 `default:"0"`
+
+	// TaskPoolSize limits the number of tasks of the input stage (the first stage, fed by the driver) executing
+	// concurrently on the worker, so that CPU-bound jobs would not oversubscribe cores. Zero means no limit.
+	// Tasks of later stages are not limited, since they must consume their inputs for upstream tasks to finish.
+	// Inputs of the tasks waiting in the pool are buffered in memory until they run.
+	TaskPoolSize int `default:"0"`
+
+	// SchedulingPolicy decides how the task pools are shared by concurrent jobs. By default, each job has its own
+	// pools. With FIFOScheduling or FairScheduling, the input stages of the jobs share a pool of TaskPoolSize,
+	// so that the number of running input tasks is bounded regardless of the number of jobs.
+	SchedulingPolicy SchedulingPolicy `default:""`
+
+	// MaxEmittedRowsPerTask fails a task emitting more rows than the limit with ErrEmitLimitExceeded,
+	// to catch runaway transformations before they exhaust memories of the cluster. Zero means no limit.
+	MaxEmittedRowsPerTask int `default:"0"`
+
 	// NodeTags is used for partitioner.
 	NodeTags map[string]string `default:"{}"`
 	NodeType node.Type         `default:"worker"`
 
+	// TempDir is a base directory of the scratch directories of the tasks.
+	// By default, it will be the default directory for temporary files of the OS.
+	TempDir string `default:""`
+
+	// ProgressReportInterval is the minimum interval between writes of metrics of a running task,
+	// which can be read from RunningJob.Metrics before the job completes. Zero disables progress reports.
+	ProgressReportInterval time.Duration `default:"1s"`
+
+	// PersistedOutputRetention is a duration to keep the output of a job run with Dataset.Persist in the worker
+	// after the job completes. Outputs of failed jobs are released right after the jobs fail.
+	// Zero keeps the outputs until the worker stops.
+	PersistedOutputRetention time.Duration `default:"1h"`
+
+	// TaskLogs configures recent lines logged by the tasks kept in memory, which can be fetched with
+	// GetTaskLogs RPC after the task fails.
+	TaskLogs struct {
+		// MaxLines is the maximum number of lines kept per task. Zero disables keeping the logs.
+		MaxLines int `default:"1000"`
+
+		// Retention is a duration to keep the logs after the job of the task completes.
+		Retention time.Duration `default:"10m"`
+	}
+
 	Input struct {
 		QueueLength int `default:"1000"`
 		MaxRecvSize int `default:"67108864"`
+
+		// ResumeTimeout is a duration to wait for a broken input stream to be resumed by its sender.
+		ResumeTimeout time.Duration `default:"5s"`
 	}
 	Output output.Options
+
+	// RPC configures connections to other nodes.
+	RPC cluster.Options
 }
 
 func DefaultOptions() (o Options) {
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+	"go.uber.org/atomic"
+)
+
+// pauseGate parks tasks of a paused job until the job is resumed.
+type pauseGate struct {
+	paused  atomic.Bool
+	resumed chan struct{}
+	mu      sync.Mutex
+}
+
+func newPauseGate() *pauseGate {
+	return &pauseGate{resumed: make(chan struct{})}
+}
+
+func (g *pauseGate) set(paused bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused.Load() == paused {
+		return
+	}
+	g.paused.Store(paused)
+	if paused {
+		g.resumed = make(chan struct{})
+	} else {
+		close(g.resumed)
+	}
+}
+
+// wait blocks while the job is paused. It returns an error if the context is done while waiting.
+func (g *pauseGate) wait(ctx context.Context) error {
+	if !g.paused.Load() {
+		return nil
+	}
+	g.mu.Lock()
+	resumed := g.resumed
+	g.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pausingOutput blocks writes while the job is paused, so that transformations stop emitting rows.
+// Like waiting for the input, being blocked while paused is not counted as the task being stuck.
+type pausingOutput struct {
+	output.Output
+	exec *TaskExecutor
+}
+
+func (p *pausingOutput) Write(rows ...*lrdd.Row) error {
+	if err := p.exec.awaitResume(); err != nil {
+		return err
+	}
+	return p.Output.Write(rows...)
+}
+
+// pauseGateOf returns the pause gate of the job in the worker, watching the job to be paused until it completes.
+func (w *Worker) pauseGateOf(j *job.Job) *pauseGate {
+	gate := newPauseGate()
+	v, loaded := w.pauseGates.LoadOrStore(j.ID, gate)
+	if loaded {
+		return v.(*pauseGate)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.jobTracker.OnJobCompletion(j, func(*job.Job, *job.Status) {
+		cancel()
+		w.pauseGates.Delete(j.ID)
+	})
+	go func() {
+		for paused := range w.jobManager.WatchJobPause(ctx, j.ID) {
+			gate.set(paused)
+		}
+		// tasks should not be parked after the job completes
+		gate.set(false)
+	}()
+	return gate
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"sync"
+
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+)
+
+// peekingOutput keeps first rows written to the output as samples, passing every row through.
+type peekingOutput struct {
+	output.Output
+
+	n    int
+	rows []*lrdd.Row
+	mu   sync.Mutex
+}
+
+func newPeekingOutput(out output.Output, n int) *peekingOutput {
+	return &peekingOutput{Output: out, n: n}
+}
+
+func (p *peekingOutput) Write(rows ...*lrdd.Row) error {
+	p.mu.Lock()
+	if remaining := p.n - len(p.rows); remaining > 0 {
+		if len(rows) < remaining {
+			remaining = len(rows)
+		}
+		for _, r := range rows[:remaining] {
+			// rows can be modified by downstream in the same worker
+			sample := *r
+			p.rows = append(p.rows, &sample)
+		}
+	}
+	p.mu.Unlock()
+	return p.Output.Write(rows...)
+}
+
+func (p *peekingOutput) peeked() []*lrdd.Row {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rows
+}
@@ -0,0 +1,201 @@
+package worker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ab180/lrmr/internal/columnar"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/pkg/errors"
+)
+
+// persistedOutput keeps output rows of a partition in the final stage in the worker,
+// so that they can be read by another job running on the worker.
+type persistedOutput struct {
+	worker      *Worker
+	ctx         context.Context
+	jobID       string
+	partitionID string
+	compression job.Compression
+	rows        []*lrdd.Row
+
+	// aborted returns true if the task has been aborted, whose output should not be persisted.
+	aborted func() bool
+}
+
+func newPersistedOutput(ctx context.Context, w *Worker, jobID, partitionID string, c job.Compression, aborted func() bool) *persistedOutput {
+	return &persistedOutput{
+		worker:      w,
+		ctx:         ctx,
+		jobID:       jobID,
+		partitionID: partitionID,
+		compression: c,
+		aborted:     aborted,
+	}
+}
+
+func (p *persistedOutput) Write(rows ...*lrdd.Row) error {
+	p.rows = append(p.rows, rows...)
+	return nil
+}
+
+// Close stores the rows in the worker and records the location of the partition.
+// Rows of an aborted task are discarded.
+func (p *persistedOutput) Close() error {
+	if p.aborted() {
+		p.rows = nil
+		return nil
+	}
+	var stored interface{} = p.rows
+	if p.compression != job.NoCompression {
+		cr, err := compressRows(p.rows, p.compression)
+		if err != nil {
+			return errors.Wrap(err, "compress persisted output")
+		}
+		stored = cr
+	}
+	p.worker.persistedOutputs.Store(path.Join(p.jobID, p.partitionID), stored)
+	if err := p.worker.jobManager.MarkOutputPersisted(p.ctx, p.jobID, p.partitionID, p.worker.Node.Info().Host, len(p.rows)); err != nil {
+		return errors.Wrap(err, "mark output persisted")
+	}
+	return nil
+}
+
+// releasePersistedOutput removes the persisted output of given partition from the worker and its record.
+func (w *Worker) releasePersistedOutput(jobID, partitionID string) {
+	key := path.Join(jobID, partitionID)
+	if _, ok := w.persistedOutputs.Load(key); !ok {
+		return
+	}
+	w.persistedOutputs.Delete(key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := w.jobManager.UnmarkOutputPersisted(ctx, jobID, partitionID); err != nil {
+		log.Warn("Failed to unmark persisted output of {}: {}", key, err)
+	}
+}
+
+// loadPersistedOutput returns the persisted output of given partition in the worker.
+func (w *Worker) loadPersistedOutput(jobID, partitionID string) ([]*lrdd.Row, error) {
+	v, ok := w.persistedOutputs.Load(path.Join(jobID, partitionID))
+	if !ok {
+		return nil, errors.Errorf("persisted output of %s/%s not found on %s", jobID, partitionID, w.Node.Info().Host)
+	}
+	if cr, ok := v.(*compressedRows); ok {
+		rows, err := cr.decompress()
+		if err != nil {
+			return nil, errors.Wrapf(err, "decompress persisted output of %s/%s", jobID, partitionID)
+		}
+		return rows, nil
+	}
+	return v.([]*lrdd.Row), nil
+}
+
+// compressedRows are rows encoded in the columnar format and compressed. Keys and values are
+// stored apart, which compresses better than rows encoded one by one.
+type compressedRows struct {
+	compression job.Compression
+	data        []byte
+	count       int
+}
+
+func compressRows(rows []*lrdd.Row, c job.Compression) (*compressedRows, error) {
+	buf := new(bytes.Buffer)
+	w, err := newCompressWriter(buf, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := columnar.Encode(w, rows); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &compressedRows{compression: c, data: buf.Bytes(), count: len(rows)}, nil
+}
+
+func (c *compressedRows) decompress() ([]*lrdd.Row, error) {
+	r, err := newDecompressReader(bytes.NewReader(c.data), c.compression)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	rows, err := columnar.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != c.count {
+		return nil, errors.Errorf("expected %d rows, but got %d", c.count, len(rows))
+	}
+	return rows, nil
+}
+
+func newCompressWriter(w io.Writer, c job.Compression) (io.WriteCloser, error) {
+	switch c {
+	case job.GzipCompression:
+		gw := gzipWriterPool.Get().(*gzip.Writer)
+		gw.Reset(w)
+		return pooledGzipWriter{gw}, nil
+	}
+	return nil, errors.Errorf("unsupported compression %q", c)
+}
+
+func newDecompressReader(r io.Reader, c job.Compression) (io.ReadCloser, error) {
+	switch c {
+	case job.GzipCompression:
+		gr := gzipReaderPool.Get().(*gzip.Reader)
+		if err := gr.Reset(r); err != nil {
+			gzipReaderPool.Put(gr)
+			return nil, err
+		}
+		return pooledGzipReader{gr}, nil
+	}
+	return nil, errors.Errorf("unsupported compression %q", c)
+}
+
+// gzip writers and readers are pooled, since they allocate large states for compression.
+var (
+	gzipWriterPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(nil)
+		},
+	}
+	gzipReaderPool = sync.Pool{
+		New: func() interface{} {
+			return new(gzip.Reader)
+		},
+	}
+)
+
+// pooledGzipWriter returns the writer to the pool on Close.
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+func (w pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+
+	// the writer should not keep the underlying writer alive in the pool
+	w.Writer.Reset(nil)
+	gzipWriterPool.Put(w.Writer)
+	return err
+}
+
+// pooledGzipReader returns the reader to the pool on Close.
+type pooledGzipReader struct {
+	*gzip.Reader
+}
+
+func (r pooledGzipReader) Close() error {
+	defer gzipReaderPool.Put(r.Reader)
+	return r.Reader.Close()
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompressRows(t *testing.T) {
+	Convey("Given compressible rows", t, func() {
+		rows := make([]*lrdd.Row, 1000)
+		rawSize := 0
+		for i := range rows {
+			rows[i] = lrdd.KeyValue(fmt.Sprintf("key-%d", i%10), "a highly repetitive value of the row")
+			rawSize += rows[i].Size()
+		}
+
+		Convey("When compressing them with gzip", func() {
+			cr, err := compressRows(rows, job.GzipCompression)
+			So(err, ShouldBeNil)
+
+			Convey("It should take less space than the rows", func() {
+				So(len(cr.data), ShouldBeLessThan, rawSize/10)
+			})
+
+			Convey("Decompressed rows should be identical", func() {
+				decompressed, err := cr.decompress()
+				So(err, ShouldBeNil)
+				So(decompressed, ShouldHaveLength, len(rows))
+				for i, r := range decompressed {
+					So(r.Key, ShouldEqual, rows[i].Key)
+					So(r.Value, ShouldResemble, rows[i].Value)
+				}
+			})
+		})
+
+		Convey("When compressing them with unknown algorithm", func() {
+			_, err := compressRows(rows, job.Compression("unknown"))
+
+			Convey("It should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
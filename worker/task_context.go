@@ -2,20 +2,27 @@ package worker
 
 import (
 	"context"
+	"math/rand"
+	"time"
 
 	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/transformation"
+	"github.com/airbloc/logger"
+	"github.com/pkg/errors"
 )
 
 type taskContext struct {
 	context.Context
 	executor *TaskExecutor
+	rand     *rand.Rand
 }
 
 func newTaskContext(ctx context.Context, executor *TaskExecutor) *taskContext {
 	return &taskContext{
 		Context:  ctx,
 		executor: executor,
+		rand:     transformation.NewPartitionRand(executor.task.JobID, executor.task.PartitionID),
 	}
 }
 
@@ -23,22 +30,62 @@ func (c taskContext) PartitionID() string {
 	return c.executor.task.PartitionID
 }
 
+func (c taskContext) StageName() string {
+	return c.executor.task.StageName
+}
+
 func (c taskContext) JobID() string {
 	return c.executor.task.JobID
 }
 
+func (c taskContext) NumPartitions() int {
+	return c.executor.numPartitions
+}
+
+func (c taskContext) PeerPartitions() map[string]string {
+	return c.executor.peerPartitions.ToMap()
+}
+
+func (c taskContext) SaveState(key string, value interface{}) error {
+	return c.executor.jobManager.SaveTaskState(c, c.executor.task.ID(), key, value)
+}
+
+func (c taskContext) LoadState(key string, valuePtr interface{}) (bool, error) {
+	return c.executor.jobManager.LoadTaskState(c, c.executor.task.ID(), key, valuePtr)
+}
+
+func (c taskContext) Rand() *rand.Rand {
+	return c.rand
+}
+
 func (c taskContext) Broadcast(key string) interface{} {
 	return c.executor.broadcast[key]
 }
 
+func (c taskContext) SideInput(name string) map[string]*lrdd.Row {
+	return c.executor.sideInputs[name]
+}
+
+func (c taskContext) Param(key string) string {
+	return c.executor.params[key]
+}
+
 func (c taskContext) WorkerLocalOption(key string) interface{} {
 	return c.executor.localOptions[key]
 }
 
-func (c *taskContext) AddMetric(name string, delta int) {
-	c.executor.taskReporter.UpdateMetric(func(metrics job.Metrics) {
-		metrics[name] += int(delta)
+func (c *taskContext) AddMetric(name string, delta int, agg ...transformation.MetricAggregation) {
+	aggregation := transformation.SumMetric
+	if len(agg) > 0 {
+		aggregation = agg[0]
+	}
+	var err error
+	c.executor.taskReporter.UpdateStatus(func(ts *job.TaskStatus) {
+		err = ts.AddMetric(name, delta, aggregation)
 	})
+	if err != nil {
+		c.executor.fail(job.Classify(err, job.UserError))
+	}
 }
 
 func (c *taskContext) SetMetric(name string, val int) {
@@ -47,6 +94,42 @@ func (c *taskContext) SetMetric(name string, val int) {
 	})
 }
 
+func (c *taskContext) Heartbeat() {
+	now := time.Now()
+	c.executor.lastProgressAt.Store(now.UnixNano())
+	c.executor.taskReporter.UpdateStatus(func(ts *job.TaskStatus) {
+		ts.LastHeartbeatAt = &now
+	})
+}
+
+func (c taskContext) Provenance() bool {
+	return c.executor.provenance
+}
+
+func (c *taskContext) TempDir() (string, error) {
+	return c.executor.getTempDir()
+}
+
+func (c *taskContext) Quarantine(row *lrdd.Row, reason error) {
+	c.executor.quarantine(row, reason)
+}
+
+func (c *taskContext) EmitToDriver(row *lrdd.Row) {
+	c.executor.emitToDriver(row)
+}
+
+func (c *taskContext) EmitBatch([]*lrdd.Row) {
+	c.executor.fail(job.Classify(errors.New("EmitBatch is only available in Transformer"), job.UserError))
+}
+
+func (c *taskContext) Fail(err error) {
+	c.executor.fail(err)
+}
+
+func (c taskContext) Logger() logger.Logger {
+	return c.executor.logger
+}
+
 func (c *taskContext) SetGauge(name string, val float64) {
 	panic("implement me")
 }
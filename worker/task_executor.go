@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/ab180/lrmr/cluster"
 	"github.com/ab180/lrmr/input"
@@ -11,26 +15,119 @@ import (
 	"github.com/ab180/lrmr/job"
 	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/partitions"
 	"github.com/ab180/lrmr/transformation"
 	"github.com/airbloc/logger"
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ErrTaskTimeout is raised when a task does not make any progress in the timeout of its job.
+var ErrTaskTimeout = errors.New("task timed out")
+
+// maxQuarantinedRows is the maximum number of quarantined rows kept per task.
+const maxQuarantinedRows = 1000
+
+// maxDriverOutputs is the maximum number of rows emitted to the driver kept per task.
+const maxDriverOutputs = 1000
+
 type TaskExecutor struct {
 	context *taskContext
 	cancel  context.CancelFunc
 	task    *job.Task
 
+	// inputCtx is done when the task stops receiving its input. Unlike the context of the task,
+	// it lasts after the task is dropped within the failure tolerance, while the input is drained.
+	inputCtx    context.Context
+	cancelInput context.CancelFunc
+
 	Input    *input.Reader
 	function transformation.Transformation
 	Output   *output.Writer
 
 	broadcast    serialization.Broadcast
+	sideInputs   map[string]serialization.SideInput
+	params       map[string]string
 	localOptions map[string]interface{}
 
+	// persistedInput is output of the previous job which the task reads as its input.
+	persistedInput []*lrdd.Row
+
+	// backlog is input rows received before the task runs (by awaitInput, or while waiting in a task pool),
+	// which are fed before the rest. inputEnded is set if the input has been closed while buffering.
+	backlog    [][]*lrdd.Row
+	inputEnded bool
+
+	// buffering and buffered stop and wait for bufferInput.
+	buffering chan struct{}
+	buffered  chan struct{}
+
+	// provenance tags input rows without lineage with their origin in the task.
+	provenance bool
+
+	// peek is the number of output rows sampled for Dataset.Peek.
+	peek int
+
+	// holdOutput keeps output rows of the task until it succeeds, if its stage tolerates failures.
+	holdOutput bool
+
+	// maxEmittedRows fails the task if it emits more rows than the limit. Zero means no limit.
+	maxEmittedRows int
+
+	// weight is a share of task slots of the job under fair scheduling.
+	weight int
+
+	// numPartitions is the number of partitions of the stage of the task.
+	numPartitions int
+
+	// peerPartitions are hosts of the partitions of the stage of the task, keyed by partition IDs.
+	peerPartitions partitions.Assignments
+
+	// pause parks the task while the job is paused.
+	pause *pauseGate
+
+	// logger is given to the transformation as a logger of the task.
+	logger logger.Logger
+
+	// quarantined are rows excluded from the output by the transformation, recorded after the task succeeds.
+	quarantined     []job.QuarantinedRow
+	quarantinedLock sync.Mutex
+
+	// driverOutputs are rows emitted to the driver by the transformation, recorded after the task succeeds.
+	driverOutputs     []*lrdd.Row
+	driverOutputsLock sync.Mutex
+
+	// tempDir is a scratch directory of the task under tempDirBase, created on the first use.
+	tempDirBase string
+	tempDir     string
+	tempDirLock sync.Mutex
+	finished    bool
+
 	finishChan   chan struct{}
+	finishOnce   sync.Once
 	taskReporter *job.TaskReporter
 	jobManager   *job.Manager
+
+	// timeout and lastProgressAt (in Unix nanoseconds) are used for aborting a task without any progress.
+	timeout        time.Duration
+	lastProgressAt atomic.Int64
+	waitingInput   atomic.Bool
+	waitingOutput  atomic.Bool
+
+	// serializationTime measures serialization of the task, reported as a metric on its completion.
+	serializationTime *serialization.Timer
+
+	// failed is set if the transformation failed the task with Context.Fail.
+	failed atomic.Bool
+
+	// abortErr is the error the task has been aborted with.
+	abortErr atomic.Error
+
+	// completed is set once the task succeeds or fails, so that its result is reported only once
+	// even if it is aborted concurrently (e.g. on timeout).
+	completed atomic.Bool
 }
 
 func NewTaskExecutor(
@@ -53,36 +150,122 @@ func NewTaskExecutor(
 		Output:       out,
 		broadcast:    broadcast,
 		localOptions: localOptions,
-		finishChan:   make(chan struct{}, 1),
+		finishChan:   make(chan struct{}),
 		taskReporter: job.NewTaskReporter(parentCtx, cs, j, task.ID(), status),
 		jobManager:   job.NewManager(cs),
+		timeout:      j.TaskTimeout,
+		provenance:   j.Provenance,
+		weight:       1,
+		logger:       newTaskLogger(task.ID().String(), nil),
+
+		numPartitions:  len(j.GetPartitionsOfStage(task.StageName)),
+		peerPartitions: j.GetPartitionsOfStage(task.StageName),
+	}
+	if j.Weight > 0 {
+		exec.weight = j.Weight
+	}
+	if s := j.GetStage(task.StageName); s != nil && s.FailureTolerance > 0 {
+		exec.holdOutput = true
 	}
 	exec.context = newTaskContext(ctx, exec)
 	exec.cancel = cancel
+	exec.inputCtx, exec.cancelInput = context.WithCancel(parentCtx)
 	return exec
 }
 
 func (e *TaskExecutor) Run() {
+	defer e.finish()
 	defer e.guardPanic()
-	totalRows := 0
+	totalRows, totalBytes := 0, 0
+
+	e.taskReporter.UpdateStatus(func(ts *job.TaskStatus) { ts.Start() })
+	e.lastProgressAt.Store(time.Now().UnixNano())
+	if e.timeout > 0 {
+		go e.abortOnTimeout()
+	}
+
+	e.stopBuffering()
 
 	// pipe input.Reader.C to function input channel
 	inputChan := make(chan *lrdd.Row, 100)
 	go func() {
 		defer e.guardPanic()
 		defer close(inputChan)
-		for rows := range e.Input.C {
-			for _, r := range rows {
+		for {
+			var rows []*lrdd.Row
+			ok := true
+			if len(e.backlog) > 0 {
+				rows, e.backlog = e.backlog[0], e.backlog[1:]
+			} else if e.inputEnded {
+				ok = false
+			} else {
+				// waiting for upstream is not counted as the task being stuck
+				e.waitingInput.Store(true)
+				rows, ok = <-e.Input.C
+				e.waitingInput.Store(false)
+			}
+			e.lastProgressAt.Store(time.Now().UnixNano())
+			if !ok {
+				break
+			}
+			for i, r := range rows {
 				if e.context.Err() != nil {
 					return
 				}
+				if err := e.awaitResume(); err != nil {
+					return
+				}
+				if e.provenance && len(r.Lineage) == 0 {
+					r.Lineage = []*lrdd.Lineage{{
+						Stage:       e.task.StageName,
+						PartitionID: e.task.PartitionID,
+						Index:       int64(totalRows + i),
+					}}
+				}
+				totalBytes += r.Size()
 				inputChan <- r
 			}
 			totalRows += len(rows)
+			if !e.taskReporter.ProgressDue() {
+				continue
+			}
+			if err := e.taskReporter.ReportProgress(job.Metrics{e.inputRowsMetric(): totalRows, e.inputBytesMetric(): totalBytes}); err != nil {
+				log.Warn("Failed to report progress of task {}: {}", e.task.ID(), err)
+			}
 		}
 	}()
 
-	if err := e.function.Apply(e.context, inputChan, e.Output); err != nil {
+	var out output.Output = &progressOutput{Output: e.Output, exec: e}
+	var held *heldOutput
+	if e.holdOutput {
+		held = newHeldOutput(out)
+		out = held
+	}
+	var limiter *limitingOutput
+	if e.maxEmittedRows > 0 {
+		limiter = newLimitingOutput(out, e.maxEmittedRows, e.task.StageName, e.task.PartitionID)
+		out = limiter
+	}
+	var peeker *peekingOutput
+	if e.peek > 0 {
+		peeker = newPeekingOutput(out, e.peek)
+		out = peeker
+	}
+	if e.pause != nil {
+		out = &pausingOutput{Output: out, exec: e}
+	}
+	err := e.function.Apply(e.context, inputChan, out)
+	if e.failed.Load() {
+		// already reported by Context.Fail
+		return
+	}
+	if limiter != nil {
+		if err := limiter.exceeded(); err != nil {
+			e.Abort(job.Classify(err, job.UserError))
+			return
+		}
+	}
+	if err != nil {
 		if errors.Cause(err) == context.Canceled || (e.context.Err() != nil && errors.Cause(err) == io.EOF) {
 			// ignore errors caused by task cancellation
 			return
@@ -92,28 +275,289 @@ func (e *TaskExecutor) Run() {
 	} else if e.context.Err() != nil {
 		return
 	}
+
+	if peeker != nil {
+		// peeked rows need to be recorded before downstream tasks (e.g. collect) can finish
+		if err := e.jobManager.AddPeekedRows(e.context, e.task.ID(), peeker.peeked()); err != nil {
+			log.Warn("Failed to record peeked rows of task {}: {}", e.task.ID(), err)
+		}
+	}
+
+	if rows := e.quarantinedRows(); len(rows) > 0 {
+		if err := e.jobManager.AddQuarantinedRows(e.context, e.task.ID(), rows); err != nil {
+			log.Warn("Failed to record quarantined rows of task {}: {}", e.task.ID(), err)
+		}
+	}
+
+	if rows := e.emittedToDriver(); len(rows) > 0 {
+		// like peeked rows, they need to be recorded before the driver collects the results
+		if err := e.jobManager.AddDriverOutputs(e.context, e.task.ID(), rows); err != nil {
+			log.Warn("Failed to record rows emitted to the driver by task {}: {}", e.task.ID(), err)
+		}
+	}
+
+	if held != nil {
+		if err := held.release(); err != nil {
+			e.Abort(job.Classify(errors.Wrap(err, "write output"), job.InfrastructureError))
+			return
+		}
+	}
+
+	// outputs should be flushed before the task is signalled as finished,
+	// so that the data can be delivered before upstream connections are closed
+	e.waitingOutput.Store(true)
+	err = e.Output.Close()
+	e.waitingOutput.Store(false)
+	if err != nil {
+		e.Abort(job.Classify(errors.Wrap(err, "close output"), job.InfrastructureError))
+		return
+	}
+	if !e.completed.CAS(false, true) {
+		// aborted while closing the outputs
+		return
+	}
 	e.close()
-	e.context.AddMetric(fmt.Sprintf("%s/%s/InputRows", e.task.StageName, e.task.PartitionID), totalRows)
+	e.context.SetMetric(e.inputRowsMetric(), totalRows)
+	e.context.SetMetric(e.inputBytesMetric(), totalBytes)
+	e.context.SetMetric(job.SerializationTimeMetric(e.task.StageName), int(e.serializationTime.Elapsed()/time.Microsecond))
+
+	if err := e.taskReporter.ReportSuccess(); err != nil {
+		log.Error("Task {} have been successfully done, but failed to report: {}", e.task.ID(), err)
+	}
+}
+
+// awaitInput waits for the first input rows of the task. It returns false if the inputs are closed
+// without any rows, which means that every upstream task has written no rows to the partition.
+func (e *TaskExecutor) awaitInput() bool {
+	for {
+		select {
+		case rows, ok := <-e.Input.C:
+			if !ok {
+				return false
+			}
+			if len(rows) > 0 {
+				e.backlog = append(e.backlog, rows)
+				return true
+			}
+		case <-e.context.Done():
+			return true
+		}
+	}
+}
+
+// bufferInput keeps receiving the input of the task until it runs, so that the task waiting in a task pool
+// would not block the ones feeding it. Received rows are kept in memory.
+func (e *TaskExecutor) bufferInput() {
+	e.buffering = make(chan struct{})
+	e.buffered = make(chan struct{})
+	go func() {
+		defer close(e.buffered)
+		for {
+			select {
+			case rows, ok := <-e.Input.C:
+				if !ok {
+					e.inputEnded = true
+					return
+				}
+				e.backlog = append(e.backlog, rows)
+			case <-e.buffering:
+				return
+			case <-e.context.Done():
+				return
+			}
+		}
+	}()
+}
+
+// stopBuffering stops bufferInput, if running, and waits for it to return.
+func (e *TaskExecutor) stopBuffering() {
+	if e.buffering == nil {
+		return
+	}
+	close(e.buffering)
+	<-e.buffered
+}
+
+// awaitResume blocks while the job is paused. Being paused is not counted as the task being stuck.
+func (e *TaskExecutor) awaitResume() error {
+	if e.pause == nil || !e.pause.paused.Load() {
+		return nil
+	}
+	e.waitingInput.Store(true)
+	defer func() {
+		e.waitingInput.Store(false)
+		e.lastProgressAt.Store(time.Now().UnixNano())
+	}()
+	return e.pause.wait(e.context)
+}
+
+// skip completes the task with empty input without running the transformation.
+func (e *TaskExecutor) skip() {
+	defer e.finish()
 
 	if err := e.Output.Close(); err != nil {
-		e.Abort(errors.Wrap(err, "close output"))
+		e.Abort(job.Classify(errors.Wrap(err, "close output"), job.InfrastructureError))
+		return
+	}
+	if !e.completed.CAS(false, true) {
 		return
 	}
 	e.close()
-	e.context.AddMetric(fmt.Sprintf("%s/%s/InputRows", e.task.StageName, e.task.PartitionID), totalRows)
+	e.context.SetMetric(e.inputRowsMetric(), 0)
+	e.context.AddMetric("SkippedEmptyTasks", 1)
 
 	if err := e.taskReporter.ReportSuccess(); err != nil {
-		log.Error("Task {} have been successfully done, but failed to report: {}", e.task.ID(), err)
+		log.Error("Task {} have been skipped, but failed to report: {}", e.task.ID(), err)
+	}
+}
+
+// quarantine keeps the row excluded from the output, up to maxQuarantinedRows.
+// Every quarantined row is counted in the metric, including the ones not kept.
+func (e *TaskExecutor) quarantine(row *lrdd.Row, reason error) {
+	e.context.AddMetric("QuarantinedRows", 1)
+
+	e.quarantinedLock.Lock()
+	defer e.quarantinedLock.Unlock()
+	if len(e.quarantined) >= maxQuarantinedRows {
+		return
 	}
+	e.quarantined = append(e.quarantined, job.QuarantinedRow{
+		Task:   e.task.ID().String(),
+		Row:    row,
+		Reason: reason.Error(),
+	})
 }
 
+func (e *TaskExecutor) quarantinedRows() []job.QuarantinedRow {
+	e.quarantinedLock.Lock()
+	defer e.quarantinedLock.Unlock()
+	return e.quarantined
+}
+
+// emitToDriver keeps the row to be sent to the driver, up to maxDriverOutputs.
+func (e *TaskExecutor) emitToDriver(row *lrdd.Row) {
+	e.driverOutputsLock.Lock()
+	defer e.driverOutputsLock.Unlock()
+	if len(e.driverOutputs) >= maxDriverOutputs {
+		log.Warn("Task {} emitted more than {} rows to the driver. Rest of them are discarded.", e.task.ID(), maxDriverOutputs)
+		return
+	}
+	e.driverOutputs = append(e.driverOutputs, row)
+}
+
+func (e *TaskExecutor) emittedToDriver() []*lrdd.Row {
+	e.driverOutputsLock.Lock()
+	defer e.driverOutputsLock.Unlock()
+	return e.driverOutputs
+}
+
+// inputRowsMetric returns a name of the metric counting input rows of the task.
+func (e *TaskExecutor) inputRowsMetric() string {
+	return job.InputRowsMetric(e.task.StageName, e.task.PartitionID)
+}
+
+// inputBytesMetric returns a name of the metric counting bytes of input rows of the task.
+func (e *TaskExecutor) inputBytesMetric() string {
+	return job.InputBytesMetric(e.task.StageName, e.task.PartitionID)
+}
+
+// fail aborts the task with the error reported by the transformation. It is reported only once.
+func (e *TaskExecutor) fail(err error) {
+	if !e.failed.CAS(false, true) {
+		return
+	}
+	if err == nil {
+		err = errors.New("task failed without an error")
+	}
+	e.Abort(err)
+}
+
+// Abort fails the task with the error, or cancels it if the error is nil. It is ignored if the task
+// has already completed.
 func (e *TaskExecutor) Abort(err error) {
-	e.close()
+	if !e.completed.CAS(false, true) {
+		return
+	}
+	if err != nil {
+		e.abortErr.Store(err)
+	}
+	// unlike close, the input is kept until it turns out whether the task is dropped
+	e.cancel()
+	e.function = nil
 	reportErr := e.taskReporter.ReportFailure(err)
 	if reportErr != nil {
 		log.Error("While reporting the error, another error occurred", reportErr)
 	}
+	if e.taskReporter.Dropped() {
+		// upstream tasks go on sending rows to the dropped partition, which are discarded.
+		// rows held by the task are discarded too, and its outputs are closed without them.
+		go e.drainInput()
+	} else {
+		e.cancelInput()
+	}
 	_ = e.Output.Close()
+	e.finish()
+}
+
+// drainInput discards the rest of the input, so that upstream tasks can finish without the task.
+func (e *TaskExecutor) drainInput() {
+	defer e.cancelInput()
+	for {
+		select {
+		case _, ok := <-e.Input.C:
+			if !ok {
+				return
+			}
+		case <-e.inputCtx.Done():
+			return
+		}
+	}
+}
+
+// abortOnTimeout aborts the task if the task does not make any progress until the timeout.
+func (e *TaskExecutor) abortOnTimeout() {
+	ticker := time.NewTicker(e.timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if e.waitingInput.Load() || e.waitingOutput.Load() {
+				continue
+			}
+			idle := time.Since(time.Unix(0, e.lastProgressAt.Load()))
+			if idle > e.timeout {
+				err := errors.Wrapf(ErrTaskTimeout, "no progress for %s", idle.Round(time.Millisecond))
+				e.Abort(job.Classify(err, job.TransientError))
+				return
+			}
+		case <-e.context.Done():
+			return
+		}
+	}
+}
+
+// progressOutput counts the time blocked in writing to the output as progress of the task, since it is
+// waiting for the downstream tasks rather than being stuck.
+type progressOutput struct {
+	output.Output
+	exec *TaskExecutor
+}
+
+func (p *progressOutput) Write(rows ...*lrdd.Row) error {
+	p.exec.waitingOutput.Store(true)
+	err := p.Output.Write(rows...)
+	p.exec.waitingOutput.Store(false)
+	p.exec.lastProgressAt.Store(time.Now().UnixNano())
+	return err
+}
+
+// abortedError returns a gRPC status telling senders that the task no longer receives rows, with the cause.
+func (e *TaskExecutor) abortedError() error {
+	if err := e.abortErr.Load(); err != nil {
+		return status.Errorf(codes.Aborted, "task %s failed: %v", e.task.ID(), err)
+	}
+	return status.Errorf(codes.Aborted, "task %s aborted", e.task.ID())
 }
 
 func (e *TaskExecutor) guardPanic() {
@@ -125,9 +569,53 @@ func (e *TaskExecutor) guardPanic() {
 // close frees occupied resources and memories.
 func (e *TaskExecutor) close() {
 	e.cancel()
+	e.cancelInput()
 	e.function = nil
 }
 
+// finish signals that the task has finished and its outputs are closed.
+func (e *TaskExecutor) finish() {
+	e.finishOnce.Do(func() {
+		e.removeTempDir()
+		close(e.finishChan)
+	})
+}
+
+// getTempDir returns the scratch directory of the task, creating it if it does not exist.
+func (e *TaskExecutor) getTempDir() (string, error) {
+	e.tempDirLock.Lock()
+	defer e.tempDirLock.Unlock()
+
+	if e.finished {
+		return "", errors.New("task already finished")
+	}
+	if e.tempDir != "" {
+		return e.tempDir, nil
+	}
+	prefix := fmt.Sprintf("lrmr-%s-%s-%s-", e.task.JobID, e.task.StageName, e.task.PartitionID)
+	dir, err := ioutil.TempDir(e.tempDirBase, prefix)
+	if err != nil {
+		return "", errors.Wrap(err, "create temp dir")
+	}
+	e.tempDir = dir
+	return dir, nil
+}
+
+func (e *TaskExecutor) removeTempDir() {
+	e.tempDirLock.Lock()
+	defer e.tempDirLock.Unlock()
+
+	e.finished = true
+	if e.tempDir == "" {
+		return
+	}
+	if err := os.RemoveAll(e.tempDir); err != nil {
+		log.Warn("Failed to remove temp dir {} of task {}: {}", e.tempDir, e.task.ID(), err)
+	}
+	e.tempDir = ""
+}
+
+// WaitForFinish blocks until the task finishes and every output of the task is flushed and closed.
 func (e *TaskExecutor) WaitForFinish() {
-	<-e.context.Done()
+	<-e.finishChan
 }
@@ -0,0 +1,372 @@
+package worker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/input"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	"github.com/ab180/lrmr/transformation"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTaskExecutor_WaitForFinish(t *testing.T) {
+	Convey("Given a task executor with buffered output", t, func() {
+		const numRows = 50
+
+		downstream := &slowOutput{delay: 50 * time.Millisecond}
+		out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": output.NewBufferedOutput(downstream, numRows*2),
+		})
+		in := input.NewReader(1)
+		in.Close()
+
+		exec := newTestTaskExecutor(&rowEmitter{numRows: numRows}, in, out)
+
+		Convey("When the task finishes with rows remaining in the buffer", func() {
+			go exec.Run()
+			exec.WaitForFinish()
+
+			Convey("Every buffered row should be delivered downstream", func() {
+				So(downstream.rows(), ShouldHaveLength, numRows)
+				So(downstream.closed(), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func newTestTaskExecutor(fn transformation.Transformation, in *input.Reader, out *output.Writer) *TaskExecutor {
+	return newTestTaskExecutorOfJob(&job.Job{ID: "J-test"}, fn, in, out)
+}
+
+func newTestTaskExecutorOfJob(j *job.Job, fn transformation.Transformation, in *input.Reader, out *output.Writer) *TaskExecutor {
+	return newTestTaskExecutorOn(coordinator.NewLocalMemory(), j, fn, in, out)
+}
+
+func newTestTaskExecutorOn(crd coordinator.Coordinator, j *job.Job, fn transformation.Transformation, in *input.Reader, out *output.Writer) *TaskExecutor {
+	s := stage.New("test0", fn)
+	j.Stages = []stage.Stage{s}
+	task := job.NewTask("0", node.New("localhost", node.Worker), j.ID, &s)
+
+	ts, err := job.NewManager(crd).CreateTask(context.Background(), task)
+	So(err, ShouldBeNil)
+	return NewTaskExecutor(context.Background(), crd, j, task, ts, fn, in, out, nil, nil)
+}
+
+// rowEmitter emits given number of rows regardless of its input.
+type rowEmitter struct {
+	numRows int
+}
+
+func (r *rowEmitter) Apply(_ transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	for range in {
+	}
+	for i := 0; i < r.numRows; i++ {
+		if err := out.Write(lrdd.Value(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTaskExecutor_EmitLimit(t *testing.T) {
+	Convey("Given a task emitting more rows than the limit", t, func() {
+		downstream := &slowOutput{}
+		out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": downstream,
+		})
+		in := input.NewReader(1)
+		in.Close()
+
+		fns := map[string]transformation.Transformation{
+			"returning the error from Write": &rowEmitter{numRows: 100},
+			"ignoring the error from Write":  &carelessRowEmitter{numRows: 100},
+		}
+		for desc, fn := range fns {
+			Convey("When the transformation is "+desc, func() {
+				exec := newTestTaskExecutor(fn, in, out)
+				exec.maxEmittedRows = 10
+				go exec.Run()
+				exec.WaitForFinish()
+
+				Convey("The task should fail with the stage and the partition", func() {
+					ts, err := exec.jobManager.GetTaskStatus(context.Background(), exec.task.ID())
+					So(err, ShouldBeNil)
+					So(ts.Status, ShouldEqual, job.Failed)
+					So(ts.Error, ShouldContainSubstring, "emit limit exceeded")
+					So(ts.Error, ShouldContainSubstring, "stage test0, partition 0")
+					So(ts.ErrorClass, ShouldEqual, job.UserError)
+				})
+
+				Convey("Rows past the limit should not be written", func() {
+					So(len(downstream.rows()), ShouldBeLessThanOrEqualTo, 10)
+				})
+			})
+		}
+	})
+}
+
+func TestTaskExecutor_EmitBatch(t *testing.T) {
+	Convey("Given a transformation calling EmitBatch outside of Transformer", t, func() {
+		in := input.NewReader(1)
+		in.Close()
+		out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": &slowOutput{},
+		})
+		exec := newTestTaskExecutor(&batchEmitter{}, in, out)
+		go exec.Run()
+		exec.WaitForFinish()
+
+		Convey("The task should fail", func() {
+			ts, err := exec.jobManager.GetTaskStatus(context.Background(), exec.task.ID())
+			So(err, ShouldBeNil)
+			So(ts.Status, ShouldEqual, job.Failed)
+			So(ts.Error, ShouldContainSubstring, "only available in Transformer")
+		})
+	})
+}
+
+// batchEmitter emits a batch with the context.
+type batchEmitter struct{}
+
+func (batchEmitter) Apply(ctx transformation.Context, in chan *lrdd.Row, _ output.Output) error {
+	for range in {
+	}
+	ctx.EmitBatch([]*lrdd.Row{lrdd.Value(1)})
+	return nil
+}
+
+// carelessRowEmitter emits given number of rows, ignoring errors from the output.
+type carelessRowEmitter struct {
+	numRows int
+}
+
+func (r *carelessRowEmitter) Apply(_ transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	for range in {
+	}
+	for i := 0; i < r.numRows; i++ {
+		_ = out.Write(lrdd.Value(i))
+	}
+	return nil
+}
+
+// slowOutput records written rows after a delay, as a downstream on a slow network.
+type slowOutput struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	written  []*lrdd.Row
+	isClosed bool
+}
+
+func (s *slowOutput) Write(rows ...*lrdd.Row) error {
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written = append(s.written, rows...)
+	return nil
+}
+
+func (s *slowOutput) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.isClosed = true
+	return nil
+}
+
+func (s *slowOutput) rows() []*lrdd.Row {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written
+}
+
+func (s *slowOutput) closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isClosed
+}
+
+func TestTaskExecutor_Abort(t *testing.T) {
+	Convey("Given a running task", t, func() {
+		crd := coordinator.NewLocalMemory()
+		in := input.NewReader(1)
+		out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": &slowOutput{},
+		})
+		exec := newTestTaskExecutorOn(crd, &job.Job{ID: "J-test"}, &rowEmitter{}, in, out)
+		go exec.Run()
+
+		Convey("When it is aborted concurrently", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					exec.Abort(errors.New("boom"))
+				}()
+			}
+			wg.Wait()
+			exec.WaitForFinish()
+
+			Convey("The failure should be reported only once", func() {
+				for _, counter := range []string{"doneTasks", "failedTasks"} {
+					n, err := crd.ReadCounter(context.Background(), "status/stages/J-test/test0/"+counter)
+					So(err, ShouldBeNil)
+					So(n, ShouldEqual, 1)
+				}
+			})
+		})
+	})
+
+	Convey("Given a task blocked in writing to its output longer than the timeout", t, func() {
+		in := input.NewReader(1)
+		in.Close()
+		out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": &slowOutput{delay: 500 * time.Millisecond},
+		})
+		exec := newTestTaskExecutor(&rowEmitter{numRows: 2}, in, out)
+		exec.timeout = 100 * time.Millisecond
+
+		Convey("It should not be aborted, since it is waiting for the downstream", func() {
+			go exec.Run()
+			exec.WaitForFinish()
+
+			ts, err := exec.jobManager.GetTaskStatus(context.Background(), exec.task.ID())
+			So(err, ShouldBeNil)
+			So(ts.Status, ShouldEqual, job.Succeeded)
+		})
+	})
+}
+
+func TestTaskExecutor_TempDir(t *testing.T) {
+	Convey("Given a task using a temp directory", t, func() {
+		in := input.NewReader(1)
+		in.Close()
+		out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": &slowOutput{},
+		})
+		fn := &tempDirUser{}
+		exec := newTestTaskExecutor(fn, in, out)
+		exec.tempDirBase = os.TempDir()
+
+		Convey("When the task finishes", func() {
+			go exec.Run()
+			exec.WaitForFinish()
+
+			Convey("The directory should exist during the task under the base directory", func() {
+				So(fn.err, ShouldBeNil)
+				So(fn.existed, ShouldBeTrue)
+				So(filepath.Dir(fn.dir), ShouldEqual, filepath.Clean(os.TempDir()))
+			})
+
+			Convey("The directory should be removed afterward", func() {
+				_, err := os.Stat(fn.dir)
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+// tempDirUser writes a file in the temp directory of the task.
+type tempDirUser struct {
+	dir     string
+	existed bool
+	err     error
+}
+
+func (u *tempDirUser) Apply(ctx transformation.Context, in chan *lrdd.Row, _ output.Output) error {
+	for range in {
+	}
+	u.dir, u.err = ctx.TempDir()
+	if u.err != nil {
+		return u.err
+	}
+	u.err = ioutil.WriteFile(filepath.Join(u.dir, "spill"), []byte("data"), 0644)
+	_, err := os.Stat(u.dir)
+	u.existed = err == nil
+	return u.err
+}
+
+func TestTaskExecutor_State(t *testing.T) {
+	Convey("Given a task saving its progress in the state", t, func() {
+		crd := coordinator.NewLocalMemory()
+		j := &job.Job{ID: "J-test"}
+		downstream := &slowOutput{}
+
+		run := func(fn *resumableEmitter) *TaskExecutor {
+			in := input.NewReader(1)
+			in.Close()
+			out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+				"0": downstream,
+			})
+			exec := newTestTaskExecutorOn(crd, j, fn, in, out)
+			go exec.Run()
+			exec.WaitForFinish()
+			return exec
+		}
+
+		Convey("When the task is retried after failing in the middle", func() {
+			first := run(&resumableEmitter{numRows: 10, failAt: 4})
+			ts, err := first.jobManager.GetTaskStatus(context.Background(), first.task.ID())
+			So(err, ShouldBeNil)
+			So(ts.Status, ShouldEqual, job.Failed)
+
+			retry := &resumableEmitter{numRows: 10}
+			run(retry)
+
+			Convey("The retry should read the state written by the prior attempt", func() {
+				So(retry.resumedFrom, ShouldEqual, 4)
+			})
+
+			Convey("Rows done by the prior attempt should not be emitted again", func() {
+				var values []int
+				for _, row := range downstream.rows() {
+					var v int
+					row.UnmarshalValue(&v)
+					values = append(values, v)
+				}
+				So(values, ShouldResemble, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+			})
+		})
+	})
+}
+
+// resumableEmitter emits numbers up to numRows, saving the next number in the state of the task
+// to resume from it on retries. It fails before emitting failAt if failAt is set.
+type resumableEmitter struct {
+	numRows     int
+	failAt      int
+	resumedFrom int
+}
+
+func (r *resumableEmitter) Apply(ctx transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	for range in {
+	}
+	if _, err := ctx.LoadState("next", &r.resumedFrom); err != nil {
+		return err
+	}
+	for i := r.resumedFrom; i < r.numRows; i++ {
+		if r.failAt > 0 && i == r.failAt {
+			return errors.New("failure in the middle")
+		}
+		if err := out.Write(lrdd.Value(i)); err != nil {
+			return err
+		}
+		if err := ctx.SaveState("next", i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/airbloc/logger"
+)
+
+// logBuffer is a ring buffer keeping recent log lines of a task.
+type logBuffer struct {
+	lines []string
+	next  int
+	full  bool
+	mu    sync.Mutex
+}
+
+func newLogBuffer(maxLines int) *logBuffer {
+	return &logBuffer{lines: make([]string, maxLines)}
+}
+
+func (b *logBuffer) append(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// recent returns the lines in the buffer, from the oldest.
+func (b *logBuffer) recent() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		return append([]string(nil), b.lines[:b.next]...)
+	}
+	return append(append([]string(nil), b.lines[b.next:]...), b.lines[:b.next]...)
+}
+
+// taskLogger is a logger of a task, which records formatted lines to the log buffer of the task.
+type taskLogger struct {
+	logger.Logger
+	buf *logBuffer
+}
+
+func newTaskLogger(taskID string, buf *logBuffer) logger.Logger {
+	return &taskLogger{
+		Logger: logger.New("lrmr.task").WithAttrs(logger.Attrs{"task": taskID}),
+		buf:    buf,
+	}
+}
+
+func (l *taskLogger) Log(level *logger.LogLevel, msg string, args []interface{}) {
+	if l.buf != nil {
+		formatted, _ := logger.Format(msg, *logger.MergeAttrs(args))
+		l.buf.append(fmt.Sprintf("%s %s %s", time.Now().Format(time.RFC3339Nano), level, formatted))
+	}
+	l.Logger.Log(level, msg, args)
+}
+
+func (l *taskLogger) Verbose(msg string, v ...interface{}) {
+	l.Log(logger.Verbose, msg, v)
+}
+
+func (l *taskLogger) Debug(msg string, v ...interface{}) {
+	l.Log(logger.Debug, msg, v)
+}
+
+func (l *taskLogger) Info(msg string, v ...interface{}) {
+	l.Log(logger.Info, msg, v)
+}
+
+func (l *taskLogger) Warn(msg string, v ...interface{}) {
+	l.Log(logger.Warn, msg, v)
+}
+
+// Error logs an error message. Like other loggers, an error given as a first argument is appended to the message.
+func (l *taskLogger) Error(msg string, v ...interface{}) {
+	if len(v) > 0 {
+		if err, ok := v[0].(error); ok {
+			msg = fmt.Sprintf("%s: %v", msg, err)
+			v = v[1:]
+		}
+	}
+	l.Log(logger.Error, msg, v)
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLogBuffer(t *testing.T) {
+	Convey("Given a log buffer", t, func() {
+		buf := newLogBuffer(3)
+
+		Convey("It should return lines from the oldest", func() {
+			buf.append("0")
+			buf.append("1")
+			So(buf.recent(), ShouldResemble, []string{"0", "1"})
+		})
+
+		Convey("It should keep only the most recent lines up to its size", func() {
+			for i := 0; i < 5; i++ {
+				buf.append(strconv.Itoa(i))
+			}
+			So(buf.recent(), ShouldResemble, []string{"2", "3", "4"})
+		})
+	})
+}
@@ -0,0 +1,188 @@
+package worker
+
+import (
+	"path"
+	"sync"
+)
+
+// sharedPoolKey is the key of the pool shared by the jobs under FIFOScheduling or FairScheduling.
+const sharedPoolKey = "shared"
+
+// SchedulingPolicy decides how the task pools of a worker are shared by concurrent jobs.
+type SchedulingPolicy string
+
+const (
+	// IsolatedScheduling gives each stage of the jobs its own pool, so that jobs never wait for each other's tasks.
+	IsolatedScheduling SchedulingPolicy = ""
+
+	// FIFOScheduling shares the pools among the jobs, running the tasks in the order of their submission.
+	FIFOScheduling SchedulingPolicy = "fifo"
+
+	// FairScheduling shares the pools among the jobs, giving a freed slot to the job with
+	// the fewest running tasks relative to its weight, so that a large job cannot starve smaller ones.
+	FairScheduling SchedulingPolicy = "fair"
+)
+
+// taskPool runs tasks with bounded concurrency, so that CPU-bound tasks would not oversubscribe cores.
+// Tasks submitted over the size of the pool wait for running tasks to finish.
+type taskPool struct {
+	size int
+	fair bool
+
+	// running is the number of running tasks by jobs.
+	running map[string]int
+
+	// served is the number of tasks started by jobs divided by their weights, which breaks ties on fair scheduling.
+	// Jobs entering the pool start from the least served one, so that they would not overtake the jobs already running.
+	served map[string]float64
+
+	numRunning int
+	waiting    []*TaskExecutor
+	lock       sync.Mutex
+}
+
+func newTaskPool(size int, fair bool) *taskPool {
+	return &taskPool{
+		size:    size,
+		fair:    fair,
+		running: make(map[string]int),
+		served:  make(map[string]float64),
+	}
+}
+
+// Submit runs the task once a slot of the pool is available. A task aborted while waiting is not run.
+// The input of the task is buffered while waiting, so that the ones feeding the task are not blocked;
+// otherwise, running tasks of the stage would wait forever for their feeders to finish.
+func (p *taskPool) Submit(exec *TaskExecutor) {
+	exec.bufferInput()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.served[exec.task.JobID]; !ok {
+		p.served[exec.task.JobID] = p.leastServed()
+	}
+	p.waiting = append(p.waiting, exec)
+	p.dispatch()
+}
+
+// dispatch runs waiting tasks while slots are available. It must be called with the lock held.
+func (p *taskPool) dispatch() {
+	for p.numRunning < p.size && len(p.waiting) > 0 {
+		i := p.next()
+		exec := p.waiting[i]
+		p.waiting = append(p.waiting[:i], p.waiting[i+1:]...)
+		jobID := exec.task.JobID
+		if exec.context.Err() != nil {
+			if !p.hasTasksOf(jobID) {
+				delete(p.served, jobID)
+			}
+			continue
+		}
+		p.running[jobID]++
+		p.served[jobID] += 1 / float64(exec.weight)
+		p.numRunning++
+
+		go func() {
+			exec.Run()
+
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			if p.running[jobID]--; p.running[jobID] == 0 {
+				delete(p.running, jobID)
+			}
+			p.numRunning--
+			if !p.hasTasksOf(jobID) {
+				delete(p.served, jobID)
+			}
+			p.dispatch()
+		}()
+	}
+}
+
+// next returns an index of the waiting task to run. Under fair scheduling, it is the first task of the job
+// whose running tasks are the fewest relative to its weight, or the least served one on ties.
+// Otherwise, it is the first task.
+func (p *taskPool) next() (selected int) {
+	if !p.fair {
+		return 0
+	}
+	for i, exec := range p.waiting {
+		if p.lessShare(exec, p.waiting[selected]) {
+			selected = i
+		}
+	}
+	return selected
+}
+
+func (p *taskPool) lessShare(a, b *TaskExecutor) bool {
+	shareA := float64(p.running[a.task.JobID]) / float64(a.weight)
+	shareB := float64(p.running[b.task.JobID]) / float64(b.weight)
+	if shareA != shareB {
+		return shareA < shareB
+	}
+	return p.served[a.task.JobID] < p.served[b.task.JobID]
+}
+
+func (p *taskPool) leastServed() (least float64) {
+	first := true
+	for _, served := range p.served {
+		if first || served < least {
+			least, first = served, false
+		}
+	}
+	return least
+}
+
+func (p *taskPool) hasTasksOf(jobID string) bool {
+	if p.running[jobID] > 0 {
+		return true
+	}
+	for _, exec := range p.waiting {
+		if exec.task.JobID == jobID {
+			return true
+		}
+	}
+	return false
+}
+
+// taskPools are pools of the input stages running on a worker (see Worker.launch).
+// Under IsolatedScheduling, the pools are keyed by jobID/stageName. Otherwise, the input stages of every job
+// share a pool. Since tasks of the input stages are fed only by the driver, and their inputs are buffered while
+// waiting, a running task never waits for a task queued behind it, even if the pool is shared by the jobs.
+type taskPools struct {
+	size   int
+	policy SchedulingPolicy
+	pools  sync.Map
+}
+
+func (t *taskPools) get(jobID, stageName string) *taskPool {
+	v, _ := t.pools.LoadOrStore(t.key(jobID, stageName), newTaskPool(t.size, t.policy == FairScheduling))
+	return v.(*taskPool)
+}
+
+// release removes the pool of the stage, unless it is shared with other jobs.
+func (t *taskPools) release(jobID, stageName string) {
+	if t.policy == IsolatedScheduling {
+		t.pools.Delete(path.Join(jobID, stageName))
+	}
+}
+
+// numWaiting returns the number of the tasks waiting for slots in every pool.
+func (t *taskPools) numWaiting() (n int) {
+	t.pools.Range(func(_, v interface{}) bool {
+		p := v.(*taskPool)
+		p.lock.Lock()
+		n += len(p.waiting)
+		p.lock.Unlock()
+		return true
+	})
+	return n
+}
+
+func (t *taskPools) key(jobID, stageName string) string {
+	if t.policy == IsolatedScheduling {
+		return path.Join(jobID, stageName)
+	}
+	return sharedPoolKey
+}
@@ -0,0 +1,313 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/input"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	"github.com/ab180/lrmr/transformation"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTaskPool_Submit(t *testing.T) {
+	Convey("Given a task pool", t, func() {
+		const poolSize, numTasks = 2, 8
+		pool := newTaskPool(poolSize, false)
+		counter := &concurrencyCounter{}
+
+		Convey("When more tasks than the pool size are submitted", func() {
+			var execs []*TaskExecutor
+			for i := 0; i < numTasks; i++ {
+				in := input.NewReader(1)
+				in.Close()
+				out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+					"0": &slowOutput{},
+				})
+				exec := newTestTaskExecutor(counter, in, out)
+				pool.Submit(exec)
+				execs = append(execs, exec)
+			}
+			for _, exec := range execs {
+				exec.WaitForFinish()
+			}
+
+			Convey("No more than the pool size of tasks should run concurrently", func() {
+				So(atomic.LoadInt32(&counter.max), ShouldBeLessThanOrEqualTo, poolSize)
+				So(atomic.LoadInt32(&counter.max), ShouldBeGreaterThan, 0)
+			})
+
+			Convey("Every task should be run", func() {
+				So(atomic.LoadInt32(&counter.total), ShouldEqual, numTasks)
+			})
+		})
+	})
+}
+
+func TestTaskPool_LiveInputs(t *testing.T) {
+	Convey("Given tasks fed by a live upstream, more than the pool size", t, func() {
+		const poolSize, numTasks, numBatches = 1, 4, 10
+		pool := newTaskPool(poolSize, false)
+		counter := &concurrencyCounter{}
+
+		var readers []*input.Reader
+		var execs []*TaskExecutor
+		for i := 0; i < numTasks; i++ {
+			in := input.NewReader(1)
+			out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+				"0": &slowOutput{},
+			})
+			exec := newTestTaskExecutor(counter, in, out)
+			pool.Submit(exec)
+			readers = append(readers, in)
+			execs = append(execs, exec)
+		}
+
+		Convey("The upstream should not be blocked by the waiting tasks", func() {
+			// like a shuffle, the upstream writes to every task and closes them only after it finishes
+			go func() {
+				for b := 0; b < numBatches; b++ {
+					for _, in := range readers {
+						in.Write("0", []*lrdd.Row{lrdd.Value(b)})
+					}
+				}
+				for _, in := range readers {
+					in.Close()
+				}
+			}()
+
+			finished := make(chan struct{})
+			go func() {
+				for _, exec := range execs {
+					exec.WaitForFinish()
+				}
+				close(finished)
+			}()
+			select {
+			case <-finished:
+			case <-time.After(10 * time.Second):
+				So("tasks are deadlocked", ShouldBeEmpty)
+			}
+			So(atomic.LoadInt32(&counter.total), ShouldEqual, numTasks)
+			So(atomic.LoadInt32(&counter.max), ShouldBeLessThanOrEqualTo, poolSize)
+		})
+	})
+}
+
+func TestTaskPools_SharedByJobs(t *testing.T) {
+	Convey("Given two jobs shuffling their inputs on a worker with a shared task pool", t, func() {
+		const numBatches = 10
+		keys := []string{"0", "1"}
+		w := &Worker{taskPools: &taskPools{size: 1, policy: FIFOScheduling}}
+		counter := &concurrencyCounter{}
+
+		type launched struct {
+			j         *job.Job
+			stageName string
+			exec      *TaskExecutor
+		}
+		var tasks []launched
+		var inputs []*input.Reader
+		for _, jobID := range []string{"J-a", "J-b"} {
+			j := &job.Job{ID: jobID}
+
+			downstreams := make(map[string]*input.Reader)
+			for _, key := range keys {
+				in := input.NewReader(1)
+				out := output.NewWriter(key, partitions.NewPreservePartitioner(), map[string]output.Output{
+					key: &slowOutput{},
+				})
+				downstreams[key] = in
+				tasks = append(tasks, launched{j, "test1", newTestTaskExecutorOfJob(j, counter, in, out)})
+			}
+			for _, key := range keys {
+				pipes := make(map[string]output.Output)
+				for k, in := range downstreams {
+					pipes[k] = NewLocalPipe(in, key)
+				}
+				in := input.NewReader(1)
+				out := output.NewWriter(key, partitions.NewFiniteKeyPartitioner(keys), pipes)
+				inputs = append(inputs, in)
+				tasks = append(tasks, launched{j, "test0", newTestTaskExecutorOfJob(j, &forwarder{}, in, out)})
+			}
+			j.Stages = []stage.Stage{{Name: "_input"}, {Name: "test0"}, {Name: "test1"}}
+		}
+		for _, t := range tasks {
+			w.launch(t.j, t.stageName, t.exec)
+		}
+
+		Convey("Every task of the jobs should finish", func() {
+			// the driver feeds the jobs in turn, and closes the inputs after feeding every row
+			go func() {
+				for b := 0; b < numBatches; b++ {
+					for _, in := range inputs {
+						in.Write("0", []*lrdd.Row{lrdd.KeyValue(keys[b%len(keys)], b)})
+					}
+				}
+				for _, in := range inputs {
+					in.Close()
+				}
+			}()
+
+			finished := make(chan struct{})
+			go func() {
+				for _, t := range tasks {
+					t.exec.WaitForFinish()
+				}
+				close(finished)
+			}()
+			select {
+			case <-finished:
+			case <-time.After(10 * time.Second):
+				So("tasks are deadlocked", ShouldBeEmpty)
+			}
+			So(atomic.LoadInt32(&counter.total), ShouldEqual, 2*len(keys))
+		})
+	})
+}
+
+func TestTaskPool_FairScheduling(t *testing.T) {
+	Convey("Given a large job and a small job submitted concurrently", t, func() {
+		const numLargeTasks = 6
+		recorder := &completionRecorder{}
+
+		runJobs := func(pool *taskPool) []string {
+			var execs []*TaskExecutor
+			submit := func(j *job.Job) {
+				in := input.NewReader(1)
+				in.Close()
+				out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+					"0": &slowOutput{},
+				})
+				exec := newTestTaskExecutorOfJob(j, recorder, in, out)
+				pool.Submit(exec)
+				execs = append(execs, exec)
+			}
+			for i := 0; i < numLargeTasks; i++ {
+				submit(&job.Job{ID: "J-large"})
+			}
+			submit(&job.Job{ID: "J-small"})
+
+			for _, exec := range execs {
+				exec.WaitForFinish()
+			}
+			return recorder.completed()
+		}
+
+		Convey("The small job should wait for the large job under FIFO policy", func() {
+			completed := runJobs(newTaskPool(1, false))
+			So(completed, ShouldHaveLength, numLargeTasks+1)
+			So(completed[numLargeTasks], ShouldEqual, "J-small")
+		})
+
+		Convey("The small job should make progress between tasks of the large job under fair policy", func() {
+			completed := runJobs(newTaskPool(1, true))
+			So(completed, ShouldHaveLength, numLargeTasks+1)
+			So(completed[:3], ShouldContain, "J-small")
+		})
+	})
+
+	Convey("Given jobs with different weights waiting for a full pool", t, func() {
+		const poolSize = 4
+		pool := newTaskPool(poolSize, true)
+		recorder := &completionRecorder{}
+		var execs []*TaskExecutor
+		submit := func(j *job.Job) {
+			in := input.NewReader(1)
+			in.Close()
+			out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+				"0": &slowOutput{},
+			})
+			exec := newTestTaskExecutorOfJob(j, recorder, in, out)
+			pool.Submit(exec)
+			execs = append(execs, exec)
+		}
+		for i := 0; i < poolSize; i++ {
+			submit(&job.Job{ID: "J-light", Weight: 1})
+		}
+		for i := 0; i < poolSize; i++ {
+			submit(&job.Job{ID: "J-light", Weight: 1})
+			submit(&job.Job{ID: "J-heavy", Weight: 3})
+		}
+
+		Convey("Freed slots should be shared in proportion to the weights", func() {
+			for _, exec := range execs {
+				exec.WaitForFinish()
+			}
+			secondRound := recorder.completed()[poolSize : poolSize*2]
+			So(countOf(secondRound, "J-heavy"), ShouldEqual, 3)
+			So(countOf(secondRound, "J-light"), ShouldEqual, 1)
+		})
+	})
+}
+
+// completionRecorder records job IDs of the tasks in the order of their completion.
+type completionRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (c *completionRecorder) Apply(ctx transformation.Context, in chan *lrdd.Row, _ output.Output) error {
+	for range in {
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order = append(c.order, ctx.JobID())
+	return nil
+}
+
+func (c *completionRecorder) completed() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.order...)
+}
+
+func countOf(ss []string, s string) (n int) {
+	for _, v := range ss {
+		if v == s {
+			n++
+		}
+	}
+	return n
+}
+
+// forwarder passes its input through.
+type forwarder struct{}
+
+func (forwarder) Apply(_ transformation.Context, in chan *lrdd.Row, out output.Output) error {
+	for row := range in {
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// concurrencyCounter records the maximum number of concurrent Apply calls.
+type concurrencyCounter struct {
+	running, max, total int32
+}
+
+func (c *concurrencyCounter) Apply(_ transformation.Context, in chan *lrdd.Row, _ output.Output) error {
+	for range in {
+	}
+	n := atomic.AddInt32(&c.running, 1)
+	defer atomic.AddInt32(&c.running, -1)
+	for {
+		m := atomic.LoadInt32(&c.max)
+		if n <= m || atomic.CompareAndSwapInt32(&c.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	atomic.AddInt32(&c.total, 1)
+	return nil
+}
@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/ab180/lrmr/cluster"
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/pkg/errors"
+)
+
+// WarmUp connects other workers in the cluster ahead of time, so that the first shuffle would not stall
+// on dialing them. It returns errors of the workers failed to connect, by their hosts.
+func (w *Worker) WarmUp(ctx context.Context) (unreachable map[string]error, err error) {
+	workers, err := w.Cluster.List(ctx, cluster.ListOption{Type: node.Worker})
+	if err != nil {
+		return nil, errors.WithMessage(err, "list available workers")
+	}
+	var hosts []string
+	for _, n := range workers {
+		if n.Host != w.Node.Info().Host {
+			hosts = append(hosts, n.Host)
+		}
+	}
+	unreachable = cluster.WarmUp(ctx, w.Cluster, hosts)
+	for host, err := range unreachable {
+		log.Warn("Failed to warm up connection to {}: {}", host, err)
+	}
+	return unreachable, nil
+}
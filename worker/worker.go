@@ -15,14 +15,17 @@ import (
 	"github.com/ab180/lrmr/input"
 	"github.com/ab180/lrmr/internal/serialization"
 	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
 	"github.com/ab180/lrmr/lrmrpb"
 	"github.com/ab180/lrmr/output"
 	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/version"
 	"github.com/airbloc/logger"
 	"github.com/airbloc/logger/module/loggergrpc"
 	"github.com/golang/protobuf/ptypes/empty"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -31,6 +34,9 @@ import (
 
 var log = logger.New("lrmr")
 
+// collectStageName is the name of the stage collecting results to the master.
+const collectStageName = "_collect"
+
 type Worker struct {
 	Cluster   cluster.Cluster
 	Node      node.Registration
@@ -42,16 +48,47 @@ type Worker struct {
 	runningTasks    sync.Map
 	workerLocalOpts map[string]interface{}
 
+	// taskLogs are log buffers of the tasks keyed by task ID, kept until the retention after the job completes.
+	taskLogs sync.Map
+
+	// persistedOutputs are output rows of the jobs with persisted output, keyed by jobID/partitionID.
+	// They are released after Options.PersistedOutputRetention, or right after the job fails.
+	persistedOutputs sync.Map
+
+	// resumer lets input streams broken by transient failures to be resumed.
+	resumer *input.Resumer
+
+	// connectSlots limits the number of output streams open at the same time.
+	connectSlots *connectSlots
+
+	// pauseGates are pause gates of the running jobs keyed by job ID.
+	pauseGates sync.Map
+
+	// taskPools limits the number of tasks running concurrently if TaskPoolSize is set.
+	taskPools *taskPools
+
+	// numTasks is the number of the tasks created but not finished yet, including those waiting in taskPools.
+	numTasks atomic.Int64
+
 	opt Options
 }
 
 func New(crd coordinator.Coordinator, opt Options) (*Worker, error) {
-	c, err := cluster.OpenRemote(crd, cluster.DefaultOptions())
+	clusterOpt := opt.RPC
+	if clusterOpt.MaxRecvMsgSize == 0 {
+		clusterOpt.MaxRecvMsgSize = opt.Input.MaxRecvSize
+	}
+	if clusterOpt.MaxSendMsgSize == 0 {
+		clusterOpt.MaxSendMsgSize = opt.Output.MaxSendMsgSize
+	}
+	c, err := cluster.OpenRemote(crd, clusterOpt)
 	if err != nil {
 		return nil, err
 	}
 	srv := grpc.NewServer(
 		grpc.MaxRecvMsgSize(opt.Input.MaxRecvSize),
+		grpc.MaxSendMsgSize(opt.Output.MaxSendMsgSize),
+		grpc.KeepaliveEnforcementPolicy(clusterOpt.KeepaliveEnforcementPolicy()),
 		grpc.UnaryInterceptor(loggergrpc.UnaryServerRecover()),
 		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(
 			errorLogMiddleware,
@@ -65,8 +102,15 @@ func New(crd coordinator.Coordinator, opt Options) (*Worker, error) {
 		jobTracker:      job.NewJobTracker(c.States(), jm),
 		RPCServer:       srv,
 		workerLocalOpts: make(map[string]interface{}),
+		resumer:         input.NewResumer(opt.Input.ResumeTimeout),
 		opt:             opt,
 	}
+	if opt.Output.MaxConcurrentConnects > 0 {
+		w.connectSlots = newConnectSlots(opt.Output.MaxConcurrentConnects)
+	}
+	if opt.TaskPoolSize > 0 {
+		w.taskPools = &taskPools{size: opt.TaskPoolSize, policy: opt.SchedulingPolicy}
+	}
 	if err := w.register(); err != nil {
 		return nil, errors.WithMessage(err, "register worker")
 	}
@@ -81,14 +125,17 @@ func (w *Worker) register() error {
 
 	// if port is not specified on ListenHost, it must be automatically
 	// assigned with any available port in system by net.Listen.
-	lis, err := net.Listen("tcp", w.opt.ListenHost)
+	lis, err := cluster.Listen(w.opt.ListenHost)
 	if err != nil {
 		return errors.Wrapf(err, "listen %s", w.opt.ListenHost)
 	}
 	w.serverLis = lis
 
 	advHost := w.opt.AdvertisedHost
-	if strings.HasSuffix(advHost, ":") {
+	if lis.Addr().Network() == "unix" && strings.HasSuffix(advHost, ":") {
+		// no port can be assigned to unix socket. advertise the socket instead
+		advHost = w.opt.ListenHost
+	} else if strings.HasSuffix(advHost, ":") {
 		// port is assigned automatically
 		_, actualPort, _ := net.SplitHostPort(lis.Addr().String())
 		advHost += actualPort
@@ -96,15 +143,43 @@ func (w *Worker) register() error {
 	n := node.New(advHost, w.opt.NodeType)
 	n.Tag = w.opt.NodeTags
 	n.Executors = w.opt.Concurrency
+	n.Memory = w.opt.Memory
 
 	nr, err := w.Cluster.Register(ctx, n)
 	if err != nil {
 		return err
 	}
 	w.Node = nr
+	w.Node.ReportLoad(w.Load)
 	return nil
 }
 
+// Load returns the number of the tasks running on the worker and waiting for the task pools.
+func (w *Worker) Load() node.Load {
+	var queued int
+	if w.taskPools != nil {
+		queued = w.taskPools.numWaiting()
+	}
+	running := int(w.numTasks.Load()) - queued
+	if running < 0 {
+		// tasks aborted while waiting stay in the pools until their turns
+		running = 0
+	}
+	return node.Load{
+		RunningTasks: running,
+		QueuedTasks:  queued,
+	}
+}
+
+// trackLoad counts the task into the load of the worker until it finishes.
+func (w *Worker) trackLoad(exec *TaskExecutor) {
+	w.numTasks.Inc()
+	go func() {
+		exec.WaitForFinish()
+		w.numTasks.Dec()
+	}()
+}
+
 func (w *Worker) Start() error {
 	return w.RPCServer.Serve(w.serverLis)
 }
@@ -118,15 +193,28 @@ func (w *Worker) State() node.State {
 }
 
 func (w *Worker) CreateTasks(ctx context.Context, req *lrmrpb.CreateTasksRequest) (*empty.Empty, error) {
+	start := time.Now()
 	broadcasts, err := serialization.DeserializeBroadcast(req.Broadcasts)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	sideInputs, err := serialization.DeserializeSideInputs(req.SideInputs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// broadcasts and side inputs are deserialized once for the tasks, so they share the time
+	var decodeTime time.Duration
+	if len(req.PartitionIDs) > 0 {
+		decodeTime = time.Since(start) / time.Duration(len(req.PartitionIDs))
+	}
+	if err := w.resolveRouting(ctx, req); err != nil {
+		return nil, err
+	}
 
 	wg, wctx := errgroup.WithContext(ctx)
 	for _, p := range req.PartitionIDs {
 		partitionID := p
-		wg.Go(func() error { return w.createTask(wctx, req, partitionID, broadcasts) })
+		wg.Go(func() error { return w.createTask(wctx, req, partitionID, broadcasts, sideInputs, decodeTime) })
 	}
 	if err := wg.Wait(); err != nil {
 		return nil, err
@@ -134,31 +222,99 @@ func (w *Worker) CreateTasks(ctx context.Context, req *lrmrpb.CreateTasksRequest
 	return &empty.Empty{}, nil
 }
 
-func (w *Worker) createTask(ctx context.Context, req *lrmrpb.CreateTasksRequest, partitionID string, broadcasts serialization.Broadcast) error {
+// resolveRouting fills the output routing of the request with the one stored in the coordinator,
+// if the job is created to read the routing from the coordinator.
+func (w *Worker) resolveRouting(ctx context.Context, req *lrmrpb.CreateTasksRequest) error {
 	j := new(job.Job)
 	if err := req.Job.UnmarshalJSON(j); err != nil {
 		return status.Errorf(codes.InvalidArgument, "invalid JSON in Job: %v", err)
 	}
 	s := j.GetStage(req.Stage)
+	if !j.RoutingInCoordinator || s == nil || s.Output.Stage == "" {
+		return nil
+	}
+	routing, err := w.jobManager.GetRouting(ctx, j.ID, s.Output.Stage)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "read routing of stage %s: %v", s.Output.Stage, err)
+	}
+	if req.Output == nil {
+		req.Output = &lrmrpb.Output{}
+	}
+	req.Output.PartitionToHost = routing.ToMap()
+	return nil
+}
+
+func (w *Worker) createTask(ctx context.Context, req *lrmrpb.CreateTasksRequest, partitionID string, broadcasts serialization.Broadcast, sideInputs map[string]serialization.SideInput, decodeTime time.Duration) error {
+	// timer measures serialization of the task, including decoding of the stage and its input
+	// and encoding of its output
+	timer := new(serialization.Timer)
+	timer.Add(decodeTime)
+
+	start := time.Now()
+	j := new(job.Job)
+	if err := req.Job.UnmarshalJSON(j); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid JSON in Job: %v", err)
+	}
+	timer.Since(start)
+	s := j.GetStage(req.Stage)
 
 	// jobCtx will be disposed after the job completes
-	jobCtx, cancelJobCtx := context.WithCancel(context.Background())
+	jobCtx, cancelJobCtx := context.WithCancel(serialization.WithTimer(context.Background(), timer))
 
 	task := job.NewTask(partitionID, w.Node.Info(), j.ID, s)
 	ts, err := w.jobManager.CreateTask(ctx, task)
 	if err != nil {
 		return status.Errorf(codes.Internal, "create task failed: %v", err)
 	}
-	in := input.NewReader(w.opt.Input.QueueLength)
+	readerOpts := []input.ReaderOption{input.WithSerializationTimer(timer)}
+	if len(s.Inputs) > 0 && s.Inputs[0].MergeSortedBy != nil {
+		numSources, err := numUpstreamPartitions(j, s.Inputs[0].Stage, partitionID)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "unable to merge-sort input: %v", err)
+		}
+		readerOpts = append(readerOpts, input.WithMergeSortByKey(numSources, s.Inputs[0].MergeSortedBy.Compare))
+	}
+	in := input.NewReader(w.opt.Input.QueueLength, readerOpts...)
+
+	var persistedInput []*lrdd.Row
+	if j.InputJobID != "" && j.Stages[1].Name == s.Name {
+		persistedInput, err = w.loadPersistedOutput(j.InputJobID, partitionID)
+		if err != nil {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
 
 	// after job finishes, remaining connections should be closed
-	out, err := w.newOutputWriter(jobCtx, j, s.Name, partitionID, req.Output)
+	var exec *TaskExecutor
+	aborted := func() bool { return exec.completed.Load() }
+	out, err := w.newOutputWriter(jobCtx, j, s.Name, partitionID, req.Output, aborted)
 	if err != nil {
 		return status.Errorf(codes.Internal, "unable to create output: %v", err)
 	}
 
-	exec := NewTaskExecutor(jobCtx, w.Cluster.States(), j, task, ts, s.Function, in, out, broadcasts, w.workerLocalOpts)
+	exec = NewTaskExecutor(jobCtx, w.Cluster.States(), j, task, ts, s.Function, in, out, broadcasts, w.workerLocalOpts)
+	exec.persistedInput = persistedInput
+	exec.sideInputs = sideInputs
+	exec.params = req.Params
+	exec.peek = s.Peek
+	exec.tempDirBase = w.opt.TempDir
+	exec.pause = w.pauseGateOf(j)
+	exec.serializationTime = timer
+	if s.Name != collectStageName {
+		// the collect stage only passes through rows emitted by the previous stage
+		exec.maxEmittedRows = w.opt.MaxEmittedRowsPerTask
+	}
+	exec.taskReporter.ProgressInterval = w.opt.ProgressReportInterval
+	if w.opt.TaskLogs.MaxLines > 0 {
+		logs := newLogBuffer(w.opt.TaskLogs.MaxLines)
+		exec.logger = newTaskLogger(task.ID().String(), logs)
+		w.taskLogs.Store(task.ID().String(), logs)
+		w.jobTracker.OnJobCompletion(j, func(*job.Job, *job.Status) {
+			time.AfterFunc(w.opt.TaskLogs.Retention, func() { w.taskLogs.Delete(task.ID().String()) })
+		})
+	}
 	w.runningTasks.Store(task.ID().String(), exec)
+	w.trackLoad(exec)
 
 	w.jobTracker.OnJobCompletion(j, func(j *job.Job, stat *job.Status) {
 		if len(stat.Errors) > 0 {
@@ -168,16 +324,84 @@ func (w *Worker) createTask(ctx context.Context, req *lrmrpb.CreateTasksRequest,
 		}
 		cancelJobCtx()
 	})
-	go exec.Run()
+	if j.PersistOutput && s.Output.Stage == "" {
+		w.jobTracker.OnJobCompletion(j, func(j *job.Job, stat *job.Status) {
+			if stat.Status == job.Failed {
+				w.releasePersistedOutput(j.ID, partitionID)
+				return
+			}
+			if w.opt.PersistedOutputRetention > 0 {
+				time.AfterFunc(w.opt.PersistedOutputRetention, func() { w.releasePersistedOutput(j.ID, partitionID) })
+			}
+		})
+	}
+	if w.taskPools != nil {
+		w.jobTracker.OnJobCompletion(j, func(j *job.Job, _ *job.Status) {
+			w.taskPools.release(j.ID, s.Name)
+		})
+	}
+	// tasks of the collect stage are never skipped, since the master waits for
+	// the collected result of every partition.
+	if j.SkipEmptyPartitions && s.Name != collectStageName {
+		go func() {
+			if !exec.awaitInput() {
+				exec.skip()
+				return
+			}
+			w.launch(j, s.Name, exec)
+		}()
+		return nil
+	}
+	w.launch(j, s.Name, exec)
 	return nil
 }
 
-func (w *Worker) newOutputWriter(ctx context.Context, j *job.Job, stageName, curPartitionID string, o *lrmrpb.Output) (*output.Writer, error) {
+// launch runs the task, through the task pool of the stage if TaskPoolSize is set and the task is of the input
+// stage. Only the input stage is pooled, since its tasks are fed by the driver and never wait for other tasks,
+// while tasks waiting in a pool of later stages would need to buffer whole outputs of their upstream tasks.
+func (w *Worker) launch(j *job.Job, stageName string, exec *TaskExecutor) {
+	if w.taskPools != nil && len(j.Stages) > 1 && j.Stages[1].Name == stageName {
+		w.taskPools.get(j.ID, stageName).Submit(exec)
+		return
+	}
+	go exec.Run()
+}
+
+// newOutputWriter creates an output of the task. aborted tells whether the task is aborted before the output is closed,
+// which is only used for the persisted output.
+// numUpstreamPartitions returns the number of partitions of the upstream stage sending rows to given partition.
+func numUpstreamPartitions(j *job.Job, upstream, partitionID string) (int, error) {
+	p := j.GetStage(upstream).Output.Partitioner
+	if partitions.IsPreserved(p) {
+		return 1, nil
+	}
+	upstreamPartitions := j.GetPartitionsOfStage(upstream)
+	cp, ok := partitions.UnwrapPartitioner(p).(*partitions.CoalescingPartitioner)
+	if !ok {
+		return len(upstreamPartitions), nil
+	}
+	n := 0
+	for _, a := range upstreamPartitions {
+		target, err := cp.TargetOf(a.PartitionID)
+		if err != nil {
+			return 0, errors.Wrapf(err, "coalesce partition %s", a.PartitionID)
+		}
+		if target == partitionID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (w *Worker) newOutputWriter(ctx context.Context, j *job.Job, stageName, curPartitionID string, o *lrmrpb.Output, aborted func() bool) (*output.Writer, error) {
 	idToOutput := make(map[string]output.Output)
 	cur := j.GetStage(stageName)
 	if cur.Output.Stage == "" {
 		// last stage
-		return output.NewWriter(curPartitionID, partitions.NewPreservePartitioner(), idToOutput), nil
+		if j.PersistOutput {
+			idToOutput[curPartitionID] = newPersistedOutput(ctx, w, j.ID, curPartitionID, j.PersistCompression, aborted)
+		}
+		return output.NewWriter(curPartitionID, partitions.NewPreservePartitioner(), idToOutput, w.writerOptions()...), nil
 	}
 
 	// only connect local
@@ -185,67 +409,205 @@ func (w *Worker) newOutputWriter(ctx context.Context, j *job.Job, stageName, cur
 		taskID := path.Join(j.ID, cur.Output.Stage, curPartitionID)
 		nextTask := w.getRunningTask(taskID)
 
-		idToOutput[curPartitionID] = NewLocalPipe(nextTask.Input)
-		return output.NewWriter(curPartitionID, partitions.NewPreservePartitioner(), idToOutput), nil
+		idToOutput[curPartitionID] = NewLocalPipe(nextTask.Input, curPartitionID)
+		return output.NewWriter(curPartitionID, partitions.NewPreservePartitioner(), idToOutput, w.writerOptions()...), nil
 	}
 
-	var mu sync.Mutex
-	var wg errgroup.Group
-	for i, h := range o.PartitionToHost {
-		id, host := i, h
+	partitionToHost := o.PartitionToHost
+	if cp, ok := partitions.UnwrapPartitioner(cur.Output.Partitioner).(*partitions.CoalescingPartitioner); ok {
+		// only connect the partition which current partition is merged into
+		target, err := cp.TargetOf(curPartitionID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "coalesce partition %s", curPartitionID)
+		}
+		partitionToHost = map[string]string{target: o.PartitionToHost[target]}
+	}
 
-		taskID := path.Join(j.ID, cur.Output.Stage, id)
+	// remote partitions on a same host share a stream
+	idsByHost := make(map[string][]string)
+	for id, host := range partitionToHost {
 		if host == w.Node.Info().Host {
+			taskID := path.Join(j.ID, cur.Output.Stage, id)
 			nextTask := w.getRunningTask(taskID)
 			if nextTask != nil {
-				idToOutput[id] = NewLocalPipe(nextTask.Input)
+				idToOutput[id] = NewLocalPipe(nextTask.Input, curPartitionID)
 				continue
 			}
 		}
+		idsByHost[host] = append(idsByHost[host], id)
+	}
+
+	// the slots are held until every stream of the task is closed
+	releaseSlots := func() {}
+	if w.connectSlots != nil && len(idsByHost) > 0 {
+		release, err := w.connectSlots.acquire(ctx, len(idsByHost))
+		if err != nil {
+			return nil, errors.Wrap(err, "wait for connect slots")
+		}
+		releaseSlots = release
+	}
+	openStreams := atomic.NewInt32(int32(len(idsByHost)))
+
+	var mu sync.Mutex
+	var wg errgroup.Group
+	for h, partitionIDs := range idsByHost {
+		host, ids := h, partitionIDs
 		wg.Go(func() error {
-			out, err := output.OpenPushStream(ctx, w.Cluster, w.Node.Info(), host, taskID)
+			taskIDs := make([]string, len(ids))
+			for i, id := range ids {
+				taskIDs[i] = path.Join(j.ID, cur.Output.Stage, id)
+			}
+			stream, err := output.OpenMuxPushStream(ctx, w.Cluster, w.Node.Info(), curPartitionID, host, taskIDs, w.opt.Output.Reconnect)
 			if err != nil {
 				return err
 			}
+			stream.OnClose(func() {
+				if openStreams.Dec() == 0 {
+					releaseSlots()
+				}
+			})
 			mu.Lock()
-			idToOutput[id] = output.NewBufferedOutput(out, w.opt.Output.BufferLength)
-			mu.Unlock()
+			defer mu.Unlock()
+			for i, id := range ids {
+				idToOutput[id] = output.NewBufferedOutput(stream.Output(taskIDs[i]), w.opt.Output.BufferLength,
+					output.WithMaxBufferBytes(w.opt.Output.BufferBytes))
+			}
 			return nil
 		})
 	}
 	if err := wg.Wait(); err != nil {
+		releaseSlots()
 		return nil, err
 	}
-	return output.NewWriter(curPartitionID, partitions.UnwrapPartitioner(cur.Output.Partitioner), idToOutput), nil
+	return output.NewWriter(curPartitionID, partitions.UnwrapPartitioner(cur.Output.Partitioner), idToOutput, w.writerOptions()...), nil
+}
+
+func (w *Worker) writerOptions() []output.WriterOption {
+	writerOpts := []output.WriterOption{output.WithNilRowPolicy(w.opt.Output.NilRows)}
+	if w.opt.Output.PoolBatches {
+		writerOpts = append(writerOpts, output.WithPooledBatches())
+	}
+	return writerOpts
 }
 
 func (w *Worker) getRunningTask(taskID string) *TaskExecutor {
-	task, _ := w.runningTasks.Load(taskID)
+	task, ok := w.runningTasks.Load(taskID)
+	if !ok {
+		return nil
+	}
 	return task.(*TaskExecutor)
 }
 
+// PushData receives rows pushed to the tasks. The stream is acknowledged as soon as every row in it is
+// delivered to the input of the tasks, before the tasks finish; results of the tasks are reported
+// through the job status, not through the stream.
 func (w *Worker) PushData(stream lrmrpb.Node_PushDataServer) error {
 	h, err := lrmrpb.DataHeaderFromMetadata(stream)
 	if err != nil {
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
+	if h.Resume {
+		if err := w.resumer.Resume(stream.Context(), input.StreamKey(h), stream); err != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		_ = stream.SendAndClose(&empty.Empty{})
+		return nil
+	}
+	if len(h.TaskIDs) > 0 {
+		return w.pushMuxData(stream, h)
+	}
 	exec := w.getRunningTask(h.TaskID)
 	if exec == nil {
 		return status.Errorf(codes.InvalidArgument, "task not found: %s", h.TaskID)
 	}
-	defer w.runningTasks.Delete(h.TaskID)
-
-	in := input.NewPushStream(exec.Input, stream)
-	if err := in.Dispatch(exec.context); err != nil {
+	if exec.inputCtx.Err() != nil {
+		// the sender should stop pushing to the task failed before the stream is opened
+		w.runningTasks.Delete(h.TaskID)
+		return exec.abortedError()
+	}
+	in := input.NewPushStream(exec.Input, stream, h, w.resumer)
+	if exec.persistedInput != nil {
+		// persisted output of the previous job is sent along the input from the master,
+		// after every task in the job is created
+		exec.Input.Write(h.FromPartitionID, exec.persistedInput)
+		exec.persistedInput = nil
+	}
+	if err := in.Dispatch(exec.inputCtx); err != nil {
+		w.runningTasks.Delete(h.TaskID)
+		if exec.inputCtx.Err() != nil {
+			return exec.abortedError()
+		}
 		return err
 	}
-	exec.WaitForFinish()
+	// acknowledge every row received without waiting for the task to finish,
+	// so that the sender can close the stream as soon as its rows are delivered.
+	// upstream may have been closed, but that should not affect the task result
+	_ = stream.SendAndClose(&empty.Empty{})
+
+	go func() {
+		exec.WaitForFinish()
+		w.runningTasks.Delete(h.TaskID)
+	}()
+	return nil
+}
+
+// pushMuxData dispatches rows from a stream multiplexed to multiple tasks.
+func (w *Worker) pushMuxData(stream lrmrpb.Node_PushDataServer, h *lrmrpb.DataHeader) error {
+	execs := make([]*TaskExecutor, len(h.TaskIDs))
+	dests := make(map[string]input.MuxDestination, len(h.TaskIDs))
+	for i, taskID := range h.TaskIDs {
+		exec := w.getRunningTask(taskID)
+		if exec == nil {
+			return status.Errorf(codes.InvalidArgument, "task not found: %s", taskID)
+		}
+		execs[i] = exec
+		dests[taskID] = input.MuxDestination{Reader: exec.Input, Context: exec.inputCtx}
+	}
+	deleteTasks := func() {
+		for _, taskID := range h.TaskIDs {
+			w.runningTasks.Delete(taskID)
+		}
+	}
 
+	// rows to a cancelled task are discarded, while the other tasks go on receiving theirs.
+	// the dispatch stops only after every task is cancelled. it should not depend on the stream's context,
+	// since the stream can be resumed by another stream.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for _, exec := range execs {
+			select {
+			case <-exec.inputCtx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+
+	in := input.NewMuxPushStream(dests, stream, h, w.resumer)
+	if err := in.Dispatch(ctx); err != nil {
+		deleteTasks()
+		if ctx.Err() != nil {
+			return execs[0].abortedError()
+		}
+		return err
+	}
 	// upstream may have been closed, but that should not affect the task result
 	_ = stream.SendAndClose(&empty.Empty{})
+
+	go func() {
+		for _, exec := range execs {
+			exec.WaitForFinish()
+		}
+		deleteTasks()
+	}()
 	return nil
 }
 
+// PollData dispatches output rows of the task to the partition of the poller, given as fromPartitionID
+// of the header. If the poller stops reading before the end of the partition, the rows kept for the partition
+// are released.
 func (w *Worker) PollData(stream lrmrpb.Node_PollDataServer) error {
 	h, err := lrmrpb.DataHeaderFromMetadata(stream)
 	if err != nil {
@@ -255,24 +617,48 @@ func (w *Worker) PollData(stream lrmrpb.Node_PollDataServer) error {
 	if exec == nil {
 		return status.Errorf(codes.InvalidArgument, "task not found: %s", h.TaskID)
 	}
+	ctx := stream.Context()
 	for {
 		req, err := stream.Recv()
 		if err != nil {
 			if err == io.EOF {
-				break
+				return nil
 			}
+			exec.Output.Release(h.FromPartitionID)
 			return err
 		}
-		rows, err := exec.Output.Dispatch(h.TaskID, int(req.N))
-		if err != nil {
+		rows, err := exec.Output.Dispatch(ctx, h.FromPartitionID, int(req.N))
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			exec.Output.Release(h.FromPartitionID)
 			return err
 		}
 		resp := &lrmrpb.PollDataResponse{Data: rows}
 		if err := stream.Send(resp); err != nil {
+			exec.Output.Release(h.FromPartitionID)
 			return err
 		}
 	}
-	panic("implement me")
+}
+
+// Info returns the version of lrmr running on the worker.
+func (w *Worker) Info(context.Context, *empty.Empty) (*lrmrpb.NodeInfo, error) {
+	return &lrmrpb.NodeInfo{
+		Version:         version.Version,
+		GitCommit:       version.GitCommit,
+		ProtocolVersion: version.ProtocolVersion,
+		Features:        version.Features(),
+	}, nil
+}
+
+// GetTaskLogs returns recent lines logged by the task on the worker.
+func (w *Worker) GetTaskLogs(_ context.Context, req *lrmrpb.GetTaskLogsRequest) (*lrmrpb.GetTaskLogsResponse, error) {
+	logs, ok := w.taskLogs.Load(req.TaskID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "logs of task %s not found", req.TaskID)
+	}
+	return &lrmrpb.GetTaskLogsResponse{Lines: logs.(*logBuffer).recent()}, nil
 }
 
 func (w *Worker) Close() error {
@@ -0,0 +1,283 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ab180/lrmr/cluster/node"
+	"github.com/ab180/lrmr/coordinator"
+	"github.com/ab180/lrmr/input"
+	"github.com/ab180/lrmr/job"
+	"github.com/ab180/lrmr/lrdd"
+	"github.com/ab180/lrmr/lrmrpb"
+	"github.com/ab180/lrmr/output"
+	"github.com/ab180/lrmr/partitions"
+	"github.com/ab180/lrmr/stage"
+	"github.com/ab180/lrmr/transformation"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestWorker_PushData(t *testing.T) {
+	Convey("Given a worker running a task which fails after its first input", t, func() {
+		out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": &slowOutput{},
+		})
+		exec := newTestTaskExecutor(&failingTransformation{}, input.NewReader(1), out)
+		w := &Worker{}
+		w.runningTasks.Store(exec.task.ID().String(), exec)
+
+		srv := grpc.NewServer()
+		lrmrpb.RegisterNodeServer(srv, w)
+		lis, err := net.Listen("tcp", "127.0.0.1:")
+		So(err, ShouldBeNil)
+		go srv.Serve(lis)
+		defer srv.Stop()
+
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+		So(err, ShouldBeNil)
+		defer conn.Close()
+
+		rawHead, _ := jsoniter.MarshalToString(&lrmrpb.DataHeader{TaskID: exec.task.ID().String(), FromPartitionID: "0"})
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "dataHeader", rawHead)
+		stream, err := lrmrpb.NewNodeClient(conn).PushData(ctx)
+		So(err, ShouldBeNil)
+		go exec.Run()
+
+		Convey("The sender should be stopped promptly with the failure of the task", func() {
+			sendErr := make(chan error, 1)
+			go func() {
+				for i := 0; ; i++ {
+					if err := stream.Send(&lrmrpb.PushDataRequest{Data: []*lrdd.Row{lrdd.Value(i)}}); err != nil {
+						// the actual error is received after the stream is closed
+						sendErr <- stream.RecvMsg(new(lrmrpb.PushDataRequest))
+						return
+					}
+				}
+			}()
+
+			select {
+			case err := <-sendErr:
+				So(status.Code(err), ShouldEqual, codes.Aborted)
+				So(err.Error(), ShouldContainSubstring, exec.task.ID().String())
+				So(err.Error(), ShouldContainSubstring, "downstream exploded")
+			case <-time.After(3 * time.Second):
+				So("sender not stopped", ShouldBeEmpty)
+			}
+		})
+	})
+}
+
+// failingTransformation fails after receiving its first input row.
+type failingTransformation struct{}
+
+func (f *failingTransformation) Apply(_ transformation.Context, in chan *lrdd.Row, _ output.Output) error {
+	<-in
+	return errors.New("downstream exploded")
+}
+
+func TestWorker_PushMuxData(t *testing.T) {
+	Convey("Given a worker running two tasks sharing a stream, one of which fails after its first input", t, func() {
+		const numRows = 100
+		failing := newTestTaskExecutorOfJob(&job.Job{ID: "J-failing"}, &failingTransformation{}, input.NewReader(1),
+			output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{"0": &slowOutput{}}))
+		healthyOut := &slowOutput{}
+		healthy := newTestTaskExecutorOfJob(&job.Job{ID: "J-healthy"}, forwarder{}, input.NewReader(1),
+			output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{"0": healthyOut}))
+
+		w := &Worker{}
+		taskIDs := []string{failing.task.ID().String(), healthy.task.ID().String()}
+		w.runningTasks.Store(taskIDs[0], failing)
+		w.runningTasks.Store(taskIDs[1], healthy)
+
+		srv := grpc.NewServer()
+		lrmrpb.RegisterNodeServer(srv, w)
+		lis, err := net.Listen("tcp", "127.0.0.1:")
+		So(err, ShouldBeNil)
+		go srv.Serve(lis)
+		defer srv.Stop()
+
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+		So(err, ShouldBeNil)
+		defer conn.Close()
+
+		rawHead, _ := jsoniter.MarshalToString(&lrmrpb.DataHeader{TaskIDs: taskIDs, FromPartitionID: "0"})
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "dataHeader", rawHead)
+		stream, err := lrmrpb.NewNodeClient(conn).PushData(ctx)
+		So(err, ShouldBeNil)
+		go failing.Run()
+		go healthy.Run()
+
+		Convey("When rows are pushed to both tasks", func() {
+			for i := 0; i < numRows; i++ {
+				for _, taskID := range taskIDs {
+					So(stream.Send(&lrmrpb.PushDataRequest{TaskID: taskID, Data: []*lrdd.Row{lrdd.Value(i)}}), ShouldBeNil)
+				}
+			}
+			_, err := stream.CloseAndRecv()
+			So(err, ShouldBeNil)
+
+			Convey("The other task should receive every row of its own", func() {
+				healthy.WaitForFinish()
+				So(healthy.failed.Load(), ShouldBeFalse)
+				So(healthyOut.rows(), ShouldHaveLength, numRows)
+			})
+		})
+	})
+}
+
+func TestWorker_Load(t *testing.T) {
+	Convey("Given a worker with a task pool of size 1", t, func() {
+		w := &Worker{taskPools: &taskPools{size: 1}}
+
+		Convey("When 3 tasks blocked on their inputs are launched", func() {
+			j := &job.Job{ID: "J-test"}
+			var inputs []*input.Reader
+			for i := 0; i < 3; i++ {
+				in := input.NewReader(1)
+				out := output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+					"0": &slowOutput{},
+				})
+				exec := newTestTaskExecutorOfJob(j, &rowEmitter{}, in, out)
+				// only the input stage is pooled
+				j.Stages = append([]stage.Stage{{Name: "_input"}}, j.Stages...)
+				w.trackLoad(exec)
+				w.launch(j, "test0", exec)
+				inputs = append(inputs, in)
+			}
+
+			Convey("One should be running and the others should be queued", func() {
+				So(w.Load(), ShouldResemble, node.Load{RunningTasks: 1, QueuedTasks: 2})
+
+				Convey("The load should be cleared after the tasks finish", func() {
+					for _, in := range inputs {
+						in.Close()
+					}
+					deadline := time.Now().Add(3 * time.Second)
+					for w.Load() != (node.Load{}) && time.Now().Before(deadline) {
+						time.Sleep(10 * time.Millisecond)
+					}
+					So(w.Load(), ShouldResemble, node.Load{})
+				})
+			})
+		})
+	})
+}
+
+func TestWorker_PollData(t *testing.T) {
+	Convey("Given a worker running a task whose output is polled", t, func() {
+		pull := output.NewPullStream(10)
+		exec := newTestTaskExecutor(&failingTransformation{}, input.NewReader(1), output.NewWriter("0", partitions.NewPreservePartitioner(), map[string]output.Output{
+			"0": pull,
+		}))
+		w := &Worker{}
+		w.runningTasks.Store(exec.task.ID().String(), exec)
+
+		// the task keeps writing rows more than the stream can keep
+		written := make(chan struct{})
+		go func() {
+			defer close(written)
+			for i := 0; i < 100; i++ {
+				if err := pull.Write(lrdd.Value(i)); err != nil {
+					return
+				}
+			}
+		}()
+
+		srv := grpc.NewServer()
+		lrmrpb.RegisterNodeServer(srv, w)
+		lis, err := net.Listen("tcp", "127.0.0.1:")
+		So(err, ShouldBeNil)
+		go srv.Serve(lis)
+		defer srv.Stop()
+
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+		So(err, ShouldBeNil)
+		defer conn.Close()
+
+		rawHead, _ := jsoniter.MarshalToString(&lrmrpb.DataHeader{TaskID: exec.task.ID().String(), FromPartitionID: "0"})
+		ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(context.Background(), "dataHeader", rawHead))
+		defer cancel()
+		stream, err := lrmrpb.NewNodeClient(conn).PollData(ctx)
+		So(err, ShouldBeNil)
+
+		Convey("When the poller cancels after reading a batch", func() {
+			So(stream.Send(&lrmrpb.PollDataRequest{N: 5}), ShouldBeNil)
+			resp, err := stream.Recv()
+			So(err, ShouldBeNil)
+			So(resp.Data, ShouldHaveLength, 5)
+
+			cancel()
+
+			Convey("The worker should release the rows kept for the partition", func() {
+				select {
+				case <-written:
+				case <-time.After(3 * time.Second):
+					So("writer blocked on released partition", ShouldBeEmpty)
+				}
+				So(pull.Write(lrdd.Value(100)), ShouldBeNil)
+				So(pull.Close(), ShouldBeNil)
+
+				_, err := pull.Dispatch(context.Background(), 1)
+				So(err, ShouldEqual, io.EOF)
+			})
+		})
+	})
+}
+
+func TestWorker_MaxMsgSize(t *testing.T) {
+	Convey("Given a worker with message size limits", t, func() {
+		const limit = 4096
+
+		opt := DefaultOptions()
+		opt.ListenHost = "127.0.0.1:"
+		opt.AdvertisedHost = "127.0.0.1:"
+		opt.Input.MaxRecvSize = limit
+		opt.Output.MaxSendMsgSize = limit
+		w, err := New(coordinator.NewLocalMemory(), opt)
+		So(err, ShouldBeNil)
+		go w.Start()
+		defer w.Close()
+
+		conn, err := w.Cluster.Connect(context.Background(), w.Node.Info().Host)
+		So(err, ShouldBeNil)
+		client := lrmrpb.NewNodeClient(conn)
+
+		getTaskLogs := func(taskIDLen int) error {
+			_, err := client.GetTaskLogs(context.Background(), &lrmrpb.GetTaskLogsRequest{TaskID: strings.Repeat("t", taskIDLen)})
+			return err
+		}
+		logLineOf := func(lineLen int) error {
+			logs := newLogBuffer(1)
+			logs.append(strings.Repeat("l", lineLen))
+			w.taskLogs.Store("T", logs)
+			_, err := client.GetTaskLogs(context.Background(), &lrmrpb.GetTaskLogsRequest{TaskID: "T"})
+			return err
+		}
+
+		Convey("Requests near the limit should be sent", func() {
+			So(status.Code(getTaskLogs(limit-100)), ShouldEqual, codes.NotFound)
+		})
+
+		Convey("Requests over the limit should fail on the client", func() {
+			So(status.Code(getTaskLogs(limit+100)), ShouldEqual, codes.ResourceExhausted)
+		})
+
+		Convey("Responses near the limit should be sent", func() {
+			So(logLineOf(limit-100), ShouldBeNil)
+		})
+
+		Convey("Responses over the limit should fail on the server", func() {
+			So(status.Code(logLineOf(limit+100)), ShouldEqual, codes.ResourceExhausted)
+		})
+	})
+}